@@ -0,0 +1,17 @@
+/*
+Shortener-migrate переносит ссылки из одного хранилища сокращателя в другое,
+например из файла в Postgres.
+
+# Как запускать
+
+	shortener-migrate -from-file storage.txt -to-dsn postgres://... [-dry-run] [-state migrate.state]
+
+Источник и приемник задаются флагами -from-file/-from-dsn и -to-file/-to-dsn,
+хранилище выбирается так же, как в сервере. Переносятся ID, исходные URL,
+владельцы, признак удаления и ограничения срока действия.
+
+С флагом -state прогресс сохраняется в файл, и повторный запуск продолжит перенос
+с места остановки. Ссылки, которые уже есть в приемнике, пропускаются,
+а конфликтующие выводятся в лог и не прерывают перенос.
+*/
+package main
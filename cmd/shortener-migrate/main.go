@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+)
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	var from, to configs.Config
+	var opts storage.MigrateOptions
+
+	flag.StringVar(&from.FileStoragePath, "from-file", "", "source file storage path")
+	flag.StringVar(&from.DatabaseDSN, "from-dsn", "", "source database data source name")
+	flag.StringVar(&to.FileStoragePath, "to-file", "", "target file storage path")
+	flag.StringVar(&to.DatabaseDSN, "to-dsn", "", "target database data source name")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "only report what would be migrated")
+	flag.StringVar(&opts.StateFile, "state", "", "file to save progress to and resume from")
+	flag.IntVar(&opts.CheckpointEvery, "checkpoint", 1000, "save progress every N links")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer stop()
+
+	if err := run(ctx, from, to, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, from, to configs.Config, opts storage.MigrateOptions) error {
+	if from.FileStoragePath == "" && from.DatabaseDSN == "" {
+		return errors.New("source storage is not set, use -from-file or -from-dsn")
+	}
+	if to.FileStoragePath == "" && to.DatabaseDSN == "" {
+		return errors.New("target storage is not set, use -to-file or -to-dsn")
+	}
+	if from.FileStoragePath == to.FileStoragePath && from.DatabaseDSN == to.DatabaseDSN {
+		return errors.New("source and target storages are the same")
+	}
+
+	// При переносе файл синхронизируется с диском раз в секунду и при закрытии.
+	to.FileSync = "interval"
+
+	l, err := logger.New(configs.Config{LogLevel: "info", LogFormat: logger.FormatText})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = l.Sync() }()
+	zap.ReplaceGlobals(l)
+
+	src, err := storage.NewStorager(from, l)
+	if err != nil {
+		return fmt.Errorf("unable to open source storage: %w", err)
+	}
+	defer func() { _ = src.Close(context.Background()) }()
+
+	dst, err := storage.NewStorager(to, l)
+	if err != nil {
+		return fmt.Errorf("unable to open target storage: %w", err)
+	}
+	defer func() {
+		if closeErr := dst.Close(context.Background()); closeErr != nil {
+			log.Printf("unable to close target storage: %v", closeErr)
+		}
+	}()
+
+	report, err := storage.Migrate(ctx, src, dst, opts)
+
+	for _, c := range report.Conflicts {
+		log.Printf("conflict: %s: %v", c.ID, c.Err)
+	}
+	log.Printf("migrated: %d, skipped: %d, conflicts: %d, last id: %q",
+		report.Migrated, report.Skipped, len(report.Conflicts), report.LastID)
+	if opts.DryRun {
+		log.Print("dry run, nothing was written")
+	}
+
+	if err != nil {
+		return fmt.Errorf("migration stopped: %w", err)
+	}
+	return nil
+}
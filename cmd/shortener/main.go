@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"log"
 	"net"
 	"net/http"
@@ -11,17 +12,30 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/acme/autocert"
 	"google.golang.org/grpc"
 
 	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/alias"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/analytics"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/grpc/interceptors"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/grpc/shortener"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/handlers"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/middlewares"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/policy"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/routers"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/teams"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/tracing"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/urlnorm"
 	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
 )
 
@@ -37,16 +51,42 @@ func main() {
 	sigint := make(chan os.Signal, 1)
 	signal.Notify(sigint, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 
-	printInfo()
+	cfg := configs.NewConfig()
 
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(cfg, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	cfg := configs.NewConfig()
+	l, err := logger.New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	zap.ReplaceGlobals(l)
+
+	printInfo(l)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		panic(err)
+	}
 
-	s, err := storage.NewStorager(cfg)
+	s, err := storage.NewStorager(cfg, l)
 	if err != nil {
 		panic(err)
 	}
+	if c, ok := s.(interface{ Collectors() []prometheus.Collector }); ok {
+		if err = metrics.Register(c.Collectors()...); err != nil {
+			panic(err)
+		}
+	}
+	s = storage.Instrument(s)
+
+	sweepCtx, sweepCancel := context.WithCancel(context.Background())
+	go storage.SweepExpired(sweepCtx, s, cfg.ExpiredSweepInterval)
+	go storage.SweepDeleted(sweepCtx, s, cfg.TrashSweepInterval, cfg.TrashRetention)
 
 	_, n, _ := net.ParseCIDR(cfg.TrustedSubnet)
 	if n == nil {
@@ -56,14 +96,54 @@ func main() {
 		}
 	}
 
-	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, n)
+	v := alias.NewValidator(cfg)
+	un := urlnorm.NewNormalizer(cfg)
+	p, err := policy.New(cfg.PolicyFile)
+	if err != nil {
+		panic(err)
+	}
+	policyCtx, policyCancel := context.WithCancel(context.Background())
+	go p.Watch(policyCtx, cfg.PolicyReloadInterval)
+	rec := analytics.NewRecorder(s)
+	q, err := quota.NewChecker(cfg, s)
+	if err != nil {
+		panic(err)
+	}
+
+	t := teams.NewService(cfg, s)
+	acc := accounts.NewService(cfg, s)
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, n, v, un, p, rec, q, t, acc,
+		cfg.TrashRestoreWindow, cfg.TrashRetention, l)
 	a := authenticator.New(cfg)
-	m := middlewares.NewMiddlewares(cfg, a)
+	rlStore := ratelimit.NewMemoryStore()
+	rl := ratelimit.NewLimiter(cfg, rlStore)
+	m := middlewares.NewMiddlewares(cfg, a, acc, l, rl, ratelimit.NewAuthLimiter(cfg, rlStore))
 	r := routers.NewRouter(h, m)
 
+	http.Handle("/metrics", metrics.Handler())
+
+	go func() {
+		if cfg.MetricsAddress == "" {
+			return
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		srv := http.Server{
+			Addr:              cfg.MetricsAddress,
+			Handler:           mux,
+			ReadHeaderTimeout: time.Second,
+		}
+
+		if metricsErr := srv.ListenAndServe(); metricsErr != nil {
+			l.Error("metrics server error", zap.Error(metricsErr))
+		}
+	}()
+
 	go func() {
 		if cfg.PprofServerAddress == "" {
-			log.Println("pprof server address is empty, skipping")
+			l.Info("pprof server address is empty, skipping")
 			return
 		}
 
@@ -74,29 +154,44 @@ func main() {
 		var ln net.Listener
 		ln, err = net.Listen("tcp", cfg.PprofServerAddress)
 		if err != nil {
-			log.Printf("pprof listen failed: %v\n", err)
+			l.Error("pprof listen failed", zap.Error(err))
 			return
 		}
 
 		err = srv.Serve(ln)
 		if err != nil {
-			log.Printf("pprof server error: %s\n", err)
+			l.Error("pprof server error", zap.Error(err))
 		}
 	}()
 
 	go func() {
 		ln, grpcErr := net.Listen("tcp", cfg.GRPCAdress)
 		if grpcErr != nil {
-			log.Printf("listen grpc port error: %s\n", grpcErr)
+			l.Error("listen grpc port error", zap.Error(grpcErr))
 			return
 		}
 
-		i := interceptors.New(a)
-		g := grpc.NewServer(grpc.UnaryInterceptor(i.AuthUnaryInterceptor))
-		pb.RegisterShortenerServer(g, shortener.NewGRPCServer(s, cfg.EnableHTTPS, cfg.ServerBaseURL))
+		i := interceptors.New(a, acc, l, rl)
+		g := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(
+				i.TracingUnaryInterceptor,
+				i.MetricsUnaryInterceptor,
+				i.LoggingUnaryInterceptor,
+				i.AuthUnaryInterceptor,
+				i.RateLimitUnaryInterceptor,
+			),
+			grpc.ChainStreamInterceptor(
+				i.TracingStreamInterceptor,
+				i.MetricsStreamInterceptor,
+				i.LoggingStreamInterceptor,
+				i.AuthStreamInterceptor,
+				i.RateLimitStreamInterceptor,
+			),
+		)
+		pb.RegisterShortenerServer(g, shortener.NewGRPCServer(s, cfg.EnableHTTPS, cfg.ServerBaseURL, v, un, p, q, l))
 
 		if grpcErr = g.Serve(ln); grpcErr != nil {
-			log.Printf("gRPC server error: %s\n", grpcErr)
+			l.Error("gRPC server error", zap.Error(grpcErr))
 			return
 		}
 	}()
@@ -122,12 +217,21 @@ func main() {
 	go func() {
 		<-sigint
 
+		sweepCancel()
+		policyCancel()
+
 		if shutdownErr := srv.Shutdown(context.Background()); shutdownErr != nil {
-			log.Printf("error shutdown server: %v", err)
+			l.Error("error shutdown server", zap.Error(shutdownErr))
 		}
 
+		rec.Close(context.Background())
+
 		if closeErr := s.Close(context.Background()); closeErr != nil {
-			log.Printf("error close storage: %v", err)
+			l.Error("error close storage", zap.Error(closeErr))
+		}
+
+		if tracingErr := shutdownTracing(context.Background()); tracingErr != nil {
+			l.Error("error shutdown tracing", zap.Error(tracingErr))
 		}
 
 		close(shutdown)
@@ -140,11 +244,14 @@ func main() {
 
 	<-shutdown
 
-	log.Print("shutdown successful")
+	l.Info("shutdown successful")
+	_ = l.Sync()
 }
 
-func printInfo() {
-	log.Printf("Build version: %s", buildVersion)
-	log.Printf("Build date: %s", buildDate)
-	log.Printf("Build commit: %s", buildCommit)
+func printInfo(l *zap.Logger) {
+	l.Info("build info",
+		zap.String("version", buildVersion),
+		zap.String("date", buildDate),
+		zap.String("commit", buildCommit),
+	)
 }
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/postgres"
+)
+
+var errMigrateUsage = errors.New("usage: shortener [flags] migrate up [N] | down [N] | version | force VERSION")
+
+// runMigrate - выполнить команду migrate над базой данных cfg.DatabaseDSN.
+//
+//	up [N]        - применить все миграции или N следующих
+//	down [N]      - откатить последнюю миграцию или N последних
+//	version       - вывести текущую версию схемы
+//	force VERSION - записать версию схемы без выполнения миграций, чтобы снять признак dirty
+func runMigrate(cfg configs.Config, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errMigrateUsage
+	}
+	switch args[0] {
+	case "up", "down":
+	case "force":
+		if len(args) != 2 {
+			return errMigrateUsage
+		}
+	case "version":
+		if len(args) != 1 {
+			return errMigrateUsage
+		}
+	default:
+		return errMigrateUsage
+	}
+	if cfg.DatabaseDSN == "" {
+		return errors.New("database is not set, use -d or DATABASE_DSN")
+	}
+
+	var n int
+	if len(args) == 2 {
+		var err error
+		n, err = strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("%w: wrong number %q", errMigrateUsage, args[1])
+		}
+	}
+
+	m, err := postgres.NewMigrator(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		srcErr, dbErr := m.Close()
+		if srcErr != nil {
+			log.Printf("unable to close migrations source: %v", srcErr)
+		}
+		if dbErr != nil {
+			log.Printf("unable to close database: %v", dbErr)
+		}
+	}()
+
+	switch {
+	case args[0] == "up" && n == 0:
+		err = m.Up()
+	case args[0] == "up":
+		err = m.Steps(n)
+	case args[0] == "down" && n == 0:
+		err = m.Steps(-1)
+	case args[0] == "down":
+		err = m.Steps(-n)
+	case args[0] == "force":
+		err = m.Force(n)
+	}
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Print("no change")
+	} else if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		log.Print("no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("version: %d, dirty: %t", version, dirty)
+	return nil
+}
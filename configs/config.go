@@ -7,12 +7,26 @@ import (
 	"io"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// QuotaLimits - квоты пользователя на создание ссылок, 0 - без ограничений.
+type QuotaLimits struct {
+	MaxLinks   int64 `json:"max_links"`   // Максимальное количество активных ссылок.
+	DailyLinks int64 `json:"daily_links"` // Максимальное количество ссылок, созданных за сутки (UTC).
+}
+
 // Config - структура для хранения конфигурации сервера.
 type Config struct {
 	ServerAddress      string // Адрес сервера, по умолчанию ":8080".
 	PprofServerAddress string // Адрес сервера профилирования.
+	MetricsAddress     string // Адрес отдельного сервера метрик, по умолчанию метрики отдает сервер профилирования.
+	TraceExporter      string // Куда отправлять трассировку: none, stdout, otlp.
+	TraceEndpoint      string // Адрес OTLP/HTTP коллектора, по умолчанию "localhost:4318".
+	LogLevel           string // Минимальный уровень логов: debug, info, warn, error.
+	LogFormat          string // Формат логов: json или text.
 	ServerBaseURL      string // URL сервера, по умолчанию "http://localhost:8080".
 	FileStoragePath    string // Путь для файлового хранилища.
 	DatabaseDSN        string // Адрес базы данных.
@@ -21,6 +35,53 @@ type Config struct {
 	ConfigFile         string // JSON-файл, в котором хранится конфигурация.
 	TrustedSubnet      string // Доверенная сеть, из которой можно получать статистику сервиса.
 	GRPCAdress         string // Адрес сервера grpc.
+
+	SessionTTL time.Duration // Сколько действует сессия учетной записи после входа, 0 - не истекает.
+
+	ExpiredSweepInterval time.Duration // Как часто удалять ссылки с истекшим сроком действия.
+	URLDedup             string        // Режим поиска уже сокращенных URL: global, per-user, none.
+
+	DatabaseSkipMigrate bool // Не применять миграции при запуске, схему обновляет команда migrate.
+
+	TrashRestoreWindow time.Duration // Сколько времени после удаления ссылку можно восстановить.
+	TrashRetention     time.Duration // Через сколько после удаления ссылка удаляется безвозвратно, 0 - никогда.
+	TrashSweepInterval time.Duration // Как часто безвозвратно удалять ссылки, которые хранятся дольше TrashRetention.
+
+	URLAllowedSchemes []string // Схемы, которые можно сокращать.
+	URLMaxLength      int      // Максимальная длина URL, 0 - без ограничений.
+	URLStripFragment  bool     // Убирать фрагмент (#...) из URL перед сокращением.
+
+	PolicyFile           string        // JSON-файл с правилами, какие хосты можно сокращать и открывать.
+	PolicyReloadInterval time.Duration // Как часто проверять, изменился ли файл с правилами, 0 - только по SIGHUP.
+
+	RateLimit      float64 // Запросов на создание ссылок в секунду для пользователя и IP, 0 - без ограничений.
+	RateLimitBurst int     // Сколько запросов можно сделать подряд: емкость корзины токенов.
+
+	AuthRateLimit      float64 // Запросов на вход и регистрацию в секунду для IP, 0 - без ограничений.
+	AuthRateLimitBurst int     // Сколько запросов на вход и регистрацию можно сделать подряд.
+
+	Quota          QuotaLimits            // Квоты для всех пользователей.
+	QuotaOverrides map[string]QuotaLimits // Квоты отдельных пользователей, ключ - UUID пользователя.
+
+	FileCompactInterval time.Duration // Как часто сжимать файловое хранилище, 0 - не сжимать.
+	FileSnapshot        bool          // Хранить состояние файлового хранилища в отдельном снимке.
+	FileSync            string        // Режим синхронизации файлового хранилища с диском: always, interval, never.
+	FileSyncInterval    time.Duration // Период синхронизации в режиме interval.
+
+	AliasAlphabet  string   // Символы, допустимые в пользовательских ID ссылок.
+	AliasMinLength int      // Минимальная длина пользовательского ID.
+	AliasMaxLength int      // Максимальная длина пользовательского ID.
+	AliasReserved  []string // Слова, которые нельзя использовать как пользовательский ID.
+
+	IDStrategy        string  // Стратегия генерации ID: random, sequential, hashids, hash.
+	IDLength          int     // Длина генерируемого ID, для счетчиков - минимальная.
+	IDAlphabet        string  // Символы, из которых генерируются ID.
+	IDSalt            string  // Соль для стратегии hashids.
+	IDGrowthThreshold float64 // Доля коллизий, после которой длина случайных ID растет, 0 - не расти.
+
+	CacheSize        int           // Сколько ссылок держать в кэше переходов, 0 - не кэшировать.
+	CacheTTL         time.Duration // Сколько хранить найденную ссылку в кэше.
+	CacheNegativeTTL time.Duration // Сколько помнить, что ссылки нет, 0 - не запоминать.
 }
 
 // NewConfig - конструктор для Config, сам получит и запишет значения.
@@ -30,19 +91,87 @@ type Config struct {
 //  1. env-переменные
 //  2. JSON-файл с конфигурацией
 //  3. константы из исходника
+//
+// Каждый источник перекрывает только заданные в нем значения, в том числе нулевые:
+// "url_max_length": 0 в JSON-файле заменяет константу, а -rate-limit 0 - значение из файла.
 func NewConfig() Config {
-	cfg := Config{
+	return load(flag.CommandLine, os.Args[1:])
+}
+
+// load - собрать конфигурацию, разобрав аргументы args в fs.
+//
+// Путь к JSON-файлу задается в env-переменной или аргументах, поэтому сначала он ищется там,
+// а затем источники применяются от менее приоритетного к более приоритетному.
+func load(fs *flag.FlagSet, args []string) Config {
+	cfg := defaultConfig()
+	cfg.ConfigFile = configFile(args)
+
+	cfg.loadJSON()
+	cfg.loadEnv()
+	err := cfg.loadArgs(fs, args)
+	if err != nil {
+		log.Printf("unable to parse arguments: %v", err)
+	}
+
+	return cfg
+}
+
+// defaultConfig - константы из исходника.
+func defaultConfig() Config {
+	return Config{
 		ServerAddress: ":8080",
 		ServerBaseURL: "http://localhost:8080",
 		CookieKey:     []byte{14, 180, 4, 236, 208, 28, 133, 5, 116, 159, 137, 123, 80, 176, 209, 179},
+		SessionTTL:    30 * 24 * time.Hour,
 		GRPCAdress:    ":3200",
+		TraceExporter: "none",
+		TraceEndpoint: "localhost:4318",
+		LogLevel:      "info",
+		LogFormat:     "json",
+
+		ExpiredSweepInterval: time.Minute,
+		URLDedup:             "global",
+
+		TrashRestoreWindow: 7 * 24 * time.Hour,
+		TrashSweepInterval: time.Hour,
+
+		URLAllowedSchemes: []string{"http", "https"},
+		URLMaxLength:      2048,
+
+		PolicyReloadInterval: 10 * time.Second,
+
+		RateLimitBurst: 10,
+
+		AuthRateLimit:      0.1,
+		AuthRateLimitBurst: 5,
+
+		FileSync:         "always",
+		FileSyncInterval: time.Second,
+
+		AliasAlphabet:  "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_",
+		AliasMinLength: 3,
+		AliasMaxLength: 64,
+		AliasReserved:  []string{"ping", "api"},
+
+		IDStrategy:        "random",
+		IDLength:          5,
+		IDAlphabet:        "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789",
+		IDGrowthThreshold: 0.1,
+
+		CacheTTL:         time.Minute,
+		CacheNegativeTTL: 10 * time.Second,
 	}
+}
 
-	cfg.loadEnv()
-	cfg.loadArgs()
-	cfg.loadJSON()
+// configFile - путь к JSON-файлу с конфигурацией из env-переменной CONFIG или аргументов args.
+func configFile(args []string) string {
+	cfg := Config{ConfigFile: os.Getenv("CONFIG")}
 
-	return cfg
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	_ = cfg.loadArgs(fs, args) // Об ошибках в аргументах сообщит основной разбор.
+
+	return cfg.ConfigFile
 }
 
 func (cfg *Config) loadEnv() {
@@ -50,6 +179,26 @@ func (cfg *Config) loadEnv() {
 		cfg.ServerAddress = s
 	}
 
+	if s, ok := os.LookupEnv("METRICS_ADDRESS"); ok {
+		cfg.MetricsAddress = s
+	}
+
+	if s, ok := os.LookupEnv("TRACE_EXPORTER"); ok {
+		cfg.TraceExporter = s
+	}
+
+	if s, ok := os.LookupEnv("TRACE_ENDPOINT"); ok {
+		cfg.TraceEndpoint = s
+	}
+
+	if s, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		cfg.LogLevel = s
+	}
+
+	if s, ok := os.LookupEnv("LOG_FORMAT"); ok {
+		cfg.LogFormat = s
+	}
+
 	if s, ok := os.LookupEnv("BASE_URL"); ok {
 		cfg.ServerBaseURL = s
 	}
@@ -66,6 +215,10 @@ func (cfg *Config) loadEnv() {
 		cfg.EnableHTTPS = true
 	}
 
+	if _, ok := os.LookupEnv("DATABASE_SKIP_MIGRATE"); ok {
+		cfg.DatabaseSkipMigrate = true
+	}
+
 	if s, ok := os.LookupEnv("CONFIG"); ok {
 		cfg.ConfigFile = s
 	}
@@ -73,21 +226,312 @@ func (cfg *Config) loadEnv() {
 	if s, ok := os.LookupEnv("TRUSTED_SUBNET"); ok {
 		cfg.TrustedSubnet = s
 	}
+
+	if s, ok := os.LookupEnv("EXPIRED_SWEEP_INTERVAL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse EXPIRED_SWEEP_INTERVAL: %v", err)
+		} else {
+			cfg.ExpiredSweepInterval = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("TRASH_RESTORE_WINDOW"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse TRASH_RESTORE_WINDOW: %v", err)
+		} else {
+			cfg.TrashRestoreWindow = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("TRASH_RETENTION"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse TRASH_RETENTION: %v", err)
+		} else {
+			cfg.TrashRetention = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("TRASH_SWEEP_INTERVAL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse TRASH_SWEEP_INTERVAL: %v", err)
+		} else {
+			cfg.TrashSweepInterval = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("URL_DEDUP"); ok {
+		cfg.URLDedup = s
+	}
+
+	if s, ok := os.LookupEnv("URL_ALLOWED_SCHEMES"); ok {
+		cfg.URLAllowedSchemes = strings.Split(s, ",")
+	}
+
+	if s, ok := os.LookupEnv("URL_MAX_LENGTH"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Printf("unable to parse URL_MAX_LENGTH: %v", err)
+		} else {
+			cfg.URLMaxLength = n
+		}
+	}
+
+	if _, ok := os.LookupEnv("URL_STRIP_FRAGMENT"); ok {
+		cfg.URLStripFragment = true
+	}
+
+	if s, ok := os.LookupEnv("POLICY_FILE"); ok {
+		cfg.PolicyFile = s
+	}
+
+	if s, ok := os.LookupEnv("POLICY_RELOAD_INTERVAL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse POLICY_RELOAD_INTERVAL: %v", err)
+		} else {
+			cfg.PolicyReloadInterval = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("RATE_LIMIT"); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			log.Printf("unable to parse RATE_LIMIT: %v", err)
+		} else {
+			cfg.RateLimit = f
+		}
+	}
+
+	if s, ok := os.LookupEnv("SESSION_TTL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse SESSION_TTL: %v", err)
+		} else {
+			cfg.SessionTTL = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("RATE_LIMIT_BURST"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Printf("unable to parse RATE_LIMIT_BURST: %v", err)
+		} else {
+			cfg.RateLimitBurst = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("AUTH_RATE_LIMIT"); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			log.Printf("unable to parse AUTH_RATE_LIMIT: %v", err)
+		} else {
+			cfg.AuthRateLimit = f
+		}
+	}
+
+	if s, ok := os.LookupEnv("AUTH_RATE_LIMIT_BURST"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Printf("unable to parse AUTH_RATE_LIMIT_BURST: %v", err)
+		} else {
+			cfg.AuthRateLimitBurst = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("QUOTA_MAX_LINKS"); ok {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Printf("unable to parse QUOTA_MAX_LINKS: %v", err)
+		} else {
+			cfg.Quota.MaxLinks = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("QUOTA_DAILY_LINKS"); ok {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Printf("unable to parse QUOTA_DAILY_LINKS: %v", err)
+		} else {
+			cfg.Quota.DailyLinks = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("FILE_COMPACT_INTERVAL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse FILE_COMPACT_INTERVAL: %v", err)
+		} else {
+			cfg.FileCompactInterval = d
+		}
+	}
+
+	if _, ok := os.LookupEnv("FILE_SNAPSHOT"); ok {
+		cfg.FileSnapshot = true
+	}
+
+	if s, ok := os.LookupEnv("FILE_SYNC"); ok {
+		cfg.FileSync = s
+	}
+
+	if s, ok := os.LookupEnv("FILE_SYNC_INTERVAL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse FILE_SYNC_INTERVAL: %v", err)
+		} else {
+			cfg.FileSyncInterval = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("ALIAS_ALPHABET"); ok {
+		cfg.AliasAlphabet = s
+	}
+
+	if s, ok := os.LookupEnv("ALIAS_MIN_LENGTH"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Printf("unable to parse ALIAS_MIN_LENGTH: %v", err)
+		} else {
+			cfg.AliasMinLength = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("ALIAS_MAX_LENGTH"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Printf("unable to parse ALIAS_MAX_LENGTH: %v", err)
+		} else {
+			cfg.AliasMaxLength = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("ALIAS_RESERVED"); ok {
+		cfg.AliasReserved = strings.Split(s, ",")
+	}
+
+	if s, ok := os.LookupEnv("ID_STRATEGY"); ok {
+		cfg.IDStrategy = s
+	}
+
+	if s, ok := os.LookupEnv("ID_LENGTH"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Printf("unable to parse ID_LENGTH: %v", err)
+		} else {
+			cfg.IDLength = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("ID_ALPHABET"); ok {
+		cfg.IDAlphabet = s
+	}
+
+	if s, ok := os.LookupEnv("ID_SALT"); ok {
+		cfg.IDSalt = s
+	}
+
+	if s, ok := os.LookupEnv("ID_GROWTH_THRESHOLD"); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			log.Printf("unable to parse ID_GROWTH_THRESHOLD: %v", err)
+		} else {
+			cfg.IDGrowthThreshold = f
+		}
+	}
+
+	if s, ok := os.LookupEnv("CACHE_SIZE"); ok {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			log.Printf("unable to parse CACHE_SIZE: %v", err)
+		} else {
+			cfg.CacheSize = n
+		}
+	}
+
+	if s, ok := os.LookupEnv("CACHE_TTL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse CACHE_TTL: %v", err)
+		} else {
+			cfg.CacheTTL = d
+		}
+	}
+
+	if s, ok := os.LookupEnv("CACHE_NEGATIVE_TTL"); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Printf("unable to parse CACHE_NEGATIVE_TTL: %v", err)
+		} else {
+			cfg.CacheNegativeTTL = d
+		}
+	}
 }
 
-func (cfg *Config) loadArgs() {
-	flag.StringVar(&cfg.ServerAddress, "a", cfg.ServerAddress, "server address")
-	flag.StringVar(&cfg.ServerBaseURL, "b", cfg.ServerBaseURL, "server base url")
-	flag.StringVar(&cfg.FileStoragePath, "f", cfg.FileStoragePath, "file storage path")
-	flag.StringVar(&cfg.DatabaseDSN, "d", cfg.DatabaseDSN, "database data source name")
-	flag.BoolVar(&cfg.EnableHTTPS, "s", cfg.EnableHTTPS, "enable https support")
-	flag.StringVar(&cfg.ConfigFile, "c", cfg.ConfigFile, "JSON config file")
-	flag.StringVar(&cfg.ConfigFile, "config", cfg.ConfigFile, "JSON config file")
-	flag.StringVar(&cfg.TrustedSubnet, "t", cfg.TrustedSubnet, "trusted subnet")
-
-	flag.Parse()
+func (cfg *Config) loadArgs(fs *flag.FlagSet, args []string) error {
+	fs.StringVar(&cfg.ServerAddress, "a", cfg.ServerAddress, "server address")
+	fs.StringVar(&cfg.MetricsAddress, "metrics-address", cfg.MetricsAddress, "metrics server address")
+	fs.StringVar(&cfg.TraceExporter, "trace-exporter", cfg.TraceExporter, "trace exporter: none, stdout or otlp")
+	fs.StringVar(&cfg.TraceEndpoint, "trace-endpoint", cfg.TraceEndpoint, "OTLP/HTTP collector address")
+	fs.StringVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "log level: debug, info, warn or error")
+	fs.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "log format: json or text")
+	fs.StringVar(&cfg.ServerBaseURL, "b", cfg.ServerBaseURL, "server base url")
+	fs.StringVar(&cfg.FileStoragePath, "f", cfg.FileStoragePath, "file storage path")
+	fs.StringVar(&cfg.DatabaseDSN, "d", cfg.DatabaseDSN, "database data source name")
+	fs.BoolVar(&cfg.DatabaseSkipMigrate, "database-skip-migrate", cfg.DatabaseSkipMigrate,
+		"do not apply database migrations on startup")
+	fs.BoolVar(&cfg.EnableHTTPS, "s", cfg.EnableHTTPS, "enable https support")
+	fs.StringVar(&cfg.ConfigFile, "c", cfg.ConfigFile, "JSON config file")
+	fs.StringVar(&cfg.ConfigFile, "config", cfg.ConfigFile, "JSON config file")
+	fs.StringVar(&cfg.TrustedSubnet, "t", cfg.TrustedSubnet, "trusted subnet")
+	fs.DurationVar(&cfg.ExpiredSweepInterval, "expired-sweep-interval", cfg.ExpiredSweepInterval,
+		"expired links sweep interval")
+	fs.DurationVar(&cfg.TrashRestoreWindow, "trash-restore-window", cfg.TrashRestoreWindow,
+		"how long deleted links can be restored")
+	fs.DurationVar(&cfg.TrashRetention, "trash-retention", cfg.TrashRetention,
+		"how long deleted links are kept before permanent removal, 0 keeps them forever")
+	fs.DurationVar(&cfg.TrashSweepInterval, "trash-sweep-interval", cfg.TrashSweepInterval,
+		"deleted links permanent removal interval")
+	fs.StringVar(&cfg.URLDedup, "url-dedup", cfg.URLDedup, "URL deduplication mode: global, per-user or none")
+	fs.IntVar(&cfg.URLMaxLength, "url-max-length", cfg.URLMaxLength, "maximum URL length")
+	fs.BoolVar(&cfg.URLStripFragment, "url-strip-fragment", cfg.URLStripFragment, "strip fragments from URLs")
+	fs.StringVar(&cfg.PolicyFile, "policy-file", cfg.PolicyFile, "JSON file with host allow and deny rules")
+	fs.DurationVar(&cfg.PolicyReloadInterval, "policy-reload-interval", cfg.PolicyReloadInterval,
+		"policy file change check interval")
+	fs.DurationVar(&cfg.SessionTTL, "session-ttl", cfg.SessionTTL,
+		"how long account sessions last after login, 0 disables expiry")
+	fs.Float64Var(&cfg.RateLimit, "rate-limit", cfg.RateLimit, "link creation requests per second per user and IP")
+	fs.IntVar(&cfg.RateLimitBurst, "rate-limit-burst", cfg.RateLimitBurst, "link creation requests burst")
+	fs.Float64Var(&cfg.AuthRateLimit, "auth-rate-limit", cfg.AuthRateLimit,
+		"login and registration requests per second per IP")
+	fs.IntVar(&cfg.AuthRateLimitBurst, "auth-rate-limit-burst", cfg.AuthRateLimitBurst,
+		"login and registration requests burst")
+	fs.Int64Var(&cfg.Quota.MaxLinks, "quota-max-links", cfg.Quota.MaxLinks, "maximum active links per user")
+	fs.Int64Var(&cfg.Quota.DailyLinks, "quota-daily-links", cfg.Quota.DailyLinks,
+		"maximum links created per user per day")
+	fs.DurationVar(&cfg.FileCompactInterval, "file-compact-interval", cfg.FileCompactInterval,
+		"file storage compaction interval")
+	fs.BoolVar(&cfg.FileSnapshot, "file-snapshot", cfg.FileSnapshot, "keep file storage state in a snapshot")
+	fs.StringVar(&cfg.FileSync, "file-sync", cfg.FileSync, "file storage sync mode: always, interval or never")
+	fs.DurationVar(&cfg.FileSyncInterval, "file-sync-interval", cfg.FileSyncInterval,
+		"file storage sync interval")
+	fs.StringVar(&cfg.IDStrategy, "id-strategy", cfg.IDStrategy,
+		"link ID generation strategy: random, sequential, hashids or hash")
+	fs.IntVar(&cfg.IDLength, "id-length", cfg.IDLength, "generated link ID length")
+	fs.IntVar(&cfg.CacheSize, "cache-size", cfg.CacheSize, "redirect cache size in links, 0 disables the cache")
+	fs.DurationVar(&cfg.CacheTTL, "cache-ttl", cfg.CacheTTL, "how long found links are cached")
+	fs.DurationVar(&cfg.CacheNegativeTTL, "cache-negative-ttl", cfg.CacheNegativeTTL,
+		"how long unknown link IDs are cached, 0 disables negative caching")
+
+	return fs.Parse(args)
 }
 
+// loadJSON - применить значения из JSON-файла с конфигурацией поверх текущих.
+//
+// Применяются только ключи, которые есть в файле, поэтому числа и флаги хранятся указателями:
+// так явный 0 или false в файле отличается от отсутствующего ключа.
 func (cfg *Config) loadJSON() {
 	if cfg.ConfigFile == "" {
 		return
@@ -96,10 +540,64 @@ func (cfg *Config) loadJSON() {
 	c := struct {
 		ServerAddress   string `json:"server_address"`
 		BaseURL         string `json:"base_url"`
+		MetricsAddress  string `json:"metrics_address"`
+		TraceExporter   string `json:"trace_exporter"`
+		TraceEndpoint   string `json:"trace_endpoint"`
+		LogLevel        string `json:"log_level"`
+		LogFormat       string `json:"log_format"`
 		FileStoragePath string `json:"file_storage_path"`
 		DatabaseDSN     string `json:"database_dsn"`
-		EnableHTTPS     bool   `json:"enable_https"`
+		EnableHTTPS     *bool  `json:"enable_https"`
 		TrustedSubnet   string `json:"trusted_subnet"`
+		SessionTTL      string `json:"session_ttl"`
+
+		ExpiredSweepInterval string `json:"expired_sweep_interval"`
+		URLDedup             string `json:"url_dedup"`
+
+		DatabaseSkipMigrate *bool `json:"database_skip_migrate"`
+
+		TrashRestoreWindow string `json:"trash_restore_window"`
+		TrashRetention     string `json:"trash_retention"`
+		TrashSweepInterval string `json:"trash_sweep_interval"`
+
+		URLAllowedSchemes []string `json:"url_allowed_schemes"`
+		URLMaxLength      *int     `json:"url_max_length"`
+		URLStripFragment  *bool    `json:"url_strip_fragment"`
+
+		PolicyFile           string `json:"policy_file"`
+		PolicyReloadInterval string `json:"policy_reload_interval"`
+
+		RateLimit      *float64 `json:"rate_limit"`
+		RateLimitBurst *int     `json:"rate_limit_burst"`
+
+		AuthRateLimit      *float64 `json:"auth_rate_limit"`
+		AuthRateLimitBurst *int     `json:"auth_rate_limit_burst"`
+
+		Quota struct {
+			MaxLinks   *int64 `json:"max_links"`
+			DailyLinks *int64 `json:"daily_links"`
+		} `json:"quota"`
+		QuotaOverrides map[string]QuotaLimits `json:"quota_overrides"`
+
+		FileCompactInterval string `json:"file_compact_interval"`
+		FileSnapshot        *bool  `json:"file_snapshot"`
+		FileSync            string `json:"file_sync"`
+		FileSyncInterval    string `json:"file_sync_interval"`
+
+		AliasAlphabet  string   `json:"alias_alphabet"`
+		AliasMinLength *int     `json:"alias_min_length"`
+		AliasMaxLength *int     `json:"alias_max_length"`
+		AliasReserved  []string `json:"alias_reserved"`
+
+		IDStrategy        string   `json:"id_strategy"`
+		IDLength          *int     `json:"id_length"`
+		IDAlphabet        string   `json:"id_alphabet"`
+		IDSalt            string   `json:"id_salt"`
+		IDGrowthThreshold *float64 `json:"id_growth_threshold"`
+
+		CacheSize        *int   `json:"cache_size"`
+		CacheTTL         string `json:"cache_ttl"`
+		CacheNegativeTTL string `json:"cache_negative_ttl"`
 	}{}
 
 	f, err := os.Open(cfg.ConfigFile)
@@ -107,6 +605,7 @@ func (cfg *Config) loadJSON() {
 		log.Printf("unable to open config file: %v", err)
 		return
 	}
+	defer f.Close()
 
 	data, err := io.ReadAll(f)
 	if err != nil {
@@ -120,22 +619,85 @@ func (cfg *Config) loadJSON() {
 		return
 	}
 
-	if cfg.ServerAddress == "" {
-		cfg.ServerAddress = c.ServerAddress
+	setString(&cfg.ServerAddress, c.ServerAddress)
+	setString(&cfg.ServerBaseURL, c.BaseURL)
+	setString(&cfg.MetricsAddress, c.MetricsAddress)
+	setString(&cfg.TraceExporter, c.TraceExporter)
+	setString(&cfg.TraceEndpoint, c.TraceEndpoint)
+	setString(&cfg.LogLevel, c.LogLevel)
+	setString(&cfg.LogFormat, c.LogFormat)
+	setString(&cfg.FileStoragePath, c.FileStoragePath)
+	setString(&cfg.DatabaseDSN, c.DatabaseDSN)
+	setValue(&cfg.DatabaseSkipMigrate, c.DatabaseSkipMigrate)
+	setValue(&cfg.EnableHTTPS, c.EnableHTTPS)
+	setString(&cfg.TrustedSubnet, c.TrustedSubnet)
+	setDuration(&cfg.SessionTTL, c.SessionTTL, "session_ttl")
+	setDuration(&cfg.ExpiredSweepInterval, c.ExpiredSweepInterval, "expired_sweep_interval")
+	setString(&cfg.URLDedup, c.URLDedup)
+	setDuration(&cfg.TrashRestoreWindow, c.TrashRestoreWindow, "trash_restore_window")
+	setDuration(&cfg.TrashRetention, c.TrashRetention, "trash_retention")
+	setDuration(&cfg.TrashSweepInterval, c.TrashSweepInterval, "trash_sweep_interval")
+	if c.URLAllowedSchemes != nil {
+		cfg.URLAllowedSchemes = c.URLAllowedSchemes
+	}
+	setValue(&cfg.URLMaxLength, c.URLMaxLength)
+	setValue(&cfg.URLStripFragment, c.URLStripFragment)
+	setString(&cfg.PolicyFile, c.PolicyFile)
+	setDuration(&cfg.PolicyReloadInterval, c.PolicyReloadInterval, "policy_reload_interval")
+	setValue(&cfg.RateLimit, c.RateLimit)
+	setValue(&cfg.RateLimitBurst, c.RateLimitBurst)
+	setValue(&cfg.AuthRateLimit, c.AuthRateLimit)
+	setValue(&cfg.AuthRateLimitBurst, c.AuthRateLimitBurst)
+	setValue(&cfg.Quota.MaxLinks, c.Quota.MaxLinks)
+	setValue(&cfg.Quota.DailyLinks, c.Quota.DailyLinks)
+	if c.QuotaOverrides != nil {
+		cfg.QuotaOverrides = c.QuotaOverrides
 	}
-	if cfg.ServerBaseURL == "" {
-		cfg.ServerBaseURL = c.BaseURL
+	setDuration(&cfg.FileCompactInterval, c.FileCompactInterval, "file_compact_interval")
+	setValue(&cfg.FileSnapshot, c.FileSnapshot)
+	setString(&cfg.FileSync, c.FileSync)
+	setDuration(&cfg.FileSyncInterval, c.FileSyncInterval, "file_sync_interval")
+	setString(&cfg.AliasAlphabet, c.AliasAlphabet)
+	setValue(&cfg.AliasMinLength, c.AliasMinLength)
+	setValue(&cfg.AliasMaxLength, c.AliasMaxLength)
+	if c.AliasReserved != nil {
+		cfg.AliasReserved = c.AliasReserved
 	}
-	if cfg.FileStoragePath == "" {
-		cfg.FileStoragePath = c.FileStoragePath
+	setString(&cfg.IDStrategy, c.IDStrategy)
+	setValue(&cfg.IDLength, c.IDLength)
+	setString(&cfg.IDAlphabet, c.IDAlphabet)
+	setString(&cfg.IDSalt, c.IDSalt)
+	setValue(&cfg.IDGrowthThreshold, c.IDGrowthThreshold)
+
+	setValue(&cfg.CacheSize, c.CacheSize)
+	setDuration(&cfg.CacheTTL, c.CacheTTL, "cache_ttl")
+	setDuration(&cfg.CacheNegativeTTL, c.CacheNegativeTTL, "cache_negative_ttl")
+}
+
+// setString - записать в dst непустую строку из JSON-файла.
+func setString(dst *string, s string) {
+	if s != "" {
+		*dst = s
 	}
-	if cfg.DatabaseDSN == "" {
-		cfg.DatabaseDSN = c.DatabaseDSN
+}
+
+// setValue - записать в dst значение из JSON-файла, если ключ в нем есть.
+func setValue[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
 	}
-	if !cfg.EnableHTTPS {
-		cfg.EnableHTTPS = c.EnableHTTPS
+}
+
+// setDuration - записать в dst длительность из JSON-файла, key - ключ для сообщения об ошибке.
+func setDuration(dst *time.Duration, s, key string) {
+	if s == "" {
+		return
 	}
-	if cfg.TrustedSubnet == "" {
-		cfg.TrustedSubnet = c.TrustedSubnet
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		log.Printf("unable to parse %s: %v", key, err)
+		return
 	}
+	*dst = d
 }
@@ -0,0 +1,157 @@
+package configs
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"base_url": "https://short.example",
+		"url_max_length": 0,
+		"id_length": 8,
+		"cache_ttl": "5m",
+		"database_dsn": "postgres://json",
+		"file_storage_path": "/json/links.db",
+		"rate_limit": 5,
+		"quota": {"max_links": 100}
+	}`), 0o600))
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want func(cfg *Config)
+	}{
+		{
+			name: "defaults",
+			want: func(cfg *Config) {},
+		},
+		{
+			// Раньше ключи, у которых есть константа в исходнике, из JSON-файла не применялись.
+			name: "json overrides defaults",
+			args: []string{"-c", path},
+			want: func(cfg *Config) {
+				cfg.ConfigFile = path
+				cfg.ServerBaseURL = "https://short.example"
+				cfg.URLMaxLength = 0
+				cfg.IDLength = 8
+				cfg.CacheTTL = 5 * time.Minute
+				cfg.Quota.MaxLinks = 100
+				cfg.DatabaseDSN = "postgres://json"
+				cfg.FileStoragePath = "/json/links.db"
+				cfg.RateLimit = 5
+			},
+		},
+		{
+			name: "json file from env",
+			env:  map[string]string{"CONFIG": path},
+			want: func(cfg *Config) {
+				cfg.ConfigFile = path
+				cfg.ServerBaseURL = "https://short.example"
+				cfg.URLMaxLength = 0
+				cfg.IDLength = 8
+				cfg.CacheTTL = 5 * time.Minute
+				cfg.Quota.MaxLinks = 100
+				cfg.DatabaseDSN = "postgres://json"
+				cfg.FileStoragePath = "/json/links.db"
+				cfg.RateLimit = 5
+			},
+		},
+		{
+			name: "env overrides json",
+			env:  map[string]string{"CACHE_TTL": "3m", "ID_LENGTH": "6"},
+			args: []string{"-config", path},
+			want: func(cfg *Config) {
+				cfg.ConfigFile = path
+				cfg.ServerBaseURL = "https://short.example"
+				cfg.URLMaxLength = 0
+				cfg.IDLength = 6
+				cfg.CacheTTL = 3 * time.Minute
+				cfg.Quota.MaxLinks = 100
+				cfg.DatabaseDSN = "postgres://json"
+				cfg.FileStoragePath = "/json/links.db"
+				cfg.RateLimit = 5
+			},
+		},
+		{
+			name: "args override env and json",
+			env:  map[string]string{"CACHE_TTL": "3m"},
+			args: []string{"-c", path, "-cache-ttl", "2m", "-url-max-length", "512"},
+			want: func(cfg *Config) {
+				cfg.ConfigFile = path
+				cfg.ServerBaseURL = "https://short.example"
+				cfg.URLMaxLength = 512
+				cfg.IDLength = 8
+				cfg.CacheTTL = 2 * time.Minute
+				cfg.Quota.MaxLinks = 100
+				cfg.DatabaseDSN = "postgres://json"
+				cfg.FileStoragePath = "/json/links.db"
+				cfg.RateLimit = 5
+			},
+		},
+		{
+			// Как и раньше: ключи без констант в исходнике перекрываются env и аргументами.
+			name: "env and args override json without defaults",
+			env:  map[string]string{"FILE_STORAGE_PATH": "/env/links.db"},
+			args: []string{"-c", path, "-d", "postgres://args"},
+			want: func(cfg *Config) {
+				cfg.ConfigFile = path
+				cfg.ServerBaseURL = "https://short.example"
+				cfg.URLMaxLength = 0
+				cfg.IDLength = 8
+				cfg.CacheTTL = 5 * time.Minute
+				cfg.Quota.MaxLinks = 100
+				cfg.DatabaseDSN = "postgres://args"
+				cfg.FileStoragePath = "/env/links.db"
+				cfg.RateLimit = 5
+			},
+		},
+		{
+			// Раньше JSON-файл заполнял нулевые значения и заменял явный 0 из аргументов.
+			name: "explicit zero in args is kept",
+			args: []string{"-c", path, "-rate-limit", "0"},
+			want: func(cfg *Config) {
+				cfg.ConfigFile = path
+				cfg.ServerBaseURL = "https://short.example"
+				cfg.URLMaxLength = 0
+				cfg.IDLength = 8
+				cfg.CacheTTL = 5 * time.Minute
+				cfg.Quota.MaxLinks = 100
+				cfg.DatabaseDSN = "postgres://json"
+				cfg.FileStoragePath = "/json/links.db"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			cfg := load(fs, tt.args)
+
+			want := defaultConfig()
+			tt.want(&want)
+			assert.Equal(t, want, cfg)
+		})
+	}
+}
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := defaultConfig()
+
+	assert.Zero(t, cfg.TrashRetention, "deleted links are kept until the operator enables purging")
+	assert.Equal(t, time.Hour, cfg.TrashSweepInterval)
+}
@@ -0,0 +1,336 @@
+// Package accounts хранит регистрацию учетных записей, вход по паролю и API-ключи.
+//
+// Учетная запись - это такой же пользователь, как анонимный пользователь из cookie,
+// но с логином и паролем. После входа cookie подписывается для пользователя учетной записи,
+// а API-ключ позволяет действовать от его имени без cookie: ключ передается
+// в заголовке Authorization: Bearer или в метаданных gRPC authorization.
+package accounts
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Ошибки учетных записей.
+var (
+	ErrWrongLogin       = errors.New("login must be 3 to 64 characters without spaces") // Неподходящий логин.
+	ErrWeakPassword     = errors.New("password must be 8 to 72 bytes")                  // Неподходящий пароль.
+	ErrWrongCredentials = errors.New("wrong login or password")                         // Неверный логин или пароль.
+	ErrWrongKeyName     = errors.New("key name must be at most 100 characters")         // Слишком длинное название.
+	ErrWrongKey         = errors.New("wrong API key")                                   // Ключ неизвестен или отозван.
+	ErrWrongIdentity    = errors.New("wrong identity")                                  // Подпись пользователя неверна.
+	ErrNotAccount       = errors.New("account required")                                // Пользователь не зарегистрирован.
+)
+
+// Ограничения учетных записей и ключей.
+const (
+	MinLoginLength    = 3      // Минимальная длина логина в символах.
+	MaxLoginLength    = 64     // Максимальная длина логина в символах.
+	MinPasswordLength = 8      // Минимальная длина пароля в байтах.
+	MaxPasswordLength = 72     // Максимальная длина пароля в байтах, дальше bcrypt не учитывает.
+	MaxKeyNameLength  = 100    // Максимальная длина названия ключа в символах.
+	KeyPrefix         = "usk_" // С этого начинается каждый API-ключ.
+)
+
+// keyPrefixLength - сколько первых символов ключа сохраняется, чтобы его можно было узнать в списке.
+const keyPrefixLength = len(KeyPrefix) + 8
+
+// Store - хранилище учетных записей.
+type Store interface {
+	CreateAccount(ctx context.Context, account repositories.Account) error
+	GetAccount(ctx context.Context, id repositories.User) (repositories.Account, error)
+	GetAccountByLogin(ctx context.Context, login string) (repositories.Account, error)
+	CreateAPIKey(ctx context.Context, key repositories.APIKey) error
+	GetAPIKey(ctx context.Context, hash []byte) (repositories.APIKey, error)
+	GetAccountAPIKeys(ctx context.Context, account repositories.User) ([]repositories.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id repositories.KeyID, account repositories.User, at time.Time) error
+	RevokeSessions(ctx context.Context, account repositories.User, at time.Time) error
+	ClaimLinks(ctx context.Context, from, to repositories.User) ([]repositories.ID, error)
+}
+
+// Service - управление учетными записями и их API-ключами.
+type Service struct {
+	st    Store
+	a     authenticator.Authenticator
+	now   func() time.Time
+	cost  int    // Сложность bcrypt.
+	dummy []byte // Хеш, с которым сравнивается пароль неизвестного логина, чтобы время ответа не выдавало логины.
+}
+
+// NewService - конструктор для Service, сессии подписываются ключом cookie.
+func NewService(cfg configs.Config, st Store) *Service {
+	return newService(cfg, st, bcrypt.DefaultCost)
+}
+
+func newService(cfg configs.Config, st Store, cost int) *Service {
+	dummy, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), cost)
+	return &Service{
+		st:    st,
+		a:     authenticator.New(cfg),
+		now:   time.Now,
+		cost:  cost,
+		dummy: dummy,
+	}
+}
+
+// Register - зарегистрировать учетную запись.
+//
+// Логин сравнивается без учета регистра и хранится в нижнем регистре.
+func (s *Service) Register(ctx context.Context, login, password string) (repositories.Account, error) {
+	login, err := normalizeLogin(login)
+	if err != nil {
+		return repositories.Account{}, err
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return repositories.Account{}, ErrWeakPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.cost)
+	if err != nil {
+		return repositories.Account{}, err
+	}
+
+	account := repositories.Account{
+		ID:           uuid.New(),
+		Login:        login,
+		PasswordHash: hash,
+		CreatedAt:    s.now().Round(0),
+	}
+
+	err = s.st.CreateAccount(ctx, account)
+	if err != nil {
+		return repositories.Account{}, err
+	}
+
+	return account, nil
+}
+
+// Login - проверить логин и пароль.
+func (s *Service) Login(ctx context.Context, login, password string) (repositories.Account, error) {
+	account, err := s.st.GetAccountByLogin(ctx, strings.ToLower(strings.TrimSpace(login)))
+	if errors.Is(err, repositories.ErrAccountNotFound) {
+		_ = bcrypt.CompareHashAndPassword(s.dummy, []byte(password))
+		return repositories.Account{}, ErrWrongCredentials
+	}
+	if err != nil {
+		return repositories.Account{}, err
+	}
+
+	err = bcrypt.CompareHashAndPassword(account.PasswordHash, []byte(password))
+	if err != nil {
+		return repositories.Account{}, ErrWrongCredentials
+	}
+
+	return account, nil
+}
+
+// Session - новая сессия учетной записи для cookie или метаданных gRPC user.
+//
+// Вместе с сессией возвращает, когда она истечет, нулевое значение - никогда.
+func (s *Service) Session(account repositories.Account) (session string, expiresAt time.Time) {
+	issuedAt := s.now()
+	return s.a.SignSession(account.ID, issuedAt), s.a.SessionExpiresAt(issuedAt)
+}
+
+// Verify - проверить подписанного пользователя из cookie или метаданных gRPC user.
+//
+// Сессия учетной записи недействительна, если истекла или была завершена через Logout,
+// в этих случаях вернет authenticator.ErrUnauthorized.
+func (s *Service) Verify(ctx context.Context, signed string) (repositories.User, error) {
+	user, issuedAt, err := s.a.LoadSession(signed)
+	if err != nil || issuedAt.IsZero() {
+		return user, err
+	}
+
+	account, err := s.st.GetAccount(ctx, user)
+	if errors.Is(err, repositories.ErrAccountNotFound) {
+		return uuid.Nil, authenticator.ErrUnauthorized
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !issuedAt.After(account.SessionsRevokedAt) {
+		return uuid.Nil, authenticator.ErrUnauthorized
+	}
+
+	return user, nil
+}
+
+// Logout - завершить все сессии учетной записи user, выданные до этого момента.
+func (s *Service) Logout(ctx context.Context, user repositories.User) error {
+	err := s.st.RevokeSessions(ctx, user, s.now().Round(0))
+	if errors.Is(err, repositories.ErrAccountNotFound) {
+		return ErrNotAccount
+	}
+	return err
+}
+
+// Account - получить учетную запись пользователя, если он не зарегистрирован - ErrNotAccount.
+func (s *Service) Account(ctx context.Context, user repositories.User) (repositories.Account, error) {
+	account, err := s.st.GetAccount(ctx, user)
+	if errors.Is(err, repositories.ErrAccountNotFound) {
+		return repositories.Account{}, ErrNotAccount
+	}
+	return account, err
+}
+
+// CreateKey - создать API-ключ учетной записи user с правами scope.
+//
+// Сам ключ возвращается только здесь, в хранилище остается его хеш.
+func (s *Service) CreateKey(
+	ctx context.Context,
+	user repositories.User,
+	name string,
+	scope repositories.Scope,
+) (token string, key repositories.APIKey, err error) {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > MaxKeyNameLength {
+		return "", repositories.APIKey{}, ErrWrongKeyName
+	}
+	if _, err = repositories.ParseScope(string(scope)); err != nil {
+		return "", repositories.APIKey{}, err
+	}
+
+	_, err = s.Account(ctx, user)
+	if err != nil {
+		return "", repositories.APIKey{}, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", repositories.APIKey{}, err
+	}
+	token = KeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key = repositories.APIKey{
+		ID:        uuid.New(),
+		Account:   user,
+		Name:      name,
+		Prefix:    token[:keyPrefixLength],
+		Hash:      hashKey(token),
+		Scope:     scope,
+		CreatedAt: s.now().Round(0),
+	}
+
+	err = s.st.CreateAPIKey(ctx, key)
+	if err != nil {
+		return "", repositories.APIKey{}, err
+	}
+
+	return token, key, nil
+}
+
+// Keys - API-ключи учетной записи user, в том числе отозванные.
+func (s *Service) Keys(ctx context.Context, user repositories.User) ([]repositories.APIKey, error) {
+	_, err := s.Account(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.st.GetAccountAPIKeys(ctx, user)
+}
+
+// RevokeKey - отозвать API-ключ учетной записи user.
+func (s *Service) RevokeKey(ctx context.Context, user repositories.User, id repositories.KeyID) error {
+	return s.st.RevokeAPIKey(ctx, id, user, s.now().Round(0))
+}
+
+// Authenticate - найти действующий API-ключ.
+func (s *Service) Authenticate(ctx context.Context, token string) (repositories.APIKey, error) {
+	if !strings.HasPrefix(token, KeyPrefix) {
+		return repositories.APIKey{}, ErrWrongKey
+	}
+
+	key, err := s.st.GetAPIKey(ctx, hashKey(token))
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		return repositories.APIKey{}, ErrWrongKey
+	}
+	if err != nil {
+		return repositories.APIKey{}, err
+	}
+	if key.Revoked() {
+		return repositories.APIKey{}, ErrWrongKey
+	}
+
+	return key, nil
+}
+
+// Claim - передать учетной записи user личные ссылки анонимного пользователя from.
+//
+// Ссылки другой учетной записи забрать нельзя, в этом случае вернет repositories.ErrForbidden.
+func (s *Service) Claim(
+	ctx context.Context,
+	user repositories.User,
+	from repositories.User,
+) ([]repositories.ID, error) {
+	_, err := s.Account(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if from == user {
+		return []repositories.ID{}, nil
+	}
+
+	_, err = s.st.GetAccount(ctx, from)
+	if err == nil {
+		return nil, repositories.ErrForbidden
+	}
+	if !errors.Is(err, repositories.ErrAccountNotFound) {
+		return nil, err
+	}
+
+	return s.st.ClaimLinks(ctx, from, user)
+}
+
+// ClaimIdentity - то же, что Claim, но анонимный пользователь передается подписанным, как в cookie.
+func (s *Service) ClaimIdentity(
+	ctx context.Context,
+	user repositories.User,
+	identity string,
+) ([]repositories.ID, error) {
+	from, err := s.a.Load(identity)
+	if err != nil {
+		return nil, ErrWrongIdentity
+	}
+
+	return s.Claim(ctx, user, from)
+}
+
+// normalizeLogin - привести логин к нижнему регистру и проверить его.
+func normalizeLogin(login string) (string, error) {
+	login = strings.ToLower(strings.TrimSpace(login))
+
+	n := utf8.RuneCountInString(login)
+	if n < MinLoginLength || n > MaxLoginLength {
+		return "", ErrWrongLogin
+	}
+	for _, r := range login {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return "", ErrWrongLogin
+		}
+	}
+
+	return login, nil
+}
+
+// hashKey - хеш ключа, по которому он ищется в хранилище.
+//
+// Ключ случайный и длинный, поэтому медленный хеш, как для паролей, не нужен.
+func hashKey(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
@@ -0,0 +1,189 @@
+package accounts
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
+)
+
+func newTestService(t *testing.T) (*Service, *memory.MemStorage) {
+	st, err := memory.NewMemoryStorage()
+	require.NoError(t, err)
+
+	cfg := configs.Config{CookieKey: []byte("0123456789abcdef"), SessionTTL: time.Hour}
+	return newService(cfg, st, bcrypt.MinCost), st
+}
+
+func TestService_Register(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	_, err := s.Register(ctx, "ab", "password")
+	assert.ErrorIs(t, err, ErrWrongLogin)
+	_, err = s.Register(ctx, "john doe", "password")
+	assert.ErrorIs(t, err, ErrWrongLogin)
+	_, err = s.Register(ctx, "john", "short")
+	assert.ErrorIs(t, err, ErrWeakPassword)
+	_, err = s.Register(ctx, "john", strings.Repeat("a", MaxPasswordLength+1))
+	assert.ErrorIs(t, err, ErrWeakPassword)
+
+	account, err := s.Register(ctx, "  John ", "password")
+	require.NoError(t, err)
+	assert.Equal(t, "john", account.Login)
+
+	_, err = s.Register(ctx, "JOHN", "password")
+	assert.ErrorIs(t, err, repositories.ErrLoginTaken)
+
+	got, err := s.Login(ctx, "John", "password")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, got.ID)
+
+	_, err = s.Login(ctx, "john", "wrong password")
+	assert.ErrorIs(t, err, ErrWrongCredentials)
+	_, err = s.Login(ctx, "nobody", "password")
+	assert.ErrorIs(t, err, ErrWrongCredentials)
+
+	session, _ := s.Session(account)
+	user, err := s.Verify(ctx, session)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, user)
+
+	_, err = s.Account(ctx, uuid.New())
+	assert.ErrorIs(t, err, ErrNotAccount)
+}
+
+func TestService_Sessions(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+	now := time.Now().Round(0)
+	s.now = func() time.Time { return now }
+
+	account, err := s.Register(ctx, "john", "password")
+	require.NoError(t, err)
+	anonymous := uuid.New()
+
+	session, expiresAt := s.Session(account)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	tests := []struct {
+		name   string
+		signed string
+		user   uuid.UUID
+		err    error
+	}{
+		{name: "session", signed: session, user: account.ID},
+		{name: "anonymous", signed: s.a.Sign(anonymous), user: anonymous},
+		{name: "expired", signed: s.a.SignSession(account.ID, now.Add(-2*time.Hour)), err: authenticator.ErrUnauthorized},
+		{name: "not an account", signed: s.a.SignSession(anonymous, now), err: authenticator.ErrUnauthorized},
+		{name: "tampered", signed: session[:len(session)-4] + "AAA=", err: authenticator.ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := s.Verify(ctx, tt.signed)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.user, user)
+		})
+	}
+
+	assert.ErrorIs(t, s.Logout(ctx, anonymous), ErrNotAccount)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, s.Logout(ctx, account.ID))
+	_, err = s.Verify(ctx, session)
+	assert.ErrorIs(t, err, authenticator.ErrUnauthorized, "sessions issued before logout are revoked")
+
+	now = now.Add(time.Second)
+	session, _ = s.Session(account)
+	user, err := s.Verify(ctx, session)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, user, "a new login works after logout")
+}
+
+func TestService_Keys(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestService(t)
+
+	account, err := s.Register(ctx, "john", "password")
+	require.NoError(t, err)
+
+	_, _, err = s.CreateKey(ctx, uuid.New(), "ci", repositories.ScopeRead)
+	assert.ErrorIs(t, err, ErrNotAccount)
+	_, _, err = s.CreateKey(ctx, account.ID, "ci", "admin")
+	assert.ErrorIs(t, err, repositories.ErrUnknownScope)
+	_, _, err = s.CreateKey(ctx, account.ID, strings.Repeat("x", MaxKeyNameLength+1), repositories.ScopeRead)
+	assert.ErrorIs(t, err, ErrWrongKeyName)
+
+	token, key, err := s.CreateKey(ctx, account.ID, " ci ", repositories.ScopeRead)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, KeyPrefix))
+	assert.True(t, strings.HasPrefix(token, key.Prefix))
+	assert.Equal(t, "ci", key.Name)
+
+	got, err := s.Authenticate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, got.Account)
+	assert.Equal(t, repositories.ScopeRead, got.Scope)
+
+	_, err = s.Authenticate(ctx, token+"x")
+	assert.ErrorIs(t, err, ErrWrongKey)
+	_, err = s.Authenticate(ctx, "garbage")
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	err = s.RevokeKey(ctx, uuid.New(), key.ID)
+	assert.ErrorIs(t, err, repositories.ErrAPIKeyNotFound)
+	require.NoError(t, s.RevokeKey(ctx, account.ID, key.ID))
+
+	_, err = s.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	keys, err := s.Keys(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.True(t, keys[0].Revoked())
+}
+
+func TestService_Claim(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestService(t)
+	anonymous := uuid.New()
+
+	account, err := s.Register(ctx, "john", "password")
+	require.NoError(t, err)
+	other, err := s.Register(ctx, "jane", "password")
+	require.NoError(t, err)
+
+	id, err := st.Add(ctx, "https://example.com", anonymous, repositories.LinkOptions{})
+	require.NoError(t, err)
+	_, err = st.Add(ctx, "https://example.org", other.ID, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	_, err = s.Claim(ctx, anonymous, uuid.New())
+	assert.ErrorIs(t, err, ErrNotAccount)
+	_, err = s.Claim(ctx, account.ID, other.ID)
+	assert.ErrorIs(t, err, repositories.ErrForbidden)
+	_, err = s.ClaimIdentity(ctx, account.ID, "garbage")
+	assert.ErrorIs(t, err, ErrWrongIdentity)
+
+	claimed, err := s.ClaimIdentity(ctx, account.ID, s.a.Sign(anonymous))
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.ID{id}, claimed)
+
+	link, err := st.GetLink(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, link.User)
+
+	claimed, err = s.Claim(ctx, account.ID, anonymous)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
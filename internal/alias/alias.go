@@ -0,0 +1,59 @@
+// Package alias хранит проверку пользовательских ID (алиасов) для коротких ссылок.
+package alias
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+// Ошибки проверки алиаса.
+var (
+	ErrWrongLength     = errors.New("wrong alias length")       // Длина алиаса вне допустимых границ.
+	ErrWrongCharacters = errors.New("wrong alias characters")   // Алиас содержит недопустимые символы.
+	ErrReserved        = errors.New("alias is a reserved word") // Алиас совпадает с зарезервированным словом.
+)
+
+// Validator - структура для проверки алиасов.
+type Validator struct {
+	reserved  map[string]struct{}
+	alphabet  string
+	minLength int
+	maxLength int
+}
+
+// NewValidator - конструктор для Validator.
+func NewValidator(cfg configs.Config) Validator {
+	v := Validator{
+		reserved:  make(map[string]struct{}, len(cfg.AliasReserved)),
+		alphabet:  cfg.AliasAlphabet,
+		minLength: cfg.AliasMinLength,
+		maxLength: cfg.AliasMaxLength,
+	}
+
+	for _, word := range cfg.AliasReserved {
+		v.reserved[strings.ToLower(word)] = struct{}{}
+	}
+
+	return v
+}
+
+// Validate - проверить, что алиас можно использовать как ID ссылки.
+func (v Validator) Validate(alias string) error {
+	if len(alias) < v.minLength || len(alias) > v.maxLength {
+		return ErrWrongLength
+	}
+
+	for _, char := range alias {
+		if !strings.ContainsRune(v.alphabet, char) {
+			return ErrWrongCharacters
+		}
+	}
+
+	if _, ok := v.reserved[strings.ToLower(alias)]; ok {
+		return ErrReserved
+	}
+
+	return nil
+}
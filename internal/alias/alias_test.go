@@ -0,0 +1,37 @@
+package alias
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	v := NewValidator(configs.Config{
+		AliasAlphabet:  "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-",
+		AliasMinLength: 3,
+		AliasMaxLength: 16,
+		AliasReserved:  []string{"ping", "api"},
+	})
+
+	tests := []struct {
+		err   error
+		name  string
+		alias string
+	}{
+		{name: "ok", alias: "spring-sale", err: nil},
+		{name: "too short", alias: "ab", err: ErrWrongLength},
+		{name: "too long", alias: "abcdefghijklmnopq", err: ErrWrongLength},
+		{name: "wrong characters", alias: "spring_sale", err: ErrWrongCharacters},
+		{name: "slash", alias: "spring/sale", err: ErrWrongCharacters},
+		{name: "reserved", alias: "ping", err: ErrReserved},
+		{name: "reserved other case", alias: "API", err: ErrReserved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, v.Validate(tt.alias), tt.err)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Package analytics хранит асинхронную запись переходов по коротким ссылкам.
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+const (
+	clickQueueSize     = 1000
+	clickBufferSize    = 100
+	clickBufferTimeout = time.Second
+	shutdownTimeout    = 15 * time.Second
+)
+
+// Writer - хранилище, в которое Recorder сохраняет переходы.
+type Writer interface {
+	AddClicks(ctx context.Context, clicks []repositories.Click) error
+}
+
+// Recorder - структура для буферизованной асинхронной записи переходов.
+type Recorder struct {
+	w        Writer
+	clickCh  chan repositories.Click
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewRecorder - конструктор для Recorder, сразу запускает фоновую запись.
+func NewRecorder(w Writer) *Recorder {
+	r := &Recorder{
+		w:        w,
+		clickCh:  make(chan repositories.Click, clickQueueSize),
+		shutdown: make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.worker(context.Background(), clickBufferSize, clickBufferTimeout)
+
+	return r
+}
+
+// Record - поставить переход в очередь на запись.
+//
+// Не блокирует вызывающего: если очередь переполнена, переход будет потерян.
+func (r *Recorder) Record(click repositories.Click) {
+	select {
+	case r.clickCh <- click:
+	default:
+		zap.L().Warn("click queue is full, dropping click", zap.String("id", click.ID))
+	}
+}
+
+// Close - дописать накопленные переходы и остановить фоновую запись.
+func (r *Recorder) Close(_ context.Context) {
+	close(r.shutdown)
+
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		r.wg.Wait()
+	}()
+	select {
+	case <-c:
+	case <-time.After(shutdownTimeout):
+		zap.L().Warn("click recorder close timeout exceed")
+	}
+}
+
+func (r *Recorder) worker(ctx context.Context, bufferSize int, bufferTimeout time.Duration) {
+	defer r.wg.Done()
+
+	clicks := make([]repositories.Click, 0, bufferSize)
+
+worker:
+	for {
+		clicks = clicks[:0]
+
+		timeoutCtx, timeoutCancel := context.WithTimeout(ctx, bufferTimeout)
+
+	loop:
+		for {
+			select {
+			case v := <-r.clickCh:
+				clicks = append(clicks, v)
+				if len(clicks) == bufferSize {
+					break loop
+				}
+			case <-timeoutCtx.Done():
+				break loop
+			case <-r.shutdown:
+				timeoutCancel()
+				break worker
+			}
+		}
+		timeoutCancel()
+
+		r.write(ctx, clicks)
+	}
+
+	// Дописываем то, что успело накопиться в буфере и очереди к моменту остановки.
+	for {
+		for len(clicks) < bufferSize {
+			select {
+			case v := <-r.clickCh:
+				clicks = append(clicks, v)
+				continue
+			default:
+			}
+			break
+		}
+
+		r.write(ctx, clicks)
+		if len(clicks) < bufferSize {
+			return
+		}
+		clicks = clicks[:0]
+	}
+}
+
+func (r *Recorder) write(ctx context.Context, clicks []repositories.Click) {
+	if len(clicks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	if err := r.w.AddClicks(ctx, clicks); err != nil {
+		zap.L().Error("unable to write clicks", zap.Error(err))
+	}
+}
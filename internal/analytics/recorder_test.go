@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+type testWriter struct {
+	clicks  []repositories.Click
+	batches int
+	sync.Mutex
+}
+
+func (w *testWriter) AddClicks(_ context.Context, clicks []repositories.Click) error {
+	w.Lock()
+	defer w.Unlock()
+
+	w.clicks = append(w.clicks, clicks...)
+	w.batches++
+
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	w := &testWriter{}
+	r := NewRecorder(w)
+
+	count := clickBufferSize*2 + 50
+	for i := 0; i < count; i++ {
+		r.Record(repositories.Click{ID: strconv.Itoa(i), Time: time.Now()})
+	}
+
+	r.Close(context.Background())
+
+	w.Lock()
+	defer w.Unlock()
+
+	assert.Len(t, w.clicks, count)
+	assert.GreaterOrEqual(t, w.batches, 3)
+}
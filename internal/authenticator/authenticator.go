@@ -5,7 +5,11 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -15,6 +19,15 @@ import (
 // ErrUnauthorized - Пользователь не авторизован.
 var ErrUnauthorized = errors.New("unauthorized")
 
+// CookieName - имя cookie, в которой хранится подписанный пользователь.
+const CookieName = "USER"
+
+// Длины подписываемых данных: пользователь или пользователь и время выдачи сессии.
+const (
+	userLength    = 16
+	sessionLength = userLength + 8
+)
+
 // Authenticator - структура, которая содержит функции для аутентификации.
 type Authenticator struct {
 	cfg configs.Config
@@ -28,39 +41,106 @@ func New(cfg configs.Config) Authenticator {
 }
 
 // Load - функция, которая проверяет подпись строки и достает пользователя.
+//
+// Подходит и для анонимного пользователя, и для действующей сессии учетной записи.
 func (a Authenticator) Load(s string) (user uuid.UUID, err error) {
+	user, _, err = a.LoadSession(s)
+	return user, err
+}
+
+// LoadSession - проверить подпись строки и достать пользователя и время выдачи сессии.
+//
+// Для анонимного пользователя время выдачи нулевое. Сессия, выданная раньше, чем SessionTTL назад,
+// считается недействительной.
+func (a Authenticator) LoadSession(s string) (user uuid.UUID, issuedAt time.Time, err error) {
 	payload, err := base64.StdEncoding.DecodeString(s)
-	if err != nil || len(payload) < 16 {
-		return uuid.Nil, ErrUnauthorized
+	if err != nil {
+		return uuid.Nil, time.Time{}, ErrUnauthorized
 	}
 
-	h := hmac.New(sha256.New, a.cfg.CookieKey)
-	h.Write(payload[:16])
-	sign := h.Sum(nil)
+	var n int
+	switch len(payload) {
+	case userLength + sha256.Size:
+		n = userLength
+	case sessionLength + sha256.Size:
+		n = sessionLength
+	default:
+		return uuid.Nil, time.Time{}, ErrUnauthorized
+	}
 
-	if !hmac.Equal(sign, payload[16:]) {
-		return uuid.Nil, ErrUnauthorized
+	if !hmac.Equal(a.sign(payload[:n]), payload[n:]) {
+		return uuid.Nil, time.Time{}, ErrUnauthorized
 	}
 
-	user, err = uuid.FromBytes(payload[:16])
+	user, err = uuid.FromBytes(payload[:userLength])
 	if err != nil {
-		return uuid.Nil, ErrUnauthorized
+		return uuid.Nil, time.Time{}, ErrUnauthorized
+	}
+
+	if n == sessionLength {
+		issuedAt = time.Unix(0, int64(binary.BigEndian.Uint64(payload[userLength:n])))
+		if a.cfg.SessionTTL > 0 && time.Since(issuedAt) > a.cfg.SessionTTL {
+			return uuid.Nil, time.Time{}, ErrUnauthorized
+		}
 	}
 
-	return user, nil
+	return user, issuedAt, nil
 }
 
 // Gen - функция, которая генерирует нового пользователя.
 func (a Authenticator) Gen() (user uuid.UUID, signed string) {
 	user = uuid.New()
+	return user, a.Sign(user)
+}
 
+// Sign - подписать пользователя, результат можно проверить через Load.
+func (a Authenticator) Sign(user uuid.UUID) (signed string) {
 	b, _ := user.MarshalBinary()
 
+	return base64.StdEncoding.EncodeToString(append(b, a.sign(b)...))
+}
+
+// SignSession - подписать сессию пользователя user, выданную в issuedAt.
+//
+// В отличие от Sign, время выдачи входит в подпись, и сессия истекает через SessionTTL.
+func (a Authenticator) SignSession(user uuid.UUID, issuedAt time.Time) (signed string) {
+	b, _ := user.MarshalBinary()
+	b = binary.BigEndian.AppendUint64(b, uint64(issuedAt.UnixNano()))
+
+	return base64.StdEncoding.EncodeToString(append(b, a.sign(b)...))
+}
+
+// SessionExpiresAt - когда истечет сессия, выданная в issuedAt, нулевое значение - никогда.
+func (a Authenticator) SessionExpiresAt(issuedAt time.Time) time.Time {
+	if a.cfg.SessionTTL <= 0 {
+		return time.Time{}
+	}
+	return issuedAt.Add(a.cfg.SessionTTL)
+}
+
+func (a Authenticator) sign(payload []byte) []byte {
 	h := hmac.New(sha256.New, a.cfg.CookieKey)
-	h.Write(b)
-	sign := h.Sum(nil)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// Cookie - cookie с подписанным пользователем signed.
+func Cookie(signed string) *http.Cookie {
+	return &http.Cookie{
+		Name:    CookieName,
+		Value:   signed,
+		Expires: time.Now().Add(365 * 24 * time.Hour),
+		Path:    "/",
+	}
+}
 
-	signed = base64.StdEncoding.EncodeToString(append(b, sign...))
+// BearerToken - достать токен из значения заголовка Authorization вида "Bearer <токен>".
+func BearerToken(header string) (token string, ok bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
 
-	return user, signed
+	token = strings.TrimSpace(token)
+	return token, token != ""
 }
@@ -0,0 +1,343 @@
+// Package bulk хранит форматы для массовой выгрузки и загрузки ссылок: CSV и NDJSON.
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Format - формат файла выгрузки.
+type Format string
+
+// Поддерживаемые форматы.
+const (
+	CSV    Format = "csv"    // CSV с заголовком в первой строке.
+	NDJSON Format = "ndjson" // Один JSON-объект на строку.
+)
+
+// Ошибки разбора.
+var (
+	ErrUnknownFormat = errors.New("unknown format")               // Формат не поддерживается.
+	ErrNoURLColumn   = errors.New("no original_url column")       // В заголовке CSV нет колонки original_url.
+	ErrEmptyURL      = errors.New("original_url is empty")        // В строке не указан исходный URL.
+	ErrWrongValue    = errors.New("wrong value")                  // Значение колонки не удалось разобрать.
+	ErrRowTooLong    = errors.New("row exceeds max size")         // Строка длиннее допустимого.
+	ErrHeaderMissing = errors.New("csv header is missing or bad") // Не удалось прочитать заголовок CSV.
+)
+
+// ParseFormat - получить формат из строки (значения параметра format или Content-Type).
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(strings.Split(s, ";")[0])) {
+	case "csv", "text/csv":
+		return CSV, nil
+	case "ndjson", "jsonl", "application/x-ndjson", "application/jsonl":
+		return NDJSON, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// ContentType - MIME-тип формата.
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// Record - запись, которую можно выгрузить в любом из форматов.
+type Record interface {
+	csvHeader() []string
+	csvRow() []string
+}
+
+// Link - выгружаемая ссылка пользователя.
+//
+// Колонки id и team читает Reader, поэтому выгрузку можно загрузить обратно с теми же ID.
+type Link struct {
+	ExpiresAt   *time.Time           `json:"expires_at,omitempty"` // Время, после которого ссылка перестает работать.
+	Team        *repositories.TeamID `json:"team,omitempty"`       // Команда, которой принадлежит ссылка.
+	ID          repositories.ID      `json:"id"`                   // ID короткой ссылки.
+	ShortURL    repositories.URL     `json:"short_url"`            // Сокращенный URL.
+	OriginalURL repositories.URL     `json:"original_url"`         // Исходный URL.
+	MaxHits     uint64               `json:"max_hits,omitempty"`   // Максимальное количество переходов по ссылке.
+	Hits        uint64               `json:"hits,omitempty"`       // Количество переходов по ссылке с ограничением.
+}
+
+func (Link) csvHeader() []string {
+	return []string{"id", "short_url", "original_url", "expires_at", "max_hits", "hits", "team"}
+}
+
+func (l Link) csvRow() []string {
+	team := ""
+	if l.Team != nil {
+		team = l.Team.String()
+	}
+	return []string{
+		l.ID, l.ShortURL, l.OriginalURL, formatTime(l.ExpiresAt), formatUint(l.MaxHits), formatUint(l.Hits), team,
+	}
+}
+
+// Статусы результата загрузки строки.
+const (
+	StatusCreated = "created" // Ссылка создана.
+	StatusExists  = "exists"  // Ссылка на этот URL уже была, возвращена существующая.
+	StatusError   = "error"   // Строку не удалось загрузить, причина в поле Error.
+)
+
+// Result - результат загрузки одной строки.
+type Result struct {
+	Row         int              `json:"row"`                 // Номер строки данных, начиная с 1.
+	OriginalURL repositories.URL `json:"original_url"`        // Исходный URL.
+	ShortURL    repositories.URL `json:"short_url,omitempty"` // Сокращенный URL.
+	Status      string           `json:"status"`              // Статус: created, exists или error.
+	Error       string           `json:"error,omitempty"`     // Причина ошибки.
+}
+
+func (Result) csvHeader() []string {
+	return []string{"row", "original_url", "short_url", "status", "error"}
+}
+
+func (r Result) csvRow() []string {
+	return []string{strconv.Itoa(r.Row), r.OriginalURL, r.ShortURL, r.Status, r.Error}
+}
+
+// Writer - запись Record в выбранном формате.
+type Writer struct {
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool
+}
+
+// NewWriter - конструктор Writer.
+func NewWriter(w io.Writer, f Format) *Writer {
+	if f == CSV {
+		return &Writer{csv: csv.NewWriter(w)}
+	}
+	return &Writer{json: json.NewEncoder(w)}
+}
+
+// Write - записать одну запись. В CSV перед первой записью пишется заголовок.
+func (w *Writer) Write(r Record) error {
+	if w.json != nil {
+		return w.json.Encode(r)
+	}
+
+	if !w.header {
+		w.header = true
+		if err := w.csv.Write(r.csvHeader()); err != nil {
+			return err
+		}
+	}
+	return w.csv.Write(r.csvRow())
+}
+
+// Flush - дописать буферизованные данные.
+func (w *Writer) Flush() error {
+	if w.csv == nil {
+		return nil
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// Row - загружаемая строка.
+//
+// Если alias не задан, желаемым ID считается колонка id из выгрузки.
+type Row struct {
+	ExpiresAt   *time.Time          `json:"expires_at,omitempty"` // Время, после которого ссылка перестает работать.
+	OriginalURL repositories.URL    `json:"original_url"`         // Исходный URL.
+	Alias       repositories.ID     `json:"alias,omitempty"`      // Желаемый ID короткой ссылки.
+	MaxHits     uint64              `json:"max_hits,omitempty"`   // Максимальное количество переходов по ссылке.
+	Team        repositories.TeamID `json:"team,omitempty"`       // Команда, которой будет принадлежать ссылка.
+}
+
+// RowError - ошибка в отдельной строке, после нее чтение можно продолжать.
+type RowError struct {
+	Row int   // Номер строки данных, начиная с 1.
+	Err error // Причина.
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// MaxRowSize - максимальный размер строки NDJSON.
+const MaxRowSize = 64 * 1024
+
+// Reader - чтение строк Row в выбранном формате.
+type Reader struct {
+	csv     *csv.Reader
+	columns map[string]int
+	lines   *lineReader
+	row     int
+}
+
+// NewReader - конструктор Reader.
+func NewReader(r io.Reader, f Format) *Reader {
+	if f == CSV {
+		c := csv.NewReader(r)
+		c.FieldsPerRecord = -1
+		c.ReuseRecord = true
+		return &Reader{csv: c}
+	}
+	return &Reader{lines: newLineReader(r, MaxRowSize)}
+}
+
+// Row - номер последней прочитанной строки данных.
+func (r *Reader) Row() int {
+	return r.row
+}
+
+// Read - прочитать следующую строку.
+//
+// В конце данных вернет io.EOF. Ошибки в отдельных строках возвращаются как *RowError,
+// остальные ошибки означают, что продолжать чтение нельзя.
+func (r *Reader) Read() (Row, error) {
+	if r.csv != nil {
+		return r.readCSV()
+	}
+	return r.readNDJSON()
+}
+
+func (r *Reader) readCSV() (Row, error) {
+	if r.columns == nil {
+		header, err := r.csv.Read()
+		if errors.Is(err, io.EOF) {
+			return Row{}, io.EOF
+		}
+		if err != nil {
+			return Row{}, ErrHeaderMissing
+		}
+		r.columns = make(map[string]int, len(header))
+		for i, name := range header {
+			r.columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+		if _, ok := r.columns["original_url"]; !ok {
+			return Row{}, ErrNoURLColumn
+		}
+	}
+
+	record, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return Row{}, io.EOF
+	}
+	r.row++
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Row{}, &RowError{Row: r.row, Err: parseErr.Err}
+	}
+	if err != nil {
+		return Row{}, err
+	}
+
+	get := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := Row{
+		OriginalURL: get("original_url"),
+		Alias:       get("alias"),
+	}
+	if row.Alias == "" {
+		row.Alias = get("id")
+	}
+
+	if s := get("team"); s != "" {
+		row.Team, err = uuid.Parse(s)
+		if err != nil {
+			return row, &RowError{Row: r.row, Err: fmt.Errorf("%w: team", ErrWrongValue)}
+		}
+	}
+
+	if s := get("expires_at"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return row, &RowError{Row: r.row, Err: fmt.Errorf("%w: expires_at", ErrWrongValue)}
+		}
+		row.ExpiresAt = &t
+	}
+
+	if s := get("max_hits"); s != "" {
+		row.MaxHits, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return row, &RowError{Row: r.row, Err: fmt.Errorf("%w: max_hits", ErrWrongValue)}
+		}
+	}
+
+	return row, r.checkRow(row)
+}
+
+func (r *Reader) readNDJSON() (Row, error) {
+	for {
+		line, err := r.lines.next()
+		if errors.Is(err, io.EOF) {
+			return Row{}, io.EOF
+		}
+		if errors.Is(err, ErrRowTooLong) {
+			r.row++
+			return Row{}, &RowError{Row: r.row, Err: err}
+		}
+		if err != nil {
+			return Row{}, err
+		}
+
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		r.row++
+
+		var v struct {
+			Row
+			ID repositories.ID `json:"id"`
+		}
+		err = json.Unmarshal(line, &v)
+		if err != nil {
+			return Row{}, &RowError{Row: r.row, Err: fmt.Errorf("%w: %v", ErrWrongValue, err)}
+		}
+		if v.Alias == "" {
+			v.Alias = v.ID
+		}
+
+		return v.Row, r.checkRow(v.Row)
+	}
+}
+
+func (r *Reader) checkRow(row Row) error {
+	if row.OriginalURL == "" {
+		return &RowError{Row: r.row, Err: ErrEmptyURL}
+	}
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatUint(n uint64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatUint(n, 10)
+}
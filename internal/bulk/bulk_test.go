@@ -0,0 +1,172 @@
+package bulk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Format
+		wantErr bool
+	}{
+		{in: "csv", want: CSV},
+		{in: "text/csv; charset=utf-8", want: CSV},
+		{in: "NDJSON", want: NDJSON},
+		{in: "application/x-ndjson", want: NDJSON},
+		{in: "application/json", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseFormat(tt.in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownFormat)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// readAll - прочитать все строки, ошибки строк вернуть отдельно.
+func readAll(t *testing.T, r *Reader) (rows []Row, rowErrs map[int]error) {
+	rowErrs = make(map[int]error)
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, rowErrs
+		}
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrs[rowErr.Row] = rowErr.Err
+			continue
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestReader_CSV(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		in := "alias,Original_URL,max_hits,expires_at,comment\n" +
+			"one,https://example.com/1,,,\n" +
+			"two,https://example.com/2,3,2030-01-02T03:04:05Z\n" +
+			"three,https://example.com/3,many,,\n" +
+			",,,,\n" +
+			"\"broken,https://example.com/4\n"
+
+		rows, rowErrs := readAll(t, NewReader(strings.NewReader(in), CSV))
+
+		expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		assert.Equal(t, []Row{
+			{OriginalURL: "https://example.com/1", Alias: "one"},
+			{OriginalURL: "https://example.com/2", Alias: "two", MaxHits: 3, ExpiresAt: &expiresAt},
+		}, rows)
+		assert.ErrorIs(t, rowErrs[3], ErrWrongValue)
+		assert.ErrorIs(t, rowErrs[4], ErrEmptyURL)
+		assert.Error(t, rowErrs[5])
+	})
+
+	t.Run("id and team", func(t *testing.T) {
+		in := "id,alias,original_url,team\n" +
+			"one,,https://example.com/1,\n" +
+			"two,alias,https://example.com/2,\n" +
+			"three,,https://example.com/3,wrong\n"
+
+		rows, rowErrs := readAll(t, NewReader(strings.NewReader(in), CSV))
+
+		assert.Equal(t, []Row{
+			{OriginalURL: "https://example.com/1", Alias: "one"},
+			{OriginalURL: "https://example.com/2", Alias: "alias"},
+		}, rows)
+		assert.ErrorIs(t, rowErrs[3], ErrWrongValue)
+	})
+
+	t.Run("no url column", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader("url\nhttps://example.com\n"), CSV).Read()
+		assert.ErrorIs(t, err, ErrNoURLColumn)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := NewReader(strings.NewReader(""), CSV).Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestReader_NDJSON(t *testing.T) {
+	in := `{"original_url": "https://example.com/1", "alias": "one"}` + "\n" +
+		"\n" +
+		`{"original_url": "https://example.com/` + strings.Repeat("a", MaxRowSize) + `"}` + "\n" +
+		`{"original_url": 1}` + "\n" +
+		`{"original_url": "https://example.com/2", "max_hits": 3}` + "\n" +
+		`{"original_url": "https://example.com/3", "id": "three"}`
+
+	rows, rowErrs := readAll(t, NewReader(strings.NewReader(in), NDJSON))
+
+	assert.Equal(t, []Row{
+		{OriginalURL: "https://example.com/1", Alias: "one"},
+		{OriginalURL: "https://example.com/2", MaxHits: 3},
+		{OriginalURL: "https://example.com/3", Alias: "three"},
+	}, rows)
+	assert.ErrorIs(t, rowErrs[2], ErrRowTooLong)
+	assert.ErrorIs(t, rowErrs[3], ErrWrongValue)
+}
+
+func TestWriter(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	team := uuid.MustParse("8b5d6a2e-7f4c-4c1e-9a55-0f3b7d2c1e90")
+	links := []Link{
+		{ID: "one", ShortURL: "http://localhost/one", OriginalURL: "https://example.com/1"},
+		{
+			ID: "two", ShortURL: "http://localhost/two", OriginalURL: "https://example.com/2",
+			ExpiresAt: &expiresAt, MaxHits: 3, Hits: 1, Team: &team,
+		},
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: CSV,
+			want: "id,short_url,original_url,expires_at,max_hits,hits,team\n" +
+				"one,http://localhost/one,https://example.com/1,,,,\n" +
+				"two,http://localhost/two,https://example.com/2,2030-01-02T03:04:05Z,3,1," + team.String() + "\n",
+		},
+		{
+			format: NDJSON,
+			want: `{"id":"one","short_url":"http://localhost/one","original_url":"https://example.com/1"}` + "\n" +
+				`{"expires_at":"2030-01-02T03:04:05Z","team":"` + team.String() + `","id":"two",` +
+				`"short_url":"http://localhost/two","original_url":"https://example.com/2","max_hits":3,"hits":1}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf, tt.format)
+			for _, link := range links {
+				require.NoError(t, w.Write(link))
+			}
+			require.NoError(t, w.Flush())
+			assert.Equal(t, tt.want, buf.String())
+
+			rows, rowErrs := readAll(t, NewReader(&buf, tt.format))
+			assert.Empty(t, rowErrs)
+			assert.Equal(t, []Row{
+				{OriginalURL: "https://example.com/1", Alias: "one"},
+				{OriginalURL: "https://example.com/2", Alias: "two", MaxHits: 3, ExpiresAt: &expiresAt, Team: team},
+			}, rows, "export is read back with the same IDs")
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package bulk
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// lineReader - построчное чтение с ограничением длины строки.
+//
+// В отличие от bufio.Scanner, после слишком длинной строки чтение можно продолжить.
+type lineReader struct {
+	r *bufio.Reader
+}
+
+func newLineReader(r io.Reader, maxSize int) *lineReader {
+	return &lineReader{r: bufio.NewReaderSize(r, maxSize)}
+}
+
+// next - прочитать следующую строку без перевода строки.
+//
+// Слишком длинная строка пропускается целиком, возвращается ErrRowTooLong.
+func (l *lineReader) next() ([]byte, error) {
+	line, err := l.r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		for errors.Is(err, bufio.ErrBufferFull) {
+			_, err = l.r.ReadSlice('\n')
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return nil, ErrRowTooLong
+	}
+	if errors.Is(err, io.EOF) && len(line) > 0 {
+		return line, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return line[:len(line)-1], nil
+}
@@ -3,22 +3,61 @@ package interceptors
 import (
 	"context"
 	"errors"
-	"log"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/utils"
+	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
 )
 
+// readMethods - методы, которые доступны API-ключу только для чтения.
+var readMethods = map[string]struct{}{
+	pb.Shortener_Ping_FullMethodName:              {},
+	pb.Shortener_Get_FullMethodName:               {},
+	pb.Shortener_GetLinks_FullMethodName:          {},
+	pb.Shortener_GetDeletionStatus_FullMethodName: {},
+	pb.Shortener_GetStats_FullMethodName:          {},
+	pb.Shortener_GetLinkStats_FullMethodName:      {},
+	pb.Shortener_Export_FullMethodName:            {},
+	pb.Shortener_GetQuota_FullMethodName:          {},
+}
+
 // AuthUnaryInterceptor отвечает за аутентификацию grpc-клиентов.
 func (i interceptors) AuthUnaryInterceptor(ctx context.Context,
-	req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
 ) (resp interface{}, err error) {
+	ctx, err = i.auth(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AuthStreamInterceptor отвечает за аутентификацию grpc-клиентов в потоковых запросах.
+func (i interceptors) AuthStreamInterceptor(srv interface{},
+	ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	ctx, err := i.auth(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// auth - получить пользователя из метаданных запроса или выдать нового.
+//
+// Если в метаданных есть authorization: Bearer, пользователь определяется по API-ключу,
+// а ключу только для чтения доступны лишь методы из readMethods.
+func (i interceptors) auth(ctx context.Context, method string) (context.Context, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, status.Errorf(codes.InvalidArgument, "Retrieving metadata is failed")
@@ -26,16 +65,27 @@ func (i interceptors) AuthUnaryInterceptor(ctx context.Context,
 
 	var user uuid.UUID
 	var signed string
+	var err error
+
+	if authorization := md.Get("authorization"); len(authorization) > 0 {
+		user, err = i.apiKeyUser(ctx, authorization[0], method)
+		if err != nil {
+			return nil, err
+		}
+		logger.SetUser(ctx, user)
+		return context.WithValue(ctx, utils.ContextKey("userID"), user), nil
+	}
 
 	authHeader, ok := md["user"]
 	if !ok {
 		user, signed = i.a.Gen()
 	} else {
 		token := authHeader[0]
-		user, err = i.a.Load(token)
+		user, err = i.loadUser(ctx, token)
 		if errors.Is(err, authenticator.ErrUnauthorized) {
 			user, signed = i.a.Gen()
 		} else if err != nil {
+			logger.Ctx(ctx, i.logger).Error("session check failed", zap.Error(err))
 			return nil, status.Errorf(codes.Internal, "Server error")
 		}
 	}
@@ -43,10 +93,57 @@ func (i interceptors) AuthUnaryInterceptor(ctx context.Context,
 	if len(signed) > 0 {
 		err = grpc.SendHeader(ctx, metadata.Pairs("user", signed))
 		if err != nil {
-			log.Printf("unable to send metadata: %v", err)
+			logger.Ctx(ctx, i.logger).Warn("unable to send metadata", zap.Error(err))
 		}
 	}
 
-	ctx = context.WithValue(ctx, utils.ContextKey("userID"), user)
-	return handler(ctx, req)
+	logger.SetUser(ctx, user)
+
+	return context.WithValue(ctx, utils.ContextKey("userID"), user), nil
+}
+
+// loadUser - проверить подписанного пользователя, без acc сессии учетных записей не проверяются на выход.
+func (i interceptors) loadUser(ctx context.Context, signed string) (uuid.UUID, error) {
+	if i.acc == nil {
+		return i.a.Load(signed)
+	}
+	return i.acc.Verify(ctx, signed)
+}
+
+// apiKeyUser - определить пользователя по API-ключу из метаданных authorization.
+func (i interceptors) apiKeyUser(ctx context.Context, authorization, method string) (uuid.UUID, error) {
+	token, ok := authenticator.BearerToken(authorization)
+
+	var key repositories.APIKey
+	err := accounts.ErrWrongKey
+	if ok && i.acc != nil {
+		key, err = i.acc.Authenticate(ctx, token)
+	}
+	if errors.Is(err, accounts.ErrWrongKey) {
+		return uuid.Nil, status.Errorf(codes.Unauthenticated, "Wrong API key")
+	}
+	if err != nil {
+		logger.Ctx(ctx, i.logger).Error("API key check failed", zap.Error(err))
+		return uuid.Nil, status.Errorf(codes.Internal, "Server error")
+	}
+
+	need := repositories.ScopeWrite
+	if _, ok = readMethods[method]; ok {
+		need = repositories.ScopeRead
+	}
+	if !key.Scope.Allows(need) {
+		return uuid.Nil, status.Errorf(codes.PermissionDenied, "API key scope %q is not enough", key.Scope)
+	}
+
+	return key.Account, nil
+}
+
+// contextStream - поток с подмененным контекстом: с пользователем или со спаном трассировки.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
 }
@@ -0,0 +1,149 @@
+package interceptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
+)
+
+func TestInterceptors_AuthAPIKey(t *testing.T) {
+	cfg := configs.Config{
+		CookieKey: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+	acc := accounts.NewService(cfg, s)
+
+	ctx := context.Background()
+	account, err := acc.Register(ctx, "john", "password")
+	require.NoError(t, err)
+	readKey, _, err := acc.CreateKey(ctx, account.ID, "read", repositories.ScopeRead)
+	require.NoError(t, err)
+	writeKey, _, err := acc.CreateKey(ctx, account.ID, "write", repositories.ScopeWrite)
+	require.NoError(t, err)
+
+	i := New(authenticator.New(cfg), acc, zap.NewNop(), nil)
+
+	tests := []struct {
+		name          string
+		method        string
+		stream        bool
+		authorization string
+		code          codes.Code
+	}{
+		{
+			name:          "read key reads",
+			method:        pb.Shortener_Get_FullMethodName,
+			authorization: "Bearer " + readKey,
+			code:          codes.OK,
+		},
+		{
+			name:          "read key exports",
+			method:        pb.Shortener_Export_FullMethodName,
+			stream:        true,
+			authorization: "Bearer " + readKey,
+			code:          codes.OK,
+		},
+		{
+			name:          "read key shortens",
+			method:        pb.Shortener_Short_FullMethodName,
+			authorization: "Bearer " + readKey,
+			code:          codes.PermissionDenied,
+		},
+		{
+			name:          "read key deletes",
+			method:        pb.Shortener_Delete_FullMethodName,
+			authorization: "Bearer " + readKey,
+			code:          codes.PermissionDenied,
+		},
+		{
+			name:          "read key streams links",
+			method:        pb.Shortener_StreamShort_FullMethodName,
+			stream:        true,
+			authorization: "Bearer " + readKey,
+			code:          codes.PermissionDenied,
+		},
+		{
+			name:          "read key imports",
+			method:        pb.Shortener_Import_FullMethodName,
+			stream:        true,
+			authorization: "Bearer " + readKey,
+			code:          codes.PermissionDenied,
+		},
+		{
+			name:          "write key shortens",
+			method:        pb.Shortener_Short_FullMethodName,
+			authorization: "Bearer " + writeKey,
+			code:          codes.OK,
+		},
+		{
+			name:          "write key imports",
+			method:        pb.Shortener_Import_FullMethodName,
+			stream:        true,
+			authorization: "Bearer " + writeKey,
+			code:          codes.OK,
+		},
+		{
+			name:          "wrong key",
+			method:        pb.Shortener_Get_FullMethodName,
+			authorization: "Bearer " + accounts.KeyPrefix + "wrong",
+			code:          codes.Unauthenticated,
+		},
+		{
+			name:          "not a bearer token",
+			method:        pb.Shortener_Get_FullMethodName,
+			authorization: "Basic " + readKey,
+			code:          codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs("authorization", tt.authorization))
+			var user uuid.UUID
+			var err error
+			called := false
+
+			if tt.stream {
+				err = i.AuthStreamInterceptor(nil, &testStream{ctx: ctx},
+					&grpc.StreamServerInfo{FullMethod: tt.method},
+					func(_ interface{}, ss grpc.ServerStream) error {
+						called = true
+						user, _ = authenticator.GetUser(ss.Context())
+						return nil
+					})
+			} else {
+				_, err = i.AuthUnaryInterceptor(ctx, nil,
+					&grpc.UnaryServerInfo{FullMethod: tt.method},
+					func(ctx context.Context, _ interface{}) (interface{}, error) {
+						called = true
+						user, _ = authenticator.GetUser(ctx)
+						return nil, nil
+					})
+			}
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.code == codes.OK, called)
+			if called {
+				assert.Equal(t, account.ID, user)
+			}
+		})
+	}
+}
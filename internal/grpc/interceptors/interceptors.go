@@ -1,15 +1,32 @@
 // Package interceptors хранит interceptors для grpc.
 package interceptors
 
-import "github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+import (
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
+)
 
 type interceptors struct {
-	a authenticator.Authenticator
+	a       authenticator.Authenticator
+	acc     *accounts.Service
+	logger  *zap.Logger
+	limiter *ratelimit.Limiter
 }
 
-// New - конструктор interceptors.
-func New(a authenticator.Authenticator) interceptors {
+// New - конструктор interceptors, acc проверяет API-ключи клиентов.
+func New(
+	a authenticator.Authenticator,
+	acc *accounts.Service,
+	l *zap.Logger,
+	limiter *ratelimit.Limiter,
+) interceptors {
 	return interceptors{
-		a: a,
+		a:       a,
+		acc:     acc,
+		logger:  l,
+		limiter: limiter,
 	}
 }
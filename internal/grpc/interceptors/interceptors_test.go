@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// testStream - серверный поток без транспорта: отдает messages сообщений и запоминает метаданные.
+//
+// Если headerSent, заголовки уже отправлены и SetHeader возвращает ошибку, как настоящий поток.
+type testStream struct {
+	grpc.ServerStream
+	ctx        context.Context
+	messages   int
+	headerSent bool
+	header     metadata.MD
+	trailer    metadata.MD
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) SetHeader(md metadata.MD) error {
+	if s.headerSent {
+		return errors.New("header already sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *testStream) SetTrailer(md metadata.MD) {
+	s.trailer = metadata.Join(s.trailer, md)
+}
+
+func (s *testStream) RecvMsg(interface{}) error {
+	if s.messages == 0 {
+		return io.EOF
+	}
+	s.messages--
+	return nil
+}
@@ -0,0 +1,59 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+)
+
+// LoggingUnaryInterceptor присваивает запросу ID и пишет строку лога по его завершении.
+func (i interceptors) LoggingUnaryInterceptor(ctx context.Context,
+	req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	start := time.Now()
+	ctx = i.withRequestID(ctx)
+	resp, err := handler(ctx, req)
+	i.logRequest(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+// LoggingStreamInterceptor присваивает потоковому запросу ID и пишет строку лога по его завершении.
+func (i interceptors) LoggingStreamInterceptor(srv interface{},
+	ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	ctx := i.withRequestID(ss.Context())
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	i.logRequest(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// withRequestID - взять ID запроса из метаданных x-request-id или сгенерировать новый.
+func (i interceptors) withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("x-request-id"); len(v) > 0 {
+			requestID = v[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	return logger.WithRequest(ctx, requestID)
+}
+
+// logRequest - записать строку лога, аналогичную access-логу HTTP.
+func (i interceptors) logRequest(ctx context.Context, method string, start time.Time, err error) {
+	logger.Ctx(ctx, i.logger).Info("grpc request",
+		zap.String("method", method),
+		zap.String("code", status.Code(err).String()),
+		zap.Duration("duration", time.Since(start)),
+	)
+}
@@ -0,0 +1,31 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
+)
+
+// MetricsUnaryInterceptor считает количество и время выполнения grpc-вызовов.
+func (i interceptors) MetricsUnaryInterceptor(ctx context.Context,
+	req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	start := time.Now()
+	resp, err = handler(ctx, req)
+	metrics.ObserveGRPCRequest(info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
+
+// MetricsStreamInterceptor считает количество и время выполнения потоковых grpc-вызовов.
+func (i interceptors) MetricsStreamInterceptor(srv interface{},
+	ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	metrics.ObserveGRPCRequest(info.FullMethod, status.Code(err), time.Since(start))
+	return err
+}
@@ -0,0 +1,115 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
+	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
+)
+
+// counterValue - значение счетчика name с метками labels из metrics.Registry.
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			match := len(m.GetLabel()) == len(labels)
+			for _, l := range m.GetLabel() {
+				if labels[l.GetName()] != l.GetValue() {
+					match = false
+				}
+			}
+			if match {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestInterceptors_Metrics(t *testing.T) {
+	cfg := configs.Config{}
+	i := New(authenticator.New(cfg), nil, zap.NewNop(), nil)
+
+	tests := []struct {
+		name   string
+		method string
+		stream bool
+		err    error
+		code   string
+	}{
+		{
+			name:   "unary call",
+			method: pb.Shortener_Short_FullMethodName,
+			code:   "OK",
+		},
+		{
+			name:   "unary error status",
+			method: pb.Shortener_Get_FullMethodName,
+			err:    status.Error(codes.NotFound, "Not found"),
+			code:   "NotFound",
+		},
+		{
+			name:   "plain error",
+			method: pb.Shortener_Get_FullMethodName,
+			err:    errors.New("storage is down"),
+			code:   "Unknown",
+		},
+		{
+			name:   "stream call",
+			method: pb.Shortener_Export_FullMethodName,
+			stream: true,
+			code:   "OK",
+		},
+		{
+			name:   "stream error status",
+			method: pb.Shortener_StreamShort_FullMethodName,
+			stream: true,
+			err:    status.Error(codes.ResourceExhausted, "quota exceeded"),
+			code:   "ResourceExhausted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{"method": tt.method, "code": tt.code}
+			before := counterValue(t, "urlshortener_grpc_requests_total", labels)
+
+			var err error
+			if tt.stream {
+				err = i.MetricsStreamInterceptor(nil, &testStream{ctx: context.Background()},
+					&grpc.StreamServerInfo{FullMethod: tt.method},
+					func(interface{}, grpc.ServerStream) error {
+						return tt.err
+					})
+			} else {
+				_, err = i.MetricsUnaryInterceptor(context.Background(), nil,
+					&grpc.UnaryServerInfo{FullMethod: tt.method},
+					func(context.Context, interface{}) (interface{}, error) {
+						return nil, tt.err
+					})
+			}
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, before+1, counterValue(t, "urlshortener_grpc_requests_total", labels))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
+	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
+)
+
+// rateLimitedMethods - методы, которые создают ссылки и поэтому ограничены по частоте.
+var rateLimitedMethods = map[string]struct{}{
+	pb.Shortener_Short_FullMethodName:      {},
+	pb.Shortener_BatchShort_FullMethodName: {},
+}
+
+// rateLimitedStreams - потоковые методы, каждое сообщение которых создает ссылку.
+var rateLimitedStreams = map[string]struct{}{
+	pb.Shortener_StreamShort_FullMethodName: {},
+	pb.Shortener_Import_FullMethodName:      {},
+}
+
+// RateLimitUnaryInterceptor ограничивает частоту вызовов, создающих ссылки, для пользователя и его IP.
+//
+// Должен стоять после AuthUnaryInterceptor. При превышении лимита возвращает ResourceExhausted
+// и передает в заголовке retry-after, через сколько секунд вызов можно повторить.
+func (i interceptors) RateLimitUnaryInterceptor(ctx context.Context,
+	req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if _, ok := rateLimitedMethods[info.FullMethod]; !ok {
+		return handler(ctx, req)
+	}
+
+	err := i.allow(ctx, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// RateLimitStreamInterceptor ограничивает частоту сообщений в потоках, создающих ссылки.
+//
+// Должен стоять после AuthStreamInterceptor. Каждое полученное сообщение расходует лимит так же,
+// как один унарный вызов. При превышении лимита получение сообщения завершается ResourceExhausted.
+func (i interceptors) RateLimitStreamInterceptor(srv interface{},
+	ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	if _, ok := rateLimitedStreams[info.FullMethod]; !ok {
+		return handler(srv, ss)
+	}
+
+	return handler(srv, &rateLimitedStream{ServerStream: ss, i: i})
+}
+
+// rateLimitedStream - поток, который проверяет лимит на каждом полученном сообщении.
+type rateLimitedStream struct {
+	grpc.ServerStream
+	i interceptors
+}
+
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+
+	return s.i.allow(s.Context(), func(md metadata.MD) error {
+		// Заголовки могли уже уйти вместе с первым ответом, тогда retry-after передается в трейлере.
+		if headerErr := s.SetHeader(md); headerErr != nil {
+			s.SetTrailer(md)
+		}
+		return nil
+	})
+}
+
+// allow - проверить лимит пользователя из контекста и его IP.
+//
+// При превышении лимита передает retry-after через setHeader и возвращает ResourceExhausted.
+func (i interceptors) allow(ctx context.Context, setHeader func(metadata.MD) error) error {
+	user, _ := authenticator.GetUser(ctx)
+
+	retryAfter, err := i.limiter.Allow(ctx, user, peerIP(ctx))
+	if errors.Is(err, ratelimit.ErrLimitExceeded) {
+		seconds := strconv.Itoa(ratelimit.RetryAfterSeconds(retryAfter))
+		if headerErr := setHeader(metadata.Pairs("retry-after", seconds)); headerErr != nil {
+			logger.Ctx(ctx, i.logger).Warn("unable to send metadata", zap.Error(headerErr))
+		}
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %s seconds", seconds)
+	}
+	if err != nil {
+		logger.Ctx(ctx, i.logger).Error("rate limit check failed", zap.Error(err))
+	}
+
+	return nil
+}
+
+// peerIP - IP клиента без порта.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
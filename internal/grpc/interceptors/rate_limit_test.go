@@ -0,0 +1,189 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/utils"
+	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
+)
+
+// testTransportStream - транспорт унарного вызова, который запоминает заголовки.
+type testTransportStream struct {
+	method string
+	header metadata.MD
+}
+
+func (s *testTransportStream) Method() string {
+	return s.method
+}
+
+func (s *testTransportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *testTransportStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *testTransportStream) SetTrailer(metadata.MD) error {
+	return nil
+}
+
+func TestInterceptors_RateLimit(t *testing.T) {
+	cfg := configs.Config{RateLimit: 0.01, RateLimitBurst: 1}
+
+	tests := []struct {
+		name       string
+		method     string
+		stream     bool
+		headerSent bool
+		disabled   bool
+		calls      int // Вызовов унарного метода или сообщений в потоке.
+		code       codes.Code
+		header     string
+		trailer    string
+	}{
+		{
+			name:   "first call",
+			method: pb.Shortener_Short_FullMethodName,
+			calls:  1,
+			code:   codes.OK,
+		},
+		{
+			name:   "limit exceeded",
+			method: pb.Shortener_Short_FullMethodName,
+			calls:  2,
+			code:   codes.ResourceExhausted,
+			header: "100",
+		},
+		{
+			name:   "batch",
+			method: pb.Shortener_BatchShort_FullMethodName,
+			calls:  2,
+			code:   codes.ResourceExhausted,
+			header: "100",
+		},
+		{
+			name:   "reading is not limited",
+			method: pb.Shortener_Get_FullMethodName,
+			calls:  3,
+			code:   codes.OK,
+		},
+		{
+			name:     "limit disabled",
+			method:   pb.Shortener_Short_FullMethodName,
+			disabled: true,
+			calls:    3,
+			code:     codes.OK,
+		},
+		{
+			name:   "stream message exceeds the limit",
+			method: pb.Shortener_StreamShort_FullMethodName,
+			stream: true,
+			calls:  2,
+			code:   codes.ResourceExhausted,
+			header: "100",
+		},
+		{
+			name:       "retry-after in trailer after header is sent",
+			method:     pb.Shortener_StreamShort_FullMethodName,
+			stream:     true,
+			headerSent: true,
+			calls:      2,
+			code:       codes.ResourceExhausted,
+			trailer:    "100",
+		},
+		{
+			name:   "import",
+			method: pb.Shortener_Import_FullMethodName,
+			stream: true,
+			calls:  2,
+			code:   codes.ResourceExhausted,
+			header: "100",
+		},
+		{
+			name:   "single message stream",
+			method: pb.Shortener_Import_FullMethodName,
+			stream: true,
+			calls:  1,
+			code:   codes.OK,
+		},
+		{
+			name:   "export is not limited",
+			method: pb.Shortener_Export_FullMethodName,
+			stream: true,
+			calls:  3,
+			code:   codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var limiter *ratelimit.Limiter
+			if !tt.disabled {
+				limiter = ratelimit.NewLimiter(cfg, ratelimit.NewMemoryStore())
+			}
+			i := New(authenticator.New(cfg), nil, zap.NewNop(), limiter)
+
+			ctx := context.WithValue(context.Background(), utils.ContextKey("userID"), uuid.New())
+			ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+
+			var err error
+			var header, trailer metadata.MD
+			if tt.stream {
+				ss := &testStream{ctx: ctx, messages: tt.calls, headerSent: tt.headerSent}
+				err = i.RateLimitStreamInterceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: tt.method},
+					func(_ interface{}, ss grpc.ServerStream) error {
+						for {
+							if recvErr := ss.RecvMsg(nil); recvErr != nil {
+								if errors.Is(recvErr, io.EOF) {
+									return nil
+								}
+								return recvErr
+							}
+						}
+					})
+				header, trailer = ss.header, ss.trailer
+			} else {
+				ts := &testTransportStream{method: tt.method}
+				ctx = grpc.NewContextWithServerTransportStream(ctx, ts)
+				for n := 0; n < tt.calls && err == nil; n++ {
+					_, err = i.RateLimitUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+						func(context.Context, interface{}) (interface{}, error) {
+							return nil, nil
+						})
+				}
+				header = ts.header
+			}
+
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Equal(t, tt.header, first(header.Get("retry-after")))
+			assert.Equal(t, tt.trailer, first(trailer.Get("retry-after")))
+		})
+	}
+}
+
+// first - первое значение метаданных или пустая строка.
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
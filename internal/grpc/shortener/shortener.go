@@ -5,36 +5,72 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/ImpressionableRaccoon/urlshortener/internal/alias"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/bulk"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/policy"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/urlnorm"
 	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
 )
 
-var errWrongURL = errors.New("wrong url")
+// streamBatchSize - сколько ссылок из потока StreamShort сохраняется одной пачкой.
+const streamBatchSize = 100
+
+var (
+	errWrongURL       = errors.New("wrong url")
+	errWrongExpiresAt = errors.New("wrong expires_at")
+	errWrongTeam      = errors.New("wrong team")
+)
 
 type server struct {
 	pb.UnimplementedShortenerServer
 
-	s      storage.Storager
-	https  bool
-	domain string
+	s       storage.Storager
+	https   bool
+	domain  string
+	aliases alias.Validator
+	urls    urlnorm.Normalizer
+	policy  *policy.Policy
+	quotas  *quota.Checker
+	logger  *zap.Logger
 }
 
 // NewGRPCServer - конструктор сервера шортенера.
-func NewGRPCServer(s storage.Storager, https bool, domain string) *server {
+func NewGRPCServer(
+	s storage.Storager,
+	https bool,
+	domain string,
+	aliases alias.Validator,
+	urls urlnorm.Normalizer,
+	p *policy.Policy,
+	quotas *quota.Checker,
+	l *zap.Logger,
+) *server {
 	return &server{
-		s:      s,
-		https:  https,
-		domain: domain,
+		s:       s,
+		https:   https,
+		domain:  domain,
+		aliases: aliases,
+		urls:    urls,
+		policy:  p,
+		quotas:  quotas,
+		logger:  l,
 	}
 }
 
@@ -54,21 +90,40 @@ func (s server) Short(ctx context.Context, req *pb.ShortRequest) (*pb.ShortRespo
 		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
 	}
 
-	id, url, err := s.short(ctx, user, req.Url)
-	if errors.Is(err, repositories.ErrURLAlreadyExists) {
+	opts, err := s.linkOptions(req.ExpiresAt, req.MaxHits, req.Alias)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "short error: %v", err)
+	}
+	opts.Team, err = parseTeam(req.Team)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "short error: %v", err)
+	}
+
+	id, url, shortURL, usage, err := s.short(ctx, user, req.Url, opts)
+	s.setQuotaHeader(ctx, usage)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return nil, status.Errorf(codes.ResourceExhausted, "short error: %v", err)
+	}
+	if errors.Is(err, repositories.ErrURLAlreadyExists) || errors.Is(err, repositories.ErrIDAlreadyExists) {
 		return nil, status.Errorf(codes.AlreadyExists, "short error: %v", err)
 	}
 	if errors.Is(err, errWrongURL) {
 		return nil, status.Errorf(codes.InvalidArgument, "short error: %v", err)
 	}
+	if errors.Is(err, policy.ErrBlocked) || errors.Is(err, repositories.ErrForbidden) {
+		return nil, status.Errorf(codes.PermissionDenied, "short error: %v", err)
+	}
+	if errors.Is(err, repositories.ErrTeamNotFound) {
+		return nil, status.Errorf(codes.NotFound, "short error: %v", err)
+	}
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
 	return &pb.ShortResponse{
 		Id:       id,
-		Url:      req.Url,
-		ShortUrl: url,
+		Url:      url,
+		ShortUrl: shortURL,
 	}, nil
 }
 
@@ -77,12 +132,27 @@ func (s server) Get(ctx context.Context, l *pb.GetRequest) (*pb.GetResponse, err
 	if len(l.Id) == 0 {
 		return nil, status.Error(codes.InvalidArgument, "id length should be greater than 0")
 	}
+
+	// Get засчитывает переход по ссылке с ограничением переходов,
+	// поэтому запрещенная политикой ссылка проверяется до него.
+	if s.policy.Enabled() {
+		link, err := s.s.GetLink(ctx, l.Id)
+		if err == nil && !link.Deleted {
+			if err = s.policy.Check(link.URL); err != nil {
+				return nil, status.Errorf(codes.PermissionDenied, "get error: %v", err)
+			}
+		}
+	}
+
 	url, deleted, err := s.s.Get(ctx, l.Id)
 	if errors.Is(err, repositories.ErrURLNotFound) {
 		return nil, status.Error(codes.NotFound, "url not found")
 	}
+	if errors.Is(err, repositories.ErrLinkExpired) {
+		return nil, status.Error(codes.FailedPrecondition, "link is expired")
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "server error: %v", err)
+		return nil, s.serverError(ctx, err)
 	}
 
 	if deleted {
@@ -96,24 +166,45 @@ func (s server) Get(ctx context.Context, l *pb.GetRequest) (*pb.GetResponse, err
 	}, nil
 }
 
-// GetLinks - обработчик возвращающий все ссылки принадлежащие текущему пользователю.
-func (s server) GetLinks(ctx context.Context, _ *emptypb.Empty) (*pb.GetLinksResponse, error) {
+// GetLinks - обработчик возвращающий страницу ссылок, принадлежащих текущему пользователю.
+func (s server) GetLinks(ctx context.Context, in *pb.GetLinksRequest) (*pb.GetLinksResponse, error) {
 	user, err := authenticator.GetUser(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
 	}
 
-	links, err := s.s.GetUserLinks(ctx, user)
+	team, err := parseTeam(in.Team)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := s.s.ListUserLinks(ctx, user, repositories.LinkQuery{
+		Cursor: in.Cursor,
+		Filter: in.Filter,
+		Order:  repositories.SortOrder(in.Order),
+		Limit:  int(in.Limit),
+		Team:   team,
+	})
+	if errors.Is(err, repositories.ErrWrongCursor) || errors.Is(err, repositories.ErrWrongOrder) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, repositories.ErrTeamNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, repositories.ErrForbidden) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "server error: %v", err)
+		return nil, s.serverError(ctx, err)
 	}
 
-	b := &pb.GetLinksResponse{}
-	for _, link := range links {
+	b := &pb.GetLinksResponse{NextCursor: page.NextCursor}
+	for _, link := range page.Links {
 		b.Links = append(b.Links, &pb.GetLinksResponse_Link{
-			Id:       link.ID,
-			Url:      link.URL,
-			ShortUrl: s.genShortLink(link.ID),
+			Id:        link.ID,
+			Url:       link.URL,
+			ShortUrl:  s.genShortLink(link.ID),
+			CreatedAt: timestamppb.New(link.CreatedAt),
 		})
 	}
 
@@ -121,32 +212,96 @@ func (s server) GetLinks(ctx context.Context, _ *emptypb.Empty) (*pb.GetLinksRes
 }
 
 // BatchShort - обработчик для создания пачки коротких ссылок.
+//
+// Если ссылку не удалось создать, в ответе для нее заполнено поле error.
 func (s server) BatchShort(ctx context.Context, in *pb.BatchShortRequest) (*pb.BatchShortResponse, error) {
 	user, err := authenticator.GetUser(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
 	}
 
-	res := &pb.BatchShortResponse{}
+	links, usage, err := s.shortBatch(ctx, user, in.Links)
+	s.setQuotaHeader(ctx, usage)
+	if err != nil {
+		return nil, s.batchError(ctx, err)
+	}
+
+	return &pb.BatchShortResponse{Links: links}, nil
+}
 
-	for _, link := range in.Links {
-		id, shortURL, err := s.short(ctx, user, link.Url)
+// StreamShort - обработчик для создания коротких ссылок из потока произвольной длины.
+//
+// Ссылки сохраняются пачками по streamBatchSize, результаты отправляются в том же порядке
+// после сохранения каждой пачки. Если ссылку не удалось создать, в результате заполнено поле error.
+//
+// Остаток квот после первой пачки передается в заголовке, а после последней - в трейлере.
+func (s server) StreamShort(stream pb.Shortener_StreamShortServer) error {
+	ctx := stream.Context()
+
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	var (
+		usage      quota.Usage
+		headerSent bool
+	)
+	defer func() {
+		s.setQuotaTrailer(ctx, usage)
+	}()
+
+	batch := make([]*pb.BatchShortRequest_Link, 0, streamBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		links, batchUsage, err := s.shortBatch(ctx, user, batch)
+		if len(batchUsage.Headers()) > 0 {
+			usage = batchUsage
+			if !headerSent {
+				s.setQuotaHeader(ctx, usage)
+				headerSent = true
+			}
+		}
 		if err != nil {
-			continue
+			return s.batchError(ctx, err)
 		}
-		res.Links = append(res.Links, &pb.BatchShortResponse_Link{
-			Id:            id,
-			Url:           link.Url,
-			ShortUrl:      shortURL,
-			CorrelationId: link.CorrelationId,
-		})
+		batch = batch[:0]
+
+		for _, link := range links {
+			err = stream.Send(link)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	return res, nil
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, req)
+		if len(batch) == streamBatchSize {
+			err = flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
 }
 
 // Delete - обработчик для удаления ссылок пользователя.
-func (s server) Delete(ctx context.Context, b *pb.DeleteRequest) (*emptypb.Empty, error) {
+//
+// Ссылки удаляются асинхронно, состояние удаления можно узнать через GetDeletionStatus.
+func (s server) Delete(ctx context.Context, b *pb.DeleteRequest) (*pb.DeleteResponse, error) {
 	user, err := authenticator.GetUser(ctx)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
@@ -160,23 +315,121 @@ func (s server) Delete(ctx context.Context, b *pb.DeleteRequest) (*emptypb.Empty
 		ids = append(ids, link)
 	}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-		defer cancel()
-		err := s.s.DeleteUserLinks(ctx, ids, user)
-		if err != nil {
-			log.Printf("unable to delete user ids: %v", err)
-		}
-	}()
+	deletion, err := s.s.DeleteUserLinks(ctx, ids, user)
+	if err != nil {
+		return nil, s.serverError(ctx, err)
+	}
+
+	return &pb.DeleteResponse{DeletionId: deletion.String()}, nil
+}
+
+// Update - обработчик для замены исходного URL ссылки, прежний URL сохраняется в истории.
+func (s server) Update(ctx context.Context, in *pb.UpdateRequest) (*pb.UpdateResponse, error) {
+	if len(in.Id) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id length should be greater than 0")
+	}
+
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	url, err := s.urls.Normalize(in.Url)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "update error: %v: %v", errWrongURL, err)
+	}
+	if err = s.policy.Check(url); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "update error: %v", err)
+	}
+
+	link, err := s.s.Update(ctx, in.Id, url, user)
+	if errors.Is(err, repositories.ErrURLNotFound) {
+		return nil, status.Error(codes.NotFound, "url not found")
+	}
+	if errors.Is(err, repositories.ErrUserNotMatch) {
+		return nil, status.Error(codes.PermissionDenied, "update error: user not match")
+	}
+	if errors.Is(err, repositories.ErrURLAlreadyExists) {
+		return nil, status.Errorf(codes.AlreadyExists, "update error: %v", err)
+	}
+	if err != nil {
+		return nil, s.serverError(ctx, err)
+	}
+
+	return &pb.UpdateResponse{
+		Id:       in.Id,
+		Url:      link.URL,
+		ShortUrl: s.genShortLink(in.Id),
+	}, nil
+}
+
+// Transfer - обработчик для передачи ссылок в команду или, если команда не указана,
+// в личные ссылки пользователя.
+func (s server) Transfer(ctx context.Context, in *pb.TransferRequest) (*emptypb.Empty, error) {
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	team, err := parseTeam(in.Team)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(in.Ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ids should not be empty")
+	}
+
+	err = s.s.TransferLinks(ctx, in.Ids, user, team)
+	if errors.Is(err, repositories.ErrURLNotFound) || errors.Is(err, repositories.ErrTeamNotFound) {
+		return nil, status.Errorf(codes.NotFound, "transfer error: %v", err)
+	}
+	if errors.Is(err, repositories.ErrForbidden) {
+		return nil, status.Errorf(codes.PermissionDenied, "transfer error: %v", err)
+	}
+	if errors.Is(err, repositories.ErrURLAlreadyExists) {
+		return nil, status.Errorf(codes.AlreadyExists, "transfer error: %v", err)
+	}
+	if err != nil {
+		return nil, s.serverError(ctx, err)
+	}
 
 	return &emptypb.Empty{}, nil
 }
 
+// GetDeletionStatus - обработчик, который возвращает состояние запроса на удаление ссылок.
+func (s server) GetDeletionStatus(
+	ctx context.Context,
+	in *pb.GetDeletionStatusRequest,
+) (*pb.GetDeletionStatusResponse, error) {
+	deletion, err := uuid.Parse(in.DeletionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "wrong deletion id")
+	}
+
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	deletionStatus, err := s.s.GetDeletionStatus(ctx, deletion, user)
+	if errors.Is(err, repositories.ErrDeletionNotFound) {
+		return nil, status.Error(codes.NotFound, "deletion not found")
+	}
+	if err != nil {
+		return nil, s.serverError(ctx, err)
+	}
+
+	return &pb.GetDeletionStatusResponse{
+		DeletionId: deletion.String(),
+		Status:     string(deletionStatus),
+	}, nil
+}
+
 // GetStats - обработчик, который возвращает статистику сервера.
 func (s server) GetStats(ctx context.Context, _ *emptypb.Empty) (*pb.GetStatsResponse, error) {
 	stats, err := s.s.GetStats(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "server error: %v", err)
+		return nil, s.serverError(ctx, err)
 	}
 
 	return &pb.GetStatsResponse{
@@ -185,22 +438,347 @@ func (s server) GetStats(ctx context.Context, _ *emptypb.Empty) (*pb.GetStatsRes
 	}, nil
 }
 
-func (s server) short(ctx context.Context, user uuid.UUID, url string) (id string, shortURL string, err error) {
-	if len(url) == 0 {
-		return "", "", errWrongURL
+// GetLinkStats - обработчик, который возвращает статистику переходов по ссылке владельцу ссылки.
+func (s server) GetLinkStats(ctx context.Context, in *pb.GetLinkStatsRequest) (*pb.GetLinkStatsResponse, error) {
+	if len(in.Id) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id length should be greater than 0")
 	}
 
-	id, err = s.s.Add(ctx, url, user)
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	stats, err := s.s.GetLinkStats(ctx, in.Id, user)
+	if errors.Is(err, repositories.ErrURLNotFound) {
+		return nil, status.Error(codes.NotFound, "url not found")
+	}
+	if errors.Is(err, repositories.ErrUserNotMatch) {
+		return nil, status.Error(codes.PermissionDenied, "link belongs to another user")
+	}
+	if err != nil {
+		return nil, s.serverError(ctx, err)
+	}
+
+	res := &pb.GetLinkStatsResponse{
+		Clicks:         stats.Clicks,
+		UniqueVisitors: stats.UniqueVisitors,
+	}
+	for _, day := range stats.Days {
+		res.Days = append(res.Days, &pb.GetLinkStatsResponse_Day{
+			Date:   day.Day.Format("2006-01-02"),
+			Clicks: day.Clicks,
+		})
+	}
+
+	return res, nil
+}
+
+// GetQuota - обработчик, который возвращает квоты текущего пользователя и их использование.
+func (s server) GetQuota(ctx context.Context, _ *emptypb.Empty) (*pb.GetQuotaResponse, error) {
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	usage, err := s.quotas.Usage(ctx, user)
+	if err != nil {
+		return nil, s.serverError(ctx, err)
+	}
+
+	return &pb.GetQuotaResponse{
+		MaxLinks:    usage.MaxLinks,
+		ActiveLinks: usage.ActiveLinks,
+		DailyLinks:  usage.DailyLinks,
+		LinksToday:  usage.LinksToday,
+	}, nil
+}
+
+// Export - обработчик, который выгружает все ссылки текущего пользователя потоком.
+//
+// Ссылки читаются из хранилища постранично и отправляются по мере чтения.
+func (s server) Export(_ *pb.ExportRequest, stream pb.Shortener_ExportServer) error {
+	ctx := stream.Context()
+
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	var sendErr error
+	err = storage.WalkUserLinks(ctx, s.s, user, func(link repositories.LinkData) error {
+		l := &pb.ExportedLink{
+			Id:       link.ID,
+			Url:      link.URL,
+			ShortUrl: s.genShortLink(link.ID),
+			MaxHits:  link.MaxHits,
+			Hits:     link.Hits,
+		}
+		if !link.ExpiresAt.IsZero() {
+			l.ExpiresAt = timestamppb.New(link.ExpiresAt)
+		}
+		if link.Team != uuid.Nil {
+			l.Team = link.Team.String()
+		}
+
+		sendErr = stream.Send(l)
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return s.serverError(ctx, err)
+	}
+
+	return nil
+}
+
+// Import - обработчик для массового создания коротких ссылок из потока.
+//
+// Ошибка в отдельной ссылке не прерывает загрузку, результат по каждой ссылке
+// возвращается в ответе в том же порядке.
+func (s server) Import(stream pb.Shortener_ImportServer) error {
+	ctx := stream.Context()
+
+	user, err := authenticator.GetUser(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to get user: %v", err)
+	}
+
+	res := &pb.ImportResponse{}
+	for row := uint64(1); ; row++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(res)
+		}
+		if err != nil {
+			return err
+		}
+
+		result := &pb.ImportResponse_Result{
+			Row:    row,
+			Url:    req.Url,
+			Status: bulk.StatusCreated,
+		}
+
+		var opts repositories.LinkOptions
+		opts, err = s.linkOptions(req.ExpiresAt, req.MaxHits, req.Alias)
+		if err == nil {
+			opts.Team, err = parseTeam(req.Team)
+		}
+		if err == nil {
+			var id string
+			var url string
+			id, url, result.ShortUrl, _, err = s.short(ctx, user, req.Url, opts)
+			if url != "" {
+				result.Url = url
+			}
+			if errors.Is(err, repositories.ErrURLAlreadyExists) {
+				result.Status = bulk.StatusExists
+				result.ShortUrl = s.genShortLink(id)
+				err = nil
+			}
+		}
+		if err != nil {
+			result.Status = bulk.StatusError
+			result.Error = err.Error()
+		}
+
+		res.Results = append(res.Results, result)
+	}
+}
+
+func (s server) linkOptions(
+	expiresAt *timestamppb.Timestamp,
+	maxHits uint64,
+	linkAlias string,
+) (opts repositories.LinkOptions, err error) {
+	opts.MaxHits = maxHits
+
+	if linkAlias != "" {
+		err = s.aliases.Validate(linkAlias)
+		if err != nil {
+			return opts, err
+		}
+		opts.Alias = linkAlias
+	}
+
+	if expiresAt != nil {
+		if !expiresAt.IsValid() || !expiresAt.AsTime().After(time.Now()) {
+			return opts, errWrongExpiresAt
+		}
+		opts.ExpiresAt = expiresAt.AsTime()
+	}
+
+	return opts, nil
+}
+
+// shortBatch - создать пачку коротких ссылок с учетом квот пользователя.
+//
+// Ошибки отдельных ссылок записываются в поле error результата. Ошибка возвращается,
+// только если квоты не хватает на всю пачку или хранилище не смогло ее сохранить.
+func (s server) shortBatch(
+	ctx context.Context,
+	user uuid.UUID,
+	in []*pb.BatchShortRequest_Link,
+) (res []*pb.BatchShortResponse_Link, usage quota.Usage, err error) {
+	res = make([]*pb.BatchShortResponse_Link, len(in))
+	links := make([]repositories.BatchLink, 0, len(in))
+	rows := make([]int, 0, len(in))
+	for i, link := range in {
+		res[i] = &pb.BatchShortResponse_Link{Url: link.Url, CorrelationId: link.CorrelationId}
+
+		var batchLink repositories.BatchLink
+		batchLink.Opts, err = s.linkOptions(link.ExpiresAt, link.MaxHits, link.Alias)
+		if err == nil {
+			batchLink.Opts.Team, err = parseTeam(link.Team)
+		}
+		if err == nil {
+			batchLink.URL, err = s.targetURL(link.Url)
+		}
+		if err != nil {
+			res[i].Error = err.Error()
+			continue
+		}
+		res[i].Url = batchLink.URL
+		batchLink.Opts.Quota = s.quotas.Quota(user)
+
+		links = append(links, batchLink)
+		rows = append(rows, i)
+	}
+	if len(links) == 0 {
+		return res, usage, nil
+	}
+
+	usage, err = s.quotas.Check(ctx, user, len(links))
+	if err != nil {
+		return nil, usage, err
+	}
+
+	results, err := s.s.AddBatch(ctx, links, user)
+	if err != nil {
+		return nil, usage, err
+	}
+
+	for j, result := range results {
+		link := res[rows[j]]
+		switch {
+		case result.Err == nil:
+			usage.Created(1)
+		case errors.Is(result.Err, repositories.ErrURLAlreadyExists):
+		default:
+			link.Error = result.Err.Error()
+			continue
+		}
+		link.Id = result.ID
+		link.ShortUrl = s.genShortLink(result.ID)
+	}
+
+	return res, usage, nil
+}
+
+// batchError - ошибка, с которой пачка ссылок не сохранена целиком.
+func (s server) batchError(ctx context.Context, err error) error {
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		return status.Errorf(codes.ResourceExhausted, "batch error: %v", err)
+	}
+	return s.serverError(ctx, err)
+}
+
+// targetURL - нормализовать исходный URL и проверить, что его можно сокращать.
+func (s server) targetURL(url string) (string, error) {
+	url, err := s.urls.Normalize(url)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errWrongURL, err)
+	}
+
+	err = s.policy.Check(url)
+	if err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// short - создать короткую ссылку с учетом квот пользователя.
+//
+// Вместе со ссылкой возвращает нормализованный URL, который сохранен в хранилище,
+// и использование квот после ее создания.
+func (s server) short(
+	ctx context.Context,
+	user uuid.UUID,
+	rawURL string,
+	opts repositories.LinkOptions,
+) (id string, url string, shortURL string, usage quota.Usage, err error) {
+	url, err = s.targetURL(rawURL)
+	if err != nil {
+		return "", "", "", usage, err
+	}
+
+	usage, err = s.quotas.Check(ctx, user, 1)
+	if err != nil {
+		return "", url, "", usage, err
+	}
+
+	opts.Quota = s.quotas.Quota(user)
+	id, err = s.s.Add(ctx, url, user, opts)
 	if errors.Is(err, repositories.ErrURLAlreadyExists) {
-		return "", "", err
+		return id, url, "", usage, err
 	}
 	if err != nil {
-		return "", "", err
+		return "", url, "", usage, err
 	}
+	usage.Created(1)
 
 	shortURL = s.genShortLink(id)
 
-	return id, shortURL, nil
+	return id, url, shortURL, usage, nil
+}
+
+// setQuotaHeader - передать клиенту остаток квот в метаданных ответа.
+func (s server) setQuotaHeader(ctx context.Context, usage quota.Usage) {
+	headers := usage.Headers()
+	if len(headers) == 0 {
+		return
+	}
+
+	err := grpc.SetHeader(ctx, metadata.New(headers))
+	if err != nil {
+		logger.Ctx(ctx, s.logger).Warn("unable to send metadata", zap.Error(err))
+	}
+}
+
+// setQuotaTrailer - передать клиенту остаток квот в трейлере ответа.
+func (s server) setQuotaTrailer(ctx context.Context, usage quota.Usage) {
+	headers := usage.Headers()
+	if len(headers) == 0 {
+		return
+	}
+
+	err := grpc.SetTrailer(ctx, metadata.New(headers))
+	if err != nil {
+		logger.Ctx(ctx, s.logger).Warn("unable to send metadata", zap.Error(err))
+	}
+}
+
+// serverError - записать ошибку хранилища в лог и вернуть ее клиенту с кодом Internal.
+func (s server) serverError(ctx context.Context, err error) error {
+	logger.Ctx(ctx, s.logger).Error("server error", zap.Error(err))
+	return status.Errorf(codes.Internal, "server error: %v", err)
+}
+
+// parseTeam - получить ID команды из запроса, пустая строка - личные ссылки пользователя.
+func parseTeam(s string) (repositories.TeamID, error) {
+	if s == "" {
+		return uuid.Nil, nil
+	}
+
+	team, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, errWrongTeam
+	}
+	return team, nil
 }
 
 func (s server) genShortLink(id string) string {
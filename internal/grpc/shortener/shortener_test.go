@@ -0,0 +1,234 @@
+package shortener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/alias"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/bulk"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/grpc/interceptors"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/urlnorm"
+	pb "github.com/ImpressionableRaccoon/urlshortener/proto"
+)
+
+// newTestClient - запустить сервер шортенера в памяти и вернуть клиент к нему и его хранилище.
+func newTestClient(t *testing.T, cfg configs.Config) (pb.ShortenerClient, storage.Storager) {
+	t.Helper()
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+	q, err := quota.NewChecker(cfg, s)
+	require.NoError(t, err)
+
+	i := interceptors.New(authenticator.New(cfg), nil, zap.NewNop(), nil)
+	g := grpc.NewServer(
+		grpc.UnaryInterceptor(i.AuthUnaryInterceptor),
+		grpc.StreamInterceptor(i.AuthStreamInterceptor),
+	)
+	pb.RegisterShortenerServer(g, NewGRPCServer(s, false, cfg.ServerBaseURL,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, q, zap.NewNop()))
+
+	lis := bufconn.Listen(1 << 20)
+	go func() {
+		_ = g.Serve(lis)
+	}()
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewShortenerClient(conn), s
+}
+
+func TestServer_StreamShortQuota(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		Quota:         configs.QuotaLimits{MaxLinks: streamBatchSize + 50},
+	}
+	client, _ := newTestClient(t, cfg)
+	_, user := authenticator.New(cfg).Gen()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "user", user)
+
+	tests := []struct {
+		name    string
+		links   int
+		code    codes.Code
+		header  string
+		trailer string
+	}{
+		{
+			name:    "several batches",
+			links:   streamBatchSize + 20,
+			code:    codes.OK,
+			header:  "50",
+			trailer: "30",
+		},
+		{
+			name:   "quota exceeded",
+			links:  31,
+			code:   codes.ResourceExhausted,
+			header: "30",
+		},
+	}
+
+	for n, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.StreamShort(ctx)
+			require.NoError(t, err)
+			for i := 0; i < tt.links; i++ {
+				err = stream.Send(&pb.BatchShortRequest_Link{
+					Url: fmt.Sprintf("https://stream%d-%d.example.com/", n, i),
+				})
+				require.NoError(t, err)
+			}
+			require.NoError(t, stream.CloseSend())
+
+			received := 0
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+				received++
+			}
+			if tt.code == codes.OK {
+				assert.ErrorIs(t, err, io.EOF)
+				assert.Equal(t, tt.links, received)
+			} else {
+				assert.Equal(t, tt.code, status.Code(err))
+			}
+
+			header, err := stream.Header()
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.header}, header.Get(quota.HeaderLinksRemaining))
+			if tt.trailer != "" {
+				assert.Equal(t, []string{tt.trailer}, stream.Trailer().Get(quota.HeaderLinksRemaining))
+			}
+		})
+	}
+}
+
+func TestServer_NormalizedURL(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+	client, _ := newTestClient(t, cfg)
+	_, user := authenticator.New(cfg).Gen()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "user", user)
+
+	short, err := client.Short(ctx, &pb.ShortRequest{Url: " HTTPS://Short.Example.COM:443/a "})
+	require.NoError(t, err)
+	assert.Equal(t, "https://short.example.com/a", short.Url)
+
+	batch, err := client.BatchShort(ctx, &pb.BatchShortRequest{Links: []*pb.BatchShortRequest_Link{
+		{Url: "HTTP://Batch.Example.COM:80/b", CorrelationId: "1"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, batch.Links, 1)
+	assert.Equal(t, "http://batch.example.com/b", batch.Links[0].Url)
+
+	stream, err := client.Import(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.ImportRequest{Url: "HTTPS://Import.Example.COM/c"}))
+	require.NoError(t, stream.Send(&pb.ImportRequest{Url: "https://import.example.com/c"}))
+	imported, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	require.Len(t, imported.Results, 2)
+	for _, result := range imported.Results {
+		assert.Equal(t, "https://import.example.com/c", result.Url)
+	}
+	assert.Equal(t, imported.Results[0].ShortUrl, imported.Results[1].ShortUrl)
+}
+
+func TestServer_ExportImportTeams(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+	client, st := newTestClient(t, cfg)
+	userID, user := authenticator.New(cfg).Gen()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "user", user)
+
+	team := repositories.Team{ID: uuid.New(), Name: "team"}
+	require.NoError(t, st.CreateTeam(context.Background(), team, userID))
+
+	// Личных ссылок больше, чем помещается на одну страницу ListUserLinks.
+	personal := repositories.MaxPageSize + 1
+	links := make([]*pb.BatchShortRequest_Link, 0, personal+3)
+	for i := 0; i < personal; i++ {
+		links = append(links, &pb.BatchShortRequest_Link{Url: fmt.Sprintf("https://personal%d.example.com/", i)})
+	}
+	links = append(links,
+		&pb.BatchShortRequest_Link{Url: "https://batch.team.example.com/", Team: team.ID.String()},
+		&pb.BatchShortRequest_Link{Url: "https://wrong.team.example.com/", Team: "wrong"},
+		&pb.BatchShortRequest_Link{Url: "https://other.team.example.com/", Team: uuid.NewString()},
+	)
+	batch, err := client.BatchShort(ctx, &pb.BatchShortRequest{Links: links})
+	require.NoError(t, err)
+	require.Len(t, batch.Links, len(links))
+	assert.Empty(t, batch.Links[personal].Error)
+	assert.Equal(t, errWrongTeam.Error(), batch.Links[personal+1].Error)
+	assert.Equal(t, repositories.ErrTeamNotFound.Error(), batch.Links[personal+2].Error)
+
+	stream, err := client.Import(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.ImportRequest{Url: "https://import.team.example.com/", Team: team.ID.String()}))
+	require.NoError(t, stream.Send(&pb.ImportRequest{Url: "https://wrong.team.example.com/", Team: "wrong"}))
+	imported, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	require.Len(t, imported.Results, 2)
+	assert.Equal(t, bulk.StatusCreated, imported.Results[0].Status)
+	assert.Equal(t, bulk.StatusError, imported.Results[1].Status)
+
+	export, err := client.Export(ctx, &pb.ExportRequest{})
+	require.NoError(t, err)
+	ids := make(map[string]bool)
+	teamLinks := make(map[string]string)
+	for {
+		link, err := export.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.False(t, ids[link.Id], "each link is exported once")
+		ids[link.Id] = true
+		if link.Team != "" {
+			teamLinks[link.Url] = link.Team
+		}
+	}
+	assert.Len(t, ids, personal+2)
+	assert.Equal(t, map[string]string{
+		"https://batch.team.example.com/":  team.ID.String(),
+		"https://import.team.example.com/": team.ID.String(),
+	}, teamLinks)
+}
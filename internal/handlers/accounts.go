@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Типы, которые используют обработчики учетных записей.
+type (
+	// CredentialsRequest - структура запроса к Register и Login.
+	CredentialsRequest struct {
+		Login    string `json:"login"`           // Логин.
+		Password string `json:"password"`        // Пароль.
+		Claim    bool   `json:"claim,omitempty"` // Забрать ссылки текущего анонимного пользователя.
+	}
+
+	// AccountResponse - учетная запись в ответе обработчиков.
+	AccountResponse struct {
+		CreatedAt time.Time         `json:"created_at"`        // Время регистрации.
+		Login     string            `json:"login"`             // Логин.
+		Session   string            `json:"session,omitempty"` // Сессия учетной записи, как в cookie USER.
+		Claimed   []repositories.ID `json:"claimed,omitempty"` // Ссылки, переданные учетной записи.
+		ID        repositories.User `json:"id"`                // Пользователь учетной записи.
+
+		SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"` // Когда истечет сессия.
+	}
+
+	// CreateAPIKeyRequest - структура запроса к CreateAPIKey.
+	CreateAPIKeyRequest struct {
+		Name  string             `json:"name"`  // Название ключа.
+		Scope repositories.Scope `json:"scope"` // Права ключа: read или write.
+	}
+
+	// APIKeyResponse - API-ключ в ответе обработчиков.
+	APIKeyResponse struct {
+		CreatedAt time.Time          `json:"created_at"`           // Время создания.
+		RevokedAt *time.Time         `json:"revoked_at,omitempty"` // Время отзыва.
+		Key       string             `json:"key,omitempty"`        // Сам ключ, возвращается только при создании.
+		Name      string             `json:"name"`                 // Название.
+		Prefix    string             `json:"prefix"`               // Начало ключа.
+		Scope     repositories.Scope `json:"scope"`                // Права ключа.
+		ID        repositories.KeyID `json:"id"`                   // ID ключа.
+	}
+
+	// ClaimRequest - структура запроса к ClaimUserURLs.
+	ClaimRequest struct {
+		Identity string `json:"identity"` // Анонимный пользователь: значение его cookie USER.
+	}
+
+	// ClaimResponse - структура ответа ClaimUserURLs.
+	ClaimResponse struct {
+		Claimed []repositories.ID `json:"claimed"` // Ссылки, переданные учетной записи.
+	}
+)
+
+// Register - обработчик для регистрации учетной записи.
+//
+// После регистрации клиент входит в учетную запись: cookie USER подписывается для ее пользователя.
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var requestData CredentialsRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	account, err := h.accounts.Register(r.Context(), requestData.Login, requestData.Password)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	h.startSession(w, r, http.StatusCreated, account, requestData.Claim)
+}
+
+// Login - обработчик для входа в учетную запись по логину и паролю.
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var requestData CredentialsRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	account, err := h.accounts.Login(r.Context(), requestData.Login, requestData.Password)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	h.startSession(w, r, http.StatusOK, account, requestData.Claim)
+}
+
+// startSession - выдать клиенту cookie учетной записи.
+//
+// Если claim == true, сначала учетной записи передаются ссылки текущего пользователя,
+// если он анонимный.
+func (h *Handler) startSession(
+	w http.ResponseWriter,
+	r *http.Request,
+	code int,
+	account repositories.Account,
+	claim bool,
+) {
+	var claimed []repositories.ID
+	if claim {
+		user, err := authenticator.GetUser(r.Context())
+		if err != nil {
+			h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+			h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+			return
+		}
+
+		claimed, err = h.accounts.Claim(r.Context(), account.ID, user)
+		if err != nil && !errors.Is(err, repositories.ErrForbidden) {
+			h.accountError(w, r, err)
+			return
+		}
+	}
+
+	session, expiresAt := h.accounts.Session(account)
+	cookie := authenticator.Cookie(session)
+	response := AccountResponse{
+		ID:        account.ID,
+		Login:     account.Login,
+		CreatedAt: account.CreatedAt,
+		Session:   session,
+		Claimed:   claimed,
+	}
+	if !expiresAt.IsZero() {
+		cookie.Expires = expiresAt
+		response.SessionExpiresAt = &expiresAt
+	}
+	http.SetCookie(w, cookie)
+
+	h.writeJSON(w, r, code, response)
+}
+
+// Logout - обработчик для выхода из учетной записи.
+//
+// Завершает все сессии учетной записи, в том числе на других устройствах, и удаляет cookie USER:
+// со следующим запросом клиент получит нового анонимного пользователя.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.accounts.Logout(r.Context(), user)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	cookie := authenticator.Cookie("")
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAccount - обработчик, который возвращает учетную запись текущего пользователя.
+func (h *Handler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := h.accounts.Account(r.Context(), user)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, AccountResponse{
+		ID:        account.ID,
+		Login:     account.Login,
+		CreatedAt: account.CreatedAt,
+	})
+}
+
+// CreateAPIKey - обработчик для создания API-ключа учетной записи.
+//
+// Ключ возвращается только в этом ответе.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData CreateAPIKeyRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	token, key, err := h.accounts.CreateKey(r.Context(), user, requestData.Name, requestData.Scope)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	response := apiKeyResponse(key)
+	response.Key = token
+	h.writeJSON(w, r, http.StatusCreated, response)
+}
+
+// GetAPIKeys - обработчик, который возвращает API-ключи учетной записи, в том числе отозванные.
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.accounts.Keys(r.Context(), user)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, apiKeyResponse(key))
+	}
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+// RevokeAPIKey - обработчик для отзыва API-ключа учетной записи.
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "KeyID"))
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	err = h.accounts.RevokeKey(r.Context(), user, id)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ClaimUserURLs - обработчик, который передает учетной записи личные ссылки анонимного пользователя.
+//
+// Анонимный пользователь передается в теле запроса значением его cookie USER.
+func (h *Handler) ClaimUserURLs(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData ClaimRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	claimed, err := h.accounts.ClaimIdentity(r.Context(), user, requestData.Identity)
+	if err != nil {
+		h.accountError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, ClaimResponse{Claimed: claimed})
+}
+
+// currentUser - получить текущего пользователя.
+//
+// Если не получилось, сам отвечает клиенту и возвращает false.
+func (h *Handler) currentUser(w http.ResponseWriter, r *http.Request) (user repositories.User, ok bool) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return user, false
+	}
+
+	return user, true
+}
+
+// accountError - ответить клиенту ошибкой, связанной с учетными записями.
+func (h *Handler) accountError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, accounts.ErrWrongCredentials):
+		h.httpJSONError(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, accounts.ErrNotAccount):
+		h.httpJSONError(w, "Account required", http.StatusForbidden)
+	case errors.Is(err, repositories.ErrForbidden):
+		h.httpJSONError(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, repositories.ErrAPIKeyNotFound):
+		h.httpJSONError(w, "API key not found", http.StatusNotFound)
+	case errors.Is(err, repositories.ErrLoginTaken):
+		h.httpJSONError(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, accounts.ErrWrongLogin),
+		errors.Is(err, accounts.ErrWeakPassword),
+		errors.Is(err, accounts.ErrWrongKeyName),
+		errors.Is(err, accounts.ErrWrongIdentity),
+		errors.Is(err, repositories.ErrUnknownScope):
+		h.httpJSONError(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log(r.Context()).Error("account request failed", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+	}
+}
+
+func apiKeyResponse(key repositories.APIKey) APIKeyResponse {
+	response := APIKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scope:     key.Scope,
+		CreatedAt: key.CreatedAt,
+	}
+	if key.Revoked() {
+		revokedAt := key.RevokedAt
+		response.RevokedAt = &revokedAt
+	}
+	return response
+}
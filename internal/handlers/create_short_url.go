@@ -3,10 +3,12 @@ package handlers
 import (
 	"errors"
 	"io"
-	"log"
 	"net/http"
 
+	"go.uber.org/zap"
+
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 )
 
@@ -21,26 +23,44 @@ func (h *Handler) CreateShortURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	url, err := h.targetURL(string(b))
+	if err != nil {
+		http.Error(w, err.Error(), targetURLStatus(err))
+		return
+	}
+
 	user, err := authenticator.GetUser(r.Context())
 	if err != nil {
-		log.Printf("unable to parse user uuid: %v", err)
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
-	id, err := h.st.Add(r.Context(), string(b), user)
+	usage, ok := h.checkQuota(w, r, user, 1)
+	if !ok {
+		return
+	}
+
+	id, err := h.st.Add(r.Context(), url, user, repositories.LinkOptions{Quota: h.quotas.Quota(user)})
+	if err == nil {
+		usage.Created(1)
+	}
+	setQuotaHeaders(w, usage)
+
 	if errors.Is(err, repositories.ErrURLAlreadyExists) {
 		w.WriteHeader(http.StatusConflict)
+	} else if errors.Is(err, quota.ErrQuotaExceeded) {
+		h.httpJSONError(w, err.Error(), http.StatusForbidden)
+		return
 	} else if err != nil {
+		h.log(r.Context()).Error("unable to add link", zap.Error(err))
 		http.Error(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
-	url := h.genShortLink(id)
-
 	w.WriteHeader(http.StatusCreated)
-	_, err = w.Write([]byte(url))
+	_, err = w.Write([]byte(h.genShortLink(id)))
 	if err != nil {
-		log.Printf("write failed: %v", err)
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
 	}
 }
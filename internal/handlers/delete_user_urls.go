@@ -1,25 +1,36 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"io"
-	"log"
 	"net/http"
-	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 )
 
+// DeletionResponse - структура ответа от DeleteUserURLs и GetDeletionStatus.
+type DeletionResponse struct {
+	DeletionID string                      `json:"deletion_id"`      // ID запроса на удаление.
+	Status     repositories.DeletionStatus `json:"status,omitempty"` // Состояние запроса на удаление.
+}
+
 // DeleteUserURLs - обработчик для удаления ссылок пользователя.
+//
+// Ссылки удаляются асинхронно, состояние удаления можно узнать через GetDeletionStatus
+// по адресу из заголовка Location.
 func (h *Handler) DeleteUserURLs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
 
 	user, err := authenticator.GetUser(r.Context())
 	if err != nil {
-		log.Printf("unable to parse user uuid: %v", err)
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
@@ -37,14 +48,66 @@ func (h *Handler) DeleteUserURLs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-		defer cancel()
-		err = h.st.DeleteUserLinks(ctx, ids, user)
-		if err != nil {
-			log.Printf("unable to delete user ids: %v", err)
-		}
-	}()
+	deletion, err := h.st.DeleteUserLinks(r.Context(), ids, user)
+	if err != nil {
+		h.log(r.Context()).Error("unable to delete user ids", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	responseJSON, err := json.Marshal(DeletionResponse{DeletionID: deletion.String()})
+	if err != nil {
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
 
+	w.Header().Set("Location", "/api/user/urls/deletions/"+deletion.String())
 	w.WriteHeader(http.StatusAccepted)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
+	}
+}
+
+// GetDeletionStatus - обработчик, который возвращает состояние запроса на удаление ссылок.
+func (h *Handler) GetDeletionStatus(w http.ResponseWriter, r *http.Request) {
+	deletion, err := uuid.Parse(chi.URLParam(r, "DeletionID"))
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	status, err := h.st.GetDeletionStatus(r.Context(), deletion, user)
+	if errors.Is(err, repositories.ErrDeletionNotFound) {
+		h.httpJSONError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.log(r.Context()).Error("unable to get deletion status", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	responseJSON, err := json.Marshal(DeletionResponse{DeletionID: deletion.String(), Status: status})
+	if err != nil {
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
+	}
 }
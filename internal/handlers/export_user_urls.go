@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/bulk"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+)
+
+// ExportUserURLs - обработчик, который выгружает все ссылки текущего пользователя.
+//
+// Формат задается параметром format: csv или ndjson (по умолчанию).
+// Ссылки читаются из хранилища постранично и пишутся в ответ по мере чтения.
+func (h *Handler) ExportUserURLs(w http.ResponseWriter, r *http.Request) {
+	format := bulk.NDJSON
+	if f := r.URL.Query().Get("format"); f != "" {
+		var err error
+		format, err = bulk.ParseFormat(f)
+		if err != nil {
+			h.httpJSONError(w, "Unknown format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	setHeaders := func() {
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="urls.%s"`, format))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+	}
+
+	bw := bulk.NewWriter(w, format)
+	rows := 0
+	var writeErr error
+	err = storage.WalkUserLinks(r.Context(), h.st, user, func(link repositories.LinkData) error {
+		if rows == 0 {
+			setHeaders()
+		}
+		rows++
+		writeErr = bw.Write(exportLink(link, h.genShortLink(link.ID)))
+		return writeErr
+	})
+	switch {
+	case writeErr != nil:
+		h.log(r.Context()).Warn("write failed", zap.Error(writeErr))
+		return
+	case err != nil && rows == 0:
+		h.log(r.Context()).Error("unable to get user links", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	case err != nil:
+		// Часть ссылок уже отправлена, статус ответа изменить нельзя.
+		h.log(r.Context()).Error("export interrupted", zap.Int("rows", rows), zap.Error(err))
+		return
+	}
+	if rows == 0 {
+		setHeaders()
+	}
+
+	err = bw.Flush()
+	if err != nil {
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
+	}
+}
+
+// exportLink - строка выгрузки для ссылки.
+func exportLink(link repositories.LinkData, shortURL repositories.URL) bulk.Link {
+	l := bulk.Link{
+		ID:          link.ID,
+		ShortURL:    shortURL,
+		OriginalURL: link.URL,
+		MaxHits:     link.MaxHits,
+		Hits:        link.Hits,
+	}
+	if !link.ExpiresAt.IsZero() {
+		l.ExpiresAt = &link.ExpiresAt
+	}
+	if link.Team != uuid.Nil {
+		l.Team = &link.Team
+	}
+	return l
+}
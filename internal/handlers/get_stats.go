@@ -2,34 +2,28 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net"
 	"net/http"
+
+	"go.uber.org/zap"
 )
 
 // GetStats - обработчик, который возвращает статистику сервера при запросах из внутренней сети.
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
-	addr, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
-		return
-	}
-	ip := net.ParseIP(addr)
-	if !h.trusted.Contains(ip) {
-		h.httpJSONError(w, "Forbidden", http.StatusForbidden)
+	if !h.checkTrustedSubnet(w, r) {
 		return
 	}
 
 	stats, err := h.st.GetStats(r.Context())
 	if err != nil {
-		log.Printf("unable to get stats: %v", err)
+		h.log(r.Context()).Error("unable to get stats", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
 	response, err := json.Marshal(stats)
 	if err != nil {
-		log.Printf("unable to marshal response: %v", err)
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
@@ -39,6 +33,21 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_, err = w.Write(response)
 	if err != nil {
-		log.Printf("write failed: %v", err)
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
+	}
+}
+
+// checkTrustedSubnet - проверяет, что запрос пришел из доверенной сети, иначе отвечает ошибкой.
+func (h *Handler) checkTrustedSubnet(w http.ResponseWriter, r *http.Request) bool {
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return false
+	}
+	ip := net.ParseIP(addr)
+	if !h.trusted.Contains(ip) {
+		h.httpJSONError(w, "Forbidden", http.StatusForbidden)
+		return false
 	}
+	return true
 }
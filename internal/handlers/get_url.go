@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 )
 
 // GetURL - обработчик, который переадресует короткую ссылку на исходный URL.
@@ -14,17 +19,46 @@ func (h *Handler) GetURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Get засчитывает переход по ссылке с ограничением переходов,
+	// поэтому запрещенная политикой ссылка проверяется до него.
+	if h.policy.Enabled() {
+		link, err := h.st.GetLink(r.Context(), id)
+		if err == nil && !link.Deleted {
+			if err = h.policy.Check(link.URL); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+		}
+	}
+
 	url, deleted, err := h.st.Get(r.Context(), id)
-	if err != nil {
+	if errors.Is(err, repositories.ErrLinkExpired) {
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+	if errors.Is(err, repositories.ErrURLNotFound) {
 		http.Error(w, "Not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		h.log(r.Context()).Error("get url failed", zap.Error(err))
+		http.Error(w, "Server error", http.StatusInternalServerError)
+		return
+	}
 
 	if deleted {
 		w.WriteHeader(http.StatusGone)
 		return
 	}
 
+	h.clicks.Record(repositories.Click{
+		Time:      time.Now(),
+		ID:        id,
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		IP:        clientIP(r),
+	})
+
 	w.Header().Set("Location", url)
 	w.WriteHeader(http.StatusTemporaryRedirect)
 }
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+)
+
+// GetUserQuota - обработчик, который возвращает квоты текущего пользователя и их использование.
+func (h *Handler) GetUserQuota(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	usage, err := h.quotas.Usage(r.Context(), user)
+	if err != nil {
+		h.log(r.Context()).Error("unable to get quota usage", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(usage)
+	if err != nil {
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	setQuotaHeaders(w, usage)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(response)
+	if err != nil {
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Типы, которые использует GetUserURLStats.
+type (
+	// DayClicks - количество переходов по ссылке за сутки.
+	DayClicks struct {
+		Date   string `json:"date"`   // Дата в формате YYYY-MM-DD (UTC).
+		Clicks uint64 `json:"clicks"` // Количество переходов.
+	}
+
+	// LinkStatsResponse - структура ответа от GetUserURLStats.
+	LinkStatsResponse struct {
+		Days           []DayClicks `json:"days"`            // Гистограмма переходов по дням.
+		Clicks         uint64      `json:"clicks"`          // Общее количество переходов.
+		UniqueVisitors uint64      `json:"unique_visitors"` // Количество уникальных посетителей.
+	}
+)
+
+// GetUserURLStats - обработчик, который возвращает статистику переходов по ссылке владельцу ссылки.
+func (h *Handler) GetUserURLStats(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "ID")
+	if id == "" {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	stats, err := h.st.GetLinkStats(r.Context(), id, user)
+	if errors.Is(err, repositories.ErrURLNotFound) {
+		h.httpJSONError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repositories.ErrUserNotMatch) {
+		h.httpJSONError(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		h.log(r.Context()).Error("unable to get link stats", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := LinkStatsResponse{
+		Days:           make([]DayClicks, 0, len(stats.Days)),
+		Clicks:         stats.Clicks,
+		UniqueVisitors: stats.UniqueVisitors,
+	}
+	for _, day := range stats.Days {
+		response.Days = append(response.Days, DayClicks{
+			Date:   day.Day.Format("2006-01-02"),
+			Clicks: day.Clicks,
+		})
+	}
+
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(responseJSON)
+	if err != nil {
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
+	}
+}
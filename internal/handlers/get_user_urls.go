@@ -2,8 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
@@ -13,34 +19,76 @@ import (
 type UserLink struct {
 	ShortURL    repositories.URL `json:"short_url"`    // Сокращенный URL.
 	OriginalURL repositories.URL `json:"original_url"` // Исходный URL.
+	CreatedAt   time.Time        `json:"created_at"`   // Время создания ссылки.
 }
 
-// GetUserURLs - обработчик возвращающий все ссылки принадлежащие текущему пользователю.
+// GetUserURLs - обработчик возвращающий страницу ссылок, принадлежащих текущему пользователю.
+//
+// Параметры запроса: limit - размер страницы, cursor - курсор из заголовка X-Next-Cursor,
+// order - порядок сортировки по времени создания (asc или desc), q - подстрока исходного URL,
+// team - ID команды, если нужны только ее ссылки.
 func (h *Handler) GetUserURLs(w http.ResponseWriter, r *http.Request) {
 	user, err := authenticator.GetUser(r.Context())
 	if err != nil {
-		log.Printf("unable to parse user uuid: %v", err)
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
-	links, err := h.st.GetUserLinks(r.Context(), user)
+	params := r.URL.Query()
+	q := repositories.LinkQuery{
+		Cursor: params.Get("cursor"),
+		Filter: params.Get("q"),
+		Order:  repositories.SortOrder(params.Get("order")),
+	}
+	if s := params.Get("team"); s != "" {
+		q.Team, err = uuid.Parse(s)
+		if err != nil {
+			h.httpJSONError(w, "Wrong team", http.StatusBadRequest)
+			return
+		}
+	}
+	if s := params.Get("limit"); s != "" {
+		q.Limit, err = strconv.Atoi(s)
+		if err != nil || q.Limit <= 0 {
+			h.httpJSONError(w, "Wrong limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.st.ListUserLinks(r.Context(), user, q)
+	if errors.Is(err, repositories.ErrWrongCursor) || errors.Is(err, repositories.ErrWrongOrder) {
+		h.httpJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, repositories.ErrTeamNotFound) || errors.Is(err, repositories.ErrForbidden) {
+		h.teamError(w, r, err)
+		return
+	}
 	if err != nil {
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
 	response := make([]UserLink, 0)
-	for _, link := range links {
+	for _, link := range page.Links {
 		response = append(response, UserLink{
 			ShortURL:    h.genShortLink(link.ID),
 			OriginalURL: link.URL,
+			CreatedAt:   link.CreatedAt,
 		})
 	}
 
+	if page.NextCursor != "" {
+		params.Set("cursor", page.NextCursor)
+		next := url.URL{Path: r.URL.Path, RawQuery: params.Encode()}
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+	}
+
 	responseJSON, err := json.Marshal(response)
 	if err != nil {
-		log.Printf("unable to marshal response: %v", err)
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
@@ -52,7 +100,7 @@ func (h *Handler) GetUserURLs(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-Content-Type-Options", "nosniff")
 		_, err = w.Write(responseJSON)
 		if err != nil {
-			log.Printf("write failed: %v", err)
+			h.log(r.Context()).Warn("write failed", zap.Error(err))
 		}
 	}
 }
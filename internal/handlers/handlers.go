@@ -2,30 +2,83 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
+	"time"
 
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/alias"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/analytics"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/policy"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/teams"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/urlnorm"
 )
 
+// ErrExpiresInPast - время окончания действия ссылки уже прошло.
+var ErrExpiresInPast = errors.New("expires_at is in the past")
+
 // Handler хранит обработчики для http-запросов пользователя.
 type Handler struct {
-	st      storage.Storager
-	https   bool
-	domain  string
-	trusted *net.IPNet
+	st       storage.Storager
+	https    bool
+	domain   string
+	trusted  *net.IPNet
+	aliases  alias.Validator
+	urls     urlnorm.Normalizer
+	policy   *policy.Policy
+	clicks   *analytics.Recorder
+	quotas   *quota.Checker
+	teams    *teams.Service
+	accounts *accounts.Service
+	logger   *zap.Logger
+
+	restoreWindow  time.Duration
+	trashRetention time.Duration
 }
 
 // NewHandler - конструктор для Handler.
-func NewHandler(s storage.Storager, https bool, domain string, trusted *net.IPNet) *Handler {
+func NewHandler(
+	s storage.Storager,
+	https bool,
+	domain string,
+	trusted *net.IPNet,
+	aliases alias.Validator,
+	urls urlnorm.Normalizer,
+	p *policy.Policy,
+	clicks *analytics.Recorder,
+	quotas *quota.Checker,
+	t *teams.Service,
+	acc *accounts.Service,
+	restoreWindow time.Duration,
+	trashRetention time.Duration,
+	l *zap.Logger,
+) *Handler {
 	h := &Handler{
-		st:      s,
-		https:   https,
-		domain:  domain,
-		trusted: trusted,
+		st:       s,
+		https:    https,
+		domain:   domain,
+		trusted:  trusted,
+		aliases:  aliases,
+		urls:     urls,
+		policy:   p,
+		clicks:   clicks,
+		quotas:   quotas,
+		teams:    t,
+		accounts: acc,
+		logger:   l,
+
+		restoreWindow:  restoreWindow,
+		trashRetention: trashRetention,
 	}
 
 	return h
@@ -44,13 +97,109 @@ func (h *Handler) httpJSONError(w http.ResponseWriter, msg string, code int) {
 	w.WriteHeader(code)
 	_, err := w.Write(jsonError)
 	if err != nil {
-		log.Printf("write failed: %v", err)
+		h.logger.Warn("write failed", zap.Error(err))
 	}
 }
 
+// log - логгер с ID запроса и пользователя.
+func (h *Handler) log(ctx context.Context) *zap.Logger {
+	return logger.Ctx(ctx, h.logger)
+}
+
 func (h *Handler) genShortLink(id string) string {
 	if h.https {
 		return fmt.Sprintf("https://%s/%s", h.domain, id)
 	}
 	return fmt.Sprintf("%s/%s", h.domain, id)
 }
+
+// linkOptions - собрать и проверить параметры ссылки из запроса пользователя.
+func (h *Handler) linkOptions(
+	expiresAt *time.Time,
+	maxHits uint64,
+	linkAlias repositories.ID,
+) (opts repositories.LinkOptions, err error) {
+	opts.MaxHits = maxHits
+
+	if linkAlias != "" {
+		err = h.aliases.Validate(linkAlias)
+		if err != nil {
+			return opts, err
+		}
+		opts.Alias = linkAlias
+	}
+
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return opts, ErrExpiresInPast
+		}
+		opts.ExpiresAt = *expiresAt
+	}
+
+	return opts, nil
+}
+
+// targetURL - нормализовать URL, который хочет сократить пользователь, и проверить его по политике.
+func (h *Handler) targetURL(raw string) (string, error) {
+	url, err := h.urls.Normalize(raw)
+	if err != nil {
+		return "", err
+	}
+
+	err = h.policy.Check(url)
+	if err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// targetURLStatus - код ответа для ошибки из targetURL.
+func targetURLStatus(err error) int {
+	if errors.Is(err, policy.ErrBlocked) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+// checkQuota - проверить, что пользователь может создать еще n ссылок.
+//
+// Если не может, сам отвечает клиенту и возвращает false.
+func (h *Handler) checkQuota(
+	w http.ResponseWriter,
+	r *http.Request,
+	user repositories.User,
+	n int,
+) (usage quota.Usage, ok bool) {
+	usage, err := h.quotas.Check(r.Context(), user, n)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		setQuotaHeaders(w, usage)
+		h.httpJSONError(w, err.Error(), http.StatusForbidden)
+		return usage, false
+	}
+	if err != nil {
+		h.log(r.Context()).Error("unable to check quota", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return usage, false
+	}
+
+	return usage, true
+}
+
+// setQuotaHeaders - передать клиенту остаток квот.
+func setQuotaHeaders(w http.ResponseWriter, usage quota.Usage) {
+	for k, v := range usage.Headers() {
+		w.Header().Set(k, v)
+	}
+}
+
+// clientIP - получить IP-адрес клиента.
+//
+// Адрес берется из r.RemoteAddr, который уже подменен middleware.RealIP.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
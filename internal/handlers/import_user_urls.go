@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/bulk"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// ImportUserURLs - обработчик для массового создания коротких ссылок из CSV или NDJSON.
+//
+// Формат задается параметром format или заголовком Content-Type.
+// Ответ пишется в том же формате: по одной строке bulk.Result на каждую строку запроса,
+// ошибка в строке не прерывает загрузку остальных.
+func (h *Handler) ImportUserURLs(w http.ResponseWriter, r *http.Request) {
+	f := r.URL.Query().Get("format")
+	if f == "" {
+		f = r.Header.Get("Content-Type")
+	}
+	format, err := bulk.ParseFormat(f)
+	if err != nil {
+		h.httpJSONError(w, "Unknown format", http.StatusBadRequest)
+		return
+	}
+
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	reader := bulk.NewReader(r.Body, format)
+
+	row, err := reader.Read()
+	var rowErr *bulk.RowError
+	if err != nil && !errors.As(err, &rowErr) {
+		if errors.Is(err, io.EOF) {
+			h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		h.httpJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	bw := bulk.NewWriter(w, format)
+	for {
+		var result bulk.Result
+		switch {
+		case errors.As(err, &rowErr):
+			result = bulk.Result{
+				OriginalURL: row.OriginalURL,
+				Status:      bulk.StatusError,
+				Error:       rowErr.Err.Error(),
+			}
+		case err != nil:
+			if !errors.Is(err, io.EOF) {
+				h.log(r.Context()).Error("unable to read import", zap.Error(err))
+			}
+			err = bw.Flush()
+			if err != nil {
+				h.log(r.Context()).Warn("write failed", zap.Error(err))
+			}
+			return
+		default:
+			result = h.importRow(r.Context(), user, row)
+		}
+		result.Row = reader.Row()
+
+		err = bw.Write(result)
+		if err != nil {
+			h.log(r.Context()).Warn("write failed", zap.Error(err))
+			return
+		}
+
+		row, err = reader.Read()
+	}
+}
+
+// importRow - создать короткую ссылку для строки загрузки.
+func (h *Handler) importRow(ctx context.Context, user repositories.User, row bulk.Row) bulk.Result {
+	result := bulk.Result{
+		OriginalURL: row.OriginalURL,
+		Status:      bulk.StatusCreated,
+	}
+
+	url, err := h.targetURL(row.OriginalURL)
+	if err != nil {
+		result.Status = bulk.StatusError
+		result.Error = err.Error()
+		return result
+	}
+
+	opts, err := h.linkOptions(row.ExpiresAt, row.MaxHits, row.Alias)
+	if err != nil {
+		result.Status = bulk.StatusError
+		result.Error = err.Error()
+		return result
+	}
+	opts.Team = row.Team
+
+	_, err = h.quotas.Check(ctx, user, 1)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		result.Status = bulk.StatusError
+		result.Error = err.Error()
+		return result
+	}
+	if err != nil {
+		h.log(ctx).Error("unable to check quota", zap.Error(err))
+		result.Status = bulk.StatusError
+		result.Error = "server error"
+		return result
+	}
+
+	opts.Quota = h.quotas.Quota(user)
+	id, err := h.st.Add(ctx, url, user, opts)
+	switch {
+	case errors.Is(err, repositories.ErrURLAlreadyExists):
+		result.Status = bulk.StatusExists
+	case errors.Is(err, repositories.ErrIDAlreadyExists):
+		result.Status = bulk.StatusError
+		result.Error = "alias already exists"
+		return result
+	case errors.Is(err, quota.ErrQuotaExceeded),
+		errors.Is(err, repositories.ErrTeamNotFound),
+		errors.Is(err, repositories.ErrForbidden):
+		result.Status = bulk.StatusError
+		result.Error = err.Error()
+		return result
+	case err != nil:
+		h.log(ctx).Error("unable to add link", zap.Error(err))
+		result.Status = bulk.StatusError
+		result.Error = "server error"
+		return result
+	}
+
+	result.ShortURL = h.genShortLink(id)
+	return result
+}
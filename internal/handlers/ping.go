@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
+
+	"go.uber.org/zap"
 )
 
 // PingDB - обработчик для проверки связи с хранилищем.
@@ -15,6 +16,6 @@ func (h *Handler) PingDB(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	_, err := w.Write([]byte("OK"))
 	if err != nil {
-		log.Printf("write failed: %v", err)
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
 	}
 }
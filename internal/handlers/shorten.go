@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
-	"log"
 	"net/http"
+	"time"
+
+	"go.uber.org/zap"
 
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 )
 
@@ -15,7 +18,11 @@ import (
 type (
 	// ShortenURLRequest - структура запроса к ShortenURL.
 	ShortenURLRequest struct {
-		URL string `json:"url"` // Исходный URL.
+		ExpiresAt *time.Time          `json:"expires_at,omitempty"` // Время, после которого ссылка перестает работать.
+		URL       string              `json:"url"`                  // Исходный URL.
+		Alias     string              `json:"alias,omitempty"`      // Желаемый ID короткой ссылки.
+		MaxHits   uint64              `json:"max_hits,omitempty"`   // Максимальное количество переходов по ссылке.
+		Team      repositories.TeamID `json:"team,omitempty"`       // Команда, которой будет принадлежать ссылка.
 	}
 
 	// ShortenURLResponse - структура ответа от ShortenURL.
@@ -42,17 +49,51 @@ func (h *Handler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	url, err := h.targetURL(requestData.URL)
+	if err != nil {
+		h.httpJSONError(w, err.Error(), targetURLStatus(err))
+		return
+	}
+
+	opts, err := h.linkOptions(requestData.ExpiresAt, requestData.MaxHits, requestData.Alias)
+	if err != nil {
+		h.httpJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.Team = requestData.Team
+
 	user, err := authenticator.GetUser(r.Context())
 	if err != nil {
-		log.Printf("unable to parse user uuid: %v", err)
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
-	id, err := h.st.Add(r.Context(), requestData.URL, user)
-	if errors.Is(err, repositories.ErrURLAlreadyExists) {
+	usage, ok := h.checkQuota(w, r, user, 1)
+	if !ok {
+		return
+	}
+
+	opts.Quota = h.quotas.Quota(user)
+	id, err := h.st.Add(r.Context(), url, user, opts)
+	if err == nil {
+		usage.Created(1)
+	}
+	setQuotaHeaders(w, usage)
+
+	if errors.Is(err, repositories.ErrIDAlreadyExists) {
+		h.httpJSONError(w, "Alias already exists", http.StatusConflict)
+		return
+	} else if errors.Is(err, repositories.ErrURLAlreadyExists) {
 		w.WriteHeader(http.StatusConflict)
+	} else if errors.Is(err, quota.ErrQuotaExceeded) {
+		h.httpJSONError(w, err.Error(), http.StatusForbidden)
+		return
+	} else if errors.Is(err, repositories.ErrTeamNotFound) || errors.Is(err, repositories.ErrForbidden) {
+		h.teamError(w, r, err)
+		return
 	} else if err != nil {
+		h.log(r.Context()).Error("unable to add link", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
@@ -63,7 +104,7 @@ func (h *Handler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 
 	responseJSON, err := json.Marshal(response)
 	if err != nil {
-		log.Printf("unable to marshal response: %v", err)
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
@@ -71,6 +112,6 @@ func (h *Handler) ShortenURL(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	_, err = w.Write(responseJSON)
 	if err != nil {
-		log.Printf("write failed: %v", err)
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
 	}
 }
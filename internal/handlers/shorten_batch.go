@@ -3,11 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"time"
+
+	"go.uber.org/zap"
 
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 )
 
@@ -18,18 +22,25 @@ type correlationID = string
 type (
 	// BatchRequest - структура запроса к ShortenBatch.
 	BatchRequest struct {
-		CorrelationID correlationID    `json:"correlation_id"` // Уникальный ID ссылки в текущем запросе.
-		OriginalURL   repositories.URL `json:"original_url"`   // Исходный URL.
+		ExpiresAt     *time.Time       `json:"expires_at,omitempty"` // Время, после которого ссылка перестает работать.
+		CorrelationID correlationID    `json:"correlation_id"`       // Уникальный ID ссылки в текущем запросе.
+		OriginalURL   repositories.URL `json:"original_url"`         // Исходный URL.
+		Alias         repositories.ID  `json:"alias,omitempty"`      // Желаемый ID короткой ссылки.
+		MaxHits       uint64           `json:"max_hits,omitempty"`   // Максимальное количество переходов по ссылке.
 	}
 
 	// BatchResponse - структура ответа от ShortenBatch.
 	BatchResponse struct {
-		CorrelationID correlationID    `json:"correlation_id"` // Уникальный ID ссылки в текущем запросе.
-		ShortURL      repositories.URL `json:"short_url"`      // Сокращенный URL.
+		CorrelationID correlationID    `json:"correlation_id"`      // Уникальный ID ссылки в текущем запросе.
+		ShortURL      repositories.URL `json:"short_url,omitempty"` // Сокращенный URL.
+		Error         string           `json:"error,omitempty"`     // Почему ссылку не удалось создать.
 	}
 )
 
 // ShortenBatch - обработчик для создания пачки коротких ссылок через JSON POST body.
+//
+// Ссылки сохраняются в хранилище одной пачкой. Если ссылку не удалось создать,
+// например, алиас уже занят, в ответе для нее вместо short_url будет error.
 func (h *Handler) ShortenBatch(w http.ResponseWriter, r *http.Request) {
 	b, err := io.ReadAll(r.Body)
 	if err != nil || len(b) == 0 {
@@ -46,38 +57,71 @@ func (h *Handler) ShortenBatch(w http.ResponseWriter, r *http.Request) {
 
 	user, err := authenticator.GetUser(r.Context())
 	if err != nil {
-		log.Printf("unable to parse user uuid: %v", err)
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
-	response := make([]BatchResponse, 0, len(requestData))
-	var id repositories.ID
-	for _, link := range requestData {
-		id, err = h.st.Add(r.Context(), link.OriginalURL, user)
-		if !errors.Is(err, repositories.ErrURLAlreadyExists) && err != nil {
-			h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+	links := make([]repositories.BatchLink, len(requestData))
+	for i, link := range requestData {
+		links[i].URL, err = h.targetURL(link.OriginalURL)
+		if err != nil {
+			h.httpJSONError(w, fmt.Sprintf("%s: %v", link.CorrelationID, err), targetURLStatus(err))
 			return
 		}
+		links[i].Opts, err = h.linkOptions(link.ExpiresAt, link.MaxHits, link.Alias)
+		if err != nil {
+			h.httpJSONError(w, fmt.Sprintf("%s: %v", link.CorrelationID, err), http.StatusBadRequest)
+			return
+		}
+		links[i].Opts.Quota = h.quotas.Quota(user)
+	}
 
-		response = append(response, BatchResponse{
-			CorrelationID: link.CorrelationID,
-			ShortURL:      h.genShortLink(id),
-		})
+	usage, ok := h.checkQuota(w, r, user, len(requestData))
+	if !ok {
+		return
+	}
+
+	results, err := h.st.AddBatch(r.Context(), links, user)
+	if errors.Is(err, quota.ErrQuotaExceeded) {
+		setQuotaHeaders(w, usage)
+		h.httpJSONError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		h.log(r.Context()).Error("unable to add links", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]BatchResponse, 0, len(requestData))
+	for i, link := range requestData {
+		res := BatchResponse{CorrelationID: link.CorrelationID}
+		switch err = results[i].Err; {
+		case err == nil:
+			usage.Created(1)
+			res.ShortURL = h.genShortLink(results[i].ID)
+		case errors.Is(err, repositories.ErrURLAlreadyExists):
+			res.ShortURL = h.genShortLink(results[i].ID)
+		default:
+			res.Error = err.Error()
+		}
+		response = append(response, res)
 	}
 
 	responseJSON, err := json.Marshal(&response)
 	if err != nil {
-		log.Printf("unable to marshal response: %v", err)
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
 		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
+	setQuotaHeaders(w, usage)
 	w.WriteHeader(http.StatusCreated)
 	_, err = w.Write(responseJSON)
 	if err != nil {
-		log.Printf("write failed: %v", err)
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
 	}
 }
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/teams"
+)
+
+// Типы, которые используют обработчики команд.
+type (
+	// CreateTeamRequest - структура запроса к CreateTeam.
+	CreateTeamRequest struct {
+		Name string `json:"name"` // Название команды.
+	}
+
+	// CreateTeamInviteRequest - структура запроса к CreateTeamInvite.
+	CreateTeamInviteRequest struct {
+		Role repositories.Role `json:"role"`          // Роль, которую получит приглашенный.
+		TTL  string            `json:"ttl,omitempty"` // Срок действия приглашения, например "24h".
+	}
+
+	// JoinTeamRequest - структура запроса к JoinTeam.
+	JoinTeamRequest struct {
+		Token string `json:"token"` // Токен приглашения.
+	}
+)
+
+// CreateTeam - обработчик для создания команды, текущий пользователь становится ее владельцем.
+func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	var requestData CreateTeamRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	team, err := h.teams.Create(r.Context(), user, requestData.Name)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusCreated, team)
+}
+
+// GetUserTeams - обработчик, который возвращает команды текущего пользователя.
+func (h *Handler) GetUserTeams(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	list, err := h.teams.List(r.Context(), user)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	if len(list) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, list)
+}
+
+// GetTeamMembers - обработчик, который возвращает участников команды.
+func (h *Handler) GetTeamMembers(w http.ResponseWriter, r *http.Request) {
+	team, user, ok := h.teamRequest(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := h.teams.Members(r.Context(), team, user)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, members)
+}
+
+// CreateTeamInvite - обработчик для создания приглашения в команду, доступен только владельцу.
+func (h *Handler) CreateTeamInvite(w http.ResponseWriter, r *http.Request) {
+	team, user, ok := h.teamRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var requestData CreateTeamInviteRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	var ttl time.Duration
+	if requestData.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(requestData.TTL)
+		if err != nil || ttl <= 0 {
+			h.httpJSONError(w, "Wrong ttl", http.StatusBadRequest)
+			return
+		}
+	}
+
+	invite, err := h.teams.Invite(r.Context(), team, user, requestData.Role, ttl)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusCreated, invite)
+}
+
+// RevokeTeamInvite - обработчик для отзыва приглашения в команду, доступен только владельцу.
+func (h *Handler) RevokeTeamInvite(w http.ResponseWriter, r *http.Request) {
+	team, user, ok := h.teamRequest(w, r)
+	if !ok {
+		return
+	}
+
+	invite, err := uuid.Parse(chi.URLParam(r, "InviteID"))
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.teams.RevokeInvite(r.Context(), team, user, invite)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JoinTeam - обработчик для вступления в команду по приглашению.
+func (h *Handler) JoinTeam(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	var requestData JoinTeamRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	team, err := h.teams.Join(r.Context(), requestData.Token, user)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, team)
+}
+
+// SetTeamMember - обработчик для добавления участника в команду или изменения его роли.
+//
+// Доступен только владельцу команды, роль передается в теле запроса как {"role": "editor"}.
+func (h *Handler) SetTeamMember(w http.ResponseWriter, r *http.Request) {
+	team, user, ok := h.teamRequest(w, r)
+	if !ok {
+		return
+	}
+
+	member, err := uuid.Parse(chi.URLParam(r, "UserID"))
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	var requestData repositories.TeamMember
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+
+	err = h.teams.SetRole(r.Context(), team, user, member, requestData.Role)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, repositories.TeamMember{Role: requestData.Role, User: member})
+}
+
+// RemoveTeamMember - обработчик для исключения участника из команды.
+//
+// Исключать других может только владелец, выйти из команды может любой ее участник.
+func (h *Handler) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	team, user, ok := h.teamRequest(w, r)
+	if !ok {
+		return
+	}
+
+	member, err := uuid.Parse(chi.URLParam(r, "UserID"))
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.teams.Remove(r.Context(), team, user, member)
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// teamRequest - получить ID команды из пути и текущего пользователя.
+//
+// Если не получилось, сам отвечает клиенту и возвращает false.
+func (h *Handler) teamRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+) (team repositories.TeamID, user repositories.User, ok bool) {
+	team, err := uuid.Parse(chi.URLParam(r, "TeamID"))
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return team, user, false
+	}
+
+	user, err = authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return team, user, false
+	}
+
+	return team, user, true
+}
+
+// teamError - ответить клиенту ошибкой, связанной с командами.
+func (h *Handler) teamError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrTeamNotFound):
+		h.httpJSONError(w, "Team not found", http.StatusNotFound)
+	case errors.Is(err, repositories.ErrForbidden):
+		h.httpJSONError(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, repositories.ErrInviteNotFound):
+		h.httpJSONError(w, "Invite not found", http.StatusNotFound)
+	case errors.Is(err, repositories.ErrLastOwner),
+		errors.Is(err, repositories.ErrInviteUsed),
+		errors.Is(err, repositories.ErrInviteRevoked):
+		h.httpJSONError(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repositories.ErrUnknownRole),
+		errors.Is(err, teams.ErrWrongName),
+		errors.Is(err, teams.ErrWrongInvite),
+		errors.Is(err, teams.ErrInviteExpired):
+		h.httpJSONError(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.log(r.Context()).Error("team request failed", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+	}
+}
+
+// readJSON - прочитать JSON из тела запроса в v.
+//
+// Если не получилось, сам отвечает клиенту и возвращает false.
+func (h *Handler) readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	b, err := io.ReadAll(r.Body)
+	if err != nil || len(b) == 0 {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return false
+	}
+
+	err = json.Unmarshal(b, v)
+	if err != nil {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// writeJSON - ответить клиенту v в формате JSON.
+func (h *Handler) writeJSON(w http.ResponseWriter, r *http.Request, code int, v any) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		h.log(r.Context()).Error("unable to marshal response", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_, err = w.Write(response)
+	if err != nil {
+		h.log(r.Context()).Warn("write failed", zap.Error(err))
+	}
+}
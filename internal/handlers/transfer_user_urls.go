@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// TransferUserURLsRequest - структура запроса к TransferUserURLs.
+type TransferUserURLsRequest struct {
+	IDs  []repositories.ID   `json:"ids"`  // ID ссылок.
+	Team repositories.TeamID `json:"team"` // Команда, нулевой UUID - личные ссылки пользователя.
+}
+
+// TransferUserURLs - обработчик для передачи ссылок в команду или обратно в личные ссылки пользователя.
+//
+// Передавать можно только ссылки, владельцем которых является пользователь,
+// в команду, где у него роль не ниже editor. Ссылки передаются все или ни одной.
+func (h *Handler) TransferUserURLs(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	var requestData TransferUserURLsRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+	if len(requestData.IDs) == 0 {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	err = h.st.TransferLinks(r.Context(), requestData.IDs, user, requestData.Team)
+	if errors.Is(err, repositories.ErrURLNotFound) {
+		h.httpJSONError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repositories.ErrURLAlreadyExists) {
+		h.httpJSONError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.teamError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
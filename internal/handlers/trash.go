@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Типы, которые используют обработчики удаленных ссылок.
+type (
+	// DeletedUserLink - удаленная ссылка пользователя.
+	DeletedUserLink struct {
+		CreatedAt    time.Time        `json:"created_at"`    // Время создания ссылки.
+		DeletedAt    time.Time        `json:"deleted_at"`    // Время удаления ссылки.
+		RestoreUntil time.Time        `json:"restore_until"` // До какого времени ссылку можно восстановить.
+		ShortURL     repositories.URL `json:"short_url"`     // Сокращенный URL.
+		OriginalURL  repositories.URL `json:"original_url"`  // Исходный URL.
+	}
+
+	// PurgeDeletedResponse - структура ответа от PurgeDeleted.
+	PurgeDeletedResponse struct {
+		Purged int64 `json:"purged"` // Сколько ссылок удалено безвозвратно.
+	}
+)
+
+// GetDeletedUserURLs - обработчик, который возвращает удаленные ссылки пользователя,
+// которые еще можно восстановить.
+func (h *Handler) GetDeletedUserURLs(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	links, err := h.st.GetDeletedUserLinks(r.Context(), user)
+	if err != nil {
+		h.log(r.Context()).Error("unable to get deleted links", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	since := time.Now().Add(-h.restoreWindow)
+	response := make([]DeletedUserLink, 0, len(links))
+	for _, link := range links {
+		if link.DeletedAt.Before(since) {
+			continue
+		}
+		response = append(response, DeletedUserLink{
+			CreatedAt:    link.CreatedAt,
+			DeletedAt:    link.DeletedAt,
+			RestoreUntil: link.DeletedAt.Add(h.restoreWindow),
+			ShortURL:     h.genShortLink(link.ID),
+			OriginalURL:  link.URL,
+		})
+	}
+
+	if len(response) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, response)
+}
+
+// RestoreUserURLs - обработчик для восстановления удаленных ссылок пользователя.
+//
+// Восстановить можно только ссылки, удаленные не раньше, чем restoreWindow назад.
+// Возвращает ID восстановленных ссылок, остальные ссылки пропускаются.
+func (h *Handler) RestoreUserURLs(w http.ResponseWriter, r *http.Request) {
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]repositories.ID, 0)
+	if !h.readJSON(w, r, &ids) {
+		return
+	}
+
+	restored, err := h.st.RestoreUserLinks(r.Context(), ids, user, time.Now().Add(-h.restoreWindow))
+	if err != nil {
+		h.log(r.Context()).Error("unable to restore links", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, restored)
+}
+
+// PurgeDeleted - обработчик для безвозвратного удаления ссылок, удаленных дольше срока хранения назад.
+//
+// Доступен только из доверенной сети. Срок хранения можно передать параметром older_than,
+// например "720h", по умолчанию используется срок из конфигурации.
+func (h *Handler) PurgeDeleted(w http.ResponseWriter, r *http.Request) {
+	if !h.checkTrustedSubnet(w, r) {
+		return
+	}
+
+	retention := h.trashRetention
+	if s := r.URL.Query().Get("older_than"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			h.httpJSONError(w, "Wrong older_than", http.StatusBadRequest)
+			return
+		}
+		retention = d
+	} else if retention <= 0 {
+		h.httpJSONError(w, "Retention is not configured", http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.st.PurgeDeleted(r.Context(), time.Now().Add(-retention))
+	if err != nil {
+		h.log(r.Context()).Error("unable to purge deleted links", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, PurgeDeletedResponse{Purged: count})
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// UpdateUserURLRequest - структура запроса к UpdateUserURL.
+type UpdateUserURLRequest struct {
+	URL string `json:"url"` // Новый исходный URL.
+}
+
+// UpdateUserURL - обработчик для замены исходного URL ссылки пользователя.
+//
+// Короткая ссылка не меняется, прежний URL сохраняется в истории, см. GetUserURLHistory.
+func (h *Handler) UpdateUserURL(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "ID")
+	if id == "" {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	var requestData UpdateUserURLRequest
+	if !h.readJSON(w, r, &requestData) {
+		return
+	}
+	if requestData.URL == "" {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	url, err := h.targetURL(requestData.URL)
+	if err != nil {
+		h.httpJSONError(w, err.Error(), targetURLStatus(err))
+		return
+	}
+
+	link, err := h.st.Update(r.Context(), id, url, user)
+	if errors.Is(err, repositories.ErrURLNotFound) {
+		h.httpJSONError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repositories.ErrUserNotMatch) {
+		h.httpJSONError(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, repositories.ErrURLAlreadyExists) {
+		h.httpJSONError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.log(r.Context()).Error("unable to update link", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, UserLink{
+		ShortURL:    h.genShortLink(id),
+		OriginalURL: link.URL,
+		CreatedAt:   link.CreatedAt,
+	})
+}
+
+// GetUserURLHistory - обработчик, который возвращает прежние исходные URL ссылки, от старых к новым.
+func (h *Handler) GetUserURLHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "ID")
+	if id == "" {
+		h.httpJSONError(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	user, err := authenticator.GetUser(r.Context())
+	if err != nil {
+		h.log(r.Context()).Error("unable to parse user uuid", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	versions, err := h.st.GetLinkHistory(r.Context(), id, user)
+	if errors.Is(err, repositories.ErrURLNotFound) {
+		h.httpJSONError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repositories.ErrUserNotMatch) {
+		h.httpJSONError(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		h.log(r.Context()).Error("unable to get link history", zap.Error(err))
+		h.httpJSONError(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, versions)
+}
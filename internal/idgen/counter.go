@@ -0,0 +1,182 @@
+package idgen
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+const (
+	maxSkipShift = 20 // Больше 2^20 ID за одну попытку счетчик не пропускает.
+	mixRounds    = 3  // Сколько раундов перемешивания проходит счетчик в Hashids.
+)
+
+// counter - счетчик выданных ID.
+//
+// При коллизии счетчик пропускает 2^attempt-1 значений: после перезапуска без Seed
+// он быстро проходит уже занятый диапазон.
+type counter struct {
+	next atomic.Uint64
+}
+
+// take - получить значение счетчика для попытки.
+func (c *counter) take(attempt int) uint64 {
+	if attempt > maxSkipShift {
+		attempt = maxSkipShift
+	}
+	step := uint64(1) << attempt
+	return c.next.Add(step) - 1
+}
+
+// Seed - продвинуть счетчик не меньше, чем до n.
+func (c *counter) Seed(n uint64) {
+	for {
+		cur := c.next.Load()
+		if cur >= n || c.next.CompareAndSwap(cur, n) {
+			return
+		}
+	}
+}
+
+// Sequential - генератор ID из счетчика.
+type Sequential struct {
+	alphabet string
+	length   int
+	counter
+}
+
+// NewSequential - конструктор для Sequential.
+//
+// length - минимальная длина ID: короткие значения дополняются слева первым символом алфавита.
+func NewSequential(alphabet string, length int) *Sequential {
+	return &Sequential{alphabet: alphabet, length: length}
+}
+
+// Generate - выдать следующее значение счетчика, url не используется.
+func (g *Sequential) Generate(_ string, attempt int) (string, error) {
+	return encode(g.take(attempt), g.alphabet, g.length), nil
+}
+
+// Decode - значение счетчика, из которого получен id.
+func (g *Sequential) Decode(id string) (n uint64, ok bool) {
+	return decode(id, g.alphabet, g.length)
+}
+
+// Hashids - генератор ID из счетчика, перемешанного с солью.
+//
+// Перемешивание взаимно однозначно, поэтому разные значения счетчика дают разные ID,
+// но соседние ID не похожи друг на друга и по ним нельзя угадать следующие.
+type Hashids struct {
+	alphabet string
+	length   int
+	bits     int // Сколько бит счетчика помещается в ID длины length.
+	keys     [mixRounds]uint64
+	muls     [mixRounds]uint64
+	inverses [mixRounds]uint64 // Обратные к muls по модулю 2^64, для unmix.
+	counter
+}
+
+// NewHashids - конструктор для Hashids.
+//
+// Алфавит перемешивается солью, length - минимальная длина ID.
+func NewHashids(alphabet string, length int, salt string) *Hashids {
+	sum := sha256.Sum256([]byte(salt))
+
+	shuffled := []byte(alphabet)
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j := (int(sum[i%len(sum)]) + i) % (i + 1)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+
+	g := &Hashids{
+		alphabet: string(shuffled),
+		length:   length,
+		bits:     int(float64(length) * math.Log2(float64(len(alphabet)))),
+	}
+	if g.bits > 63 {
+		g.bits = 63
+	}
+
+	for i := 0; i < mixRounds; i++ {
+		g.keys[i] = binary.BigEndian.Uint64(sum[i*8:])
+		g.muls[i] = binary.BigEndian.Uint64(sum[(i+1)*8:]) | 1
+		g.inverses[i] = inverse(g.muls[i])
+	}
+
+	return g
+}
+
+// Generate - выдать следующее значение счетчика в перемешанном виде, url не используется.
+func (g *Hashids) Generate(_ string, attempt int) (string, error) {
+	return encode(g.scramble(g.take(attempt)), g.alphabet, g.length), nil
+}
+
+// Decode - значение счетчика, из которого получен id.
+func (g *Hashids) Decode(id string) (n uint64, ok bool) {
+	n, ok = decode(id, g.alphabet, g.length)
+	if !ok {
+		return 0, false
+	}
+	return g.unscramble(n), true
+}
+
+// scramble - взаимно однозначно перемешать n.
+//
+// Значения, которые помещаются в g.bits бит, перемешиваются внутри этого диапазона,
+// поэтому ID не длиннее length. Старшие значения перемешиваются внутри своего
+// диапазона [2^(k-1), 2^k), и диапазоны не пересекаются.
+func (g *Hashids) scramble(n uint64) uint64 {
+	k := bits.Len64(n)
+	if k <= g.bits {
+		return g.mix(n, g.bits)
+	}
+
+	high := uint64(1) << (k - 1)
+	return high | g.mix(n^high, k-1)
+}
+
+// mix - взаимно однозначно перемешать младшие k бит x.
+func (g *Hashids) mix(x uint64, k int) uint64 {
+	mask := uint64(1)<<k - 1
+	for i := 0; i < mixRounds; i++ {
+		x = (x ^ g.keys[i]) & mask
+		x = (x * g.muls[i]) & mask
+		x ^= x >> (k/2 + 1)
+	}
+	return x
+}
+
+// unscramble - обратное к scramble.
+func (g *Hashids) unscramble(n uint64) uint64 {
+	k := bits.Len64(n)
+	if k <= g.bits {
+		return g.unmix(n, g.bits)
+	}
+
+	high := uint64(1) << (k - 1)
+	return high | g.unmix(n^high, k-1)
+}
+
+// unmix - обратное к mix: раунды проходятся в обратном порядке.
+//
+// Сдвиг в x ^= x >> (k/2 + 1) больше половины k, поэтому эта операция обратна сама себе.
+func (g *Hashids) unmix(x uint64, k int) uint64 {
+	mask := uint64(1)<<k - 1
+	for i := mixRounds - 1; i >= 0; i-- {
+		x ^= x >> (k/2 + 1)
+		x = (x * g.inverses[i]) & mask
+		x = (x ^ g.keys[i]) & mask
+	}
+	return x
+}
+
+// inverse - обратное к нечетному a по модулю 2^64, методом Ньютона.
+func inverse(a uint64) uint64 {
+	x := a // Верно в младших 3 битах, каждая итерация удваивает количество верных бит.
+	for i := 0; i < 5; i++ {
+		x *= 2 - a*x
+	}
+	return x
+}
@@ -0,0 +1,49 @@
+package idgen
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"strconv"
+)
+
+// Hash - генератор ID из хеша исходного URL.
+//
+// Одинаковые URL получают одинаковые ID, поэтому при выключенном поиске совпадающих URL
+// повторная ссылка на тот же URL получит ID со следующей попытки.
+type Hash struct {
+	alphabet string
+	growth
+}
+
+// NewHash - конструктор для Hash.
+//
+// Если доля попыток с коллизией превышает threshold, длина ID увеличивается на один символ.
+func NewHash(alphabet string, length int, threshold float64) *Hash {
+	return &Hash{
+		alphabet: alphabet,
+		growth:   growth{length: length, threshold: threshold},
+	}
+}
+
+// Generate - сгенерировать ID из SHA-256 от url и номера попытки.
+func (g *Hash) Generate(url string, attempt int) (string, error) {
+	length := g.observe(attempt)
+
+	data := url
+	if attempt > 0 {
+		data += "\x00" + strconv.Itoa(attempt)
+	}
+	sum := sha256.Sum256([]byte(data))
+
+	n := new(big.Int).SetBytes(sum[:])
+	base := big.NewInt(int64(len(g.alphabet)))
+	mod := new(big.Int)
+
+	buf := make([]byte, length)
+	for i := range buf {
+		n.DivMod(n, base, mod)
+		buf[i] = g.alphabet[mod.Int64()]
+	}
+
+	return string(buf), nil
+}
@@ -0,0 +1,196 @@
+// Package idgen хранит стратегии генерации ID для коротких ссылок.
+package idgen
+
+import (
+	"errors"
+	"math/bits"
+	"strings"
+	"sync"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+// Стратегии генерации ID.
+const (
+	StrategyRandom     = "random"     // Случайные символы из алфавита.
+	StrategySequential = "sequential" // Счетчик в системе счисления по основанию длины алфавита.
+	StrategyHashids    = "hashids"    // Счетчик, перемешанный с солью, чтобы ID нельзя было перебрать по порядку.
+	StrategyHash       = "hash"       // Хеш от исходного URL: одинаковые URL получают одинаковые ID.
+)
+
+const (
+	// MaxAttempts - сколько раз хранилище пробует сгенерировать свободный ID для одной ссылки.
+	MaxAttempts = 10
+
+	// DefaultAlphabet - символы base62 без похожих друг на друга 0/O/o и 1/l/I.
+	DefaultAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	// DefaultLength - длина ID по умолчанию.
+	DefaultLength = 5
+
+	maxLength    = 32  // Максимальная длина ID, до которой он может вырасти.
+	growthWindow = 100 // Сколько попыток генерации учитывается при подсчете доли коллизий.
+	urlSafe      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+)
+
+// Ошибки конфигурации генератора.
+var (
+	ErrUnknownStrategy = errors.New("unknown id strategy") // Неизвестная стратегия генерации ID.
+	ErrWrongAlphabet   = errors.New("wrong id alphabet")   // Алфавит слишком короткий или с недопустимыми символами.
+	ErrWrongLength     = errors.New("wrong id length")     // Длина ID вне допустимых границ.
+)
+
+// Default - генератор, который используют хранилища, если стратегия не задана.
+var Default IDGenerator = NewRandom(DefaultAlphabet, DefaultLength, 0)
+
+// IDGenerator - стратегия генерации ID для ссылок.
+type IDGenerator interface {
+	// Generate - сгенерировать ID для ссылки на url.
+	//
+	// attempt - номер попытки для этой ссылки, начиная с 0.
+	// Попытка больше 0 означает, что предыдущий ID оказался занят.
+	Generate(url string, attempt int) (string, error)
+}
+
+// Seeder - генератор со счетчиком, который нужно продвинуть за уже выданные ID при запуске.
+type Seeder interface {
+	// Seed - продвинуть счетчик не меньше, чем до n.
+	Seed(n uint64)
+	// Decode - значение счетчика, из которого получен id.
+	//
+	// Если ok == false, генератор не мог выдать такой ID.
+	Decode(id string) (n uint64, ok bool)
+}
+
+// New - создать генератор по конфигурации.
+func New(cfg configs.Config) (IDGenerator, error) {
+	alphabet := cfg.IDAlphabet
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	err := validateAlphabet(alphabet)
+	if err != nil {
+		return nil, err
+	}
+
+	length := cfg.IDLength
+	if length == 0 {
+		length = DefaultLength
+	}
+	if length < 1 || length > maxLength {
+		return nil, ErrWrongLength
+	}
+
+	switch cfg.IDStrategy {
+	case "", StrategyRandom:
+		return NewRandom(alphabet, length, cfg.IDGrowthThreshold), nil
+	case StrategySequential:
+		return NewSequential(alphabet, length), nil
+	case StrategyHashids:
+		return NewHashids(alphabet, length, cfg.IDSalt), nil
+	case StrategyHash:
+		return NewHash(alphabet, length, cfg.IDGrowthThreshold), nil
+	default:
+		return nil, ErrUnknownStrategy
+	}
+}
+
+// validateAlphabet - проверить, что из символов алфавита можно собрать ID для URL.
+func validateAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return ErrWrongAlphabet
+	}
+
+	for i, char := range alphabet {
+		if !strings.ContainsRune(urlSafe, char) || strings.ContainsRune(alphabet[i+1:], char) {
+			return ErrWrongAlphabet
+		}
+	}
+
+	return nil
+}
+
+// encode - записать n в системе счисления по основанию длины алфавита,
+// дополнив слева нулевым символом до length символов.
+func encode(n uint64, alphabet string, length int) string {
+	base := uint64(len(alphabet))
+
+	buf := make([]byte, 0, length)
+	for n > 0 {
+		buf = append(buf, alphabet[n%base])
+		n /= base
+	}
+	for len(buf) < length {
+		buf = append(buf, alphabet[0])
+	}
+
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+
+	return string(buf)
+}
+
+// decode - обратное к encode: ok == false, если id не мог получиться из encode.
+func decode(id, alphabet string, length int) (n uint64, ok bool) {
+	base := uint64(len(alphabet))
+
+	for i := 0; i < len(id); i++ {
+		digit := strings.IndexByte(alphabet, id[i])
+		if digit < 0 {
+			return 0, false
+		}
+		hi, lo := bits.Mul64(n, base)
+		n = lo + uint64(digit)
+		if hi != 0 || n < lo {
+			return 0, false
+		}
+	}
+
+	// Лишние ведущие нулевые символы encode не дописывает.
+	if encode(n, alphabet, length) != id {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// growth - длина ID, которая растет, когда доля коллизий превышает порог.
+type growth struct {
+	mu         sync.Mutex
+	length     int
+	threshold  float64 // Доля попыток с коллизией, после которой длина растет, 0 - не расти.
+	attempts   int
+	collisions int
+}
+
+// observe - учесть попытку генерации и вернуть длину ID для нее.
+//
+// Доля коллизий считается по окнам из growthWindow попыток.
+func (g *growth) observe(attempt int) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.attempts++
+	if attempt > 0 {
+		g.collisions++
+	}
+
+	if g.attempts >= growthWindow {
+		rate := float64(g.collisions) / float64(g.attempts)
+		if g.threshold > 0 && rate > g.threshold && g.length < maxLength {
+			g.length++
+		}
+		g.attempts, g.collisions = 0, 0
+	}
+
+	return g.length
+}
+
+// Length - текущая длина ID.
+func (g *growth) Length() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.length
+}
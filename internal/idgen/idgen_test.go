@@ -0,0 +1,182 @@
+package idgen
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  configs.Config
+		want IDGenerator
+		err  error
+	}{
+		{name: "default", cfg: configs.Config{}, want: &Random{}},
+		{name: "sequential", cfg: configs.Config{IDStrategy: StrategySequential}, want: &Sequential{}},
+		{name: "hashids", cfg: configs.Config{IDStrategy: StrategyHashids}, want: &Hashids{}},
+		{name: "hash", cfg: configs.Config{IDStrategy: StrategyHash}, want: &Hash{}},
+		{name: "unknown", cfg: configs.Config{IDStrategy: "uuid"}, err: ErrUnknownStrategy},
+		{name: "short alphabet", cfg: configs.Config{IDAlphabet: "a"}, err: ErrWrongAlphabet},
+		{name: "repeated chars", cfg: configs.Config{IDAlphabet: "abca"}, err: ErrWrongAlphabet},
+		{name: "unsafe chars", cfg: configs.Config{IDAlphabet: "ab/"}, err: ErrWrongAlphabet},
+		{name: "wrong length", cfg: configs.Config{IDLength: 100}, err: ErrWrongLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := New(tt.cfg)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, gen)
+		})
+	}
+}
+
+func TestRandom(t *testing.T) {
+	gen := NewRandom(DefaultAlphabet, DefaultLength, 0)
+
+	id, err := gen.Generate("https://example.com", 0)
+	require.NoError(t, err)
+	assert.Len(t, id, DefaultLength)
+	for _, char := range id {
+		assert.Contains(t, DefaultAlphabet, string(char))
+	}
+	assert.NotContains(t, DefaultAlphabet, "0")
+	assert.NotContains(t, DefaultAlphabet, "l")
+}
+
+func TestRandom_Growth(t *testing.T) {
+	gen := NewRandom(DefaultAlphabet, 3, 0.1)
+
+	for i := 0; i < growthWindow; i++ {
+		_, err := gen.Generate("", 0)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, gen.Length(), "no collisions")
+
+	for i := 0; i < growthWindow; i++ {
+		_, err := gen.Generate("", i%2)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 4, gen.Length(), "half of attempts collided")
+
+	id, err := gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Len(t, id, 4)
+}
+
+func TestSequential(t *testing.T) {
+	gen := NewSequential("ab", 3)
+
+	ids := make([]string, 0, 4)
+	for i := 0; i < 4; i++ {
+		id, err := gen.Generate("", 0)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.Equal(t, []string{"aaa", "aab", "aba", "abb"}, ids)
+
+	id, err := gen.Generate("", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "bbb", id, "collision skips 2^attempt-1 values")
+	id, err = gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "baaa", id, "length grows past the minimum")
+
+	gen.Seed(10)
+	id, err = gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "baba", id)
+	gen.Seed(1)
+	id, err = gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "babb", id, "seed never moves the counter back")
+
+	n, ok := gen.Decode("babb")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(11), n)
+	_, ok = gen.Decode("abab")
+	assert.False(t, ok, "generated ids have no extra leading zeros")
+	_, ok = gen.Decode("abc")
+	assert.False(t, ok, "c is not in the alphabet")
+}
+
+func TestHashids(t *testing.T) {
+	gen := NewHashids(DefaultAlphabet, 3, "salt")
+
+	seen := make(map[string]struct{})
+	for n := uint64(0); n < 1<<18; n++ {
+		id := encode(gen.scramble(n), gen.alphabet, gen.length)
+		_, exists := seen[id]
+		require.False(t, exists, "scramble must be a bijection")
+		seen[id] = struct{}{}
+
+		if bits.Len64(n) <= gen.bits {
+			require.Len(t, id, 3)
+		}
+
+		decoded, ok := gen.Decode(id)
+		require.True(t, ok)
+		require.Equal(t, n, decoded)
+	}
+	for _, n := range []uint64{1 << 18, 1<<40 + 12345, 1<<63 - 1} {
+		decoded, ok := gen.Decode(encode(gen.scramble(n), gen.alphabet, gen.length))
+		require.True(t, ok)
+		assert.Equal(t, n, decoded, "ids longer than length decode too")
+	}
+
+	first, err := gen.Generate("", 0)
+	require.NoError(t, err)
+	second, err := gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	other := NewHashids(DefaultAlphabet, 3, "pepper")
+	id, err := other.Generate("", 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, id, "salt changes ids")
+}
+
+func TestHash(t *testing.T) {
+	gen := NewHash(DefaultAlphabet, 7, 0)
+
+	first, err := gen.Generate("https://example.com", 0)
+	require.NoError(t, err)
+	assert.Len(t, first, 7)
+
+	again, err := gen.Generate("https://example.com", 0)
+	require.NoError(t, err)
+	assert.Equal(t, first, again, "same URL gives same id")
+
+	retry, err := gen.Generate("https://example.com", 1)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, retry)
+
+	other, err := gen.Generate("https://example.org", 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other)
+}
+
+func BenchmarkRandom(b *testing.B) {
+	gen := NewRandom(DefaultAlphabet, DefaultLength, 0)
+	for i := 0; i < b.N; i++ {
+		_, err := gen.Generate("", 0)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkHashids(b *testing.B) {
+	gen := NewHashids(DefaultAlphabet, DefaultLength, "salt")
+	for i := 0; i < b.N; i++ {
+		_, err := gen.Generate("", 0)
+		require.NoError(b, err)
+	}
+}
@@ -0,0 +1,39 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// Random - генератор случайных ID из символов алфавита.
+type Random struct {
+	alphabet string
+	growth
+}
+
+// NewRandom - конструктор для Random.
+//
+// Если доля попыток с коллизией превышает threshold, длина ID увеличивается на один символ.
+func NewRandom(alphabet string, length int, threshold float64) *Random {
+	return &Random{
+		alphabet: alphabet,
+		growth:   growth{length: length, threshold: threshold},
+	}
+}
+
+// Generate - сгенерировать случайный ID, url не используется.
+func (g *Random) Generate(_ string, attempt int) (string, error) {
+	length := g.observe(attempt)
+	base := big.NewInt(int64(len(g.alphabet)))
+
+	buf := make([]byte, length)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, base)
+		if err != nil {
+			return "", err
+		}
+		buf[i] = g.alphabet[n.Int64()]
+	}
+
+	return string(buf), nil
+}
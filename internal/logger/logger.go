@@ -0,0 +1,93 @@
+// Package logger создает структурированный логгер и хранит поля запроса, которые попадают в каждую его строку.
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+// Форматы вывода.
+const (
+	FormatJSON = "json" // Одна JSON-строка на запись, формат по умолчанию.
+	FormatText = "text" // Человекочитаемый текст.
+)
+
+// ErrUnknownFormat - неизвестный формат вывода логов.
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// New - создать логгер с уровнем и форматом из конфигурации.
+func New(cfg configs.Config) (*zap.Logger, error) {
+	level, err := zap.ParseAtomicLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	var zc zap.Config
+	switch cfg.LogFormat {
+	case "", FormatJSON:
+		zc = zap.NewProductionConfig()
+		zc.EncoderConfig.TimeKey = "time"
+		zc.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	case FormatText:
+		zc = zap.NewDevelopmentConfig()
+		zc.Development = false
+	default:
+		return nil, ErrUnknownFormat
+	}
+	zc.Level = level
+	zc.Sampling = nil
+	zc.DisableStacktrace = true
+
+	return zc.Build()
+}
+
+type ctxKey struct{}
+
+// fields - поля запроса, которые добавляются к логгеру.
+//
+// Хранятся по указателю, чтобы пользователь, определенный глубже в цепочке middleware,
+// попал и в строку access-лога, которую пишет внешнее middleware.
+type fields struct {
+	mu     sync.Mutex
+	fields []zap.Field
+}
+
+// WithRequest - добавить в контекст ID запроса.
+func WithRequest(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &fields{fields: []zap.Field{zap.String("request_id", requestID)}})
+}
+
+// SetUser - добавить ID пользователя к полям запроса из ctx.
+func SetUser(ctx context.Context, user uuid.UUID) {
+	f, ok := ctx.Value(ctxKey{}).(*fields)
+	if !ok {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fields = append(f.fields, zap.String("user_id", user.String()))
+}
+
+// Ctx - логгер l с полями запроса из ctx. Если l не задан, используется глобальный логгер.
+func Ctx(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if l == nil {
+		l = zap.L()
+	}
+
+	f, ok := ctx.Value(ctxKey{}).(*fields)
+	if !ok {
+		return l
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return l.With(f.fields...)
+}
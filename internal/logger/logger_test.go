@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+func TestNew(t *testing.T) {
+	for _, format := range []string{"", FormatJSON, FormatText} {
+		l, err := New(configs.Config{LogLevel: "warn", LogFormat: format})
+		require.NoError(t, err)
+		assert.False(t, l.Core().Enabled(zap.InfoLevel))
+		assert.True(t, l.Core().Enabled(zap.WarnLevel))
+	}
+
+	_, err := New(configs.Config{LogLevel: "info", LogFormat: "xml"})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = New(configs.Config{LogLevel: "loud", LogFormat: FormatJSON})
+	assert.Error(t, err)
+}
+
+func TestCtx(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	l := zap.New(core)
+
+	Ctx(context.Background(), l).Info("no request")
+	SetUser(context.Background(), uuid.New())
+
+	ctx := WithRequest(context.Background(), "req")
+	user := uuid.New()
+	SetUser(ctx, user)
+	Ctx(ctx, l).Info("request")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+	assert.Empty(t, entries[0].ContextMap())
+	assert.Equal(t, map[string]interface{}{
+		"request_id": "req",
+		"user_id":    user.String(),
+	}, entries[1].ContextMap())
+}
@@ -4,30 +4,42 @@ import (
 	"context"
 	"errors"
 	"net/http"
-	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/utils"
 )
 
 // UserCookie - middleware для аутентификации пользователя.
 //
-// Если пользователь обращается первый раз, то генерируем userID и передаем его в cookie.
-// Если у пользователя уже есть ID, то проверяем подпись.
+// Если в запросе есть заголовок Authorization: Bearer, пользователь определяется по API-ключу:
+// с неверным ключом запрос отклоняется с 401, а ключу только для чтения доступны лишь GET и HEAD.
+// Иначе, если пользователь обращается первый раз, то генерируем userID и передаем его в cookie.
+// Если у пользователя уже есть ID, то проверяем подпись, а у сессии учетной записи - что она
+// не истекла и не завершена. Иначе выдаем нового пользователя.
 func (m *Middlewares) UserCookie(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		cookie, err := r.Cookie("USER")
+		if header := r.Header.Get("Authorization"); header != "" {
+			m.apiKeyUser(next, w, r, header)
+			return
+		}
+
+		cookie, err := r.Cookie(authenticator.CookieName)
 		if errors.Is(err, http.ErrNoCookie) || len(cookie.Value) < 16 {
 			m.setUser(next, w, r, m.createNewUser(w))
 			return
 		}
 
-		user, err := m.a.Load(cookie.Value)
+		user, err := m.loadUser(r.Context(), cookie.Value)
 		if errors.Is(err, authenticator.ErrUnauthorized) {
 			user = m.createNewUser(w)
 		} else if err != nil {
+			logger.Ctx(r.Context(), m.logger).Error("session check failed", zap.Error(err))
 			http.Error(w, "Server error", http.StatusInternalServerError)
 			return
 		}
@@ -36,8 +48,50 @@ func (m *Middlewares) UserCookie(next http.Handler) http.Handler {
 	})
 }
 
+// loadUser - проверить подписанного пользователя, без acc сессии учетных записей не проверяются на выход.
+func (m *Middlewares) loadUser(ctx context.Context, signed string) (uuid.UUID, error) {
+	if m.acc == nil {
+		return m.a.Load(signed)
+	}
+	return m.acc.Verify(ctx, signed)
+}
+
+// apiKeyUser - определить пользователя по API-ключу из заголовка Authorization.
+func (m *Middlewares) apiKeyUser(next http.Handler, w http.ResponseWriter, r *http.Request, header string) {
+	token, ok := authenticator.BearerToken(header)
+
+	var key repositories.APIKey
+	err := accounts.ErrWrongKey
+	if ok && m.acc != nil {
+		key, err = m.acc.Authenticate(r.Context(), token)
+	}
+	if errors.Is(err, accounts.ErrWrongKey) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		logger.Ctx(r.Context(), m.logger).Error("API key check failed", zap.Error(err))
+		http.Error(w, "Server error", http.StatusInternalServerError)
+		return
+	}
+
+	need := repositories.ScopeWrite
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		need = repositories.ScopeRead
+	}
+	if !key.Scope.Allows(need) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+string(need)+`"`)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	m.setUser(next, w, r, key.Account)
+}
+
 // setUser добавляет userID в контекст и передает запрос следующему обработчику
 func (m *Middlewares) setUser(next http.Handler, w http.ResponseWriter, r *http.Request, user uuid.UUID) {
+	logger.SetUser(r.Context(), user)
 	ctx := context.WithValue(r.Context(), utils.ContextKey("userID"), user)
 	next.ServeHTTP(w, r.WithContext(ctx))
 }
@@ -45,15 +99,6 @@ func (m *Middlewares) setUser(next http.Handler, w http.ResponseWriter, r *http.
 // createNewUser - генерирует пользователя, подписывает cookie и передает их клиенту.
 func (m *Middlewares) createNewUser(w http.ResponseWriter) uuid.UUID {
 	user, signed := m.a.Gen()
-
-	cookie := http.Cookie{
-		Name:    "USER",
-		Value:   signed,
-		Expires: time.Now().Add(365 * 24 * time.Hour),
-		Path:    "/",
-	}
-
-	http.SetCookie(w, &cookie)
-
+	http.SetCookie(w, authenticator.Cookie(signed))
 	return user
 }
@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
+)
+
+func TestMiddlewares_UserCookieAPIKey(t *testing.T) {
+	cfg := configs.Config{
+		CookieKey: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+	acc := accounts.NewService(cfg, s)
+
+	ctx := context.Background()
+	account, err := acc.Register(ctx, "john", "password")
+	require.NoError(t, err)
+	readKey, _, err := acc.CreateKey(ctx, account.ID, "read", repositories.ScopeRead)
+	require.NoError(t, err)
+	writeKey, _, err := acc.CreateKey(ctx, account.ID, "write", repositories.ScopeWrite)
+	require.NoError(t, err)
+
+	m := NewMiddlewares(cfg, authenticator.New(cfg), acc, zap.NewNop(), nil, nil)
+	handler := m.UserCookie(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, userErr := authenticator.GetUser(r.Context())
+		assert.NoError(t, userErr)
+		assert.Equal(t, account.ID, user)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		code          int
+		authenticate  string
+	}{
+		{
+			name:          "read key reads",
+			method:        http.MethodGet,
+			authorization: "Bearer " + readKey,
+			code:          http.StatusOK,
+		},
+		{
+			name:          "read key heads",
+			method:        http.MethodHead,
+			authorization: "Bearer " + readKey,
+			code:          http.StatusOK,
+		},
+		{
+			name:          "read key writes",
+			method:        http.MethodPost,
+			authorization: "Bearer " + readKey,
+			code:          http.StatusForbidden,
+			authenticate:  `Bearer error="insufficient_scope", scope="write"`,
+		},
+		{
+			name:          "read key deletes",
+			method:        http.MethodDelete,
+			authorization: "Bearer " + readKey,
+			code:          http.StatusForbidden,
+			authenticate:  `Bearer error="insufficient_scope", scope="write"`,
+		},
+		{
+			name:          "write key writes",
+			method:        http.MethodPost,
+			authorization: "Bearer " + writeKey,
+			code:          http.StatusOK,
+		},
+		{
+			name:          "write key reads",
+			method:        http.MethodGet,
+			authorization: "Bearer " + writeKey,
+			code:          http.StatusOK,
+		},
+		{
+			name:          "wrong key",
+			method:        http.MethodGet,
+			authorization: "Bearer " + accounts.KeyPrefix + "wrong",
+			code:          http.StatusUnauthorized,
+			authenticate:  `Bearer error="invalid_token"`,
+		},
+		{
+			name:          "not a bearer token",
+			method:        http.MethodGet,
+			authorization: "Basic " + readKey,
+			code:          http.StatusUnauthorized,
+			authenticate:  `Bearer error="invalid_token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/user/urls", nil)
+			r.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.authenticate, w.Header().Get("WWW-Authenticate"))
+			assert.Empty(t, w.Header().Values("Set-Cookie"), "API key requests get no cookie")
+		})
+	}
+}
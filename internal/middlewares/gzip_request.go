@@ -2,9 +2,12 @@ package middlewares
 
 import (
 	"compress/gzip"
-	"log"
 	"net/http"
 	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
 )
 
 // GzipRequest - middleware для распаковки gzip-запросов от клиента.
@@ -21,7 +24,7 @@ func (m *Middlewares) GzipRequest(next http.Handler) http.Handler {
 			defer func(gz *gzip.Reader) {
 				err = gz.Close()
 				if err != nil {
-					log.Printf("close failed: %v", err)
+					logger.Ctx(r.Context(), m.logger).Warn("close failed", zap.Error(err))
 				}
 			}(gz)
 		}
@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
+)
+
+// Metrics - middleware для подсчета количества и времени выполнения запросов.
+//
+// Запросы группируются по шаблону маршрута chi, который известен только после обработки запроса.
+func (m *Middlewares) Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		var route string
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		metrics.ObserveHTTPRequest(route, r.Method, status, time.Since(start))
+	})
+}
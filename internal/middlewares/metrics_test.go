@@ -0,0 +1,111 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
+)
+
+// counterValue - значение счетчика name с метками labels из metrics.Registry.
+func counterValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			match := len(m.GetLabel()) == len(labels)
+			for _, l := range m.GetLabel() {
+				if labels[l.GetName()] != l.GetValue() {
+					match = false
+				}
+			}
+			if match {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestMiddlewares_Metrics(t *testing.T) {
+	cfg := configs.Config{}
+	m := NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+
+	router := chi.NewRouter()
+	router.Use(m.Metrics)
+	router.Get("/{ID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTemporaryRedirect)
+	})
+	router.Route("/api/user", func(r chi.Router) {
+		r.Get("/urls", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("[]"))
+		})
+		r.Delete("/urls", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		route  string
+		code   string
+	}{
+		{
+			name:   "route pattern instead of link ID",
+			method: http.MethodGet,
+			path:   "/abc123",
+			route:  "/{ID}",
+			code:   "307",
+		},
+		{
+			name:   "nested route without explicit status",
+			method: http.MethodGet,
+			path:   "/api/user/urls",
+			route:  "/api/user/urls",
+			code:   "200",
+		},
+		{
+			name:   "same route with another method",
+			method: http.MethodDelete,
+			path:   "/api/user/urls",
+			route:  "/api/user/urls",
+			code:   "202",
+		},
+		{
+			name:   "unmatched route",
+			method: http.MethodGet,
+			path:   "/api/unknown/path",
+			route:  "unmatched",
+			code:   "404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{"route": tt.route, "method": tt.method, "code": tt.code}
+			before := counterValue(t, "urlshortener_http_requests_total", labels)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, before+1, counterValue(t, "urlshortener_http_requests_total", labels))
+		})
+	}
+}
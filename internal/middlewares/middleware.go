@@ -2,20 +2,39 @@
 package middlewares
 
 import (
+	"go.uber.org/zap"
+
 	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
 )
 
 // Middlewares - структура, через методы которой вызываются middlewares.
 type Middlewares struct {
-	cfg configs.Config
-	a   authenticator.Authenticator
+	cfg         configs.Config
+	a           authenticator.Authenticator
+	acc         *accounts.Service
+	logger      *zap.Logger
+	limiter     *ratelimit.Limiter
+	authLimiter *ratelimit.Limiter
 }
 
 // NewMiddlewares - конструктор для Middlewares.
-func NewMiddlewares(cfg configs.Config, a authenticator.Authenticator) Middlewares {
+func NewMiddlewares(
+	cfg configs.Config,
+	a authenticator.Authenticator,
+	acc *accounts.Service,
+	l *zap.Logger,
+	limiter *ratelimit.Limiter,
+	authLimiter *ratelimit.Limiter,
+) Middlewares {
 	return Middlewares{
-		cfg: cfg,
-		a:   a,
+		cfg:         cfg,
+		a:           a,
+		acc:         acc,
+		logger:      l,
+		limiter:     limiter,
+		authLimiter: authLimiter,
 	}
 }
@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
+)
+
+// RateLimit - middleware, которое ограничивает частоту запросов пользователя и его IP.
+//
+// Должно стоять после UserCookie. IP берется из RemoteAddr, поэтому перед ним нужен middleware.RealIP.
+// При превышении лимита отвечает 429 с заголовком Retry-After.
+// Если хранилище лимитов недоступно, запрос пропускается.
+func (m *Middlewares) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := authenticator.GetUser(r.Context())
+		m.allow(w, r, next, m.limiter, user)
+	})
+}
+
+// AuthRateLimit - middleware, которое ограничивает частоту входа и регистрации с одного IP.
+//
+// Работает независимо от RateLimit, даже если общий лимит выключен. Пользователь не учитывается:
+// подбирающий пароль клиент может каждый раз приходить без cookie.
+func (m *Middlewares) AuthRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.allow(w, r, next, m.authLimiter, uuid.Nil)
+	})
+}
+
+// allow - пропустить запрос к next, если он укладывается в лимит limiter, иначе ответить 429.
+func (m *Middlewares) allow(
+	w http.ResponseWriter,
+	r *http.Request,
+	next http.Handler,
+	limiter *ratelimit.Limiter,
+	user uuid.UUID,
+) {
+	retryAfter, err := limiter.Allow(r.Context(), user, clientIP(r))
+	if errors.Is(err, ratelimit.ErrLimitExceeded) {
+		w.Header().Set("Retry-After", strconv.Itoa(ratelimit.RetryAfterSeconds(retryAfter)))
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	}
+	if err != nil {
+		logger.Ctx(r.Context(), m.logger).Error("rate limit check failed", zap.Error(err))
+	}
+
+	next.ServeHTTP(w, r)
+}
+
+// clientIP - IP клиента без порта.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,151 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/utils"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, []string, ratelimit.Limit) (bool, time.Duration, error) {
+	return false, 0, errors.New("store is down")
+}
+
+func TestMiddlewares_RateLimit(t *testing.T) {
+	cfg := configs.Config{
+		RateLimit:          0.01,
+		RateLimitBurst:     1,
+		AuthRateLimit:      0.02,
+		AuthRateLimitBurst: 1,
+	}
+
+	tests := []struct {
+		name       string
+		auth       bool
+		store      ratelimit.Store
+		disabled   bool
+		newUsers   bool // Каждый запрос от нового пользователя.
+		newIPs     bool // Каждый запрос с нового IP.
+		requests   int
+		code       int
+		retryAfter string
+	}{
+		{
+			name:     "first request",
+			requests: 1,
+			code:     http.StatusOK,
+		},
+		{
+			name:       "limit exceeded",
+			requests:   2,
+			code:       http.StatusTooManyRequests,
+			retryAfter: "100",
+		},
+		{
+			name:       "user is limited from any IP",
+			newIPs:     true,
+			requests:   2,
+			code:       http.StatusTooManyRequests,
+			retryAfter: "100",
+		},
+		{
+			name:       "IP is limited for any user",
+			newUsers:   true,
+			requests:   2,
+			code:       http.StatusTooManyRequests,
+			retryAfter: "100",
+		},
+		{
+			name:     "limit disabled",
+			disabled: true,
+			requests: 3,
+			code:     http.StatusOK,
+		},
+		{
+			name:     "store is down",
+			store:    failingStore{},
+			requests: 3,
+			code:     http.StatusOK,
+		},
+		{
+			name:       "auth limit exceeded",
+			auth:       true,
+			requests:   2,
+			code:       http.StatusTooManyRequests,
+			retryAfter: "50",
+		},
+		{
+			name:       "auth limit ignores users",
+			auth:       true,
+			newUsers:   true,
+			requests:   2,
+			code:       http.StatusTooManyRequests,
+			retryAfter: "50",
+		},
+		{
+			name:     "auth limit is per IP",
+			auth:     true,
+			newIPs:   true,
+			requests: 3,
+			code:     http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			if store == nil {
+				store = ratelimit.NewMemoryStore()
+			}
+			var limiter, authLimiter *ratelimit.Limiter
+			if !tt.disabled {
+				limiter = ratelimit.NewLimiter(cfg, store)
+				authLimiter = ratelimit.NewAuthLimiter(cfg, store)
+			}
+
+			m := NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), limiter, authLimiter)
+			middleware := m.RateLimit
+			if tt.auth {
+				middleware = m.AuthRateLimit
+			}
+			handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			user := uuid.New()
+			var w *httptest.ResponseRecorder
+			for n := 0; n < tt.requests; n++ {
+				if tt.newUsers {
+					user = uuid.New()
+				}
+				ip := "10.0.0.1"
+				if tt.newIPs {
+					ip = fmt.Sprintf("10.0.0.%d", n+1)
+				}
+
+				r := httptest.NewRequest(http.MethodPost, "/", nil)
+				r.RemoteAddr = ip + ":5000"
+				r = r.WithContext(context.WithValue(r.Context(), utils.ContextKey("userID"), user))
+				w = httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+			}
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Package policy хранит правила, по которым проверяются адреса сокращаемых ссылок.
+//
+// Правила загружаются из JSON-файла:
+//
+//	{
+//	  "allow": [{"host": "*.example.com"}],
+//	  "deny": [{"host": "evil.example.com"}, {"cidr": "10.0.0.0/8"}, {"host": "example.com", "path": "^/login"}]
+//	}
+//
+// Правило срабатывает, если совпали все заданные в нем поля. Ссылка запрещена,
+// если сработало правило из deny или если список allow не пуст и ни одно его правило не сработало.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Ошибки проверки ссылок.
+var (
+	ErrBlocked    = errors.New("url is blocked by policy")            // Ссылка запрещена политикой.
+	ErrDenied     = fmt.Errorf("%w: host is denied", ErrBlocked)      // Сработало правило из списка deny.
+	ErrNotAllowed = fmt.Errorf("%w: host is not allowed", ErrBlocked) // Не сработало ни одно правило из списка allow.
+	ErrWrongRule  = errors.New("wrong policy rule")                   // Правило в файле политики не удалось разобрать.
+)
+
+// Rule - правило политики в файле.
+type Rule struct {
+	Host string `json:"host,omitempty"` // Хост целиком или "*.domain" для всех поддоменов domain.
+	CIDR string `json:"cidr,omitempty"` // Сеть, в которую должен попасть хост, заданный IP-адресом.
+	Path string `json:"path,omitempty"` // Регулярное выражение для пути.
+}
+
+// File - содержимое файла политики.
+type File struct {
+	Allow []Rule `json:"allow"` // Если список не пуст, разрешены только ссылки, подходящие под его правила.
+	Deny  []Rule `json:"deny"`  // Ссылки, подходящие под эти правила, запрещены.
+}
+
+// Policy - проверка ссылок по правилам из файла.
+//
+// Правила можно перечитать через Reload, проверки при этом не блокируются.
+type Policy struct {
+	rules atomic.Pointer[rules]
+	path  string
+}
+
+// New - конструктор для Policy, сразу загружает правила из файла path.
+//
+// Если path пустой, разрешены все ссылки.
+func New(path string) (*Policy, error) {
+	p := &Policy{path: path}
+	p.rules.Store(&rules{})
+
+	if path == "" {
+		return p, nil
+	}
+
+	err := p.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Reload - перечитать правила из файла.
+//
+// Если файл не удалось прочитать или разобрать, продолжают действовать прежние правила.
+func (p *Policy) Reload() error {
+	if p.path == "" {
+		return nil
+	}
+
+	file := p.stat()
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return err
+	}
+
+	var f File
+	err = json.Unmarshal(data, &f)
+	if err != nil {
+		return err
+	}
+
+	r, err := compile(f)
+	if err != nil {
+		return err
+	}
+	r.file = file
+
+	p.rules.Store(r)
+	return nil
+}
+
+// Check - проверить, что ссылку на rawURL можно создать и открыть.
+//
+// Nil Policy разрешает все ссылки.
+func (p *Policy) Check(rawURL string) error {
+	if p == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	return p.rules.Load().check(strings.ToLower(u.Hostname()), u.EscapedPath())
+}
+
+// Enabled - есть ли в политике хотя бы одно правило.
+//
+// Nil Policy правил не содержит.
+func (p *Policy) Enabled() bool {
+	if p == nil {
+		return false
+	}
+
+	r := p.rules.Load()
+	return len(r.allow) > 0 || len(r.deny) > 0
+}
+
+type rules struct {
+	file  fileState // Состояние файла, из которого загружены правила.
+	allow []rule
+	deny  []rule
+}
+
+func compile(f File) (*rules, error) {
+	r := &rules{
+		allow: make([]rule, 0, len(f.Allow)),
+		deny:  make([]rule, 0, len(f.Deny)),
+	}
+
+	for _, list := range []struct {
+		dst *[]rule
+		src []Rule
+	}{{&r.allow, f.Allow}, {&r.deny, f.Deny}} {
+		for i, src := range list.src {
+			compiled, err := compileRule(src)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d %+v: %w", i, src, err)
+			}
+			*list.dst = append(*list.dst, compiled)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *rules) check(host, path string) error {
+	for _, rule := range r.deny {
+		if rule.match(host, path) {
+			return ErrDenied
+		}
+	}
+
+	if len(r.allow) == 0 {
+		return nil
+	}
+	for _, rule := range r.allow {
+		if rule.match(host, path) {
+			return nil
+		}
+	}
+	return ErrNotAllowed
+}
+
+// rule - разобранное правило.
+type rule struct {
+	network *net.IPNet
+	path    *regexp.Regexp
+	host    string
+	suffix  string
+}
+
+func compileRule(src Rule) (r rule, err error) {
+	if src.Host == "" && src.CIDR == "" && src.Path == "" {
+		return r, ErrWrongRule
+	}
+
+	host := strings.ToLower(strings.TrimSpace(src.Host))
+	if strings.HasPrefix(host, "*.") {
+		r.suffix = host[1:]
+	} else {
+		r.host = host
+	}
+
+	if src.CIDR != "" {
+		_, r.network, err = net.ParseCIDR(src.CIDR)
+		if err != nil {
+			return r, fmt.Errorf("%w: %v", ErrWrongRule, err)
+		}
+	}
+
+	if src.Path != "" {
+		r.path, err = regexp.Compile(src.Path)
+		if err != nil {
+			return r, fmt.Errorf("%w: %v", ErrWrongRule, err)
+		}
+	}
+
+	return r, nil
+}
+
+func (r rule) match(host, path string) bool {
+	if r.host != "" && host != r.host {
+		return false
+	}
+	if r.suffix != "" && !strings.HasSuffix(host, r.suffix) {
+		return false
+	}
+	if r.network != nil {
+		ip := net.ParseIP(host)
+		if ip == nil || !r.network.Contains(ip) {
+			return false
+		}
+	}
+	if r.path != nil && !r.path.MatchString(path) {
+		return false
+	}
+	return true
+}
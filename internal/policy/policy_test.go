@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePolicy(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+}
+
+func TestPolicy_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	writePolicy(t, path, `{
+		"allow": [{"host": "*.example.com"}, {"host": "example.com"}, {"cidr": "192.0.2.0/24"}],
+		"deny": [
+			{"host": "evil.example.com"},
+			{"host": "example.com", "path": "^/login"},
+			{"cidr": "192.0.2.128/25"}
+		]
+	}`)
+
+	p, err := New(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		err  error
+		name string
+		url  string
+	}{
+		{name: "exact host", url: "https://example.com/"},
+		{name: "subdomain", url: "https://www.example.com/path"},
+		{name: "deep subdomain", url: "https://a.b.example.com/"},
+		{name: "denied host", url: "https://evil.example.com/", err: ErrDenied},
+		{name: "denied path", url: "https://example.com/login?next=/", err: ErrDenied},
+		{name: "other path", url: "https://example.com/about/login"},
+		{name: "allowed network", url: "http://192.0.2.1:8080/"},
+		{name: "denied network", url: "http://192.0.2.200/", err: ErrDenied},
+		{name: "not allowed host", url: "https://example.org/", err: ErrNotAllowed},
+		{name: "suffix is not a subdomain", url: "https://notexample.com/", err: ErrNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.url)
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, ErrBlocked)
+			}
+		})
+	}
+}
+
+func TestPolicy_empty(t *testing.T) {
+	var nilPolicy *Policy
+	assert.NoError(t, nilPolicy.Check("https://example.com/"))
+	assert.False(t, nilPolicy.Enabled())
+
+	p, err := New("")
+	require.NoError(t, err)
+	assert.NoError(t, p.Check("https://example.com/"))
+	assert.False(t, p.Enabled())
+}
+
+func TestNew_wrongFile(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := New(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(dir, "policy.json")
+	writePolicy(t, path, `{"deny": [{}]}`)
+	_, err = New(path)
+	assert.ErrorIs(t, err, ErrWrongRule)
+
+	writePolicy(t, path, `{"deny": [{"cidr": "10.0.0.0/33"}]}`)
+	_, err = New(path)
+	assert.ErrorIs(t, err, ErrWrongRule)
+
+	writePolicy(t, path, `{"deny": [{"path": "("}]}`)
+	_, err = New(path)
+	assert.ErrorIs(t, err, ErrWrongRule)
+}
+
+func TestPolicy_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	writePolicy(t, path, `{}`)
+
+	p, err := New(path)
+	require.NoError(t, err)
+	assert.NoError(t, p.Check("https://example.com/"))
+
+	writePolicy(t, path, `{"deny": [{"host": "example.com"}]}`)
+	require.NoError(t, p.Reload())
+	assert.ErrorIs(t, p.Check("https://example.com/"), ErrDenied)
+
+	writePolicy(t, path, `{"deny": [`)
+	assert.Error(t, p.Reload())
+	assert.ErrorIs(t, p.Check("https://example.com/"), ErrDenied, "previous rules are kept")
+}
+
+func TestPolicy_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	writePolicy(t, path, `{}`)
+
+	p, err := New(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Watch(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	writePolicy(t, path, `{"deny": [{"host": "example.com"}]}`)
+	assert.Eventually(t, func() bool {
+		return p.Check("https://example.com/") != nil
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
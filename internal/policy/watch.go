@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Watch - перечитывать правила по сигналу SIGHUP и при изменении файла.
+//
+// Изменение файла проверяется раз в interval по времени изменения и размеру,
+// 0 - следить только за сигналом. Блокирует выполнение, пока не будет отменен ctx.
+func (p *Policy) Watch(ctx context.Context, interval time.Duration) {
+	if p.path == "" {
+		return
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	last := p.rules.Load().file
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			last = p.stat()
+		case <-tick:
+			current := p.stat()
+			if current == last {
+				continue
+			}
+			last = current
+		}
+
+		if err := p.Reload(); err != nil {
+			zap.L().Error("unable to reload policy", zap.String("path", p.path), zap.Error(err))
+			continue
+		}
+		zap.L().Info("policy reloaded", zap.String("path", p.path))
+	}
+}
+
+// fileState - признаки изменения файла политики.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (p *Policy) stat() fileState {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,163 @@
+// Package quota хранит проверку квот пользователей на создание ссылок.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Ошибки проверки квот.
+var (
+	ErrQuotaExceeded = repositories.ErrQuotaExceeded                             // Любая квота исчерпана.
+	ErrMaxLinks      = repositories.ErrMaxLinks                                  // Исчерпана квота активных ссылок.
+	ErrDailyLinks    = repositories.ErrDailyLinks                                // Исчерпана суточная квота.
+	ErrWrongOverride = errors.New("quota override key is not a valid user UUID") // Ключ в QuotaOverrides - не UUID.
+)
+
+// Заголовки с остатком квот, в gRPC передаются в метаданных с теми же именами.
+const (
+	HeaderLinksRemaining = "X-Quota-Links-Remaining"
+	HeaderDailyRemaining = "X-Quota-Daily-Remaining"
+)
+
+// Counter - хранилище, которое умеет считать ссылки пользователя.
+type Counter interface {
+	CountUserLinks(ctx context.Context, user repositories.User, since time.Time) (repositories.LinkCounts, error)
+}
+
+// Usage - квоты пользователя и их использование.
+type Usage struct {
+	MaxLinks    int64 `json:"max_links"`    // Квота активных ссылок, 0 - без ограничений.
+	ActiveLinks int64 `json:"active_links"` // Активных ссылок сейчас.
+	DailyLinks  int64 `json:"daily_links"`  // Квота ссылок за сутки, 0 - без ограничений.
+	LinksToday  int64 `json:"links_today"`  // Создано ссылок за текущие сутки (UTC).
+}
+
+// Created - учесть n созданных ссылок.
+func (u *Usage) Created(n int) {
+	u.ActiveLinks += int64(n)
+	u.LinksToday += int64(n)
+}
+
+// Headers - остаток квот для заголовков ответа, ограниченные квоты не попадают в результат.
+func (u Usage) Headers() map[string]string {
+	headers := make(map[string]string, 2)
+	if u.MaxLinks > 0 {
+		headers[HeaderLinksRemaining] = strconv.FormatInt(remaining(u.MaxLinks, u.ActiveLinks), 10)
+	}
+	if u.DailyLinks > 0 {
+		headers[HeaderDailyRemaining] = strconv.FormatInt(remaining(u.DailyLinks, u.LinksToday), 10)
+	}
+	return headers
+}
+
+func remaining(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// Checker - проверка квот пользователей.
+//
+// Check проверяет квоты до создания ссылок, чтобы сразу отказать и вернуть остаток квот.
+// Одновременные запросы одного пользователя могут пройти эту проверку вместе,
+// поэтому при создании ссылок квоты из Quota передаются хранилищу, и оно проверяет их еще раз при записи.
+type Checker struct {
+	st        Counter
+	defaults  configs.QuotaLimits
+	overrides map[uuid.UUID]configs.QuotaLimits
+	now       func() time.Time
+}
+
+// NewChecker - конструктор для Checker.
+func NewChecker(cfg configs.Config, st Counter) (*Checker, error) {
+	c := &Checker{
+		st:        st,
+		defaults:  cfg.Quota,
+		overrides: make(map[uuid.UUID]configs.QuotaLimits, len(cfg.QuotaOverrides)),
+		now:       time.Now,
+	}
+
+	for key, limits := range cfg.QuotaOverrides {
+		user, err := uuid.Parse(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrWrongOverride, key)
+		}
+		c.overrides[user] = limits
+	}
+
+	return c, nil
+}
+
+// Usage - получить квоты пользователя и их использование.
+func (c *Checker) Usage(ctx context.Context, user uuid.UUID) (Usage, error) {
+	limits := c.limits(user)
+	usage := Usage{
+		MaxLinks:   limits.MaxLinks,
+		DailyLinks: limits.DailyLinks,
+	}
+
+	day := c.now().UTC().Truncate(24 * time.Hour)
+	counts, err := c.st.CountUserLinks(ctx, user, day)
+	if err != nil {
+		return usage, err
+	}
+	usage.ActiveLinks = counts.Active
+	usage.LinksToday = counts.CreatedSince
+
+	return usage, nil
+}
+
+// Check - проверить, что пользователь может создать еще n ссылок.
+//
+// Если квота исчерпана, вернет ErrMaxLinks или ErrDailyLinks вместе с текущим использованием.
+// Nil Checker разрешает создание без ограничений.
+func (c *Checker) Check(ctx context.Context, user uuid.UUID, n int) (Usage, error) {
+	if c == nil {
+		return Usage{}, nil
+	}
+	if limits := c.limits(user); limits.MaxLinks <= 0 && limits.DailyLinks <= 0 {
+		return Usage{}, nil
+	}
+
+	usage, err := c.Usage(ctx, user)
+	if err != nil {
+		return usage, err
+	}
+
+	counts := repositories.LinkCounts{Active: usage.ActiveLinks, CreatedSince: usage.LinksToday}
+	return usage, c.Quota(user).Check(counts, n)
+}
+
+// Quota - квоты пользователя для проверки в хранилище при создании ссылок.
+//
+// Nil Checker возвращает квоты без ограничений.
+func (c *Checker) Quota(user uuid.UUID) repositories.Quota {
+	if c == nil {
+		return repositories.Quota{}
+	}
+
+	limits := c.limits(user)
+	return repositories.Quota{
+		Since:      c.now().UTC().Truncate(24 * time.Hour),
+		MaxLinks:   limits.MaxLinks,
+		DailyLinks: limits.DailyLinks,
+	}
+}
+
+// limits - квоты пользователя с учетом персональных настроек.
+func (c *Checker) limits(user uuid.UUID) configs.QuotaLimits {
+	if limits, ok := c.overrides[user]; ok {
+		return limits
+	}
+	return c.defaults
+}
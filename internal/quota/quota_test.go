@@ -0,0 +1,114 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+type counter struct {
+	counts repositories.LinkCounts
+	since  time.Time
+	err    error
+	calls  int
+}
+
+func (c *counter) CountUserLinks(_ context.Context, _ repositories.User, since time.Time) (repositories.LinkCounts, error) {
+	c.calls++
+	c.since = since
+	return c.counts, c.err
+}
+
+func TestChecker_Check(t *testing.T) {
+	ctx := context.Background()
+	vip := uuid.New()
+	cfg := configs.Config{
+		Quota: configs.QuotaLimits{MaxLinks: 10, DailyLinks: 5},
+		QuotaOverrides: map[string]configs.QuotaLimits{
+			vip.String(): {MaxLinks: 100},
+		},
+	}
+
+	t.Run("within quota", func(t *testing.T) {
+		st := &counter{counts: repositories.LinkCounts{Active: 7, CreatedSince: 2}}
+		c, err := NewChecker(cfg, st)
+		require.NoError(t, err)
+		c.now = func() time.Time { return time.Date(2023, 4, 5, 13, 14, 15, 0, time.UTC) }
+
+		usage, err := c.Check(ctx, uuid.New(), 3)
+		require.NoError(t, err)
+		assert.Equal(t, Usage{MaxLinks: 10, ActiveLinks: 7, DailyLinks: 5, LinksToday: 2}, usage)
+		assert.Equal(t, time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC), st.since)
+
+		usage.Created(3)
+		assert.Equal(t, map[string]string{
+			HeaderLinksRemaining: "0",
+			HeaderDailyRemaining: "0",
+		}, usage.Headers())
+	})
+
+	t.Run("active links exceeded", func(t *testing.T) {
+		c, err := NewChecker(cfg, &counter{counts: repositories.LinkCounts{Active: 10}})
+		require.NoError(t, err)
+
+		_, err = c.Check(ctx, uuid.New(), 1)
+		assert.ErrorIs(t, err, ErrMaxLinks)
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("daily links exceeded", func(t *testing.T) {
+		c, err := NewChecker(cfg, &counter{counts: repositories.LinkCounts{Active: 1, CreatedSince: 4}})
+		require.NoError(t, err)
+
+		_, err = c.Check(ctx, uuid.New(), 2)
+		assert.ErrorIs(t, err, ErrDailyLinks)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		c, err := NewChecker(cfg, &counter{counts: repositories.LinkCounts{Active: 50, CreatedSince: 50}})
+		require.NoError(t, err)
+
+		usage, err := c.Check(ctx, vip, 1)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{HeaderLinksRemaining: "50"}, usage.Headers())
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		st := &counter{}
+		c, err := NewChecker(configs.Config{}, st)
+		require.NoError(t, err)
+
+		usage, err := c.Check(ctx, uuid.New(), 1000)
+		require.NoError(t, err)
+		assert.Empty(t, usage.Headers())
+		assert.Zero(t, st.calls, "storage is not queried")
+
+		var nilChecker *Checker
+		_, err = nilChecker.Check(ctx, uuid.New(), 1000)
+		require.NoError(t, err)
+	})
+
+	t.Run("storage error", func(t *testing.T) {
+		c, err := NewChecker(cfg, &counter{err: errors.New("storage is down")})
+		require.NoError(t, err)
+
+		_, err = c.Check(ctx, uuid.New(), 1)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrQuotaExceeded)
+	})
+}
+
+func TestNewChecker(t *testing.T) {
+	_, err := NewChecker(configs.Config{
+		QuotaOverrides: map[string]configs.QuotaLimits{"admin": {MaxLinks: 1}},
+	}, &counter{})
+	assert.ErrorIs(t, err, ErrWrongOverride)
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const memoryCleanupInterval = time.Minute
+
+// MemoryStore - хранилище корзин в памяти процесса.
+//
+// Полные корзины удаляются раз в минуту: их состояние не отличается от новой корзины.
+type MemoryStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit // Лимит последнего обращения: по нему корзина пополняется при очистке.
+}
+
+// NewMemoryStore - конструктор для MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:     make(map[string]*bucket),
+		lastCleanup: time.Now(),
+		now:         time.Now,
+	}
+}
+
+// Take - взять по токену из каждой корзины keys, если токены есть во всех.
+func (s *MemoryStore) Take(_ context.Context, keys []string, limit Limit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastCleanup) > memoryCleanupInterval {
+		s.cleanup(now)
+	}
+
+	buckets := make([]*bucket, len(keys))
+	var wait float64
+	for i, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{tokens: float64(limit.Burst), last: now}
+			s.buckets[key] = b
+		}
+		b.limit = limit
+		b.refill(now)
+
+		if b.tokens < 1 {
+			wait = math.Max(wait, (1-b.tokens)/limit.Rate)
+		}
+		buckets[i] = b
+	}
+
+	if wait > 0 {
+		return false, time.Duration(wait * float64(time.Second)), nil
+	}
+
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return true, 0, nil
+}
+
+// cleanup - удалить полные корзины.
+//
+// Хранилище может быть общим для нескольких Limiter, поэтому каждая корзина
+// пополняется по своему лимиту, а не по лимиту запроса, который запустил очистку.
+func (s *MemoryStore) cleanup(now time.Time) {
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastCleanup = now
+}
+
+// refill - добавить токены, накопившиеся с последнего обращения.
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+		b.last = now
+	}
+}
@@ -0,0 +1,111 @@
+// Package ratelimit хранит ограничение частоты запросов по алгоритму token bucket.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+// ErrLimitExceeded - запрос превышает лимит, повторить его можно через время, которое вернул Limiter.
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// Limit - параметры корзины токенов.
+type Limit struct {
+	Rate  float64 // Сколько токенов добавляется в секунду.
+	Burst int     // Емкость корзины: сколько запросов можно сделать подряд.
+}
+
+// Store - хранилище состояния корзин.
+//
+// Реализация в памяти подходит для одного экземпляра сервера,
+// несколько экземпляров должны использовать общее хранилище.
+type Store interface {
+	// Take - взять по токену из каждой корзины keys, только если токены есть во всех.
+	// Иначе ни одна корзина не меняется и возвращается, через сколько токены появятся во всех корзинах.
+	Take(ctx context.Context, keys []string, limit Limit) (ok bool, retryAfter time.Duration, err error)
+}
+
+// Limiter - ограничение частоты запросов отдельно для каждого пользователя и каждого IP.
+type Limiter struct {
+	store  Store
+	limit  Limit
+	prefix string
+}
+
+// NewLimiter - конструктор для Limiter, вернет nil, если лимит в конфигурации не задан.
+func NewLimiter(cfg configs.Config, store Store) *Limiter {
+	return newLimiter(store, cfg.RateLimit, cfg.RateLimitBurst, "")
+}
+
+// NewAuthLimiter - конструктор для Limiter входа и регистрации, вернет nil, если лимит в конфигурации не задан.
+//
+// Не зависит от общего лимита, его корзины хранятся под своим префиксом,
+// поэтому оба Limiter могут использовать одно хранилище.
+func NewAuthLimiter(cfg configs.Config, store Store) *Limiter {
+	return newLimiter(store, cfg.AuthRateLimit, cfg.AuthRateLimitBurst, "auth:")
+}
+
+// newLimiter - создать Limiter, если burst не задан, емкость корзины равна лимиту за секунду.
+func newLimiter(store Store, rate float64, burst int, prefix string) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+
+	return &Limiter{
+		store:  store,
+		limit:  Limit{Rate: rate, Burst: burst},
+		prefix: prefix,
+	}
+}
+
+// Allow - проверить лимиты пользователя user и адреса ip.
+//
+// Пустой user или ip не проверяется. Если лимит превышен, вернет ErrLimitExceeded
+// и время, через которое запрос можно повторить. Nil Limiter пропускает все запросы.
+func (l *Limiter) Allow(ctx context.Context, user uuid.UUID, ip string) (retryAfter time.Duration, err error) {
+	if l == nil {
+		return 0, nil
+	}
+
+	var keys []string
+	if user != uuid.Nil {
+		keys = append(keys, l.prefix+"user:"+user.String())
+	}
+	if ip != "" {
+		keys = append(keys, l.prefix+"ip:"+ip)
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// Корзины проверяются вместе: если запрос отклонен по IP, токен пользователя не тратится.
+	ok, retryAfter, err := l.store.Take(ctx, keys, l.limit)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return retryAfter, ErrLimitExceeded
+	}
+
+	return 0, nil
+}
+
+// RetryAfterSeconds - значение для заголовка Retry-After: целое число секунд, не меньше одной.
+func RetryAfterSeconds(d time.Duration) int {
+	s := int(math.Ceil(d.Seconds()))
+	if s < 1 {
+		return 1
+	}
+	return s
+}
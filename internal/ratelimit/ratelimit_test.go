@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Now()
+	st := NewMemoryStore()
+	st.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 3}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		ok, _, err := st.Take(ctx, []string{"a"}, limit)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	ok, retryAfter, err := st.Take(ctx, []string{"a"}, limit)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	ok, _, err = st.Take(ctx, []string{"b"}, limit)
+	require.NoError(t, err)
+	assert.True(t, ok, "buckets are independent")
+
+	now = now.Add(500 * time.Millisecond)
+	ok, _, err = st.Take(ctx, []string{"a"}, limit)
+	require.NoError(t, err)
+	assert.True(t, ok, "token is refilled")
+
+	now = now.Add(2 * memoryCleanupInterval)
+	ok, _, err = st.Take(ctx, []string{"c"}, limit)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, st.buckets, 1, "full buckets are removed")
+}
+
+func TestMemoryStore_cleanupSharedStore(t *testing.T) {
+	now := time.Now()
+	st := NewMemoryStore()
+	st.now = func() time.Time { return now }
+	ctx := context.Background()
+	auth := Limit{Rate: 0.001, Burst: 1}
+	general := Limit{Rate: 100, Burst: 100}
+
+	ok, _, err := st.Take(ctx, []string{"auth:ip:10.0.0.1"}, auth)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(2 * memoryCleanupInterval)
+	ok, _, err = st.Take(ctx, []string{"ip:10.0.0.1"}, general)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, _, err = st.Take(ctx, []string{"auth:ip:10.0.0.1"}, auth)
+	require.NoError(t, err)
+	assert.False(t, ok, "cleanup with another limit does not refill the bucket")
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, []string, Limit) (bool, time.Duration, error) {
+	return false, 0, errors.New("store is down")
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		l := NewLimiter(configs.Config{}, NewMemoryStore())
+		assert.Nil(t, l)
+
+		for i := 0; i < 100; i++ {
+			_, err := l.Allow(ctx, uuid.New(), "127.0.0.1")
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("per user and per IP", func(t *testing.T) {
+		l := NewLimiter(configs.Config{RateLimit: 0.001, RateLimitBurst: 2}, NewMemoryStore())
+		user := uuid.New()
+
+		_, err := l.Allow(ctx, user, "10.0.0.1")
+		require.NoError(t, err)
+		_, err = l.Allow(ctx, user, "10.0.0.2")
+		require.NoError(t, err)
+
+		retryAfter, err := l.Allow(ctx, user, "10.0.0.3")
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		assert.Greater(t, retryAfter, time.Duration(0))
+
+		_, err = l.Allow(ctx, uuid.New(), "10.0.0.1")
+		require.NoError(t, err)
+		_, err = l.Allow(ctx, uuid.New(), "10.0.0.1")
+		assert.ErrorIs(t, err, ErrLimitExceeded, "IP is limited for any user")
+
+		_, err = l.Allow(ctx, uuid.Nil, "10.0.0.4")
+		require.NoError(t, err)
+	})
+
+	t.Run("rejected request spends no tokens", func(t *testing.T) {
+		l := NewLimiter(configs.Config{RateLimit: 0.001, RateLimitBurst: 1}, NewMemoryStore())
+		user := uuid.New()
+
+		_, err := l.Allow(ctx, uuid.New(), "10.0.0.1")
+		require.NoError(t, err)
+		_, err = l.Allow(ctx, user, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrLimitExceeded, "IP is out of tokens")
+
+		_, err = l.Allow(ctx, user, "10.0.0.2")
+		require.NoError(t, err, "user token is not spent by the rejected request")
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		cfg := configs.Config{AuthRateLimit: 0.001, AuthRateLimitBurst: 1}
+		assert.Nil(t, NewLimiter(cfg, NewMemoryStore()))
+
+		store := NewMemoryStore()
+		l := NewAuthLimiter(cfg, store)
+		require.NotNil(t, l)
+
+		_, err := l.Allow(ctx, uuid.Nil, "10.0.0.1")
+		require.NoError(t, err)
+		_, err = l.Allow(ctx, uuid.Nil, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+
+		general := NewLimiter(configs.Config{RateLimit: 0.001, RateLimitBurst: 1}, store)
+		_, err = general.Allow(ctx, uuid.Nil, "10.0.0.1")
+		require.NoError(t, err, "buckets are separate from the general limit")
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		l := NewLimiter(configs.Config{RateLimit: 1}, failingStore{})
+
+		_, err := l.Allow(ctx, uuid.New(), "10.0.0.1")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrLimitExceeded)
+	})
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, RetryAfterSeconds(0))
+	assert.Equal(t, 1, RetryAfterSeconds(300*time.Millisecond))
+	assert.Equal(t, 2, RetryAfterSeconds(1100*time.Millisecond))
+}
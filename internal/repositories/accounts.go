@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyID - тип для хранения ID API-ключа.
+type KeyID = uuid.UUID
+
+// Account - зарегистрированная учетная запись.
+//
+// ID учетной записи - это пользователь, которому принадлежат ее ссылки.
+type Account struct {
+	CreatedAt    time.Time // Время регистрации.
+	Login        string    // Логин, уникальный без учета регистра.
+	PasswordHash []byte    // Хеш пароля bcrypt.
+	ID           User      // ID пользователя учетной записи.
+
+	SessionsRevokedAt time.Time // Время выхода: сессии, выданные до него, недействительны.
+}
+
+// Scope - права API-ключа.
+type Scope string
+
+// Возможные значения Scope.
+const (
+	ScopeRead  Scope = "read"  // Только чтение: ссылки, статистика, квоты.
+	ScopeWrite Scope = "write" // Чтение и изменение.
+)
+
+// ParseScope - получить Scope из строки.
+func ParseScope(s string) (Scope, error) {
+	switch sc := Scope(s); sc {
+	case ScopeRead, ScopeWrite:
+		return sc, nil
+	default:
+		return "", ErrUnknownScope
+	}
+}
+
+// Allows - достаточно ли прав s для действия, которое требует права need.
+func (s Scope) Allows(need Scope) bool {
+	switch s {
+	case ScopeWrite:
+		return need == ScopeWrite || need == ScopeRead
+	case ScopeRead:
+		return need == ScopeRead
+	default:
+		return false
+	}
+}
+
+// APIKey - долгоживущий ключ доступа учетной записи.
+//
+// Сам ключ не хранится: по нему ищут через Hash, а Prefix помогает узнать ключ в списке.
+type APIKey struct {
+	CreatedAt time.Time // Время создания.
+	RevokedAt time.Time // Время отзыва, нулевое значение - ключ действует.
+	Name      string    // Название, которое дал владелец.
+	Prefix    string    // Начало ключа.
+	Hash      []byte    // SHA-256 ключа.
+	Scope     Scope     // Права ключа.
+	ID        KeyID     // ID ключа.
+	Account   User      // Учетная запись, от имени которой действует ключ.
+}
+
+// Revoked - отозван ли ключ.
+func (k APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
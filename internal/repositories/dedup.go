@@ -0,0 +1,37 @@
+package repositories
+
+// DedupMode - режим поиска уже сокращенных URL.
+type DedupMode string
+
+// Возможные значения DedupMode.
+const (
+	DedupGlobal  DedupMode = "global"   // URL уникален среди всех пользователей, режим по умолчанию.
+	DedupPerUser DedupMode = "per-user" // URL уникален среди ссылок одного пользователя.
+	DedupNone    DedupMode = "none"     // Каждое сокращение создает новую ссылку.
+)
+
+// ParseDedupMode - получить DedupMode из строки, пустая строка - DedupGlobal.
+func ParseDedupMode(s string) (DedupMode, error) {
+	switch m := DedupMode(s); m {
+	case "":
+		return DedupGlobal, nil
+	case DedupGlobal, DedupPerUser, DedupNone:
+		return m, nil
+	default:
+		return "", ErrUnknownDedup
+	}
+}
+
+// Key - ключ, по которому ищутся совпадающие ссылки.
+//
+// Если ok == false, ссылка не участвует в поиске совпадений.
+func (m DedupMode) Key(url URL, user User) (key string, ok bool) {
+	switch m {
+	case DedupPerUser:
+		return user.String() + " " + url, true
+	case DedupNone:
+		return "", false
+	default:
+		return url, true
+	}
+}
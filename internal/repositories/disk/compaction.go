@@ -0,0 +1,305 @@
+package disk
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+const snapshotSuffix = ".snapshot"
+
+// Compact - переписать файл хранилища так, чтобы в нем осталось только текущее состояние.
+//
+// В режиме снимков состояние записывается в отдельный файл, а журнал очищается.
+// Файлы заменяются атомарно через временный файл и переименование,
+// поэтому при сбое на диске остается либо старое, либо новое состояние.
+func (st *FileStorage) Compact(_ context.Context) error {
+	st.compactMu.Lock()
+	defer st.compactMu.Unlock()
+
+	st.fileMutex.Lock()
+	defer st.fileMutex.Unlock()
+
+	st.RLock()
+	defer st.RUnlock()
+
+	if st.opts.Snapshot {
+		return st.compactSnapshot()
+	}
+	return st.compactLog()
+}
+
+func (st *FileStorage) compactLog() error {
+	err := writeFileAtomic(st.path, func(w *bufio.Writer) error {
+		_, err := w.WriteString(header(headerLog, 0) + "\n")
+		if err != nil {
+			return err
+		}
+		return st.dump(w)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(st.path + snapshotSuffix)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	st.gen = 0
+
+	return st.reopen()
+}
+
+func (st *FileStorage) compactSnapshot() error {
+	gen := st.gen + 1
+
+	err := writeFileAtomic(st.path+snapshotSuffix, func(w *bufio.Writer) error {
+		_, err := w.WriteString(header(headerSnapshot, gen) + "\n")
+		if err != nil {
+			return err
+		}
+		return st.dump(w)
+	})
+	if err != nil {
+		return err
+	}
+
+	st.gen = gen
+
+	return st.resetLog()
+}
+
+// resetLog - заменить журнал пустым журналом текущего поколения.
+func (st *FileStorage) resetLog() error {
+	err := writeFileAtomic(st.path, func(w *bufio.Writer) error {
+		_, err := w.WriteString(header(headerLog, st.gen) + "\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return st.reopen()
+}
+
+// dump - записать текущее состояние хранилища.
+//
+// Учетные записи и команды записываются раньше ключей, приглашений и ссылок, которые на них ссылаются.
+// Вызывающий должен держать блокировку на чтение.
+func (st *FileStorage) dump(w *bufio.Writer) error {
+	records := make([]string, 0, len(st.Accounts)*2+len(st.APIKeys)*2)
+	for _, account := range st.Accounts {
+		records = append(records, accountRecord(account))
+		if !account.SessionsRevokedAt.IsZero() {
+			records = append(records, logoutRecord(account.ID, account.SessionsRevokedAt))
+		}
+	}
+	for _, key := range st.APIKeys {
+		records = append(records, keyRecord(key))
+		if key.Revoked() {
+			records = append(records, revokeRecord(key.ID, key.RevokedAt))
+		}
+	}
+	for _, line := range records {
+		_, err := w.WriteString(encodeRecord(line) + "\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Истекшее приглашение уже нельзя принять, поэтому его можно не сохранять.
+	now := time.Now()
+	invites := make(map[repositories.TeamID][]repositories.TeamInvite)
+	for _, invite := range st.Invites {
+		if now.Before(invite.ExpiresAt) {
+			invites[invite.Team] = append(invites[invite.Team], invite)
+		}
+	}
+
+	for id, team := range st.Teams {
+		lines := []string{teamRecord(team)}
+		for user, role := range st.TeamMembers[id] {
+			lines = append(lines, memberRecord(id, user, role))
+		}
+		for _, invite := range invites[id] {
+			lines = append(lines, inviteRecords(invite)...)
+		}
+
+		for _, line := range lines {
+			_, err := w.WriteString(encodeRecord(line) + "\n")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for id, link := range st.IDLinkDataDictionary {
+		lines := linkRecords(id, link, st.History[id])
+		for _, click := range st.Clicks[id] {
+			lines = append(lines, clickRecord(click))
+		}
+
+		for _, line := range lines {
+			_, err := w.WriteString(encodeRecord(line) + "\n")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reopen - открыть файл журнала заново после его замены.
+func (st *FileStorage) reopen() error {
+	file, err := os.OpenFile(st.path, os.O_RDWR, 0o777)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Seek(0, io.SeekEnd)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	err = st.file.Close()
+	if err != nil {
+		st.log(context.Background()).Warn("unable to close old file", zap.Error(err))
+	}
+	st.file = file
+	st.dirty = false
+
+	return nil
+}
+
+// loadSnapshot - прочитать снимок, если он есть.
+//
+// Возвращает версию формата и поколение снимка или 0, если снимка нет.
+// Снимок заменяется атомарно, поэтому любая ошибка в нем - ошибка загрузки.
+func (st *FileStorage) loadSnapshot() (version int, gen uint64, err error) {
+	file, err := os.Open(st.path + snapshotSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return formatVersion, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+
+	i := 0
+	for {
+		bytes, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes) > 0 {
+				return 0, 0, fmt.Errorf("snapshot line %d: %w", i+1, repositories.ErrWrongRecord)
+			}
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		line := strings.TrimSuffix(string(bytes), "\n")
+
+		if i == 0 {
+			var ok bool
+			version, gen, ok = parseHeader(line, headerSnapshot)
+			if !ok {
+				return 0, 0, repositories.ErrWrongHeader
+			}
+			if version > formatVersion {
+				return 0, 0, repositories.ErrUnknownVersion
+			}
+			i++
+			continue
+		}
+
+		err = st.applyLine(version, line, i+1)
+		if err != nil {
+			return 0, 0, fmt.Errorf("snapshot line %d: %w", i+1, err)
+		}
+
+		i++
+	}
+
+	if i == 0 {
+		return 0, 0, repositories.ErrWrongHeader
+	}
+
+	return version, gen, nil
+}
+
+func (st *FileStorage) compactWorker() {
+	defer st.wg.Done()
+
+	ticker := time.NewTicker(st.opts.CompactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-st.shutdown:
+			return
+		case <-ticker.C:
+			err := st.Compact(context.Background())
+			if err != nil {
+				st.log(context.Background()).Error("unable to compact file storage", zap.Error(err))
+			}
+		}
+	}
+}
+
+// writeFileAtomic - записать файл через временный файл и переименование.
+func writeFileAtomic(path string, fill func(w *bufio.Writer) error) error {
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o777)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	err = fill(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,151 @@
+package disk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+func openFileStorage(t *testing.T, filename string, opts Options) *FileStorage {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o777)
+	require.NoError(t, err)
+
+	st, err := NewFileStorage(file, opts)
+	require.NoError(t, err)
+
+	return st
+}
+
+func fileSize(t *testing.T, filename string) int64 {
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	return info.Size()
+}
+
+// TestFileStorage_Compact - тестируем сжатие журнала без снимков.
+func TestFileStorage_Compact(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "storage")
+	ctx := context.Background()
+	testUser := uuid.New()
+
+	st := openFileStorage(t, filename, Options{})
+
+	limited, err := st.Add(ctx, "https://example.com/hits", testUser, repositories.LinkOptions{MaxHits: 100})
+	require.NoError(t, err)
+	deleted, err := st.Add(ctx, "https://example.com/deleted", testUser, repositories.LinkOptions{})
+	require.NoError(t, err)
+	for i := 0; i < 50; i++ {
+		_, _, err = st.Get(ctx, limited)
+		require.NoError(t, err)
+	}
+	_, err = st.DeleteUserLinks(ctx, []repositories.ID{deleted}, testUser)
+	require.NoError(t, err)
+
+	before := fileSize(t, filename)
+	require.NoError(t, st.Compact(ctx))
+	assert.Less(t, fileSize(t, filename), before)
+
+	// Запись после сжатия должна попасть в новый файл.
+	added, err := st.Add(ctx, "https://example.com/after", testUser, repositories.LinkOptions{})
+	require.NoError(t, err)
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, Options{})
+	defer func() { _ = st.Close(ctx) }()
+
+	st.RLock()
+	assert.Equal(t, uint64(50), st.IDLinkDataDictionary[limited].Hits)
+	st.RUnlock()
+
+	url, isDeleted, err := st.Get(ctx, deleted)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/deleted", url)
+	assert.True(t, isDeleted)
+
+	url, _, err = st.Get(ctx, added)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/after", url)
+}
+
+// TestFileStorage_Snapshot - тестируем хранение состояния в снимке.
+func TestFileStorage_Snapshot(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "storage")
+	ctx := context.Background()
+	testUser := uuid.New()
+	opts := Options{Snapshot: true}
+
+	st := openFileStorage(t, filename, opts)
+
+	first, err := st.Add(ctx, "https://example.com/first", testUser, repositories.LinkOptions{})
+	require.NoError(t, err)
+	require.NoError(t, st.Compact(ctx))
+
+	second, err := st.Add(ctx, "https://example.com/second", testUser, repositories.LinkOptions{})
+	require.NoError(t, err)
+	require.NoError(t, st.Close(ctx))
+
+	t.Run("restart with snapshot and tail log", func(t *testing.T) {
+		st = openFileStorage(t, filename, opts)
+		defer func() { require.NoError(t, st.Close(ctx)) }()
+
+		url, _, err := st.Get(ctx, first)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/first", url)
+
+		url, _, err = st.Get(ctx, second)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/second", url)
+	})
+
+	t.Run("stale log is skipped", func(t *testing.T) {
+		log, err := os.ReadFile(filename)
+		require.NoError(t, err)
+
+		st = openFileStorage(t, filename, opts)
+		require.NoError(t, st.Compact(ctx))
+		require.NoError(t, st.Close(ctx))
+
+		// Сбой между записью снимка и очисткой журнала: журнал остался от прошлого поколения.
+		require.NoError(t, os.WriteFile(filename, log, 0o777))
+
+		st = openFileStorage(t, filename, opts)
+		defer func() { require.NoError(t, st.Close(ctx)) }()
+
+		links, err := st.GetUserLinks(ctx, testUser)
+		require.NoError(t, err)
+		assert.Len(t, links, 2)
+
+		_, gen, ok := parseHeader(string(readFirstLine(t, filename)), headerLog)
+		require.True(t, ok)
+		assert.Equal(t, st.gen, gen)
+	})
+
+	t.Run("snapshot missing", func(t *testing.T) {
+		require.NoError(t, os.Remove(filename+snapshotSuffix))
+
+		file, err := os.OpenFile(filename, os.O_RDWR, 0o777)
+		require.NoError(t, err)
+		defer file.Close()
+
+		_, err = NewFileStorage(file, opts)
+		assert.ErrorIs(t, err, repositories.ErrSnapshotMissing)
+	})
+}
+
+func readFirstLine(t *testing.T, filename string) []byte {
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	for i, b := range data {
+		if b == '\n' {
+			return data[:i]
+		}
+	}
+	return data
+}
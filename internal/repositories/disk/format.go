@@ -0,0 +1,81 @@
+package disk
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Версии формата файлов хранилища.
+//
+// Версия 1 - записи без контрольных сумм, заголовок LOG,<поколение> или его отсутствие.
+// Версия 2 - заголовок <тип>,2,<поколение>, каждая запись начинается с CRC-32C
+// (Castagnoli) остальной части строки: <crc в hex>,<запись>.
+const (
+	formatLegacy  = 1
+	formatVersion = 2
+)
+
+// Заголовки файлов хранилища.
+//
+// Журнал поколения N содержит изменения поверх снимка того же поколения.
+const (
+	headerLog      = "LOG"
+	headerSnapshot = "SNAPSHOT"
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+func header(kind string, gen uint64) string {
+	return fmt.Sprintf("%s,%d,%d", kind, formatVersion, gen)
+}
+
+func parseHeader(line string, kind string) (version int, gen uint64, ok bool) {
+	splitted := strings.Split(line, ",")
+	if splitted[0] != kind {
+		return 0, 0, false
+	}
+
+	var err error
+	switch len(splitted) {
+	case 2:
+		version = formatLegacy
+		gen, err = strconv.ParseUint(splitted[1], 10, 64)
+	case 3:
+		version, err = strconv.Atoi(splitted[1])
+		if err == nil {
+			gen, err = strconv.ParseUint(splitted[2], 10, 64)
+		}
+	default:
+		return 0, 0, false
+	}
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return version, gen, true
+}
+
+func encodeRecord(record string) string {
+	return fmt.Sprintf("%08x,%s", crc32.Checksum([]byte(record), crcTable), record)
+}
+
+func decodeRecord(line string) (record string, err error) {
+	sum, record, ok := strings.Cut(line, ",")
+	if !ok || len(sum) != 8 {
+		return "", repositories.ErrWrongRecord
+	}
+
+	want, err := strconv.ParseUint(sum, 16, 32)
+	if err != nil {
+		return "", repositories.ErrWrongRecord
+	}
+	if crc32.Checksum([]byte(record), crcTable) != uint32(want) {
+		return "", repositories.ErrChecksumMismatch
+	}
+
+	return record, nil
+}
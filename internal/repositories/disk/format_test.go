@@ -0,0 +1,211 @@
+package disk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+func Test_decodeRecord(t *testing.T) {
+	record := "PURGE,abc"
+
+	got, err := decodeRecord(encodeRecord(record))
+	require.NoError(t, err)
+	assert.Equal(t, record, got)
+
+	_, err = decodeRecord(strings.Replace(encodeRecord(record), "abc", "abd", 1))
+	assert.ErrorIs(t, err, repositories.ErrChecksumMismatch)
+
+	_, err = decodeRecord(record)
+	assert.ErrorIs(t, err, repositories.ErrWrongRecord)
+}
+
+// prepareLog - создать хранилище с двумя ссылками и вернуть их ID.
+func prepareLog(t *testing.T, filename string) []repositories.ID {
+	ctx := context.Background()
+	st := openFileStorage(t, filename, Options{})
+
+	ids := make([]repositories.ID, 0, 2)
+	for _, url := range []repositories.URL{"https://example.com/1", "https://example.com/2"} {
+		id, err := st.Add(ctx, url, uuid.New(), repositories.LinkOptions{})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	require.NoError(t, st.Close(ctx))
+	return ids
+}
+
+func appendToFile(t *testing.T, filename string, data string) {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0o777)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+// TestFileStorage_TornTail - тестируем восстановление после недописанной последней записи.
+func TestFileStorage_TornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{
+			name: "unterminated record",
+			tail: encodeRecord("PURGE,abc")[:10],
+		},
+		{
+			name: "broken checksum",
+			tail: strings.Replace(encodeRecord("PURGE,abc"), "abc", "abd", 1) + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			filename := filepath.Join(t.TempDir(), "storage")
+			ids := prepareLog(t, filename)
+
+			size := fileSize(t, filename)
+			appendToFile(t, filename, tt.tail)
+
+			st := openFileStorage(t, filename, Options{})
+			assert.Equal(t, size, fileSize(t, filename))
+
+			id, err := st.Add(ctx, "https://example.com/3", uuid.New(), repositories.LinkOptions{})
+			require.NoError(t, err)
+			require.NoError(t, st.Close(ctx))
+
+			st = openFileStorage(t, filename, Options{})
+			defer func() { _ = st.Close(ctx) }()
+
+			for _, id := range append(ids, id) {
+				_, _, err = st.Get(ctx, id)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestFileStorage_Corrupted - тестируем, что повреждение в середине журнала не пропускается молча.
+func TestFileStorage_Corrupted(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(lines []string) []string
+		wantErr error
+	}{
+		{
+			name: "broken checksum",
+			corrupt: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], "NEW", "NEV", 1)
+				return lines
+			},
+			wantErr: repositories.ErrChecksumMismatch,
+		},
+		{
+			name: "unknown record",
+			corrupt: func(lines []string) []string {
+				return append(lines[:1], append([]string{encodeRecord("FOO,bar")}, lines[1:]...)...)
+			},
+			wantErr: repositories.ErrUnknownRecord,
+		},
+		{
+			name: "unknown version",
+			corrupt: func(lines []string) []string {
+				lines[0] = "LOG,3,0"
+				return lines
+			},
+			wantErr: repositories.ErrUnknownVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "storage")
+			prepareLog(t, filename)
+
+			data, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			lines = tt.corrupt(lines)
+			require.NoError(t, os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0o777))
+
+			file, err := os.OpenFile(filename, os.O_RDWR, 0o777)
+			require.NoError(t, err)
+			defer file.Close()
+
+			_, err = NewFileStorage(file, Options{})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestFileStorage_Legacy - тестируем загрузку и обновление файла без заголовка и контрольных сумм.
+func TestFileStorage_Legacy(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	user := uuid.New()
+
+	legacy := strings.Join([]string{
+		newRecord("abc", repositories.LinkData{URL: "https://example.com", User: user}),
+		recordDelete + ",abc," + user.String(),
+	}, "\n") + "\n"
+	require.NoError(t, os.WriteFile(filename, []byte(legacy), 0o777))
+
+	st := openFileStorage(t, filename, Options{})
+	defer func() { _ = st.Close(ctx) }()
+
+	url, deleted, err := st.Get(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", url)
+	assert.True(t, deleted)
+
+	version, _, ok := parseHeader(string(readFirstLine(t, filename)), headerLog)
+	require.True(t, ok)
+	assert.Equal(t, formatVersion, version)
+}
+
+// TestFileStorage_Sync - тестируем режимы синхронизации с диском.
+func TestFileStorage_Sync(t *testing.T) {
+	ctx := context.Background()
+
+	for _, mode := range []SyncMode{SyncAlways, SyncInterval, SyncNever} {
+		t.Run(string(mode), func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "storage")
+			opts := Options{Sync: mode, SyncInterval: 10 * time.Millisecond}
+
+			st := openFileStorage(t, filename, opts)
+			id, err := st.Add(ctx, "https://example.com", uuid.New(), repositories.LinkOptions{})
+			require.NoError(t, err)
+			if mode == SyncInterval {
+				assert.Eventually(t, func() bool {
+					st.fileMutex.Lock()
+					defer st.fileMutex.Unlock()
+					return !st.dirty
+				}, time.Second, 10*time.Millisecond)
+			}
+			require.NoError(t, st.Close(ctx))
+
+			st = openFileStorage(t, filename, opts)
+			defer func() { _ = st.Close(ctx) }()
+			_, _, err = st.Get(ctx, id)
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("unknown mode", func(t *testing.T) {
+		file, err := os.Create(filepath.Join(t.TempDir(), "storage"))
+		require.NoError(t, err)
+		defer file.Close()
+
+		_, err = NewFileStorage(file, Options{Sync: "sometimes"})
+		assert.ErrorIs(t, err, repositories.ErrUnknownSync)
+	})
+}
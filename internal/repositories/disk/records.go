@@ -0,0 +1,845 @@
+package disk
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Типы записей в файле хранилища.
+//
+//	NEW,<id>,<user>,<base64 url>[,<expires at, unix nano>,<max hits>[,<created at, unix nano>[,<team>]]]
+//	DELETE,<id>,<user>[,<deleted at, unix nano>]
+//	RESTORE,<id>,<user>
+//	HIT,<id>
+//	HITS,<id>,<hits>
+//	PURGE,<id>
+//	CLICK,<id>,<time, unix nano>,<base64 referrer>,<base64 user agent>,<ip>
+//	TEAM,<team>,<created at, unix nano>,<base64 name>
+//	MEMBER,<team>,<user>,<role>
+//	LEAVE,<team>,<user>
+//	INVITE,<invite>,<team>,<role>,<created at, unix nano>,<expires at, unix nano>
+//	ACCEPT,<invite>,<user>,<used at, unix nano>
+//	CANCEL,<invite>,<revoked at, unix nano>
+//	TRANSFER,<id>,<team или пустая строка>,<user>
+//	UPDATE,<id>,<user>,<base64 new url>,<replaced at, unix nano>
+//	ACCOUNT,<user>,<created at, unix nano>,<base64 login>,<base64 password hash>
+//	KEY,<key>,<user>,<created at, unix nano>,<scope>,<base64 hash>,<base64 prefix>,<base64 name>
+//	REVOKE,<key>,<revoked at, unix nano>
+//	LOGOUT,<user>,<revoked at, unix nano>
+const (
+	recordNew      = "NEW"      // Новая ссылка.
+	recordDelete   = "DELETE"   // Ссылка удалена пользователем.
+	recordRestore  = "RESTORE"  // Удаленная ссылка восстановлена пользователем.
+	recordHit      = "HIT"      // Переход по ссылке с ограничением переходов.
+	recordHits     = "HITS"     // Количество переходов по ссылке, пишется при сжатии журнала.
+	recordPurge    = "PURGE"    // Ссылка удалена из хранилища безвозвратно.
+	recordClick    = "CLICK"    // Переход по ссылке для статистики.
+	recordTeam     = "TEAM"     // Новая команда.
+	recordMember   = "MEMBER"   // Пользователь добавлен в команду или его роль изменилась.
+	recordLeave    = "LEAVE"    // Пользователь исключен из команды.
+	recordInvite   = "INVITE"   // Новое приглашение в команду.
+	recordAccept   = "ACCEPT"   // Приглашение в команду принято.
+	recordCancel   = "CANCEL"   // Приглашение в команду отозвано.
+	recordTransfer = "TRANSFER" // Ссылка передана в команду или пользователю.
+	recordUpdate   = "UPDATE"   // Исходный URL ссылки заменен.
+	recordAccount  = "ACCOUNT"  // Новая учетная запись.
+	recordKey      = "KEY"      // Новый API-ключ.
+	recordRevoke   = "REVOKE"   // API-ключ отозван.
+	recordLogout   = "LOGOUT"   // Сессии учетной записи завершены.
+)
+
+func newRecord(id repositories.ID, link repositories.LinkData) string {
+	record := fmt.Sprintf("%s,%s,%s,%s,%d,%d,%d",
+		recordNew, id, link.User.String(), base64.StdEncoding.EncodeToString([]byte(link.URL)),
+		unixNano(link.ExpiresAt), link.MaxHits, unixNano(link.CreatedAt),
+	)
+	if link.Team != uuid.Nil {
+		record += "," + link.Team.String()
+	}
+	return record
+}
+
+// unixNano - время в наносекундах, нулевое время записывается как 0.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func deleteRecord(id repositories.ID, user repositories.User, deletedAt time.Time) string {
+	return fmt.Sprintf("%s,%s,%s,%d", recordDelete, id, user.String(), unixNano(deletedAt))
+}
+
+func restoreRecord(id repositories.ID, user repositories.User) string {
+	return fmt.Sprintf("%s,%s,%s", recordRestore, id, user.String())
+}
+
+func hitRecord(id repositories.ID) string {
+	return fmt.Sprintf("%s,%s", recordHit, id)
+}
+
+func hitsRecord(id repositories.ID, hits uint64) string {
+	return fmt.Sprintf("%s,%s,%d", recordHits, id, hits)
+}
+
+func purgeRecord(id repositories.ID) string {
+	return fmt.Sprintf("%s,%s", recordPurge, id)
+}
+
+func teamRecord(team repositories.Team) string {
+	return fmt.Sprintf("%s,%s,%d,%s",
+		recordTeam, team.ID.String(), unixNano(team.CreatedAt), base64.StdEncoding.EncodeToString([]byte(team.Name)),
+	)
+}
+
+func memberRecord(team repositories.TeamID, user repositories.User, role repositories.Role) string {
+	return fmt.Sprintf("%s,%s,%s,%s", recordMember, team.String(), user.String(), role)
+}
+
+func leaveRecord(team repositories.TeamID, user repositories.User) string {
+	return fmt.Sprintf("%s,%s,%s", recordLeave, team.String(), user.String())
+}
+
+func inviteRecord(invite repositories.TeamInvite) string {
+	return fmt.Sprintf("%s,%s,%s,%s,%d,%d",
+		recordInvite, invite.ID.String(), invite.Team.String(), invite.Role,
+		unixNano(invite.CreatedAt), unixNano(invite.ExpiresAt),
+	)
+}
+
+func acceptRecord(id repositories.InviteID, user repositories.User, usedAt time.Time) string {
+	return fmt.Sprintf("%s,%s,%s,%d", recordAccept, id.String(), user.String(), unixNano(usedAt))
+}
+
+func cancelRecord(id repositories.InviteID, revokedAt time.Time) string {
+	return fmt.Sprintf("%s,%s,%d", recordCancel, id.String(), unixNano(revokedAt))
+}
+
+func transferRecord(id repositories.ID, team repositories.TeamID, user repositories.User) string {
+	var t string
+	if team != uuid.Nil {
+		t = team.String()
+	}
+	return fmt.Sprintf("%s,%s,%s,%s", recordTransfer, id, t, user.String())
+}
+
+func updateRecord(id repositories.ID, url repositories.URL, prev repositories.URLVersion) string {
+	return fmt.Sprintf("%s,%s,%s,%s,%d",
+		recordUpdate, id, prev.User.String(), base64.StdEncoding.EncodeToString([]byte(url)), unixNano(prev.ReplacedAt),
+	)
+}
+
+func accountRecord(account repositories.Account) string {
+	return fmt.Sprintf("%s,%s,%d,%s,%s",
+		recordAccount, account.ID.String(), unixNano(account.CreatedAt),
+		base64.StdEncoding.EncodeToString([]byte(account.Login)),
+		base64.StdEncoding.EncodeToString(account.PasswordHash),
+	)
+}
+
+func keyRecord(key repositories.APIKey) string {
+	return fmt.Sprintf("%s,%s,%s,%d,%s,%s,%s,%s",
+		recordKey, key.ID.String(), key.Account.String(), unixNano(key.CreatedAt), key.Scope,
+		base64.StdEncoding.EncodeToString(key.Hash),
+		base64.StdEncoding.EncodeToString([]byte(key.Prefix)),
+		base64.StdEncoding.EncodeToString([]byte(key.Name)),
+	)
+}
+
+func revokeRecord(id repositories.KeyID, revokedAt time.Time) string {
+	return fmt.Sprintf("%s,%s,%d", recordRevoke, id.String(), unixNano(revokedAt))
+}
+
+func logoutRecord(account repositories.User, revokedAt time.Time) string {
+	return fmt.Sprintf("%s,%s,%d", recordLogout, account.String(), unixNano(revokedAt))
+}
+
+func clickRecord(click repositories.Click) string {
+	return fmt.Sprintf("%s,%s,%d,%s,%s,%s",
+		recordClick, click.ID, click.Time.UnixNano(),
+		base64.StdEncoding.EncodeToString([]byte(click.Referrer)),
+		base64.StdEncoding.EncodeToString([]byte(click.UserAgent)),
+		click.IP,
+	)
+}
+
+// inviteRecords - записи, которые восстанавливают приглашение со всем его состоянием.
+func inviteRecords(invite repositories.TeamInvite) []string {
+	records := []string{inviteRecord(invite)}
+	if !invite.UsedAt.IsZero() {
+		records = append(records, acceptRecord(invite.ID, invite.UsedBy, invite.UsedAt))
+	}
+	if !invite.RevokedAt.IsZero() {
+		records = append(records, cancelRecord(invite.ID, invite.RevokedAt))
+	}
+	return records
+}
+
+// linkRecords - записи, которые восстанавливают ссылку со всем ее состоянием.
+//
+// Ссылка создается с первым исходным URL из history, а затем повторяются все его замены.
+func linkRecords(id repositories.ID, link repositories.LinkData, history []repositories.URLVersion) []string {
+	current := link.URL
+	if len(history) > 0 {
+		link.URL = history[0].URL
+	}
+
+	records := []string{newRecord(id, link)}
+	for i, prev := range history {
+		url := current
+		if i+1 < len(history) {
+			url = history[i+1].URL
+		}
+		records = append(records, updateRecord(id, url, prev))
+	}
+	if link.Hits > 0 {
+		records = append(records, hitsRecord(id, link.Hits))
+	}
+	if link.Deleted {
+		records = append(records, deleteRecord(id, link.User, link.DeletedAt))
+	}
+	return records
+}
+
+// apply - применить запись из файла к состоянию хранилища.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *FileStorage) apply(line string) error {
+	splitted := strings.Split(line, ",")
+
+	switch splitted[0] {
+	case recordNew:
+		return st.loadNew(splitted)
+	case recordDelete:
+		return st.loadDelete(splitted)
+	case recordRestore:
+		return st.loadRestore(splitted)
+	case recordHit:
+		return st.loadHit(splitted)
+	case recordHits:
+		return st.loadHits(splitted)
+	case recordPurge:
+		return st.loadPurge(splitted)
+	case recordClick:
+		return st.loadClick(splitted)
+	case recordTeam:
+		return st.loadTeam(splitted)
+	case recordMember:
+		return st.loadMember(splitted)
+	case recordLeave:
+		return st.loadLeave(splitted)
+	case recordInvite:
+		return st.loadInvite(splitted)
+	case recordAccept:
+		return st.loadAccept(splitted)
+	case recordCancel:
+		return st.loadCancel(splitted)
+	case recordTransfer:
+		return st.loadTransfer(splitted)
+	case recordUpdate:
+		return st.loadUpdate(splitted)
+	case recordAccount:
+		return st.loadAccount(splitted)
+	case recordKey:
+		return st.loadKey(splitted)
+	case recordRevoke:
+		return st.loadRevoke(splitted)
+	case recordLogout:
+		return st.loadLogout(splitted)
+	}
+
+	return repositories.ErrUnknownRecord
+}
+
+func (st *FileStorage) loadNew(splitted []string) error {
+	if len(splitted) != 4 && len(splitted) != 6 && len(splitted) != 7 && len(splitted) != 8 {
+		return repositories.ErrWrongRecord
+	}
+
+	id := splitted[1]
+	user, err := uuid.Parse(splitted[2])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	var data []byte
+	data, err = base64.StdEncoding.DecodeString(splitted[3])
+	if err != nil {
+		return repositories.ErrUnableDecodeURL
+	}
+	url := repositories.URL(data)
+
+	link := repositories.LinkData{
+		URL:  url,
+		User: user,
+	}
+
+	if len(splitted) >= 6 {
+		var expiresAt int64
+		expiresAt, err = strconv.ParseInt(splitted[4], 10, 64)
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+		if expiresAt != 0 {
+			link.ExpiresAt = time.Unix(0, expiresAt)
+		}
+
+		link.MaxHits, err = strconv.ParseUint(splitted[5], 10, 64)
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+	}
+
+	if len(splitted) >= 7 {
+		var createdAt int64
+		createdAt, err = strconv.ParseInt(splitted[6], 10, 64)
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+		if createdAt != 0 {
+			link.CreatedAt = time.Unix(0, createdAt)
+		}
+	}
+
+	if len(splitted) == 8 {
+		link.Team, err = uuid.Parse(splitted[7])
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+	}
+
+	st.PutLink(id, link)
+
+	return nil
+}
+
+// loadDelete - загрузить запись об удалении.
+//
+// В записях старого формата нет времени удаления, для них срок хранения удаленных ссылок
+// отсчитывается от загрузки.
+func (st *FileStorage) loadDelete(splitted []string) error {
+	if len(splitted) != 3 && len(splitted) != 4 {
+		return repositories.ErrWrongRecord
+	}
+
+	id := splitted[1]
+	user, err := uuid.Parse(splitted[2])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok {
+		return repositories.ErrLinkNotExists
+	}
+	// Права на удаление ссылки команды проверены при записи, состав команды с тех пор мог измениться.
+	if link.Team == uuid.Nil && link.User != user {
+		return repositories.ErrUserNotMatch
+	}
+
+	link.Deleted = true
+	link.DeletedAt = time.Now().Round(0)
+	if len(splitted) == 4 {
+		var deletedAt int64
+		deletedAt, err = strconv.ParseInt(splitted[3], 10, 64)
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+		if deletedAt != 0 {
+			link.DeletedAt = time.Unix(0, deletedAt)
+		}
+	}
+	st.IDLinkDataDictionary[id] = link
+
+	return nil
+}
+
+func (st *FileStorage) loadRestore(splitted []string) error {
+	if len(splitted) != 3 {
+		return repositories.ErrWrongRecord
+	}
+
+	if _, err := uuid.Parse(splitted[2]); err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	if _, ok := st.IDLinkDataDictionary[splitted[1]]; !ok {
+		return repositories.ErrLinkNotExists
+	}
+
+	st.UndeleteLink(splitted[1])
+
+	return nil
+}
+
+func (st *FileStorage) loadHit(splitted []string) error {
+	if len(splitted) != 2 {
+		return repositories.ErrWrongRecord
+	}
+
+	link, ok := st.IDLinkDataDictionary[splitted[1]]
+	if !ok {
+		return repositories.ErrLinkNotExists
+	}
+
+	link.Hits++
+	st.IDLinkDataDictionary[splitted[1]] = link
+
+	return nil
+}
+
+func (st *FileStorage) loadHits(splitted []string) error {
+	if len(splitted) != 3 {
+		return repositories.ErrWrongRecord
+	}
+
+	link, ok := st.IDLinkDataDictionary[splitted[1]]
+	if !ok {
+		return repositories.ErrLinkNotExists
+	}
+
+	hits, err := strconv.ParseUint(splitted[2], 10, 64)
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	link.Hits = hits
+	st.IDLinkDataDictionary[splitted[1]] = link
+
+	return nil
+}
+
+func (st *FileStorage) loadPurge(splitted []string) error {
+	if len(splitted) != 2 {
+		return repositories.ErrWrongRecord
+	}
+
+	st.RemoveLink(splitted[1])
+
+	return nil
+}
+
+func (st *FileStorage) loadClick(splitted []string) error {
+	if len(splitted) != 6 {
+		return repositories.ErrWrongRecord
+	}
+
+	click := repositories.Click{
+		ID: splitted[1],
+		IP: splitted[5],
+	}
+
+	if _, ok := st.IDLinkDataDictionary[click.ID]; !ok {
+		return repositories.ErrLinkNotExists
+	}
+
+	t, err := strconv.ParseInt(splitted[2], 10, 64)
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+	click.Time = time.Unix(0, t)
+
+	referrer, err := base64.StdEncoding.DecodeString(splitted[3])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+	click.Referrer = string(referrer)
+
+	userAgent, err := base64.StdEncoding.DecodeString(splitted[4])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+	click.UserAgent = string(userAgent)
+
+	st.Clicks[click.ID] = append(st.Clicks[click.ID], click)
+
+	return nil
+}
+
+func (st *FileStorage) loadTeam(splitted []string) error {
+	if len(splitted) != 4 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	createdAt, err := strconv.ParseInt(splitted[2], 10, 64)
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	name, err := base64.StdEncoding.DecodeString(splitted[3])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	team := repositories.Team{ID: id, Name: string(name)}
+	if createdAt != 0 {
+		team.CreatedAt = time.Unix(0, createdAt)
+	}
+	st.PutTeam(team)
+
+	return nil
+}
+
+func (st *FileStorage) loadMember(splitted []string) error {
+	if len(splitted) != 4 {
+		return repositories.ErrWrongRecord
+	}
+
+	team, user, err := parseTeamUser(splitted[1], splitted[2])
+	if err != nil {
+		return err
+	}
+	if _, ok := st.Teams[team]; !ok {
+		return repositories.ErrTeamNotFound
+	}
+
+	role, err := repositories.ParseRole(splitted[3])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	st.PutTeamMember(team, user, role)
+
+	return nil
+}
+
+func (st *FileStorage) loadLeave(splitted []string) error {
+	if len(splitted) != 3 {
+		return repositories.ErrWrongRecord
+	}
+
+	team, user, err := parseTeamUser(splitted[1], splitted[2])
+	if err != nil {
+		return err
+	}
+
+	st.DropTeamMember(team, user)
+
+	return nil
+}
+
+func (st *FileStorage) loadInvite(splitted []string) error {
+	if len(splitted) != 6 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	team, err := uuid.Parse(splitted[2])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+	if _, ok := st.Teams[team]; !ok {
+		return repositories.ErrTeamNotFound
+	}
+
+	role, err := repositories.ParseRole(splitted[3])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	var times [2]time.Time
+	for i := range times {
+		t, err := strconv.ParseInt(splitted[4+i], 10, 64)
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+		if t != 0 {
+			times[i] = time.Unix(0, t)
+		}
+	}
+
+	st.PutInvite(repositories.TeamInvite{
+		CreatedAt: times[0],
+		ExpiresAt: times[1],
+		ID:        id,
+		Team:      team,
+		Role:      role,
+	})
+
+	return nil
+}
+
+func (st *FileStorage) loadAccept(splitted []string) error {
+	if len(splitted) != 4 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	user, err := uuid.Parse(splitted[2])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	usedAt, err := strconv.ParseInt(splitted[3], 10, 64)
+	if err != nil || usedAt == 0 {
+		return repositories.ErrWrongRecord
+	}
+
+	invite, ok := st.Invites[id]
+	if !ok {
+		return repositories.ErrInviteNotFound
+	}
+	invite.UsedAt = time.Unix(0, usedAt)
+	invite.UsedBy = user
+	st.PutInvite(invite)
+
+	return nil
+}
+
+func (st *FileStorage) loadCancel(splitted []string) error {
+	if len(splitted) != 3 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	revokedAt, err := strconv.ParseInt(splitted[2], 10, 64)
+	if err != nil || revokedAt == 0 {
+		return repositories.ErrWrongRecord
+	}
+
+	invite, ok := st.Invites[id]
+	if !ok {
+		return repositories.ErrInviteNotFound
+	}
+	invite.RevokedAt = time.Unix(0, revokedAt)
+	st.PutInvite(invite)
+
+	return nil
+}
+
+func (st *FileStorage) loadTransfer(splitted []string) error {
+	if len(splitted) != 4 {
+		return repositories.ErrWrongRecord
+	}
+
+	var team repositories.TeamID
+	if splitted[2] != "" {
+		var err error
+		team, err = uuid.Parse(splitted[2])
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+	}
+
+	user, err := uuid.Parse(splitted[3])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	if _, ok := st.IDLinkDataDictionary[splitted[1]]; !ok {
+		return repositories.ErrLinkNotExists
+	}
+
+	st.MoveLink(splitted[1], team, user)
+
+	return nil
+}
+
+func (st *FileStorage) loadUpdate(splitted []string) error {
+	if len(splitted) != 5 {
+		return repositories.ErrWrongRecord
+	}
+
+	id := splitted[1]
+	if _, ok := st.IDLinkDataDictionary[id]; !ok {
+		return repositories.ErrLinkNotExists
+	}
+
+	user, err := uuid.Parse(splitted[2])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	url, err := base64.StdEncoding.DecodeString(splitted[3])
+	if err != nil {
+		return repositories.ErrUnableDecodeURL
+	}
+
+	replacedAt, err := strconv.ParseInt(splitted[4], 10, 64)
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	prev := repositories.URLVersion{User: user}
+	if replacedAt != 0 {
+		prev.ReplacedAt = time.Unix(0, replacedAt)
+	}
+	st.ReplaceURL(id, repositories.URL(url), prev)
+
+	return nil
+}
+
+func (st *FileStorage) loadAccount(splitted []string) error {
+	if len(splitted) != 5 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	createdAt, err := strconv.ParseInt(splitted[2], 10, 64)
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	login, err := base64.StdEncoding.DecodeString(splitted[3])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	hash, err := base64.StdEncoding.DecodeString(splitted[4])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	account := repositories.Account{ID: id, Login: string(login), PasswordHash: hash}
+	if createdAt != 0 {
+		account.CreatedAt = time.Unix(0, createdAt)
+	}
+	st.PutAccount(account)
+
+	return nil
+}
+
+func (st *FileStorage) loadKey(splitted []string) error {
+	if len(splitted) != 8 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	account, err := uuid.Parse(splitted[2])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+	if _, ok := st.Accounts[account]; !ok {
+		return repositories.ErrAccountNotFound
+	}
+
+	createdAt, err := strconv.ParseInt(splitted[3], 10, 64)
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	scope, err := repositories.ParseScope(splitted[4])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	var fields [3][]byte
+	for i := range fields {
+		fields[i], err = base64.StdEncoding.DecodeString(splitted[5+i])
+		if err != nil {
+			return repositories.ErrWrongRecord
+		}
+	}
+
+	key := repositories.APIKey{
+		ID:      id,
+		Account: account,
+		Scope:   scope,
+		Hash:    fields[0],
+		Prefix:  string(fields[1]),
+		Name:    string(fields[2]),
+	}
+	if createdAt != 0 {
+		key.CreatedAt = time.Unix(0, createdAt)
+	}
+	st.PutAPIKey(key)
+
+	return nil
+}
+
+func (st *FileStorage) loadRevoke(splitted []string) error {
+	if len(splitted) != 3 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrWrongRecord
+	}
+
+	revokedAt, err := strconv.ParseInt(splitted[2], 10, 64)
+	if err != nil || revokedAt == 0 {
+		return repositories.ErrWrongRecord
+	}
+
+	key, ok := st.APIKeys[id]
+	if !ok {
+		return repositories.ErrAPIKeyNotFound
+	}
+	key.RevokedAt = time.Unix(0, revokedAt)
+	st.PutAPIKey(key)
+
+	return nil
+}
+
+func (st *FileStorage) loadLogout(splitted []string) error {
+	if len(splitted) != 3 {
+		return repositories.ErrWrongRecord
+	}
+
+	id, err := uuid.Parse(splitted[1])
+	if err != nil {
+		return repositories.ErrUnableParseUser
+	}
+
+	revokedAt, err := strconv.ParseInt(splitted[2], 10, 64)
+	if err != nil || revokedAt == 0 {
+		return repositories.ErrWrongRecord
+	}
+
+	account, ok := st.Accounts[id]
+	if !ok {
+		return repositories.ErrAccountNotFound
+	}
+	account.SessionsRevokedAt = time.Unix(0, revokedAt)
+	st.PutAccount(account)
+
+	return nil
+}
+
+func parseTeamUser(team, user string) (repositories.TeamID, repositories.User, error) {
+	teamID, err := uuid.Parse(team)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, repositories.ErrWrongRecord
+	}
+
+	userID, err := uuid.Parse(user)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, repositories.ErrUnableParseUser
+	}
+
+	return teamID, userID, nil
+}
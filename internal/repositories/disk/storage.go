@@ -4,75 +4,553 @@ package disk
 import (
 	"bufio"
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
 )
 
+// SyncMode - режим синхронизации файла хранилища с диском.
+type SyncMode string
+
+// Режимы синхронизации.
+const (
+	SyncAlways   SyncMode = "always"   // fsync после каждой записи, режим по умолчанию.
+	SyncInterval SyncMode = "interval" // fsync в фоне раз в Options.SyncInterval.
+	SyncNever    SyncMode = "never"    // Сброс данных на диск остается на усмотрение ОС.
+)
+
+const defaultSyncInterval = time.Second
+
+// Options - параметры FileStorage.
+type Options struct {
+	CompactInterval time.Duration          // Период фонового сжатия журнала, 0 - не сжимать в фоне.
+	Snapshot        bool                   // Хранить состояние в снимке, а в журнале - только изменения после него.
+	Sync            SyncMode               // Режим синхронизации с диском.
+	SyncInterval    time.Duration          // Период синхронизации в режиме SyncInterval, по умолчанию 1 секунда.
+	Dedup           repositories.DedupMode // Режим поиска уже сокращенных URL.
+	IDs             idgen.IDGenerator      // Стратегия генерации ID, по умолчанию - idgen.Default.
+	Logger          *zap.Logger            // Логгер, по умолчанию - глобальный логгер zap.
+}
+
 // FileStorage - структура для хранилища в файле.
 type FileStorage struct {
 	file *os.File
 	memory.MemStorage
+	opts      Options
+	path      string
+	gen       uint64
+	dirty     bool
 	fileMutex sync.Mutex
+	compactMu sync.RWMutex
+	shutdown  chan struct{}
+	wg        sync.WaitGroup
 }
 
 // NewFileStorage - конструктор для FileStorage.
-func NewFileStorage(file *os.File) (*FileStorage, error) {
+func NewFileStorage(file *os.File, opts Options) (*FileStorage, error) {
+	if file == nil {
+		return nil, os.ErrInvalid
+	}
+
+	switch opts.Sync {
+	case "":
+		opts.Sync = SyncAlways
+	case SyncAlways, SyncNever:
+	case SyncInterval:
+		if opts.SyncInterval <= 0 {
+			opts.SyncInterval = defaultSyncInterval
+		}
+	default:
+		return nil, repositories.ErrUnknownSync
+	}
+
 	st := &FileStorage{
-		file: file,
+		file:     file,
+		opts:     opts,
+		path:     file.Name(),
+		shutdown: make(chan struct{}),
 	}
 	st.IDLinkDataDictionary = make(map[repositories.ID]repositories.LinkData)
-	st.ExistingURLs = make(map[repositories.URL]repositories.ID)
+	st.ExistingURLs = make(map[string]repositories.ID)
+	st.Clicks = make(map[repositories.ID][]repositories.Click)
+	st.History = make(map[repositories.ID][]repositories.URLVersion)
+	st.Deletions = make(map[repositories.DeletionID]repositories.User)
+	st.UserLinks = make(map[repositories.User][]repositories.ID)
+	st.TeamLinks = make(map[repositories.TeamID][]repositories.ID)
+	st.Teams = make(map[repositories.TeamID]repositories.Team)
+	st.TeamMembers = make(map[repositories.TeamID]map[repositories.User]repositories.Role)
+	st.Invites = make(map[repositories.InviteID]repositories.TeamInvite)
+	st.Accounts = make(map[repositories.User]repositories.Account)
+	st.AccountLogins = make(map[string]repositories.User)
+	st.APIKeys = make(map[repositories.KeyID]repositories.APIKey)
+	st.APIKeyHashes = make(map[string]repositories.KeyID)
+	st.Dedup = opts.Dedup
+	st.IDs = opts.IDs
 
 	err := st.load()
 	if err != nil {
 		return nil, err
 	}
 
+	if opts.CompactInterval > 0 {
+		st.wg.Add(1)
+		go st.compactWorker()
+	}
+	if opts.Sync == SyncInterval {
+		st.wg.Add(1)
+		go st.syncWorker()
+	}
+
 	return st, nil
 }
 
 // Add - адаптер для AddLink.
 func (st *FileStorage) Add(
-	_ context.Context,
+	ctx context.Context,
 	url repositories.URL,
 	user repositories.User,
+	opts repositories.LinkOptions,
 ) (id repositories.ID, err error) {
-	id, err = st.AddLink(url, user)
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	id, err = st.AddLink(url, user, opts)
 	if err != nil {
 		return
 	}
 
-	err = st.write(fmt.Sprintf("NEW,%s,%s,%s", id, user.String(), base64.StdEncoding.EncodeToString([]byte(url))))
+	link, err := st.GetLink(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = st.write(newRecord(id, link))
 	return
 }
 
+// AddBatch - сократить пачку ссылок и записать созданные ссылки в файл одной записью.
+func (st *FileStorage) AddBatch(
+	ctx context.Context,
+	links []repositories.BatchLink,
+	user repositories.User,
+) (results []repositories.BatchResult, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	results, err = st.AddLinks(links, user)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]string, 0, len(results))
+	for _, res := range results {
+		if res.Err != nil {
+			continue
+		}
+		link, err := st.GetLink(ctx, res.ID)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, newRecord(res.ID, link))
+	}
+	if len(records) == 0 {
+		return results, nil
+	}
+
+	err = st.write(records...)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Get - получить оригинальную ссылку по ID.
+func (st *FileStorage) Get(ctx context.Context, id repositories.ID) (url repositories.URL, deleted bool, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	data, err := st.Hit(id)
+	if err != nil {
+		return "", false, err
+	}
+
+	if data.MaxHits > 0 && !data.Deleted {
+		err = st.write(hitRecord(id))
+		if err != nil {
+			st.log(ctx).Warn("unable to write hit", zap.Error(err))
+		}
+	}
+
+	return data.URL, data.Deleted, nil
+}
+
+// Import - сохранить ссылку со всеми данными как есть.
+func (st *FileStorage) Import(ctx context.Context, link repositories.LinkData) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	err := st.ImportLink(link)
+	if err != nil {
+		return err
+	}
+
+	link, err = st.GetLink(ctx, link.ID)
+	if err != nil {
+		return err
+	}
+
+	return st.write(linkRecords(link.ID, link, nil)...)
+}
+
+// PurgeExpired - удалить ссылки с истекшим сроком действия.
+func (st *FileStorage) PurgeExpired(_ context.Context) (count int64, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	ids := st.PurgeExpiredLinks(time.Now())
+	for _, id := range ids {
+		err = st.write(purgeRecord(id))
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
 // DeleteUserLinks - удалить ссылки пользователя.
-func (st *FileStorage) DeleteUserLinks(_ context.Context, ids []repositories.ID, user repositories.User) error {
+//
+// Записи об удалении дописываются в файл сразу, поэтому запрос всегда находится
+// в состоянии repositories.DeletionDone. Сами ID запросов в файле не хранятся.
+func (st *FileStorage) DeleteUserLinks(
+	ctx context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+) (deletion repositories.DeletionID, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	now := time.Now().Round(0)
 	for _, id := range ids {
-		ok := st.DeleteUserLink(id, user)
+		ok := st.DeleteUserLink(id, user, now)
 		if ok {
-			err := st.write(fmt.Sprintf("DELETE,%s,%s", id, user.String()))
+			err = st.write(deleteRecord(id, user, now))
 			if err != nil {
-				log.Printf("unable to write delete: %v", err)
+				st.log(ctx).Error("unable to write delete", zap.Error(err))
+				return uuid.Nil, err
 			}
 		}
 	}
-	return nil
+	return st.NewDeletion(user), nil
+}
+
+// RestoreUserLinks - восстановить ссылки пользователя, удаленные не раньше since.
+func (st *FileStorage) RestoreUserLinks(
+	_ context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+	since time.Time,
+) (restored []repositories.ID, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	restored = make([]repositories.ID, 0, len(ids))
+	for _, id := range ids {
+		if !st.RestoreUserLink(id, user, since) {
+			continue
+		}
+
+		err = st.write(restoreRecord(id, user))
+		if err != nil {
+			return restored, err
+		}
+		restored = append(restored, id)
+	}
+
+	return restored, nil
+}
+
+// PurgeDeleted - безвозвратно удалить ссылки, удаленные раньше before.
+func (st *FileStorage) PurgeDeleted(_ context.Context, before time.Time) (count int64, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	ids := st.PurgeDeletedLinks(before)
+	for _, id := range ids {
+		err = st.write(purgeRecord(id))
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Update - заменить исходный URL ссылки, прежний URL сохраняется в истории.
+func (st *FileStorage) Update(
+	_ context.Context,
+	id repositories.ID,
+	url repositories.URL,
+	user repositories.User,
+) (link repositories.LinkData, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	link, prev, err := st.UpdateLink(id, url, user, time.Now().Round(0))
+	if err != nil || prev.URL == "" {
+		return link, err
+	}
+
+	return link, st.write(updateRecord(id, url, prev))
+}
+
+// CreateTeam - создать команду, owner становится ее владельцем.
+func (st *FileStorage) CreateTeam(ctx context.Context, team repositories.Team, owner repositories.User) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	err := st.MemStorage.CreateTeam(ctx, team, owner)
+	if err != nil {
+		return err
+	}
+
+	return st.write(teamRecord(team), memberRecord(team.ID, owner, repositories.RoleOwner))
+}
+
+// SetTeamMember - добавить пользователя в команду или изменить его роль.
+func (st *FileStorage) SetTeamMember(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	role repositories.Role,
+) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	err := st.MemStorage.SetTeamMember(ctx, team, user, role)
+	if err != nil {
+		return err
+	}
+
+	return st.write(memberRecord(team, user, role))
+}
+
+// RemoveTeamMember - исключить пользователя из команды.
+func (st *FileStorage) RemoveTeamMember(ctx context.Context, team repositories.TeamID, user repositories.User) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	err := st.MemStorage.RemoveTeamMember(ctx, team, user)
+	if err != nil {
+		return err
+	}
+
+	return st.write(leaveRecord(team, user))
+}
+
+// CreateInvite - сохранить приглашение в команду.
+func (st *FileStorage) CreateInvite(ctx context.Context, invite repositories.TeamInvite) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	err := st.MemStorage.CreateInvite(ctx, invite)
+	if err != nil {
+		return err
+	}
+
+	return st.write(inviteRecord(invite))
+}
+
+// AcceptInvite - принять приглашение в команду.
+func (st *FileStorage) AcceptInvite(
+	_ context.Context,
+	id repositories.InviteID,
+	user repositories.User,
+	at time.Time,
+) (repositories.TeamInvite, error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	invite, joined, err := st.AcceptTeamInvite(id, user, at)
+	if err != nil {
+		return invite, err
+	}
+
+	records := []string{acceptRecord(id, user, at)}
+	if joined {
+		records = append(records, memberRecord(invite.Team, user, invite.Role))
+	}
+
+	return invite, st.write(records...)
+}
+
+// RevokeInvite - отозвать приглашение в команду.
+func (st *FileStorage) RevokeInvite(
+	_ context.Context,
+	id repositories.InviteID,
+	team repositories.TeamID,
+	at time.Time,
+) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	revoked, err := st.RevokeTeamInvite(id, team, at)
+	if err != nil || !revoked {
+		return err
+	}
+
+	return st.write(cancelRecord(id, at))
+}
+
+// TransferLinks - передать ссылки в команду или в личные ссылки пользователя.
+func (st *FileStorage) TransferLinks(
+	_ context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+	team repositories.TeamID,
+) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	links, err := st.TransferUserLinks(ids, user, team)
+	if err != nil {
+		return err
+	}
+
+	records := make([]string, 0, len(links))
+	for _, link := range links {
+		records = append(records, transferRecord(link.ID, link.Team, link.User))
+	}
+
+	return st.write(records...)
+}
+
+// CreateAccount - зарегистрировать учетную запись.
+func (st *FileStorage) CreateAccount(ctx context.Context, account repositories.Account) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	err := st.MemStorage.CreateAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	return st.write(accountRecord(account))
+}
+
+// CreateAPIKey - сохранить API-ключ учетной записи.
+func (st *FileStorage) CreateAPIKey(ctx context.Context, key repositories.APIKey) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	err := st.MemStorage.CreateAPIKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return st.write(keyRecord(key))
+}
+
+// RevokeAPIKey - отозвать API-ключ учетной записи.
+func (st *FileStorage) RevokeAPIKey(
+	_ context.Context,
+	id repositories.KeyID,
+	account repositories.User,
+	at time.Time,
+) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	revoked, err := st.RevokeAccountAPIKey(id, account, at)
+	if err != nil || !revoked {
+		return err
+	}
+
+	return st.write(revokeRecord(id, at))
+}
+
+// RevokeSessions - завершить сессии учетной записи, выданные до at.
+func (st *FileStorage) RevokeSessions(_ context.Context, account repositories.User, at time.Time) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	revoked, err := st.RevokeAccountSessions(account, at)
+	if err != nil || !revoked {
+		return err
+	}
+
+	return st.write(logoutRecord(account, at))
+}
+
+// ClaimLinks - передать личные ссылки пользователя from пользователю to.
+func (st *FileStorage) ClaimLinks(
+	_ context.Context,
+	from repositories.User,
+	to repositories.User,
+) (claimed []repositories.ID, err error) {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	claimed = st.ClaimUserLinks(from, to)
+	if len(claimed) == 0 {
+		return claimed, nil
+	}
+
+	records := make([]string, 0, len(claimed))
+	for _, id := range claimed {
+		records = append(records, transferRecord(id, uuid.Nil, to))
+	}
+
+	return claimed, st.write(records...)
+}
+
+// AddClicks - сохранить переходы по ссылкам.
+func (st *FileStorage) AddClicks(_ context.Context, clicks []repositories.Click) error {
+	st.compactMu.RLock()
+	defer st.compactMu.RUnlock()
+
+	added := st.AddClickList(clicks)
+	if len(added) == 0 {
+		return nil
+	}
+
+	records := make([]string, 0, len(added))
+	for _, click := range added {
+		records = append(records, clickRecord(click))
+	}
+
+	return st.write(records...)
 }
 
 // Close - мягко завершить работу хранилища.
 func (st *FileStorage) Close(_ context.Context) error {
+	close(st.shutdown)
+	st.wg.Wait()
+
+	st.fileMutex.Lock()
+	defer st.fileMutex.Unlock()
+
+	if st.opts.Sync != SyncNever {
+		err := st.file.Sync()
+		if err != nil {
+			_ = st.file.Close()
+			return err
+		}
+	}
+
 	return st.file.Close()
 }
 
@@ -80,89 +558,207 @@ func (st *FileStorage) load() error {
 	st.Lock()
 	defer st.Unlock()
 
+	snapshotVersion, gen, err := st.loadSnapshot()
+	if err != nil {
+		return err
+	}
+	st.gen = gen
+
+	logVersion, err := st.loadLog()
+	if err != nil {
+		return err
+	}
+
+	if snapshotVersion < formatVersion || logVersion < formatVersion {
+		st.log(context.Background()).Info("upgrading file storage", zap.Int("format_version", formatVersion))
+		return st.upgrade()
+	}
+
+	return nil
+}
+
+// loadLog - прочитать журнал изменений.
+//
+// Журнал без заголовка считается журналом в формате formatLegacy,
+// после загрузки он переписывается в текущем формате.
+// Журнал старого поколения остается после сбоя между записью снимка
+// и очисткой журнала: все его записи уже есть в снимке.
+//
+// Недописанная последняя запись (без перевода строки или с неверной контрольной суммой)
+// отрезается. Поврежденная запись в середине журнала - ошибка загрузки.
+func (st *FileStorage) loadLog() (version int, err error) {
 	reader := bufio.NewReader(st.file)
 
-	i := 0
-	for {
+	version = formatLegacy
+	gen := st.gen
+	var offset int64
+	torn := false
+
+	for i := 0; ; i++ {
 		bytes, err := reader.ReadBytes('\n')
 		if errors.Is(err, io.EOF) {
+			if len(bytes) > 0 {
+				st.log(context.Background()).Warn("unterminated record", zap.Int("line", i+1))
+				torn = version != formatLegacy
+			}
 			break
 		}
 		if err != nil {
-			log.Printf("unable to read bytes: %v", err)
-			return err
+			st.log(context.Background()).Error("unable to read bytes", zap.Error(err))
+			return 0, err
 		}
-		line := strings.Trim(string(bytes), "\n")
-		splitted := strings.Split(line, ",")
+		line := strings.TrimSuffix(string(bytes), "\n")
 
-		switch splitted[0] {
-		case "NEW":
-			err = st.loadNew(splitted)
-		case "DELETE":
-			err = st.loadDelete(splitted)
+		if i == 0 {
+			v, g, ok := parseHeader(line, headerLog)
+			if ok {
+				if v > formatVersion {
+					return 0, repositories.ErrUnknownVersion
+				}
+				version, gen = v, g
+				if gen > st.gen {
+					return 0, repositories.ErrSnapshotMissing
+				}
+				if gen < st.gen {
+					break
+				}
+				offset += int64(len(bytes))
+				continue
+			}
 		}
+
+		err = st.applyLine(version, line, i+1)
 		if err != nil {
-			log.Printf("unable to parse line %d: %v", i, err)
+			if _, peekErr := reader.Peek(1); errors.Is(peekErr, io.EOF) {
+				st.log(context.Background()).Warn("broken last record", zap.Int("line", i+1), zap.Error(err))
+				torn = true
+				break
+			}
+			return 0, fmt.Errorf("line %d: %w", i+1, err)
 		}
 
-		i++
+		offset += int64(len(bytes))
 	}
 
-	return nil
+	switch {
+	case gen < st.gen:
+		st.log(context.Background()).Info("log generation is older than snapshot generation, skipping",
+			zap.Uint64("log_generation", gen), zap.Uint64("snapshot_generation", st.gen))
+		return formatVersion, st.resetLog()
+	case offset == 0:
+		return formatVersion, st.resetLog()
+	case torn:
+		st.log(context.Background()).Warn("truncating log", zap.Int64("bytes", offset))
+		return version, st.truncate(offset)
+	}
+
+	return version, nil
 }
 
-func (st *FileStorage) loadNew(splitted []string) error {
-	id := splitted[1]
-	user, err := uuid.Parse(splitted[2])
-	if err != nil {
-		return repositories.ErrUnableParseUser
+// applyLine - разобрать и применить строку файла.
+//
+// В текущем формате ошибкой считаются только повреждение записи и неизвестный тип записи.
+// Ссылки на уже удаленные ссылки возможны из-за гонки записи в файл и PurgeExpired,
+// такие записи пропускаются.
+func (st *FileStorage) applyLine(version int, line string, n int) error {
+	if version == formatLegacy {
+		err := st.apply(line)
+		if err != nil {
+			st.log(context.Background()).Warn("unable to parse line", zap.Int("line", n), zap.Error(err))
+		}
+		return nil
 	}
 
-	var data []byte
-	data, err = base64.StdEncoding.DecodeString(splitted[3])
+	record, err := decodeRecord(line)
 	if err != nil {
-		return repositories.ErrUnableDecodeURL
+		return err
 	}
-	url := repositories.URL(data)
 
-	st.IDLinkDataDictionary[id] = repositories.LinkData{
-		URL:  url,
-		User: user,
+	err = st.apply(record)
+	if errors.Is(err, repositories.ErrUnknownRecord) {
+		return err
+	}
+	if err != nil {
+		st.log(context.Background()).Warn("skipping line", zap.Int("line", n), zap.Error(err))
 	}
-	st.ExistingURLs[url] = id
 
 	return nil
 }
 
-func (st *FileStorage) loadDelete(splitted []string) error {
-	id := splitted[1]
-	user, err := uuid.Parse(splitted[2])
-	if err != nil {
-		return repositories.ErrUnableParseUser
+// upgrade - переписать файлы хранилища в текущем формате.
+func (st *FileStorage) upgrade() error {
+	if st.opts.Snapshot {
+		return st.compactSnapshot()
 	}
+	return st.compactLog()
+}
 
-	link, ok := st.IDLinkDataDictionary[id]
-	if !ok {
-		return repositories.ErrLinkNotExists
+// truncate - отрезать недописанный хвост журнала.
+func (st *FileStorage) truncate(size int64) error {
+	err := st.file.Truncate(size)
+	if err != nil {
+		return err
 	}
-	if link.User != user {
-		return repositories.ErrUserNotMatch
+
+	_, err = st.file.Seek(size, io.SeekStart)
+	if err != nil {
+		return err
 	}
 
-	link.Deleted = true
-	st.IDLinkDataDictionary[id] = link
+	return st.file.Sync()
+}
 
-	return nil
+// log - логгер хранилища с полями запроса из ctx.
+func (st *FileStorage) log(ctx context.Context) *zap.Logger {
+	return logger.Ctx(ctx, st.opts.Logger)
 }
 
-func (st *FileStorage) write(data string) error {
+func (st *FileStorage) write(records ...string) error {
 	st.fileMutex.Lock()
 	defer st.fileMutex.Unlock()
 
-	_, err := st.file.Write([]byte(data + "\n"))
+	var b strings.Builder
+	for _, record := range records {
+		b.WriteString(encodeRecord(record))
+		b.WriteByte('\n')
+	}
+
+	_, err := st.file.WriteString(b.String())
 	if err != nil {
 		return err
 	}
 
+	switch st.opts.Sync {
+	case SyncAlways:
+		return st.file.Sync()
+	case SyncInterval:
+		st.dirty = true
+	}
+
 	return nil
 }
+
+func (st *FileStorage) syncWorker() {
+	defer st.wg.Done()
+
+	ticker := time.NewTicker(st.opts.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-st.shutdown:
+			return
+		case <-ticker.C:
+			st.fileMutex.Lock()
+			if st.dirty {
+				err := st.file.Sync()
+				if err != nil {
+					st.log(context.Background()).Error("unable to sync file storage", zap.Error(err))
+				} else {
+					st.dirty = false
+				}
+			}
+			st.fileMutex.Unlock()
+		}
+	}
+}
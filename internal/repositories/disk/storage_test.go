@@ -3,7 +3,9 @@ package disk
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +30,7 @@ func TestFileStorage(t *testing.T) {
 	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o777)
 	require.NoError(t, err)
 
-	st, err := NewFileStorage(file)
+	st, err := NewFileStorage(file, Options{})
 	require.NoError(t, err)
 
 	links := []TestLink{
@@ -49,7 +51,7 @@ func TestFileStorage(t *testing.T) {
 	t.Run("short links", func(t *testing.T) {
 		var id repositories.ID
 		for index, link := range links {
-			id, err = st.Add(context.Background(), link.URL, testUser)
+			id, err = st.Add(context.Background(), link.URL, testUser, repositories.LinkOptions{})
 			require.NoError(t, err)
 			link.ID = id
 			links[index] = link
@@ -84,7 +86,7 @@ func TestFileStorage(t *testing.T) {
 				continue
 			}
 		}
-		err = st.DeleteUserLinks(context.Background(), linksIDs, testUser)
+		_, err = st.DeleteUserLinks(context.Background(), linksIDs, testUser)
 		require.NoError(t, err)
 	})
 
@@ -105,7 +107,7 @@ func TestFileStorage(t *testing.T) {
 	file, err = os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o777)
 	require.NoError(t, err)
 
-	st, err = NewFileStorage(file)
+	st, err = NewFileStorage(file, Options{})
 	require.NoError(t, err)
 
 	t.Run("get URLs after restart", func(t *testing.T) {
@@ -126,7 +128,383 @@ func TestFileStorage(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("empty file storage", func(t *testing.T) {
-		_, err = NewFileStorage(nil)
+		_, err = NewFileStorage(nil, Options{})
 		assert.Error(t, err)
 	})
 }
+
+// TestFileStorage_Expiration - тестируем, что ограничения срока действия ссылок переживают перезапуск.
+func TestFileStorage_Expiration(t *testing.T) {
+	filename := "testingExpirationStorage"
+	defer func() { _ = os.Remove(filename) }()
+
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o777)
+	require.NoError(t, err)
+
+	st, err := NewFileStorage(file, Options{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	testUser := uuid.New()
+
+	limited, err := st.Add(ctx, "https://example.com/hits", testUser, repositories.LinkOptions{MaxHits: 2})
+	require.NoError(t, err)
+	expired, err := st.Add(ctx, "https://example.com/time", testUser, repositories.LinkOptions{
+		ExpiresAt: time.Now().Add(50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	_, _, err = st.Get(ctx, limited)
+	require.NoError(t, err)
+
+	require.NoError(t, st.Close(ctx))
+
+	time.Sleep(100 * time.Millisecond)
+
+	file, err = os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0o777)
+	require.NoError(t, err)
+
+	st, err = NewFileStorage(file, Options{})
+	require.NoError(t, err)
+	defer func() { _ = st.Close(ctx) }()
+
+	_, _, err = st.Get(ctx, expired)
+	assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+
+	_, _, err = st.Get(ctx, limited)
+	assert.NoError(t, err)
+	_, _, err = st.Get(ctx, limited)
+	assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+
+	count, err := st.PurgeExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, _, err = st.Get(ctx, limited)
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+}
+
+// TestFileStorage_Import - тестируем, что импортированные ссылки переживают перезапуск.
+func TestFileStorage_Import(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	link := repositories.LinkData{
+		CreatedAt: time.Unix(1700000000, 0),
+		ID:        "imported",
+		URL:       "https://example.com",
+		User:      uuid.New(),
+		MaxHits:   5,
+		Hits:      3,
+		Deleted:   true,
+		DeletedAt: time.Unix(1700000100, 0),
+	}
+
+	st := openFileStorage(t, filename, Options{})
+	require.NoError(t, st.Import(ctx, link))
+	assert.ErrorIs(t, st.Import(ctx, link), repositories.ErrIDAlreadyExists)
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, Options{})
+	defer func() { _ = st.Close(ctx) }()
+
+	got, err := st.GetLink(ctx, link.ID)
+	require.NoError(t, err)
+	assert.Equal(t, link, got)
+}
+
+// TestFileStorage_Dedup - тестируем, что режим поиска совпадающих URL применяется и после перезапуска.
+func TestFileStorage_Dedup(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	opts := Options{Dedup: repositories.DedupPerUser}
+	userA, userB := uuid.New(), uuid.New()
+
+	st := openFileStorage(t, filename, opts)
+	idA, err := st.Add(ctx, "https://example.com", userA, repositories.LinkOptions{})
+	require.NoError(t, err)
+	idB, err := st.Add(ctx, "https://example.com", userB, repositories.LinkOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, idA, idB)
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, opts)
+	defer func() { _ = st.Close(ctx) }()
+
+	id, err := st.Add(ctx, "https://example.com", userB, repositories.LinkOptions{})
+	assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+	assert.Equal(t, idB, id)
+}
+
+func TestFileStorage_Teams(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	owner, editor, viewer := uuid.New(), uuid.New(), uuid.New()
+	team := repositories.Team{ID: uuid.New(), Name: "team", CreatedAt: time.Now().Round(0)}
+
+	st := openFileStorage(t, filename, Options{})
+	require.NoError(t, st.CreateTeam(ctx, team, owner))
+	require.NoError(t, st.SetTeamMember(ctx, team.ID, editor, repositories.RoleEditor))
+	require.NoError(t, st.SetTeamMember(ctx, team.ID, viewer, repositories.RoleViewer))
+	require.NoError(t, st.RemoveTeamMember(ctx, team.ID, viewer))
+
+	teamID, err := st.Add(ctx, "https://example.com/team", editor, repositories.LinkOptions{Team: team.ID})
+	require.NoError(t, err)
+	movedID, err := st.Add(ctx, "https://example.com/moved", owner, repositories.LinkOptions{})
+	require.NoError(t, err)
+	require.NoError(t, st.TransferLinks(ctx, []repositories.ID{movedID}, owner, team.ID))
+
+	now := time.Now().Round(0)
+	newInvite := func() repositories.TeamInvite {
+		invite := repositories.TeamInvite{
+			ID: uuid.New(), Team: team.ID, Role: repositories.RoleViewer, CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+		}
+		require.NoError(t, st.CreateInvite(ctx, invite))
+		return invite
+	}
+	used, revoked, open := newInvite(), newInvite(), newInvite()
+	_, err = st.AcceptInvite(ctx, used.ID, editor, now)
+	require.NoError(t, err)
+	require.NoError(t, st.RevokeInvite(ctx, revoked.ID, team.ID, now))
+	require.NoError(t, st.Close(ctx))
+
+	check := func(st *FileStorage) {
+		_, err := st.AcceptInvite(ctx, used.ID, viewer, now)
+		assert.ErrorIs(t, err, repositories.ErrInviteUsed)
+		_, err = st.AcceptInvite(ctx, revoked.ID, viewer, now)
+		assert.ErrorIs(t, err, repositories.ErrInviteRevoked)
+		assert.ErrorIs(t, st.RevokeInvite(ctx, open.ID, uuid.New(), now), repositories.ErrInviteNotFound)
+
+		teams, err := st.GetUserTeams(ctx, editor)
+		require.NoError(t, err)
+		require.Len(t, teams, 1)
+		assert.Equal(t, team.Name, teams[0].Name)
+		assert.Equal(t, repositories.RoleEditor, teams[0].Role)
+
+		_, err = st.GetTeamRole(ctx, team.ID, viewer)
+		assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+
+		page, err := st.ListUserLinks(ctx, owner, repositories.LinkQuery{Team: team.ID})
+		require.NoError(t, err)
+		require.Len(t, page.Links, 2)
+		assert.ElementsMatch(t, []repositories.ID{teamID, movedID},
+			[]repositories.ID{page.Links[0].ID, page.Links[1].ID})
+	}
+
+	st = openFileStorage(t, filename, Options{})
+	check(st)
+	require.NoError(t, st.Compact(ctx))
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, Options{})
+	defer func() { _ = st.Close(ctx) }()
+	check(st)
+
+	invite, err := st.AcceptInvite(ctx, open.ID, viewer, now)
+	require.NoError(t, err)
+	assert.Equal(t, viewer, invite.UsedBy)
+	role, err := st.GetTeamRole(ctx, team.ID, viewer)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.RoleViewer, role)
+}
+
+func TestFileStorage_Update(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	opts := Options{Dedup: repositories.DedupGlobal}
+	user := uuid.New()
+
+	st := openFileStorage(t, filename, opts)
+	id, err := st.Add(ctx, "https://example.com/v1", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+	_, err = st.Update(ctx, id, "https://example.com/v2", user)
+	require.NoError(t, err)
+	_, err = st.Update(ctx, id, "https://example.com/v3", user)
+	require.NoError(t, err)
+	reusedID, err := st.Add(ctx, "https://example.com/v1", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+	require.NoError(t, st.Close(ctx))
+
+	check := func(st *FileStorage) {
+		url, _, err := st.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/v3", url)
+
+		versions, err := st.GetLinkHistory(ctx, id, user)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, "https://example.com/v1", versions[0].URL)
+		assert.Equal(t, "https://example.com/v2", versions[1].URL)
+
+		existing, err := st.Add(ctx, "https://example.com/v1", user, repositories.LinkOptions{})
+		assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+		assert.Equal(t, reusedID, existing)
+	}
+
+	st = openFileStorage(t, filename, opts)
+	check(st)
+	require.NoError(t, st.Compact(ctx))
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, opts)
+	defer func() { _ = st.Close(ctx) }()
+	check(st)
+}
+
+func TestFileStorage_Trash(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	opts := Options{Dedup: repositories.DedupGlobal}
+	user := uuid.New()
+
+	st := openFileStorage(t, filename, opts)
+	restoredID, err := st.Add(ctx, "https://example.com/restored", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+	deletedID, err := st.Add(ctx, "https://example.com/deleted", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+	purgedID, err := st.Add(ctx, "https://example.com/purged", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	ids := []repositories.ID{restoredID, deletedID, purgedID}
+	_, err = st.DeleteUserLinks(ctx, ids, user)
+	require.NoError(t, err)
+	restored, err := st.RestoreUserLinks(ctx, []repositories.ID{restoredID}, user, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.ID{restoredID}, restored)
+
+	links, err := st.GetDeletedUserLinks(ctx, user)
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	deletedAt := st.IDLinkDataDictionary[deletedID].DeletedAt
+
+	link := st.IDLinkDataDictionary[purgedID]
+	link.DeletedAt = time.Now().Add(-48 * time.Hour)
+	st.IDLinkDataDictionary[purgedID] = link
+	count, err := st.PurgeDeleted(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.NoError(t, st.Close(ctx))
+
+	check := func(st *FileStorage) {
+		_, deleted, err := st.Get(ctx, restoredID)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+
+		links, err := st.GetDeletedUserLinks(ctx, user)
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+		assert.Equal(t, deletedID, links[0].ID)
+		assert.True(t, deletedAt.Equal(links[0].DeletedAt), "deletion time survives reload")
+
+		_, _, err = st.Get(ctx, purgedID)
+		assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	}
+
+	st = openFileStorage(t, filename, opts)
+	check(st)
+	require.NoError(t, st.Compact(ctx))
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, opts)
+	defer func() { _ = st.Close(ctx) }()
+	check(st)
+}
+
+func TestFileStorage_AddBatch(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	opts := Options{Dedup: repositories.DedupGlobal}
+	user := uuid.New()
+
+	st := openFileStorage(t, filename, opts)
+	results, err := st.AddBatch(ctx, []repositories.BatchLink{
+		{URL: "https://example.com/1"},
+		{URL: "https://example.com/2", Opts: repositories.LinkOptions{Alias: "second", MaxHits: 3}},
+		{URL: "https://example.com/1"},
+	}, user)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	require.NoError(t, results[1].Err)
+	assert.ErrorIs(t, results[2].Err, repositories.ErrURLAlreadyExists)
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, opts)
+	defer func() { _ = st.Close(ctx) }()
+
+	url, _, err := st.Get(ctx, results[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", url)
+
+	link, err := st.GetLink(ctx, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/2", link.URL)
+	assert.Equal(t, uint64(3), link.MaxHits)
+}
+
+func TestFileStorage_Accounts(t *testing.T) {
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "storage")
+	opts := Options{Dedup: repositories.DedupGlobal}
+	anonymous := uuid.New()
+	now := time.Now().Round(0)
+
+	account := repositories.Account{
+		ID:           uuid.New(),
+		Login:        "john",
+		PasswordHash: []byte("hash"),
+		CreatedAt:    now,
+	}
+	key := repositories.APIKey{
+		ID:        uuid.New(),
+		Account:   account.ID,
+		Name:      "ci, nightly",
+		Prefix:    "usk_12345678",
+		Hash:      []byte("key hash"),
+		Scope:     repositories.ScopeRead,
+		CreatedAt: now,
+	}
+
+	st := openFileStorage(t, filename, opts)
+	id, err := st.Add(ctx, "https://example.com", anonymous, repositories.LinkOptions{})
+	require.NoError(t, err)
+	require.NoError(t, st.CreateAccount(ctx, account))
+	require.NoError(t, st.CreateAPIKey(ctx, key))
+	require.NoError(t, st.RevokeAPIKey(ctx, key.ID, account.ID, now.Add(time.Minute)))
+	require.NoError(t, st.RevokeSessions(ctx, account.ID, now.Add(time.Hour)))
+	require.NoError(t, st.RevokeSessions(ctx, account.ID, now.Add(time.Minute)), "logout time does not go back")
+	assert.ErrorIs(t, st.RevokeSessions(ctx, anonymous, now), repositories.ErrAccountNotFound)
+	claimed, err := st.ClaimLinks(ctx, anonymous, account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.ID{id}, claimed)
+	require.NoError(t, st.Close(ctx))
+
+	check := func(st *FileStorage) {
+		got, err := st.GetAccountByLogin(ctx, "john")
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, got.ID)
+		assert.Equal(t, account.PasswordHash, got.PasswordHash)
+		assert.True(t, now.Equal(got.CreatedAt))
+		assert.True(t, now.Add(time.Hour).Equal(got.SessionsRevokedAt))
+
+		gotKey, err := st.GetAPIKey(ctx, key.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, key.Name, gotKey.Name)
+		assert.Equal(t, key.Prefix, gotKey.Prefix)
+		assert.Equal(t, key.Scope, gotKey.Scope)
+		assert.True(t, gotKey.Revoked())
+
+		links, err := st.GetUserLinks(ctx, account.ID)
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+		assert.Equal(t, id, links[0].ID)
+	}
+
+	st = openFileStorage(t, filename, opts)
+	check(st)
+	require.NoError(t, st.Compact(ctx))
+	require.NoError(t, st.Close(ctx))
+
+	st = openFileStorage(t, filename, opts)
+	defer func() { _ = st.Close(ctx) }()
+	check(st)
+}
@@ -2,14 +2,52 @@ package repositories
 
 import (
 	"errors"
+	"fmt"
 )
 
 // Типы ошибок.
 var (
 	ErrURLNotFound      = errors.New("URL not found")      // Ссылки с таким ID не существует.
 	ErrURLAlreadyExists = errors.New("URL already exists") // Ссылка с таким исходным URL уже есть.
+	ErrIDAlreadyExists  = errors.New("ID already exists")  // Ссылка с таким ID (алиасом) уже есть.
 	ErrUnableParseUser  = errors.New("unable parse user")  // Не получается распарсить пользователя из файла.
 	ErrUnableDecodeURL  = errors.New("unable decode URL")  // Не получается загрузить ссылку из файла.
 	ErrLinkNotExists    = errors.New("link not exists")    // Ссылки с таким ID не существует.
 	ErrUserNotMatch     = errors.New("user not match")     // Пользователь не может удалить чужую ссылку.
+	ErrLinkExpired      = errors.New("link expired")       // Срок действия ссылки истек.
+	ErrWrongRecord      = errors.New("wrong record")       // Запись в файле имеет неверный формат.
+	ErrDeletionNotFound = errors.New("deletion not found") // Запроса на удаление с таким ID не существует.
+	ErrSnapshotMissing  = errors.New("snapshot missing")   // Журнал новее снимка: снимок потерян.
+	ErrWrongHeader      = errors.New("wrong header")       // Заголовок файла имеет неверный формат.
+	ErrChecksumMismatch = errors.New("checksum mismatch")  // Контрольная сумма записи в файле не совпадает.
+	ErrUnknownRecord    = errors.New("unknown record")     // Запись в файле неизвестного типа.
+	ErrUnknownVersion   = errors.New("unknown version")    // Файл записан в неизвестной версии формата.
+	ErrUnknownSync      = errors.New("unknown sync mode")  // Неизвестный режим синхронизации файла с диском.
+	ErrWrongCursor      = errors.New("wrong cursor")       // Курсор страницы имеет неверный формат.
+	ErrWrongOrder       = errors.New("wrong sort order")   // Неизвестный порядок сортировки.
+	ErrUnknownDedup     = errors.New("unknown dedup mode") // Неизвестный режим поиска совпадающих URL.
+	ErrUnknownRole      = errors.New("unknown role")       // Неизвестная роль участника команды.
+	ErrTeamNotFound     = errors.New("team not found")     // Команды нет или пользователь в ней не состоит.
+	ErrForbidden        = errors.New("forbidden")          // Роли пользователя недостаточно для действия.
+	ErrLastOwner        = errors.New("last team owner")    // У команды не останется ни одного владельца.
+	ErrNoFreeID         = errors.New("no free ID")         // Все попытки сгенерировать свободный ID заняты.
+	ErrAccountNotFound  = errors.New("account not found")  // Учетной записи нет.
+	ErrLoginTaken       = errors.New("login taken")        // Логин занят другой учетной записью.
+	ErrUnknownScope     = errors.New("unknown scope")      // Неизвестные права API-ключа.
+	ErrAPIKeyNotFound   = errors.New("API key not found")  // Ключа нет или он принадлежит другой учетной записи.
+)
+
+// Ошибки приглашений в команду.
+var (
+	ErrInviteNotFound = errors.New("invite not found")    // Приглашения нет или оно в другую команду.
+	ErrInviteUsed     = errors.New("invite already used") // Приглашение уже принято.
+	ErrInviteRevoked  = errors.New("invite revoked")      // Приглашение отозвано владельцем команды.
+	ErrInviteExpired  = errors.New("invite expired")      // Срок действия приглашения истек.
+)
+
+// Ошибки квот, хранилище возвращает их, если ссылки не помещаются в LinkOptions.Quota.
+var (
+	ErrQuotaExceeded = errors.New("quota exceeded")                    // Любая квота исчерпана.
+	ErrMaxLinks      = fmt.Errorf("active links %w", ErrQuotaExceeded) // Исчерпана квота активных ссылок.
+	ErrDailyLinks    = fmt.Errorf("daily links %w", ErrQuotaExceeded)  // Исчерпана суточная квота.
 )
@@ -0,0 +1,215 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// CreateAccount - зарегистрировать учетную запись.
+func (st *MemStorage) CreateAccount(_ context.Context, account repositories.Account) error {
+	st.Lock()
+	defer st.Unlock()
+
+	if _, ok := st.AccountLogins[account.Login]; ok {
+		return repositories.ErrLoginTaken
+	}
+
+	st.PutAccount(account)
+
+	return nil
+}
+
+// GetAccount - получить учетную запись пользователя.
+func (st *MemStorage) GetAccount(_ context.Context, id repositories.User) (repositories.Account, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	account, ok := st.Accounts[id]
+	if !ok {
+		return repositories.Account{}, repositories.ErrAccountNotFound
+	}
+
+	return account, nil
+}
+
+// GetAccountByLogin - получить учетную запись по логину.
+func (st *MemStorage) GetAccountByLogin(_ context.Context, login string) (repositories.Account, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	id, ok := st.AccountLogins[login]
+	if !ok {
+		return repositories.Account{}, repositories.ErrAccountNotFound
+	}
+
+	return st.Accounts[id], nil
+}
+
+// CreateAPIKey - сохранить API-ключ учетной записи.
+func (st *MemStorage) CreateAPIKey(_ context.Context, key repositories.APIKey) error {
+	st.Lock()
+	defer st.Unlock()
+
+	if _, ok := st.Accounts[key.Account]; !ok {
+		return repositories.ErrAccountNotFound
+	}
+
+	st.PutAPIKey(key)
+
+	return nil
+}
+
+// GetAPIKey - найти API-ключ по его хешу.
+func (st *MemStorage) GetAPIKey(_ context.Context, hash []byte) (repositories.APIKey, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	id, ok := st.APIKeyHashes[string(hash)]
+	if !ok {
+		return repositories.APIKey{}, repositories.ErrAPIKeyNotFound
+	}
+
+	return st.APIKeys[id], nil
+}
+
+// GetAccountAPIKeys - получить API-ключи учетной записи в порядке создания.
+func (st *MemStorage) GetAccountAPIKeys(_ context.Context, account repositories.User) ([]repositories.APIKey, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	keys := make([]repositories.APIKey, 0)
+	for _, key := range st.APIKeys {
+		if key.Account == account {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID.String() < keys[j].ID.String()
+	})
+
+	return keys, nil
+}
+
+// RevokeAPIKey - адаптер для RevokeAccountAPIKey.
+func (st *MemStorage) RevokeAPIKey(
+	_ context.Context,
+	id repositories.KeyID,
+	account repositories.User,
+	at time.Time,
+) error {
+	_, err := st.RevokeAccountAPIKey(id, account, at)
+	return err
+}
+
+// RevokeAccountAPIKey - отозвать API-ключ учетной записи.
+//
+// Повторный отзыв не меняет время отзыва. Возвращает, был ли ключ отозван этим вызовом.
+func (st *MemStorage) RevokeAccountAPIKey(
+	id repositories.KeyID,
+	account repositories.User,
+	at time.Time,
+) (revoked bool, err error) {
+	st.Lock()
+	defer st.Unlock()
+
+	key, ok := st.APIKeys[id]
+	if !ok || key.Account != account {
+		return false, repositories.ErrAPIKeyNotFound
+	}
+	if key.Revoked() {
+		return false, nil
+	}
+
+	key.RevokedAt = at
+	st.PutAPIKey(key)
+
+	return true, nil
+}
+
+// RevokeSessions - адаптер для RevokeAccountSessions.
+func (st *MemStorage) RevokeSessions(_ context.Context, account repositories.User, at time.Time) error {
+	_, err := st.RevokeAccountSessions(account, at)
+	return err
+}
+
+// RevokeAccountSessions - завершить сессии учетной записи, выданные до at.
+//
+// Время выхода не сдвигается назад. Возвращает, изменил ли его этот вызов.
+func (st *MemStorage) RevokeAccountSessions(account repositories.User, at time.Time) (revoked bool, err error) {
+	st.Lock()
+	defer st.Unlock()
+
+	a, ok := st.Accounts[account]
+	if !ok {
+		return false, repositories.ErrAccountNotFound
+	}
+	if !at.After(a.SessionsRevokedAt) {
+		return false, nil
+	}
+
+	a.SessionsRevokedAt = at
+	st.PutAccount(a)
+
+	return true, nil
+}
+
+// ClaimLinks - адаптер для ClaimUserLinks.
+func (st *MemStorage) ClaimLinks(
+	_ context.Context,
+	from repositories.User,
+	to repositories.User,
+) (claimed []repositories.ID, err error) {
+	return st.ClaimUserLinks(from, to), nil
+}
+
+// ClaimUserLinks - передать личные ссылки пользователя from пользователю to.
+//
+// Ссылки команд остаются в командах. Если ссылка на тот же URL у to уже есть,
+// ссылка остается у from. Возвращает ID переданных ссылок.
+func (st *MemStorage) ClaimUserLinks(from repositories.User, to repositories.User) (claimed []repositories.ID) {
+	st.Lock()
+	defer st.Unlock()
+
+	ids := append([]repositories.ID(nil), st.UserLinks[from]...)
+	claimed = make([]repositories.ID, 0, len(ids))
+	for _, id := range ids {
+		link := st.IDLinkDataDictionary[id]
+		if link.Team != uuid.Nil {
+			continue
+		}
+		if key, ok := st.Dedup.Key(link.URL, to); ok {
+			if existing, exists := st.ExistingURLs[key]; exists && existing != id {
+				continue
+			}
+		}
+
+		st.MoveLink(id, uuid.Nil, to)
+		claimed = append(claimed, id)
+	}
+
+	return claimed
+}
+
+// PutAccount - сохранить учетную запись.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) PutAccount(account repositories.Account) {
+	st.Accounts[account.ID] = account
+	st.AccountLogins[account.Login] = account.ID
+}
+
+// PutAPIKey - сохранить API-ключ.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) PutAPIKey(key repositories.APIKey) {
+	st.APIKeys[key.ID] = key
+	st.APIKeyHashes[string(key.Hash)] = key.ID
+}
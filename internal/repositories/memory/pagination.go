@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// PutLink - сохранить ссылку и добавить ее в индексы.
+//
+// Если совпадающий URL уже занят другой ссылкой, индекс совпадений не меняется:
+// при загрузке снимка ссылка создается с первым из своих URL, который мог перейти к другой ссылке.
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) PutLink(id repositories.ID, link repositories.LinkData) {
+	link.ID = ""
+	st.IDLinkDataDictionary[id] = link
+	if key, ok := st.Dedup.Key(link.URL, link.User); ok {
+		if _, exists := st.ExistingURLs[key]; !exists {
+			st.ExistingURLs[key] = id
+		}
+	}
+
+	c := repositories.Cursor{CreatedAt: link.CreatedAt, ID: id}
+	st.UserLinks[link.User] = st.insertLink(st.UserLinks[link.User], c)
+	if link.Team != uuid.Nil {
+		st.TeamLinks[link.Team] = st.insertLink(st.TeamLinks[link.Team], c)
+	}
+}
+
+// unindexLink - убрать ссылку из индексов ссылок пользователя и команды.
+func (st *MemStorage) unindexLink(id repositories.ID, link repositories.LinkData) {
+	c := repositories.Cursor{CreatedAt: link.CreatedAt, ID: id}
+
+	if ids := st.removeLink(st.UserLinks[link.User], c); len(ids) > 0 {
+		st.UserLinks[link.User] = ids
+	} else {
+		delete(st.UserLinks, link.User)
+	}
+
+	if link.Team == uuid.Nil {
+		return
+	}
+	if ids := st.removeLink(st.TeamLinks[link.Team], c); len(ids) > 0 {
+		st.TeamLinks[link.Team] = ids
+	} else {
+		delete(st.TeamLinks, link.Team)
+	}
+}
+
+// insertLink - вставить ссылку в упорядоченный список ID.
+func (st *MemStorage) insertLink(ids []repositories.ID, c repositories.Cursor) []repositories.ID {
+	i := st.userLinkPosition(ids, c)
+	ids = append(ids, "")
+	copy(ids[i+1:], ids[i:])
+	ids[i] = c.ID
+	return ids
+}
+
+// removeLink - убрать ссылку из упорядоченного списка ID.
+func (st *MemStorage) removeLink(ids []repositories.ID, c repositories.Cursor) []repositories.ID {
+	i := st.userLinkPosition(ids, c)
+	if i >= len(ids) || ids[i] != c.ID {
+		return ids
+	}
+	return append(ids[:i], ids[i+1:]...)
+}
+
+// userLinkPosition - позиция первой ссылки, которая не раньше c.
+func (st *MemStorage) userLinkPosition(ids []repositories.ID, c repositories.Cursor) int {
+	return sort.Search(len(ids), func(i int) bool {
+		return !st.cursor(ids[i]).Less(c)
+	})
+}
+
+func (st *MemStorage) cursor(id repositories.ID) repositories.Cursor {
+	return repositories.Cursor{CreatedAt: st.IDLinkDataDictionary[id].CreatedAt, ID: id}
+}
+
+// ListUserLinks - получить страницу личных ссылок пользователя или ссылок команды q.Team.
+func (st *MemStorage) ListUserLinks(
+	_ context.Context,
+	user repositories.User,
+	q repositories.LinkQuery,
+) (page repositories.LinkPage, err error) {
+	q, err = q.Normalize()
+	if err != nil {
+		return page, err
+	}
+
+	st.RLock()
+	defer st.RUnlock()
+
+	ids := st.UserLinks[user]
+	if q.Team != uuid.Nil {
+		err = st.requireTeamRole(q.Team, user, repositories.RoleViewer)
+		if err != nil {
+			return page, err
+		}
+		ids = st.TeamLinks[q.Team]
+	}
+
+	i, step := 0, 1
+	if q.Order == repositories.SortDesc {
+		i, step = len(ids)-1, -1
+	}
+	if q.Cursor != "" {
+		c, _ := repositories.DecodeCursor(q.Cursor)
+		i = st.userLinkPosition(ids, c)
+		if q.Order == repositories.SortDesc {
+			i--
+		} else if i < len(ids) && ids[i] == c.ID {
+			i++
+		}
+	}
+
+	page.Links = make([]repositories.LinkData, 0)
+	for ; i >= 0 && i < len(ids); i += step {
+		link := st.IDLinkDataDictionary[ids[i]]
+		link.ID = ids[i]
+		if link.Deleted || link.Team != q.Team || !q.Match(link) {
+			continue
+		}
+
+		if len(page.Links) == q.Limit {
+			page.NextCursor = repositories.LinkCursor(page.Links[len(page.Links)-1]).Encode()
+			break
+		}
+		page.Links = append(page.Links, link)
+	}
+
+	return page, nil
+}
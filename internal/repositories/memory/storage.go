@@ -3,17 +3,34 @@ package memory
 
 import (
 	"context"
-	"log"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
-	"github.com/ImpressionableRaccoon/urlshortener/internal/utils"
 )
 
 // MemStorage - структура для хранилища во временной памяти.
 type MemStorage struct {
-	ExistingURLs         map[repositories.URL]repositories.ID
+	ExistingURLs         map[string]repositories.ID // Ключ - результат Dedup.Key.
 	IDLinkDataDictionary map[repositories.ID]repositories.LinkData
+	Clicks               map[repositories.ID][]repositories.Click
+	History              map[repositories.ID][]repositories.URLVersion // Прежние исходные URL, от старых к новым.
+	Deletions            map[repositories.DeletionID]repositories.User
+	UserLinks            map[repositories.User][]repositories.ID // Все ссылки, созданные пользователем.
+	TeamLinks            map[repositories.TeamID][]repositories.ID
+	Teams                map[repositories.TeamID]repositories.Team
+	TeamMembers          map[repositories.TeamID]map[repositories.User]repositories.Role
+	Invites              map[repositories.InviteID]repositories.TeamInvite
+	Accounts             map[repositories.User]repositories.Account
+	AccountLogins        map[string]repositories.User
+	APIKeys              map[repositories.KeyID]repositories.APIKey
+	APIKeyHashes         map[string]repositories.KeyID
+	Dedup                repositories.DedupMode // Режим поиска уже сокращенных URL.
+	IDs                  idgen.IDGenerator      // Стратегия генерации ID, по умолчанию - idgen.Default.
 	sync.RWMutex
 }
 
@@ -21,7 +38,19 @@ type MemStorage struct {
 func NewMemoryStorage() (*MemStorage, error) {
 	st := &MemStorage{
 		IDLinkDataDictionary: make(map[repositories.ID]repositories.LinkData),
-		ExistingURLs:         make(map[repositories.URL]repositories.ID),
+		ExistingURLs:         make(map[string]repositories.ID),
+		Clicks:               make(map[repositories.ID][]repositories.Click),
+		History:              make(map[repositories.ID][]repositories.URLVersion),
+		Deletions:            make(map[repositories.DeletionID]repositories.User),
+		UserLinks:            make(map[repositories.User][]repositories.ID),
+		TeamLinks:            make(map[repositories.TeamID][]repositories.ID),
+		Teams:                make(map[repositories.TeamID]repositories.Team),
+		TeamMembers:          make(map[repositories.TeamID]map[repositories.User]repositories.Role),
+		Invites:              make(map[repositories.InviteID]repositories.TeamInvite),
+		Accounts:             make(map[repositories.User]repositories.Account),
+		AccountLogins:        make(map[string]repositories.User),
+		APIKeys:              make(map[repositories.KeyID]repositories.APIKey),
+		APIKeyHashes:         make(map[string]repositories.KeyID),
 	}
 
 	return st, nil
@@ -32,51 +61,179 @@ func (st *MemStorage) Add(
 	_ context.Context,
 	url repositories.URL,
 	user repositories.User,
+	opts repositories.LinkOptions,
 ) (id repositories.ID, err error) {
-	return st.AddLink(url, user)
+	return st.AddLink(url, user, opts)
 }
 
 // AddLink - сократить ссылку.
-func (st *MemStorage) AddLink(url repositories.URL, user repositories.User) (id repositories.ID, err error) {
+//
+// Квоты opts.Quota проверяются под той же блокировкой на запись, что и создание ссылки,
+// поэтому одновременные запросы пользователя не могут их превысить.
+func (st *MemStorage) AddLink(
+	url repositories.URL,
+	user repositories.User,
+	opts repositories.LinkOptions,
+) (id repositories.ID, err error) {
+	st.Lock()
+	defer st.Unlock()
+
+	err = opts.Quota.Check(st.countUserLinks(user, opts.Quota.Since), 1)
+	if err != nil {
+		return "", err
+	}
+
+	return st.addLink(url, user, opts)
+}
+
+// AddBatch - адаптер для AddLinks.
+func (st *MemStorage) AddBatch(
+	_ context.Context,
+	links []repositories.BatchLink,
+	user repositories.User,
+) (results []repositories.BatchResult, err error) {
+	return st.AddLinks(links, user)
+}
+
+// AddLinks - сократить пачку ссылок под одной блокировкой.
+//
+// Ошибка в отдельной ссылке не прерывает пачку, результаты возвращаются в том же порядке.
+// Если квот пользователя не хватает на всю пачку, не создается ни одна ссылка.
+func (st *MemStorage) AddLinks(
+	links []repositories.BatchLink,
+	user repositories.User,
+) (results []repositories.BatchResult, err error) {
 	st.Lock()
 	defer st.Unlock()
 
-	value, ok := st.ExistingURLs[url]
-	if ok {
-		return value, repositories.ErrURLAlreadyExists
+	q := repositories.BatchQuota(links)
+	err = q.Check(st.countUserLinks(user, q.Since), len(links))
+	if err != nil {
+		return nil, err
 	}
 
-	for exists := true; exists; _, exists = st.IDLinkDataDictionary[id] {
-		id, err = utils.GenRandomID()
+	results = make([]repositories.BatchResult, len(links))
+	for i, link := range links {
+		results[i].ID, results[i].Err = st.addLink(link.URL, user, link.Opts)
+	}
+
+	return results, nil
+}
+
+// addLink - сократить ссылку.
+//
+// Создать ссылку в команде opts.Team может только ее редактор или владелец.
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) addLink(
+	url repositories.URL,
+	user repositories.User,
+	opts repositories.LinkOptions,
+) (id repositories.ID, err error) {
+	if opts.Team != uuid.Nil {
+		err = st.requireTeamRole(opts.Team, user, repositories.RoleEditor)
 		if err != nil {
-			log.Printf("generate id failed: %v", err)
 			return "", err
 		}
 	}
 
-	st.IDLinkDataDictionary[id] = repositories.LinkData{
-		URL:  url,
-		User: user,
+	if opts.Alias != "" {
+		if _, exists := st.IDLinkDataDictionary[opts.Alias]; exists {
+			return "", repositories.ErrIDAlreadyExists
+		}
+	}
+
+	if key, ok := st.Dedup.Key(url, user); ok {
+		if value, exists := st.ExistingURLs[key]; exists {
+			return value, repositories.ErrURLAlreadyExists
+		}
 	}
-	st.ExistingURLs[url] = id
+
+	if opts.Alias != "" {
+		id = opts.Alias
+	} else {
+		id, err = st.newID(url)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	st.PutLink(id, repositories.LinkData{
+		URL:       url,
+		User:      user,
+		ExpiresAt: opts.ExpiresAt,
+		MaxHits:   opts.MaxHits,
+		Team:      opts.Team,
+		CreatedAt: time.Now().Round(0),
+	})
 
 	return id, nil
 }
 
+// newID - сгенерировать свободный ID для ссылки на url.
+//
+// Вызывающий должен держать блокировку.
+func (st *MemStorage) newID(url repositories.URL) (id repositories.ID, err error) {
+	gen := st.IDs
+	if gen == nil {
+		gen = idgen.Default
+	}
+
+	for attempt := 0; attempt < idgen.MaxAttempts; attempt++ {
+		id, err = gen.Generate(url, attempt)
+		if err != nil {
+			return "", err
+		}
+		if _, exists := st.IDLinkDataDictionary[id]; !exists {
+			return id, nil
+		}
+	}
+
+	return "", repositories.ErrNoFreeID
+}
+
 // Get - получить оригинальную ссылку по ID.
 func (st *MemStorage) Get(_ context.Context, id repositories.ID) (url repositories.URL, deleted bool, err error) {
-	st.RLock()
-	defer st.RUnlock()
+	data, err := st.Hit(id)
+	if err != nil {
+		return "", false, err
+	}
+
+	return data.URL, data.Deleted, nil
+}
+
+// Hit - получить ссылку по ID и засчитать переход по ней.
+//
+// Переходы считаются только для ссылок с ограничением MaxHits.
+// Если срок действия ссылки истек, вернет repositories.ErrLinkExpired.
+func (st *MemStorage) Hit(id repositories.ID) (data repositories.LinkData, err error) {
+	st.Lock()
+	defer st.Unlock()
 
 	data, ok := st.IDLinkDataDictionary[id]
-	if ok {
-		return data.URL, data.Deleted, nil
+	if !ok {
+		return repositories.LinkData{}, repositories.ErrURLNotFound
+	}
+	data.ID = id
+
+	if data.Deleted {
+		return data, nil
 	}
 
-	return "", false, repositories.ErrURLNotFound
+	if data.Expired(time.Now()) {
+		return data, repositories.ErrLinkExpired
+	}
+
+	if data.MaxHits > 0 {
+		data.Hits++
+		link := st.IDLinkDataDictionary[id]
+		link.Hits = data.Hits
+		st.IDLinkDataDictionary[id] = link
+	}
+
+	return data, nil
 }
 
-// GetUserLinks - получить все ссылки пользователя.
+// GetUserLinks - получить все ссылки пользователя: личные и ссылки команд, в которых он состоит.
 func (st *MemStorage) GetUserLinks(
 	_ context.Context,
 	user repositories.User,
@@ -86,54 +243,291 @@ func (st *MemStorage) GetUserLinks(
 
 	data = make([]repositories.LinkData, 0)
 
-	for id, value := range st.IDLinkDataDictionary {
-		if value.User != user {
-			continue
+	appendLinks := func(ids []repositories.ID, team repositories.TeamID) {
+		for _, id := range ids {
+			value := st.IDLinkDataDictionary[id]
+			if value.Deleted || value.Team != team {
+				continue
+			}
+
+			data = append(data, repositories.LinkData{
+				ID:        id,
+				URL:       value.URL,
+				User:      value.User,
+				Team:      value.Team,
+				Deleted:   false,
+				ExpiresAt: value.ExpiresAt,
+				MaxHits:   value.MaxHits,
+				Hits:      value.Hits,
+			})
 		}
+	}
+
+	appendLinks(st.UserLinks[user], uuid.Nil)
+	for _, m := range st.userTeams(user) {
+		appendLinks(st.TeamLinks[m.ID], m.ID)
+	}
+
+	return data, nil
+}
+
+// CountUserLinks - посчитать активные ссылки пользователя и ссылки, созданные начиная с since.
+func (st *MemStorage) CountUserLinks(
+	_ context.Context,
+	user repositories.User,
+	since time.Time,
+) (counts repositories.LinkCounts, err error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	return st.countUserLinks(user, since), nil
+}
+
+// countUserLinks - посчитать активные ссылки пользователя и ссылки, созданные начиная с since.
+//
+// Вызывающий должен держать блокировку.
+func (st *MemStorage) countUserLinks(user repositories.User, since time.Time) (counts repositories.LinkCounts) {
+	now := time.Now()
+	for _, id := range st.UserLinks[user] {
+		value := st.IDLinkDataDictionary[id]
+		if !value.Deleted && !value.Expired(now) {
+			counts.Active++
+		}
+		if !value.CreatedAt.Before(since) {
+			counts.CreatedSince++
+		}
+	}
+
+	return counts
+}
+
+// GetLink - получить данные ссылки по ID, переход при этом не учитывается.
+func (st *MemStorage) GetLink(_ context.Context, id repositories.ID) (link repositories.LinkData, err error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok {
+		return repositories.LinkData{}, repositories.ErrURLNotFound
+	}
+	link.ID = id
 
-		if value.Deleted {
+	return link, nil
+}
+
+// Iterate - обойти все ссылки в порядке возрастания ID, начиная со следующей после after.
+//
+// Ссылки копируются под блокировкой, поэтому fn может обращаться к хранилищу.
+func (st *MemStorage) Iterate(
+	ctx context.Context,
+	after repositories.ID,
+	fn func(link repositories.LinkData) error,
+) error {
+	st.RLock()
+	links := make([]repositories.LinkData, 0, len(st.IDLinkDataDictionary))
+	for id, link := range st.IDLinkDataDictionary {
+		if id <= after {
 			continue
 		}
+		link.ID = id
+		links = append(links, link)
+	}
+	st.RUnlock()
+
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
 
-		data = append(data, repositories.LinkData{
-			ID:      id,
-			URL:     value.URL,
-			User:    user,
-			Deleted: false,
-		})
+	for _, link := range links {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(link); err != nil {
+			return err
+		}
 	}
 
-	return data, nil
+	return nil
+}
+
+// Import - адаптер для ImportLink.
+func (st *MemStorage) Import(_ context.Context, link repositories.LinkData) error {
+	return st.ImportLink(link)
+}
+
+// ImportLink - сохранить ссылку со всеми данными как есть, включая ID и владельца.
+func (st *MemStorage) ImportLink(link repositories.LinkData) error {
+	st.Lock()
+	defer st.Unlock()
+
+	if _, exists := st.IDLinkDataDictionary[link.ID]; exists {
+		return repositories.ErrIDAlreadyExists
+	}
+	if key, ok := st.Dedup.Key(link.URL, link.User); ok {
+		if _, exists := st.ExistingURLs[key]; exists {
+			return repositories.ErrURLAlreadyExists
+		}
+	}
+
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now().Round(0)
+	}
+	if link.Deleted && link.DeletedAt.IsZero() {
+		link.DeletedAt = time.Now().Round(0)
+	}
+	st.PutLink(link.ID, link)
+
+	return nil
 }
 
 // DeleteUserLinks - удалить ссылки пользователя.
-func (st *MemStorage) DeleteUserLinks(_ context.Context, ids []repositories.ID, user repositories.User) error {
+//
+// Удаление выполняется сразу, поэтому запрос всегда находится в состоянии repositories.DeletionDone.
+func (st *MemStorage) DeleteUserLinks(
+	_ context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+) (deletion repositories.DeletionID, err error) {
+	now := time.Now().Round(0)
 	for _, id := range ids {
-		_ = st.DeleteUserLink(id, user)
+		_ = st.DeleteUserLink(id, user, now)
 	}
 
-	return nil
+	return st.NewDeletion(user), nil
 }
 
-// DeleteUserLink - удалить ссылку пользователя.
-func (st *MemStorage) DeleteUserLink(id repositories.ID, user repositories.User) (ok bool) {
+// NewDeletion - зарегистрировать выполненный запрос на удаление и вернуть его ID.
+func (st *MemStorage) NewDeletion(user repositories.User) repositories.DeletionID {
+	st.Lock()
+	defer st.Unlock()
+
+	deletion := uuid.New()
+	st.Deletions[deletion] = user
+
+	return deletion
+}
+
+// GetDeletionStatus - получить состояние запроса на удаление ссылок.
+func (st *MemStorage) GetDeletionStatus(
+	_ context.Context,
+	deletion repositories.DeletionID,
+	user repositories.User,
+) (repositories.DeletionStatus, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	owner, ok := st.Deletions[deletion]
+	if !ok || owner != user {
+		return "", repositories.ErrDeletionNotFound
+	}
+
+	return repositories.DeletionDone, nil
+}
+
+// DeleteUserLink - удалить ссылку пользователя в момент now.
+//
+// Ссылку команды может удалить ее редактор или владелец.
+// Уже удаленная ссылка не меняется, и ok будет false.
+func (st *MemStorage) DeleteUserLink(id repositories.ID, user repositories.User, now time.Time) (ok bool) {
 	st.Lock()
 	defer st.Unlock()
 
 	link, ok := st.IDLinkDataDictionary[id]
-	if !ok {
+	if !ok || link.Deleted {
 		return false
 	}
-	if link.User != user {
+	if !st.linkRole(link, user).Allows(repositories.RoleEditor) {
 		return false
 	}
 
 	link.Deleted = true
+	link.DeletedAt = now
 	st.IDLinkDataDictionary[id] = link
 
 	return true
 }
 
+// PurgeExpired - удалить ссылки с истекшим сроком действия.
+func (st *MemStorage) PurgeExpired(_ context.Context) (count int64, err error) {
+	return int64(len(st.PurgeExpiredLinks(time.Now()))), nil
+}
+
+// PurgeExpiredLinks - удалить ссылки, срок действия которых истек на момент now, и вернуть их ID.
+func (st *MemStorage) PurgeExpiredLinks(now time.Time) (ids []repositories.ID) {
+	st.Lock()
+	defer st.Unlock()
+
+	for id, link := range st.IDLinkDataDictionary {
+		if !link.Expired(now) {
+			continue
+		}
+
+		st.RemoveLink(id)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// RemoveLink - безвозвратно удалить ссылку из хранилища.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) RemoveLink(id repositories.ID) {
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok {
+		return
+	}
+
+	st.unindexLink(id, link)
+	delete(st.IDLinkDataDictionary, id)
+	delete(st.Clicks, id)
+	delete(st.History, id)
+	if key, ok := st.Dedup.Key(link.URL, link.User); ok && st.ExistingURLs[key] == id {
+		delete(st.ExistingURLs, key)
+	}
+}
+
+// AddClicks - сохранить переходы по ссылкам.
+func (st *MemStorage) AddClicks(_ context.Context, clicks []repositories.Click) error {
+	st.AddClickList(clicks)
+	return nil
+}
+
+// AddClickList - сохранить переходы по ссылкам и вернуть те из них, которые относятся к существующим ссылкам.
+func (st *MemStorage) AddClickList(clicks []repositories.Click) (added []repositories.Click) {
+	st.Lock()
+	defer st.Unlock()
+
+	added = make([]repositories.Click, 0, len(clicks))
+	for _, click := range clicks {
+		if _, ok := st.IDLinkDataDictionary[click.ID]; !ok {
+			continue
+		}
+		st.Clicks[click.ID] = append(st.Clicks[click.ID], click)
+		added = append(added, click)
+	}
+
+	return added
+}
+
+// GetLinkStats - получить статистику переходов по ссылке пользователя или его команды.
+func (st *MemStorage) GetLinkStats(
+	_ context.Context,
+	id repositories.ID,
+	user repositories.User,
+) (stats repositories.LinkStats, err error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok {
+		return stats, repositories.ErrURLNotFound
+	}
+	if !st.linkRole(link, user).Allows(repositories.RoleViewer) {
+		return stats, repositories.ErrUserNotMatch
+	}
+
+	return repositories.NewLinkStats(st.Clicks[id]), nil
+}
+
 // GetStats - получить статистику сервиса.
 func (st *MemStorage) GetStats(_ context.Context) (repositories.ServiceStats, error) {
 	st.RLock()
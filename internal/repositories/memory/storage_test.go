@@ -2,12 +2,17 @@ package memory
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 )
 
@@ -38,7 +43,7 @@ func TestMemoryStorage(t *testing.T) {
 
 	t.Run("short links", func(t *testing.T) {
 		for index, link := range links {
-			id, err := st.Add(context.Background(), link.URL, testUser)
+			id, err := st.Add(context.Background(), link.URL, testUser, repositories.LinkOptions{})
 			require.NoError(t, err)
 			link.ID = id
 			links[index] = link
@@ -70,8 +75,15 @@ func TestMemoryStorage(t *testing.T) {
 				continue
 			}
 		}
-		err := st.DeleteUserLinks(context.Background(), linksIDs, testUser)
+		deletion, err := st.DeleteUserLinks(context.Background(), linksIDs, testUser)
 		require.NoError(t, err)
+
+		status, err := st.GetDeletionStatus(context.Background(), deletion, testUser)
+		require.NoError(t, err)
+		assert.Equal(t, repositories.DeletionDone, status)
+
+		_, err = st.GetDeletionStatus(context.Background(), deletion, uuid.New())
+		assert.ErrorIs(t, err, repositories.ErrDeletionNotFound)
 	})
 
 	t.Run("check if only needed URL deleted", func(t *testing.T) {
@@ -83,3 +95,632 @@ func TestMemoryStorage(t *testing.T) {
 		}
 	})
 }
+
+// TestMemoryStorage_Expiration - тестируем ограничение срока действия ссылок в MemStorage.
+func TestMemoryStorage_Expiration(t *testing.T) {
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+
+	testUser := uuid.New()
+	ctx := context.Background()
+
+	t.Run("max hits", func(t *testing.T) {
+		id, err := st.Add(ctx, "https://example.com/hits", testUser, repositories.LinkOptions{MaxHits: 2})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, _, err = st.Get(ctx, id)
+			require.NoError(t, err)
+		}
+
+		_, _, err = st.Get(ctx, id)
+		assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+	})
+
+	t.Run("expires at", func(t *testing.T) {
+		id, err := st.Add(ctx, "https://example.com/time", testUser, repositories.LinkOptions{
+			ExpiresAt: time.Now().Add(-time.Second),
+		})
+		require.NoError(t, err)
+
+		_, _, err = st.Get(ctx, id)
+		assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+	})
+
+	t.Run("purge expired", func(t *testing.T) {
+		id, err := st.Add(ctx, "https://example.com/alive", testUser, repositories.LinkOptions{
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		count, err := st.PurgeExpired(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+
+		_, _, err = st.Get(ctx, id)
+		assert.NoError(t, err)
+
+		_, err = st.Add(ctx, "https://example.com/hits", testUser, repositories.LinkOptions{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestMemoryStorage_CountUserLinks(t *testing.T) {
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+
+	testUser := uuid.New()
+	ctx := context.Background()
+
+	_, err = st.Add(ctx, "https://example.com/1", testUser, repositories.LinkOptions{})
+	require.NoError(t, err)
+	_, err = st.Add(ctx, "https://example.com/2", testUser, repositories.LinkOptions{
+		ExpiresAt: time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+	id, err := st.Add(ctx, "https://example.com/3", testUser, repositories.LinkOptions{})
+	require.NoError(t, err)
+	require.True(t, st.DeleteUserLink(id, testUser, time.Now()))
+	_, err = st.Add(ctx, "https://example.com/4", uuid.New(), repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	counts, err := st.CountUserLinks(ctx, testUser, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, repositories.LinkCounts{Active: 1, CreatedSince: 3}, counts)
+
+	counts, err = st.CountUserLinks(ctx, testUser, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, repositories.LinkCounts{Active: 1}, counts)
+}
+
+// TestMemoryStorage_Alias - тестируем пользовательские ID ссылок в MemStorage.
+func TestMemoryStorage_Alias(t *testing.T) {
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+
+	testUser := uuid.New()
+	ctx := context.Background()
+
+	id, err := st.Add(ctx, "https://example.com/spring", testUser, repositories.LinkOptions{Alias: "spring-sale"})
+	require.NoError(t, err)
+	assert.Equal(t, "spring-sale", id)
+
+	_, err = st.Add(ctx, "https://example.com/summer", testUser, repositories.LinkOptions{Alias: "spring-sale"})
+	assert.ErrorIs(t, err, repositories.ErrIDAlreadyExists)
+
+	url, _, err := st.Get(ctx, "spring-sale")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/spring", url)
+}
+
+// TestMemoryStorage_LinkStats - тестируем статистику переходов в MemStorage.
+func TestMemoryStorage_LinkStats(t *testing.T) {
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+
+	testUser := uuid.New()
+	ctx := context.Background()
+
+	id, err := st.Add(ctx, "https://example.com/stats", testUser, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	day := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	err = st.AddClicks(ctx, []repositories.Click{
+		{ID: id, Time: day, IP: "10.0.0.1"},
+		{ID: id, Time: day.Add(time.Hour), IP: "10.0.0.2"},
+		{ID: id, Time: day.Add(24 * time.Hour), IP: "10.0.0.1"},
+		{ID: "unknown", Time: day, IP: "10.0.0.3"},
+	})
+	require.NoError(t, err)
+
+	stats, err := st.GetLinkStats(ctx, id, testUser)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), stats.Clicks)
+	assert.Equal(t, uint64(2), stats.UniqueVisitors)
+	assert.Equal(t, []repositories.DayClicks{
+		{Day: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), Clicks: 2},
+		{Day: time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC), Clicks: 1},
+	}, stats.Days)
+
+	_, err = st.GetLinkStats(ctx, id, uuid.New())
+	assert.ErrorIs(t, err, repositories.ErrUserNotMatch)
+
+	_, err = st.GetLinkStats(ctx, "unknown", testUser)
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+}
+
+// TestMemoryStorage_Iterate - тестируем обход, чтение и импорт ссылок.
+func TestMemoryStorage_Iterate(t *testing.T) {
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	user := uuid.New()
+
+	for _, link := range []repositories.LinkData{
+		{ID: "c", URL: "https://example.com/c", User: user, Deleted: true},
+		{ID: "a", URL: "https://example.com/a", User: user, MaxHits: 3, Hits: 1, CreatedAt: time.Unix(1700000000, 0)},
+		{ID: "b", URL: "https://example.com/b", User: user},
+	} {
+		require.NoError(t, st.Import(ctx, link))
+	}
+
+	err = st.Import(ctx, repositories.LinkData{ID: "a", URL: "https://example.com/other", User: user})
+	assert.ErrorIs(t, err, repositories.ErrIDAlreadyExists)
+	err = st.Import(ctx, repositories.LinkData{ID: "d", URL: "https://example.com/a", User: user})
+	assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+
+	link, err := st.GetLink(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, repositories.LinkData{
+		ID: "a", URL: "https://example.com/a", User: user, MaxHits: 3, Hits: 1, CreatedAt: time.Unix(1700000000, 0),
+	}, link)
+
+	_, err = st.GetLink(ctx, "x")
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+
+	var ids []repositories.ID
+	err = st.Iterate(ctx, "a", func(link repositories.LinkData) error {
+		ids = append(ids, link.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.ID{"b", "c"}, ids)
+}
+
+// TestMemoryStorage_ListUserLinks - тестируем постраничную выборку ссылок пользователя.
+func TestMemoryStorage_ListUserLinks(t *testing.T) {
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	user := uuid.New()
+	createdAt := time.Unix(1700000000, 0)
+
+	for _, link := range []repositories.LinkData{
+		{ID: "d", URL: "https://example.com/d", User: user, CreatedAt: createdAt.Add(time.Second)},
+		{ID: "b", URL: "https://example.com/b", User: user, CreatedAt: createdAt},
+		{ID: "a", URL: "https://example.com/a", User: user, CreatedAt: createdAt},
+		{ID: "c", URL: "https://go.dev/c", User: user, CreatedAt: createdAt},
+		{ID: "e", URL: "https://example.com/e", User: user, CreatedAt: createdAt, Deleted: true},
+		{ID: "f", URL: "https://example.com/f", User: uuid.New(), CreatedAt: createdAt},
+	} {
+		require.NoError(t, st.Import(ctx, link))
+	}
+
+	list := func(q repositories.LinkQuery) (ids []repositories.ID) {
+		for {
+			page, err := st.ListUserLinks(ctx, user, q)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(page.Links), q.Limit)
+			for _, link := range page.Links {
+				ids = append(ids, link.ID)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			q.Cursor = page.NextCursor
+		}
+	}
+
+	assert.Equal(t, []repositories.ID{"a", "b", "c", "d"}, list(repositories.LinkQuery{Order: repositories.SortAsc, Limit: 1}))
+	assert.Equal(t, []repositories.ID{"d", "c", "b", "a"}, list(repositories.LinkQuery{Limit: 3}))
+	assert.Equal(t, []repositories.ID{"d", "b", "a"}, list(repositories.LinkQuery{Filter: "example", Limit: 2}))
+
+	_, err = st.DeleteUserLinks(ctx, []repositories.ID{"b"}, user)
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.ID{"a", "c", "d"}, list(repositories.LinkQuery{Order: repositories.SortAsc, Limit: 2}))
+
+	_, err = st.ListUserLinks(ctx, user, repositories.LinkQuery{Order: "up"})
+	assert.ErrorIs(t, err, repositories.ErrWrongOrder)
+	_, err = st.ListUserLinks(ctx, user, repositories.LinkQuery{Cursor: "!"})
+	assert.ErrorIs(t, err, repositories.ErrWrongCursor)
+}
+
+// TestMemoryStorage_Dedup - тестируем режимы поиска уже сокращенных URL.
+func TestMemoryStorage_Dedup(t *testing.T) {
+	const url = "https://example.com/dedup"
+	ctx := context.Background()
+
+	tests := []struct {
+		dedup       repositories.DedupMode
+		sameUser    bool // Вернется ли существующая ссылка тому же пользователю.
+		anotherUser bool // Вернется ли существующая ссылка другому пользователю.
+	}{
+		{dedup: repositories.DedupGlobal, sameUser: true, anotherUser: true},
+		{dedup: repositories.DedupPerUser, sameUser: true, anotherUser: false},
+		{dedup: repositories.DedupNone, sameUser: false, anotherUser: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dedup), func(t *testing.T) {
+			st, err := NewMemoryStorage()
+			require.NoError(t, err)
+			st.Dedup = tt.dedup
+
+			userA, userB := uuid.New(), uuid.New()
+
+			id, err := st.Add(ctx, url, userA, repositories.LinkOptions{})
+			require.NoError(t, err)
+
+			sameID, err := st.Add(ctx, url, userA, repositories.LinkOptions{})
+			if tt.sameUser {
+				assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+				assert.Equal(t, id, sameID)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, id, sameID)
+			}
+
+			anotherID, err := st.Add(ctx, url, userB, repositories.LinkOptions{})
+			if tt.anotherUser {
+				assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+				assert.Equal(t, id, anotherID)
+				return
+			}
+			require.NoError(t, err)
+
+			links, err := st.GetUserLinks(ctx, userB)
+			require.NoError(t, err)
+			require.Len(t, links, 1)
+			assert.Equal(t, anotherID, links[0].ID)
+
+			_, err = st.DeleteUserLinks(ctx, []repositories.ID{anotherID}, userB)
+			require.NoError(t, err)
+			_, deleted, err := st.Get(ctx, anotherID)
+			require.NoError(t, err)
+			assert.True(t, deleted)
+		})
+	}
+
+	_, err := repositories.ParseDedupMode("sometimes")
+	assert.ErrorIs(t, err, repositories.ErrUnknownDedup)
+}
+
+func TestMemoryStorage_Teams(t *testing.T) {
+	ctx := context.Background()
+
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+
+	owner, editor, viewer, stranger := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	team := repositories.Team{ID: uuid.New(), Name: "team", CreatedAt: time.Now()}
+
+	require.NoError(t, st.CreateTeam(ctx, team, owner))
+	require.NoError(t, st.SetTeamMember(ctx, team.ID, editor, repositories.RoleEditor))
+	require.NoError(t, st.SetTeamMember(ctx, team.ID, viewer, repositories.RoleViewer))
+
+	_, err = st.Add(ctx, "https://example.com/viewer", viewer, repositories.LinkOptions{Team: team.ID})
+	assert.ErrorIs(t, err, repositories.ErrForbidden)
+	_, err = st.Add(ctx, "https://example.com/stranger", stranger, repositories.LinkOptions{Team: team.ID})
+	assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+
+	teamID, err := st.Add(ctx, "https://example.com/team", editor, repositories.LinkOptions{Team: team.ID})
+	require.NoError(t, err)
+	personalID, err := st.Add(ctx, "https://example.com/personal", owner, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	links, err := st.GetUserLinks(ctx, viewer)
+	require.NoError(t, err)
+	require.Len(t, links, 1, "viewer sees team links")
+	assert.Equal(t, teamID, links[0].ID)
+
+	page, err := st.ListUserLinks(ctx, owner, repositories.LinkQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1, "personal page has no team links")
+	assert.Equal(t, personalID, page.Links[0].ID)
+
+	page, err = st.ListUserLinks(ctx, owner, repositories.LinkQuery{Team: team.ID})
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, teamID, page.Links[0].ID)
+
+	_, err = st.ListUserLinks(ctx, stranger, repositories.LinkQuery{Team: team.ID})
+	assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+
+	_, err = st.GetLinkStats(ctx, teamID, viewer)
+	assert.NoError(t, err)
+	_, err = st.GetLinkStats(ctx, teamID, stranger)
+	assert.ErrorIs(t, err, repositories.ErrUserNotMatch)
+
+	_, err = st.DeleteUserLinks(ctx, []repositories.ID{teamID}, viewer)
+	require.NoError(t, err)
+	_, deleted, err := st.Get(ctx, teamID)
+	require.NoError(t, err)
+	assert.False(t, deleted, "viewer can not delete team links")
+
+	err = st.TransferLinks(ctx, []repositories.ID{teamID}, editor, uuid.Nil)
+	assert.ErrorIs(t, err, repositories.ErrForbidden, "only owners move team links out")
+	err = st.TransferLinks(ctx, []repositories.ID{personalID, teamID}, owner, uuid.Nil)
+	require.NoError(t, err)
+
+	links, err = st.GetUserLinks(ctx, viewer)
+	require.NoError(t, err)
+	assert.Empty(t, links)
+
+	err = st.TransferLinks(ctx, []repositories.ID{personalID}, owner, team.ID)
+	require.NoError(t, err)
+	link, err := st.GetLink(ctx, personalID)
+	require.NoError(t, err)
+	assert.Equal(t, team.ID, link.Team)
+
+	assert.ErrorIs(t, st.RemoveTeamMember(ctx, team.ID, owner), repositories.ErrLastOwner)
+	assert.ErrorIs(t, st.SetTeamMember(ctx, team.ID, owner, repositories.RoleViewer), repositories.ErrLastOwner)
+	require.NoError(t, st.RemoveTeamMember(ctx, team.ID, viewer))
+
+	members, err := st.GetTeamMembers(ctx, team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.TeamMember{
+		{Role: repositories.RoleOwner, User: owner},
+		{Role: repositories.RoleEditor, User: editor},
+	}, members)
+
+	teams, err := st.GetUserTeams(ctx, editor)
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.Membership{{Team: team, Role: repositories.RoleEditor}}, teams)
+
+	now := time.Now()
+	invite := repositories.TeamInvite{
+		ID: uuid.New(), Team: team.ID, Role: repositories.RoleViewer, CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+	}
+	require.NoError(t, st.CreateInvite(ctx, invite))
+	err = st.CreateInvite(ctx, repositories.TeamInvite{ID: uuid.New(), Team: uuid.New()})
+	assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+
+	accepted, err := st.AcceptInvite(ctx, invite.ID, editor, now)
+	require.NoError(t, err)
+	assert.Equal(t, editor, accepted.UsedBy)
+	role, err := st.GetTeamRole(ctx, team.ID, editor)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.RoleEditor, role, "invite does not downgrade members")
+	_, err = st.AcceptInvite(ctx, invite.ID, viewer, now)
+	assert.ErrorIs(t, err, repositories.ErrInviteUsed)
+	assert.ErrorIs(t, st.RevokeInvite(ctx, invite.ID, team.ID, now), repositories.ErrInviteUsed)
+
+	invite.ID = uuid.New()
+	require.NoError(t, st.CreateInvite(ctx, invite))
+	assert.ErrorIs(t, st.RevokeInvite(ctx, invite.ID, uuid.New(), now), repositories.ErrInviteNotFound)
+	require.NoError(t, st.RevokeInvite(ctx, invite.ID, team.ID, now))
+	_, err = st.AcceptInvite(ctx, invite.ID, viewer, now)
+	assert.ErrorIs(t, err, repositories.ErrInviteRevoked)
+	_, err = st.AcceptInvite(ctx, uuid.New(), viewer, now)
+	assert.ErrorIs(t, err, repositories.ErrInviteNotFound)
+}
+
+func TestMemoryStorage_Update(t *testing.T) {
+	ctx := context.Background()
+
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+	st.Dedup = repositories.DedupGlobal
+
+	owner, stranger := uuid.New(), uuid.New()
+
+	id, err := st.Add(ctx, "https://example.com/old", owner, repositories.LinkOptions{})
+	require.NoError(t, err)
+	otherID, err := st.Add(ctx, "https://example.com/other", owner, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	_, err = st.Update(ctx, id, "https://example.com/new", stranger)
+	assert.ErrorIs(t, err, repositories.ErrUserNotMatch)
+	_, err = st.Update(ctx, "missing", "https://example.com/new", owner)
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	_, err = st.Update(ctx, id, "https://example.com/other", owner)
+	assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+
+	link, err := st.Update(ctx, id, "https://example.com/new", owner)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", link.URL)
+
+	_, err = st.Update(ctx, id, "https://example.com/new", owner)
+	require.NoError(t, err, "same URL is not a new version")
+
+	url, _, err := st.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", url)
+
+	versions, err := st.GetLinkHistory(ctx, id, owner)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, "https://example.com/old", versions[0].URL)
+	assert.Equal(t, owner, versions[0].User)
+
+	_, err = st.GetLinkHistory(ctx, id, stranger)
+	assert.ErrorIs(t, err, repositories.ErrUserNotMatch)
+
+	oldID, err := st.Add(ctx, "https://example.com/old", owner, repositories.LinkOptions{})
+	require.NoError(t, err, "old URL is free again")
+	assert.NotEqual(t, id, oldID)
+
+	newID, err := st.Add(ctx, "https://example.com/new", owner, repositories.LinkOptions{})
+	assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+	assert.Equal(t, id, newID)
+
+	_, err = st.DeleteUserLinks(ctx, []repositories.ID{otherID}, owner)
+	require.NoError(t, err)
+	_, err = st.Update(ctx, otherID, "https://example.com/deleted", owner)
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+}
+
+func TestMemoryStorage_Trash(t *testing.T) {
+	ctx := context.Background()
+
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+	st.Dedup = repositories.DedupGlobal
+
+	owner, stranger := uuid.New(), uuid.New()
+
+	id, err := st.Add(ctx, "https://example.com/trash", owner, repositories.LinkOptions{})
+	require.NoError(t, err)
+	oldID, err := st.Add(ctx, "https://example.com/old", owner, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	_, err = st.DeleteUserLinks(ctx, []repositories.ID{id, oldID}, owner)
+	require.NoError(t, err)
+
+	link := st.IDLinkDataDictionary[oldID]
+	link.DeletedAt = time.Now().Add(-48 * time.Hour)
+	st.IDLinkDataDictionary[oldID] = link
+
+	links, err := st.GetDeletedUserLinks(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, id, links[0].ID, "recently deleted go first")
+	assert.False(t, links[0].DeletedAt.IsZero())
+
+	links, err = st.GetDeletedUserLinks(ctx, stranger)
+	require.NoError(t, err)
+	assert.Empty(t, links)
+
+	since := time.Now().Add(-24 * time.Hour)
+	restored, err := st.RestoreUserLinks(ctx, []repositories.ID{id, oldID}, stranger, since)
+	require.NoError(t, err)
+	assert.Empty(t, restored)
+	restored, err = st.RestoreUserLinks(ctx, []repositories.ID{id, oldID, "missing"}, owner, since)
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.ID{id}, restored, "only links inside the window are restored")
+
+	_, deleted, err := st.Get(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	count, err := st.PurgeDeleted(ctx, since)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, _, err = st.Get(ctx, oldID)
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+
+	newID, err := st.Add(ctx, "https://example.com/old", owner, repositories.LinkOptions{})
+	require.NoError(t, err, "purged URL can be shortened again")
+	assert.NotEqual(t, oldID, newID)
+}
+
+func TestMemoryStorage_IDs(t *testing.T) {
+	ctx := context.Background()
+
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+	st.IDs = idgen.NewSequential("ab", 2)
+	user := uuid.New()
+
+	id, err := st.Add(ctx, "https://example.com/1", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "aa", id)
+
+	_, err = st.Add(ctx, "https://example.com/2", user, repositories.LinkOptions{Alias: "ab"})
+	require.NoError(t, err)
+	id, err = st.Add(ctx, "https://example.com/3", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "bb", id, "taken id is skipped")
+
+	st.IDs = idgen.NewHash(idgen.DefaultAlphabet, 5, 0)
+	st.Dedup = repositories.DedupNone
+	for i := 0; i < idgen.MaxAttempts; i++ {
+		_, err = st.Add(ctx, "https://example.com/same", user, repositories.LinkOptions{})
+		require.NoError(t, err)
+	}
+	_, err = st.Add(ctx, "https://example.com/same", user, repositories.LinkOptions{})
+	assert.ErrorIs(t, err, repositories.ErrNoFreeID, "retries are bounded")
+}
+
+func TestMemoryStorage_AddBatch(t *testing.T) {
+	ctx := context.Background()
+
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+	st.Dedup = repositories.DedupGlobal
+
+	user := uuid.New()
+	existing, err := st.Add(ctx, "https://example.com/existing", user, repositories.LinkOptions{Alias: "taken"})
+	require.NoError(t, err)
+
+	results, err := st.AddBatch(ctx, []repositories.BatchLink{
+		{URL: "https://example.com/new"},
+		{URL: "https://example.com/existing"},
+		{URL: "https://example.com/alias", Opts: repositories.LinkOptions{Alias: "taken"}},
+		{URL: "https://example.com/new"},
+		{URL: "https://example.com/team", Opts: repositories.LinkOptions{Team: uuid.New()}},
+	}, user)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assert.NoError(t, results[0].Err)
+	url, _, err := st.Get(ctx, results[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/new", url)
+
+	assert.ErrorIs(t, results[1].Err, repositories.ErrURLAlreadyExists)
+	assert.Equal(t, existing, results[1].ID)
+	assert.ErrorIs(t, results[2].Err, repositories.ErrIDAlreadyExists)
+	assert.ErrorIs(t, results[3].Err, repositories.ErrURLAlreadyExists, "duplicates inside the batch")
+	assert.Equal(t, results[0].ID, results[3].ID)
+	assert.ErrorIs(t, results[4].Err, repositories.ErrTeamNotFound)
+}
+
+func TestMemStorage_ClaimLinks(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+	st.Dedup = repositories.DedupPerUser
+	from, to := uuid.New(), uuid.New()
+
+	personal, err := st.Add(ctx, "https://example.com/personal", from, repositories.LinkOptions{})
+	require.NoError(t, err)
+	duplicate, err := st.Add(ctx, "https://example.com/both", from, repositories.LinkOptions{})
+	require.NoError(t, err)
+	teamID := uuid.New()
+	require.NoError(t, st.CreateTeam(ctx, repositories.Team{ID: teamID, Name: "team"}, from))
+	team, err := st.Add(ctx, "https://example.com/team", from, repositories.LinkOptions{Team: teamID})
+	require.NoError(t, err)
+	_, err = st.Add(ctx, "https://example.com/both", to, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	claimed, err := st.ClaimLinks(ctx, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.ID{personal}, claimed)
+
+	for id, owner := range map[repositories.ID]repositories.User{personal: to, duplicate: from, team: from} {
+		link, err := st.GetLink(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, owner, link.User, id)
+	}
+
+	_, err = st.Add(ctx, "https://example.com/personal", to, repositories.LinkOptions{})
+	assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists, "dedup key moves with the link")
+}
+
+func TestMemStorage_AddQuota(t *testing.T) {
+	ctx := context.Background()
+	st, err := NewMemoryStorage()
+	require.NoError(t, err)
+	user := uuid.New()
+	q := repositories.Quota{MaxLinks: 5, DailyLinks: 100, Since: time.Now().Add(-time.Hour)}
+
+	var wg sync.WaitGroup
+	var created atomic.Int64
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := st.Add(ctx, fmt.Sprintf("https://example.com/%d", i), user, repositories.LinkOptions{Quota: q})
+			if err == nil {
+				created.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, repositories.ErrMaxLinks)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(5), created.Load())
+
+	_, err = st.AddBatch(ctx, []repositories.BatchLink{
+		{URL: "https://example.com/batch", Opts: repositories.LinkOptions{Quota: repositories.Quota{MaxLinks: 6}}},
+		{URL: "https://example.com/batch2", Opts: repositories.LinkOptions{Quota: repositories.Quota{MaxLinks: 6}}},
+	}, user)
+	assert.ErrorIs(t, err, repositories.ErrMaxLinks, "batch is checked as a whole")
+
+	counts, err := st.CountUserLinks(ctx, user, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), counts.Active)
+}
@@ -0,0 +1,401 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// CreateTeam - создать команду, owner становится ее владельцем.
+func (st *MemStorage) CreateTeam(_ context.Context, team repositories.Team, owner repositories.User) error {
+	st.Lock()
+	defer st.Unlock()
+
+	st.PutTeam(team)
+	st.PutTeamMember(team.ID, owner, repositories.RoleOwner)
+
+	return nil
+}
+
+// GetTeamRole - получить роль пользователя в команде.
+func (st *MemStorage) GetTeamRole(
+	_ context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+) (repositories.Role, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	role, ok := st.TeamMembers[team][user]
+	if !ok {
+		return "", repositories.ErrTeamNotFound
+	}
+
+	return role, nil
+}
+
+// GetUserTeams - получить команды, в которых состоит пользователь, в порядке создания.
+func (st *MemStorage) GetUserTeams(
+	_ context.Context,
+	user repositories.User,
+) ([]repositories.Membership, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	return st.userTeams(user), nil
+}
+
+// GetTeamMembers - получить участников команды: сначала старшие роли, внутри роли - по ID пользователя.
+func (st *MemStorage) GetTeamMembers(
+	_ context.Context,
+	team repositories.TeamID,
+) ([]repositories.TeamMember, error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	members, ok := st.TeamMembers[team]
+	if !ok {
+		return nil, repositories.ErrTeamNotFound
+	}
+
+	list := make([]repositories.TeamMember, 0, len(members))
+	for user, role := range members {
+		list = append(list, repositories.TeamMember{User: user, Role: role})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Role != list[j].Role {
+			return list[i].Role.Allows(list[j].Role)
+		}
+		return list[i].User.String() < list[j].User.String()
+	})
+
+	return list, nil
+}
+
+// SetTeamMember - добавить пользователя в команду или изменить его роль.
+//
+// Последнего владельца команды нельзя понизить, иначе вернет repositories.ErrLastOwner.
+func (st *MemStorage) SetTeamMember(
+	_ context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	role repositories.Role,
+) error {
+	st.Lock()
+	defer st.Unlock()
+
+	err := st.checkTeamMemberChange(team, user, role)
+	if err != nil {
+		return err
+	}
+
+	st.PutTeamMember(team, user, role)
+
+	return nil
+}
+
+// RemoveTeamMember - исключить пользователя из команды.
+//
+// Последнего владельца команды исключить нельзя, иначе вернет repositories.ErrLastOwner.
+func (st *MemStorage) RemoveTeamMember(_ context.Context, team repositories.TeamID, user repositories.User) error {
+	st.Lock()
+	defer st.Unlock()
+
+	if _, ok := st.TeamMembers[team][user]; !ok {
+		return repositories.ErrTeamNotFound
+	}
+
+	err := st.checkTeamMemberChange(team, user, "")
+	if err != nil {
+		return err
+	}
+
+	st.DropTeamMember(team, user)
+
+	return nil
+}
+
+// CreateInvite - сохранить приглашение в команду.
+func (st *MemStorage) CreateInvite(_ context.Context, invite repositories.TeamInvite) error {
+	st.Lock()
+	defer st.Unlock()
+
+	if _, ok := st.Teams[invite.Team]; !ok {
+		return repositories.ErrTeamNotFound
+	}
+
+	st.PutInvite(invite)
+
+	return nil
+}
+
+// AcceptInvite - адаптер для AcceptTeamInvite.
+func (st *MemStorage) AcceptInvite(
+	_ context.Context,
+	id repositories.InviteID,
+	user repositories.User,
+	at time.Time,
+) (repositories.TeamInvite, error) {
+	invite, _, err := st.AcceptTeamInvite(id, user, at)
+	return invite, err
+}
+
+// AcceptTeamInvite - принять приглашение.
+//
+// Если пользователь уже состоит в команде с той же или старшей ролью, его роль не меняется.
+// Возвращает принятое приглашение и то, изменилась ли роль пользователя.
+func (st *MemStorage) AcceptTeamInvite(
+	id repositories.InviteID,
+	user repositories.User,
+	at time.Time,
+) (invite repositories.TeamInvite, joined bool, err error) {
+	st.Lock()
+	defer st.Unlock()
+
+	invite, ok := st.Invites[id]
+	if !ok {
+		return invite, false, repositories.ErrInviteNotFound
+	}
+	err = invite.Usable(at)
+	if err != nil {
+		return invite, false, err
+	}
+	if _, ok = st.Teams[invite.Team]; !ok {
+		return invite, false, repositories.ErrTeamNotFound
+	}
+
+	invite.UsedAt = at
+	invite.UsedBy = user
+	st.PutInvite(invite)
+
+	if role := st.TeamMembers[invite.Team][user]; !role.Allows(invite.Role) {
+		st.PutTeamMember(invite.Team, user, invite.Role)
+		joined = true
+	}
+
+	return invite, joined, nil
+}
+
+// RevokeInvite - адаптер для RevokeTeamInvite.
+func (st *MemStorage) RevokeInvite(
+	_ context.Context,
+	id repositories.InviteID,
+	team repositories.TeamID,
+	at time.Time,
+) error {
+	_, err := st.RevokeTeamInvite(id, team, at)
+	return err
+}
+
+// RevokeTeamInvite - отозвать приглашение в команду team.
+//
+// Принятое приглашение отозвать нельзя, повторный отзыв не меняет время отзыва.
+// Возвращает, было ли приглашение отозвано этим вызовом.
+func (st *MemStorage) RevokeTeamInvite(
+	id repositories.InviteID,
+	team repositories.TeamID,
+	at time.Time,
+) (revoked bool, err error) {
+	st.Lock()
+	defer st.Unlock()
+
+	invite, ok := st.Invites[id]
+	if !ok || invite.Team != team {
+		return false, repositories.ErrInviteNotFound
+	}
+	if !invite.UsedAt.IsZero() {
+		return false, repositories.ErrInviteUsed
+	}
+	if !invite.RevokedAt.IsZero() {
+		return false, nil
+	}
+
+	invite.RevokedAt = at
+	st.PutInvite(invite)
+
+	return true, nil
+}
+
+// TransferLinks - адаптер для TransferUserLinks.
+func (st *MemStorage) TransferLinks(
+	_ context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+	team repositories.TeamID,
+) error {
+	_, err := st.TransferUserLinks(ids, user, team)
+	return err
+}
+
+// TransferUserLinks - передать ссылки в команду team или, если team == uuid.Nil, в личные ссылки user.
+//
+// Передать ссылку может только ее владелец, а в команду - только ее редактор или владелец.
+// Ссылки передаются все вместе или ни одна. Возвращает ссылки в новом состоянии.
+func (st *MemStorage) TransferUserLinks(
+	ids []repositories.ID,
+	user repositories.User,
+	team repositories.TeamID,
+) (links []repositories.LinkData, err error) {
+	st.Lock()
+	defer st.Unlock()
+
+	if team != uuid.Nil {
+		err = st.requireTeamRole(team, user, repositories.RoleEditor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	links = make([]repositories.LinkData, 0, len(ids))
+	for _, id := range ids {
+		link, ok := st.IDLinkDataDictionary[id]
+		if !ok {
+			return nil, repositories.ErrURLNotFound
+		}
+		if st.linkRole(link, user) != repositories.RoleOwner {
+			return nil, repositories.ErrForbidden
+		}
+
+		link.ID = id
+		link.Team = team
+		if team == uuid.Nil {
+			link.User = user
+		}
+		if key, ok := st.Dedup.Key(link.URL, link.User); ok {
+			if existing, exists := st.ExistingURLs[key]; exists && existing != id {
+				return nil, repositories.ErrURLAlreadyExists
+			}
+		}
+		links = append(links, link)
+	}
+
+	for _, link := range links {
+		st.MoveLink(link.ID, link.Team, link.User)
+	}
+
+	return links, nil
+}
+
+// PutTeam - сохранить команду.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) PutTeam(team repositories.Team) {
+	st.Teams[team.ID] = team
+	if st.TeamMembers[team.ID] == nil {
+		st.TeamMembers[team.ID] = make(map[repositories.User]repositories.Role)
+	}
+}
+
+// PutTeamMember - сохранить роль участника команды.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) PutTeamMember(team repositories.TeamID, user repositories.User, role repositories.Role) {
+	members, ok := st.TeamMembers[team]
+	if !ok {
+		members = make(map[repositories.User]repositories.Role)
+		st.TeamMembers[team] = members
+	}
+	members[user] = role
+}
+
+// DropTeamMember - исключить участника из команды.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) DropTeamMember(team repositories.TeamID, user repositories.User) {
+	delete(st.TeamMembers[team], user)
+}
+
+// PutInvite - сохранить приглашение в команду.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) PutInvite(invite repositories.TeamInvite) {
+	st.Invites[invite.ID] = invite
+}
+
+// MoveLink - передать ссылку в команду team и пользователю user без проверки прав.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) MoveLink(id repositories.ID, team repositories.TeamID, user repositories.User) {
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok {
+		return
+	}
+
+	st.unindexLink(id, link)
+	if key, ok := st.Dedup.Key(link.URL, link.User); ok && st.ExistingURLs[key] == id {
+		delete(st.ExistingURLs, key)
+	}
+
+	link.Team = team
+	link.User = user
+	st.PutLink(id, link)
+}
+
+// checkTeamMemberChange - проверить, что после смены роли участника (пустая роль - исключение)
+// у команды останется владелец.
+func (st *MemStorage) checkTeamMemberChange(
+	team repositories.TeamID,
+	user repositories.User,
+	role repositories.Role,
+) error {
+	members, ok := st.TeamMembers[team]
+	if !ok {
+		return repositories.ErrTeamNotFound
+	}
+
+	if members[user] != repositories.RoleOwner || role == repositories.RoleOwner {
+		return nil
+	}
+
+	for member, memberRole := range members {
+		if member != user && memberRole == repositories.RoleOwner {
+			return nil
+		}
+	}
+
+	return repositories.ErrLastOwner
+}
+
+// requireTeamRole - проверить, что роль пользователя в команде не ниже need.
+func (st *MemStorage) requireTeamRole(
+	team repositories.TeamID,
+	user repositories.User,
+	need repositories.Role,
+) error {
+	role, ok := st.TeamMembers[team][user]
+	if !ok {
+		return repositories.ErrTeamNotFound
+	}
+	if !role.Allows(need) {
+		return repositories.ErrForbidden
+	}
+	return nil
+}
+
+// linkRole - роль пользователя по отношению к ссылке.
+func (st *MemStorage) linkRole(link repositories.LinkData, user repositories.User) repositories.Role {
+	return repositories.LinkRole(link, user, st.TeamMembers[link.Team][user])
+}
+
+// userTeams - команды, в которых состоит пользователь, в порядке создания.
+func (st *MemStorage) userTeams(user repositories.User) []repositories.Membership {
+	teams := make([]repositories.Membership, 0)
+	for id, members := range st.TeamMembers {
+		role, ok := members[user]
+		if !ok {
+			continue
+		}
+		teams = append(teams, repositories.Membership{Team: st.Teams[id], Role: role})
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if !teams[i].CreatedAt.Equal(teams[j].CreatedAt) {
+			return teams[i].CreatedAt.Before(teams[j].CreatedAt)
+		}
+		return teams[i].ID.String() < teams[j].ID.String()
+	})
+	return teams
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// GetDeletedUserLinks - получить удаленные ссылки, которые пользователь может восстановить:
+// личные и ссылки команд, где он редактор или владелец. Сначала удаленные позже.
+func (st *MemStorage) GetDeletedUserLinks(
+	_ context.Context,
+	user repositories.User,
+) (links []repositories.LinkData, err error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	links = make([]repositories.LinkData, 0)
+
+	appendLinks := func(ids []repositories.ID, team repositories.TeamID) {
+		for _, id := range ids {
+			link := st.IDLinkDataDictionary[id]
+			if !link.Deleted || link.Team != team {
+				continue
+			}
+			link.ID = id
+			links = append(links, link)
+		}
+	}
+
+	appendLinks(st.UserLinks[user], uuid.Nil)
+	for _, m := range st.userTeams(user) {
+		if m.Role.Allows(repositories.RoleEditor) {
+			appendLinks(st.TeamLinks[m.ID], m.ID)
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if !links[i].DeletedAt.Equal(links[j].DeletedAt) {
+			return links[i].DeletedAt.After(links[j].DeletedAt)
+		}
+		return links[i].ID < links[j].ID
+	})
+
+	return links, nil
+}
+
+// RestoreUserLinks - восстановить ссылки пользователя, удаленные не раньше since.
+//
+// Ссылки, которые не удалены, удалены раньше since или недоступны пользователю, пропускаются.
+func (st *MemStorage) RestoreUserLinks(
+	_ context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+	since time.Time,
+) (restored []repositories.ID, err error) {
+	restored = make([]repositories.ID, 0, len(ids))
+	for _, id := range ids {
+		if st.RestoreUserLink(id, user, since) {
+			restored = append(restored, id)
+		}
+	}
+
+	return restored, nil
+}
+
+// RestoreUserLink - восстановить ссылку пользователя, удаленную не раньше since.
+//
+// Восстановить ссылку команды может ее редактор или владелец.
+func (st *MemStorage) RestoreUserLink(id repositories.ID, user repositories.User, since time.Time) (ok bool) {
+	st.Lock()
+	defer st.Unlock()
+
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok || !link.Deleted || link.DeletedAt.Before(since) {
+		return false
+	}
+	if !st.linkRole(link, user).Allows(repositories.RoleEditor) {
+		return false
+	}
+
+	st.UndeleteLink(id)
+
+	return true
+}
+
+// UndeleteLink - снять с ссылки отметку об удалении.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) UndeleteLink(id repositories.ID) {
+	link := st.IDLinkDataDictionary[id]
+	link.Deleted = false
+	link.DeletedAt = time.Time{}
+	st.IDLinkDataDictionary[id] = link
+}
+
+// PurgeDeleted - безвозвратно удалить ссылки, удаленные раньше before.
+func (st *MemStorage) PurgeDeleted(_ context.Context, before time.Time) (count int64, err error) {
+	return int64(len(st.PurgeDeletedLinks(before))), nil
+}
+
+// PurgeDeletedLinks - безвозвратно удалить ссылки, удаленные раньше before, и вернуть их ID.
+//
+// ID и исходные URL этих ссылок снова становятся свободными.
+func (st *MemStorage) PurgeDeletedLinks(before time.Time) (ids []repositories.ID) {
+	st.Lock()
+	defer st.Unlock()
+
+	for id, link := range st.IDLinkDataDictionary {
+		if !link.Deleted || !link.DeletedAt.Before(before) {
+			continue
+		}
+
+		st.RemoveLink(id)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
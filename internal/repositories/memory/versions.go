@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Update - адаптер для UpdateLink.
+func (st *MemStorage) Update(
+	_ context.Context,
+	id repositories.ID,
+	url repositories.URL,
+	user repositories.User,
+) (link repositories.LinkData, err error) {
+	link, _, err = st.UpdateLink(id, url, user, time.Now().Round(0))
+	return link, err
+}
+
+// UpdateLink - заменить исходный URL ссылки, прежний URL сохраняется в истории.
+//
+// Изменить ссылку может ее владелец, а ссылку команды - ее редактор или владелец.
+// Если URL не изменился, prev будет пустым.
+func (st *MemStorage) UpdateLink(
+	id repositories.ID,
+	url repositories.URL,
+	user repositories.User,
+	now time.Time,
+) (link repositories.LinkData, prev repositories.URLVersion, err error) {
+	st.Lock()
+	defer st.Unlock()
+
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok || link.Deleted {
+		return repositories.LinkData{}, prev, repositories.ErrURLNotFound
+	}
+	if !st.linkRole(link, user).Allows(repositories.RoleEditor) {
+		return repositories.LinkData{}, prev, repositories.ErrUserNotMatch
+	}
+
+	if link.URL != url {
+		if key, ok := st.Dedup.Key(url, link.User); ok {
+			if existing, exists := st.ExistingURLs[key]; exists && existing != id {
+				return repositories.LinkData{}, prev, repositories.ErrURLAlreadyExists
+			}
+		}
+
+		prev = repositories.URLVersion{ReplacedAt: now, URL: link.URL, User: user}
+		st.ReplaceURL(id, url, prev)
+		link = st.IDLinkDataDictionary[id]
+	}
+
+	link.ID = id
+	return link, prev, nil
+}
+
+// ReplaceURL - заменить исходный URL ссылки и обновить индекс совпадающих URL.
+//
+// Вызывающий должен держать блокировку на запись.
+func (st *MemStorage) ReplaceURL(id repositories.ID, url repositories.URL, prev repositories.URLVersion) {
+	link := st.IDLinkDataDictionary[id]
+
+	if key, ok := st.Dedup.Key(link.URL, link.User); ok && st.ExistingURLs[key] == id {
+		delete(st.ExistingURLs, key)
+	}
+	if key, ok := st.Dedup.Key(url, link.User); ok {
+		if _, exists := st.ExistingURLs[key]; !exists {
+			st.ExistingURLs[key] = id
+		}
+	}
+
+	prev.URL = link.URL
+	st.History[id] = append(st.History[id], prev)
+	link.URL = url
+	st.IDLinkDataDictionary[id] = link
+}
+
+// GetLinkHistory - получить прежние исходные URL ссылки, от старых к новым.
+func (st *MemStorage) GetLinkHistory(
+	_ context.Context,
+	id repositories.ID,
+	user repositories.User,
+) (versions []repositories.URLVersion, err error) {
+	st.RLock()
+	defer st.RUnlock()
+
+	link, ok := st.IDLinkDataDictionary[id]
+	if !ok {
+		return nil, repositories.ErrURLNotFound
+	}
+	if !st.linkRole(link, user).Allows(repositories.RoleViewer) {
+		return nil, repositories.ErrUserNotMatch
+	}
+
+	versions = make([]repositories.URLVersion, len(st.History[id]))
+	copy(versions, st.History[id])
+
+	return versions, nil
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Размеры страницы при выборке ссылок пользователя.
+const (
+	DefaultPageSize = 100  // Размер страницы, если он не указан.
+	MaxPageSize     = 1000 // Максимальный размер страницы.
+)
+
+// SortOrder - порядок сортировки ссылок по времени создания.
+type SortOrder string
+
+// Возможные значения SortOrder.
+const (
+	SortAsc  SortOrder = "asc"  // Сначала старые.
+	SortDesc SortOrder = "desc" // Сначала новые, порядок по умолчанию.
+)
+
+// LinkQuery - параметры выборки ссылок пользователя.
+type LinkQuery struct {
+	Cursor string    // Курсор из LinkPage.NextCursor, пустая строка - с начала.
+	Filter string    // Подстрока, которая должна быть в исходном URL.
+	Order  SortOrder // Порядок сортировки по времени создания.
+	Limit  int       // Размер страницы, по умолчанию DefaultPageSize.
+	Team   TeamID    // Команда, ссылки которой нужно выбрать. uuid.Nil - личные ссылки пользователя.
+}
+
+// Normalize - заполнить значения по умолчанию и проверить параметры.
+func (q LinkQuery) Normalize() (LinkQuery, error) {
+	switch q.Order {
+	case "":
+		q.Order = SortDesc
+	case SortAsc, SortDesc:
+	default:
+		return q, ErrWrongOrder
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+
+	if q.Cursor != "" {
+		if _, err := DecodeCursor(q.Cursor); err != nil {
+			return q, err
+		}
+	}
+
+	return q, nil
+}
+
+// Match - подходит ли ссылка под фильтр.
+func (q LinkQuery) Match(link LinkData) bool {
+	return strings.Contains(link.URL, q.Filter)
+}
+
+// LinkPage - страница ссылок пользователя.
+type LinkPage struct {
+	Links      []LinkData // Ссылки на странице.
+	NextCursor string     // Курсор следующей страницы, пустая строка - это последняя страница.
+}
+
+// Cursor - позиция в списке ссылок, упорядоченном по времени создания и ID.
+type Cursor struct {
+	CreatedAt time.Time // Время создания последней ссылки на странице.
+	ID        ID        // ID последней ссылки на странице.
+}
+
+// Encode - закодировать курсор в непрозрачную для клиента строку.
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID))
+}
+
+// Less - идет ли ссылка раньше курсора при сортировке по возрастанию.
+func (c Cursor) Less(o Cursor) bool {
+	if !c.CreatedAt.Equal(o.CreatedAt) {
+		return c.CreatedAt.Before(o.CreatedAt)
+	}
+	return c.ID < o.ID
+}
+
+// LinkCursor - курсор, который указывает на ссылку.
+func LinkCursor(link LinkData) Cursor {
+	return Cursor{CreatedAt: link.CreatedAt, ID: link.ID}
+}
+
+// DecodeCursor - раскодировать курсор из строки.
+func DecodeCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrWrongCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return Cursor{}, ErrWrongCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrWrongCursor
+	}
+
+	return Cursor{CreatedAt: time.Unix(0, n), ID: id}, nil
+}
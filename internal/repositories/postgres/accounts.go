@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// CreateAccount - зарегистрировать учетную запись.
+func (st *PsqlStorage) CreateAccount(ctx context.Context, account repositories.Account) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	_, err := st.execContext(
+		ctx,
+		`INSERT INTO accounts (id, login, password_hash, created_at) VALUES ($1, $2, $3, $4)`,
+		account.ID, account.Login, account.PasswordHash, account.CreatedAt,
+	)
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return repositories.ErrLoginTaken
+	}
+	if err != nil {
+		st.log(ctx).Error("insert failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetAccount - получить учетную запись пользователя.
+func (st *PsqlStorage) GetAccount(ctx context.Context, id repositories.User) (repositories.Account, error) {
+	return st.getAccount(ctx, `SELECT id, login, password_hash, created_at, sessions_revoked_at
+        FROM accounts WHERE id = $1`, id)
+}
+
+// GetAccountByLogin - получить учетную запись по логину.
+func (st *PsqlStorage) GetAccountByLogin(ctx context.Context, login string) (repositories.Account, error) {
+	return st.getAccount(ctx, `SELECT id, login, password_hash, created_at, sessions_revoked_at
+        FROM accounts WHERE login = $1`, login)
+}
+
+func (st *PsqlStorage) getAccount(
+	ctx context.Context,
+	query string,
+	arg any,
+) (account repositories.Account, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	var revokedAt sql.NullTime
+	err = st.queryRowContext(ctx, query, arg).Scan(
+		&account.ID, &account.Login, &account.PasswordHash, &account.CreatedAt, &revokedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repositories.Account{}, repositories.ErrAccountNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return repositories.Account{}, err
+	}
+	if revokedAt.Valid {
+		account.SessionsRevokedAt = revokedAt.Time
+	}
+
+	return account, nil
+}
+
+// CreateAPIKey - сохранить API-ключ учетной записи.
+func (st *PsqlStorage) CreateAPIKey(ctx context.Context, key repositories.APIKey) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	_, err := st.execContext(
+		ctx,
+		`INSERT INTO api_keys (id, account_id, name, prefix, hash, scope, created_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		key.ID, key.Account, key.Name, key.Prefix, key.Hash, key.Scope, key.CreatedAt,
+	)
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+		return repositories.ErrAccountNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("insert failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetAPIKey - найти API-ключ по его хешу.
+func (st *PsqlStorage) GetAPIKey(ctx context.Context, hash []byte) (key repositories.APIKey, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	var revokedAt sql.NullTime
+	err = st.queryRowContext(
+		ctx,
+		`SELECT id, account_id, name, prefix, hash, scope, created_at, revoked_at FROM api_keys WHERE hash = $1`,
+		hash,
+	).Scan(&key.ID, &key.Account, &key.Name, &key.Prefix, &key.Hash, &key.Scope, &key.CreatedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repositories.APIKey{}, repositories.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return repositories.APIKey{}, err
+	}
+	key.RevokedAt = revokedAt.Time
+
+	return key, nil
+}
+
+// GetAccountAPIKeys - получить API-ключи учетной записи в порядке создания.
+func (st *PsqlStorage) GetAccountAPIKeys(
+	ctx context.Context,
+	account repositories.User,
+) (keys []repositories.APIKey, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT id, account_id, name, prefix, hash, scope, created_at, revoked_at FROM api_keys
+         WHERE account_id = $1 ORDER BY created_at, id`,
+		account,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys = make([]repositories.APIKey, 0)
+	for rows.Next() {
+		var key repositories.APIKey
+		var revokedAt sql.NullTime
+		err = rows.Scan(&key.ID, &key.Account, &key.Name, &key.Prefix, &key.Hash, &key.Scope, &key.CreatedAt, &revokedAt)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		key.RevokedAt = revokedAt.Time
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey - отозвать API-ключ учетной записи, повторный отзыв не меняет время отзыва.
+func (st *PsqlStorage) RevokeAPIKey(
+	ctx context.Context,
+	id repositories.KeyID,
+	account repositories.User,
+	at time.Time,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(
+		ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $3) WHERE id = $1 AND account_id = $2`,
+		id, account, at,
+	)
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return repositories.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessions - завершить сессии учетной записи, выданные до at, время выхода не сдвигается назад.
+func (st *PsqlStorage) RevokeSessions(ctx context.Context, account repositories.User, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(
+		ctx,
+		`UPDATE accounts SET sessions_revoked_at = GREATEST(sessions_revoked_at, $2) WHERE id = $1`,
+		account, at,
+	)
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return repositories.ErrAccountNotFound
+	}
+
+	return nil
+}
+
+// ClaimLinks - передать личные ссылки пользователя from пользователю to.
+//
+// Ссылки команд остаются в командах. В режиме DedupPerUser ссылка на URL,
+// который у to уже сокращен, остается у from.
+func (st *PsqlStorage) ClaimLinks(
+	ctx context.Context,
+	from repositories.User,
+	to repositories.User,
+) (claimed []repositories.ID, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	query := `UPDATE links SET user_id = $2 WHERE user_id = $1 AND team_id IS NULL RETURNING id`
+	if st.dedup == repositories.DedupPerUser {
+		query = `UPDATE links SET user_id = $2, dedup_key = $2::text || ' ' || url
+                 WHERE user_id = $1 AND team_id IS NULL AND NOT EXISTS (
+                     SELECT 1 FROM links mine WHERE mine.dedup_key = $2::text || ' ' || links.url
+                 )
+                 RETURNING id`
+	}
+
+	rows, err := st.queryContext(ctx, query, from, to)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	claimed = make([]repositories.ID, 0)
+	for rows.Next() {
+		var id repositories.ID
+		err = rows.Scan(&id)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		claimed = append(claimed, id)
+	}
+
+	return claimed, rows.Err()
+}
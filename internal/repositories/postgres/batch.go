@@ -0,0 +1,250 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// AddBatch - сократить пачку ссылок.
+//
+// Ссылки вставляются одним запросом. Ссылки, которым достался занятый ID, получают новый ID
+// и вставляются следующим запросом, не больше idgen.MaxAttempts раз.
+// Ошибка в отдельной ссылке не прерывает пачку, результаты возвращаются в том же порядке.
+// Если заданы квоты, пачка проверяется по ним целиком и сохраняется в одной транзакции.
+func (st *PsqlStorage) AddBatch(
+	ctx context.Context,
+	links []repositories.BatchLink,
+	user repositories.User,
+) (results []repositories.BatchResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	q := repositories.BatchQuota(links)
+	if !q.Limited() {
+		return st.addBatch(ctx, links, user)
+	}
+
+	err = st.inTx(ctx, func(ctx context.Context) error {
+		err := st.checkQuota(ctx, user, q, len(links))
+		if err != nil {
+			return err
+		}
+
+		results, err = st.addBatch(ctx, links, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// addBatch - сократить пачку ссылок без проверки квот.
+func (st *PsqlStorage) addBatch(
+	ctx context.Context,
+	links []repositories.BatchLink,
+	user repositories.User,
+) (results []repositories.BatchResult, err error) {
+	gen := st.ids
+	if gen == nil {
+		gen = idgen.Default
+	}
+
+	results = make([]repositories.BatchResult, len(links))
+	pending := make([]int, 0, len(links))
+
+	teams := make(map[repositories.TeamID]error)
+	for i, link := range links {
+		team := link.Opts.Team
+		if team != uuid.Nil {
+			if _, ok := teams[team]; !ok {
+				teams[team] = st.requireTeamRole(ctx, team, user, repositories.RoleEditor)
+			}
+			if teams[team] != nil {
+				results[i].Err = teams[team]
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+
+	for attempt := 0; len(pending) > 0 && attempt < idgen.MaxAttempts; attempt++ {
+		for _, i := range pending {
+			if links[i].Opts.Alias != "" {
+				results[i].ID = links[i].Opts.Alias
+				continue
+			}
+			results[i].ID, err = gen.Generate(links[i].URL, attempt)
+			if err != nil {
+				st.log(ctx).Error("generate id failed", zap.Error(err))
+				return nil, err
+			}
+		}
+
+		pending, err = st.insertBatch(ctx, links, user, results, pending)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, i := range pending {
+		results[i] = repositories.BatchResult{Err: repositories.ErrNoFreeID}
+	}
+
+	return results, nil
+}
+
+// insertBatch - вставить ссылки links[i] для i из pending с ID из results[i].
+//
+// Для ссылок, которые не удалось вставить из-за совпадающего URL или занятого алиаса,
+// записывает ошибку в results. Возвращает индексы ссылок, которым достался занятый ID.
+func (st *PsqlStorage) insertBatch(
+	ctx context.Context,
+	links []repositories.BatchLink,
+	user repositories.User,
+	results []repositories.BatchResult,
+	pending []int,
+) (retry []int, err error) {
+	ids := make([]string, 0, len(pending))
+	urls := make([]string, 0, len(pending))
+	expiresAt := make([]string, 0, len(pending))
+	maxHits := make([]int64, 0, len(pending))
+	dedupKeys := make([]string, 0, len(pending))
+	teams := make([]string, 0, len(pending))
+	for _, i := range pending {
+		link := links[i]
+
+		ids = append(ids, results[i].ID)
+		urls = append(urls, link.URL)
+		expires := ""
+		if !link.Opts.ExpiresAt.IsZero() {
+			expires = link.Opts.ExpiresAt.Format(time.RFC3339Nano)
+		}
+		expiresAt = append(expiresAt, expires)
+		maxHits = append(maxHits, int64(link.Opts.MaxHits))
+		dedupKeys = append(dedupKeys, st.dedupKey(link.URL, user).String)
+		team := ""
+		if link.Opts.Team != uuid.Nil {
+			team = link.Opts.Team.String()
+		}
+		teams = append(teams, team)
+	}
+
+	rows, err := st.queryContext(
+		ctx,
+		`INSERT INTO links (id, url, user_id, expires_at, max_hits, dedup_key, team_id)
+         SELECT data.id, data.url, $3, NULLIF(data.expires_at, '')::timestamptz, data.max_hits,
+             NULLIF(data.dedup_key, ''), NULLIF(data.team_id, '')::uuid
+         FROM unnest($1::text[], $2::text[], $4::text[], $5::bigint[], $6::text[], $7::text[])
+             WITH ORDINALITY AS data(id, url, expires_at, max_hits, dedup_key, team_id, n)
+         ORDER BY data.n
+         ON CONFLICT DO NOTHING
+         RETURNING id`,
+		pq.Array(ids), pq.Array(urls), user, pq.Array(expiresAt), pq.Array(maxHits),
+		pq.Array(dedupKeys), pq.Array(teams),
+	)
+	if err != nil {
+		st.log(ctx).Error("insert failed", zap.Error(err))
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	inserted := make(map[repositories.ID]struct{}, len(pending))
+	for rows.Next() {
+		var id repositories.ID
+		err = rows.Scan(&id)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		inserted[id] = struct{}{}
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make([]int, 0)
+	for _, i := range pending {
+		if _, ok := inserted[results[i].ID]; ok {
+			delete(inserted, results[i].ID)
+			continue
+		}
+		skipped = append(skipped, i)
+	}
+	if len(skipped) == 0 {
+		return nil, nil
+	}
+
+	existing, err := st.existingByDedupKey(ctx, links, user, skipped)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range skipped {
+		if id, ok := existing[st.dedupKey(links[i].URL, user).String]; ok {
+			results[i] = repositories.BatchResult{ID: id, Err: repositories.ErrURLAlreadyExists}
+			continue
+		}
+		if links[i].Opts.Alias != "" {
+			results[i] = repositories.BatchResult{Err: repositories.ErrIDAlreadyExists}
+			continue
+		}
+		retry = append(retry, i)
+	}
+
+	return retry, nil
+}
+
+// existingByDedupKey - найти ID уже сохраненных ссылок с теми же ключами dedup_key,
+// что у ссылок links[i] для i из skipped.
+func (st *PsqlStorage) existingByDedupKey(
+	ctx context.Context,
+	links []repositories.BatchLink,
+	user repositories.User,
+	skipped []int,
+) (existing map[string]repositories.ID, err error) {
+	existing = make(map[string]repositories.ID)
+
+	keys := make([]string, 0, len(skipped))
+	for _, i := range skipped {
+		if key := st.dedupKey(links[i].URL, user); key.Valid {
+			keys = append(keys, key.String)
+		}
+	}
+	if len(keys) == 0 {
+		return existing, nil
+	}
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT dedup_key, id FROM links WHERE dedup_key = ANY($1)`,
+		pq.Array(keys),
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var key string
+		var id repositories.ID
+		err = rows.Scan(&key, &id)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		existing[key] = id
+	}
+
+	return existing, rows.Err()
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+const (
+	dedupModeKey = "dedup_mode"     // Запись таблицы meta с режимом, по которому построены ключи dedup_key.
+	rekeyTimeout = 10 * time.Minute // Пересчет затрагивает все ссылки, поэтому у него отдельный таймаут.
+)
+
+// syncDedup - пересчитать dedup_key всех ссылок, если они построены не по режиму st.dedup.
+//
+// Режим, по которому построены ключи, хранится в таблице meta. Если его там нет (база создана
+// до появления таблицы), ключи пересчитываются безусловно. Если после смены режима у нескольких
+// ссылок совпадают ключи, ключ остается только у самой старой из них, остальные не участвуют
+// в поиске совпадений: иначе пересчет нарушил бы уникальный индекс.
+func (st *PsqlStorage) syncDedup(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, rekeyTimeout)
+	defer cancel()
+
+	mode := st.dedup
+	if mode == "" {
+		mode = repositories.DedupGlobal
+	}
+
+	return st.inTx(ctx, func(ctx context.Context) error {
+		// Одновременно запущенные экземпляры сервиса пересчитывают ключи по очереди.
+		_, err := st.execContext(ctx, `LOCK TABLE meta IN EXCLUSIVE MODE`)
+		if err != nil {
+			st.log(ctx).Error("lock failed", zap.Error(err))
+			return err
+		}
+
+		var stored string
+		err = st.queryRowContext(ctx, `SELECT value FROM meta WHERE key = $1`, dedupModeKey).Scan(&stored)
+		if err == nil && repositories.DedupMode(stored) == mode {
+			return nil
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			st.log(ctx).Error("select failed", zap.Error(err))
+			return err
+		}
+
+		st.log(ctx).Info("rebuilding dedup keys", zap.String("from", stored), zap.String("to", string(mode)))
+		_, err = st.execContext(ctx, rekeyQuery(mode))
+		if err != nil {
+			st.log(ctx).Error("update failed", zap.Error(err))
+			return err
+		}
+
+		_, err = st.execContext(ctx,
+			`INSERT INTO meta (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value`,
+			dedupModeKey, string(mode),
+		)
+		if err != nil {
+			st.log(ctx).Error("insert failed", zap.Error(err))
+			return err
+		}
+
+		return nil
+	})
+}
+
+// rekeyQuery - запрос, который строит dedup_key всех ссылок по режиму mode, как dedupKey.
+func rekeyQuery(mode repositories.DedupMode) string {
+	var key string
+	switch mode {
+	case repositories.DedupPerUser:
+		key = `user_id::text || ' ' || url`
+	case repositories.DedupNone:
+		return `UPDATE links SET dedup_key = NULL WHERE dedup_key IS NOT NULL`
+	default:
+		key = `url`
+	}
+
+	return `UPDATE links SET dedup_key = keyed.dedup_key
+            FROM (
+                SELECT id, CASE WHEN row_number() OVER (PARTITION BY ` + key + ` ORDER BY created_at, id) = 1
+                                THEN ` + key + ` END AS dedup_key
+                FROM links
+            ) keyed
+            WHERE links.id = keyed.id AND links.dedup_key IS DISTINCT FROM keyed.dedup_key`
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.uber.org/zap"
+)
+
+// Collectors - метрики хранилища: состояние пула соединений sql.DB и длина очереди удалений.
+func (st *PsqlStorage) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		collectors.NewDBStatsCollector(st.db, "urlshortener"),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "urlshortener",
+			Subsystem: "postgres",
+			Name:      "pending_deletes",
+			Help:      "Number of accepted link deletions not yet applied by the delete worker.",
+		}, st.pendingDeletes),
+	}
+}
+
+// pendingDeletes - количество ссылок в очереди на удаление, NaN - если его не удалось получить.
+func (st *PsqlStorage) pendingDeletes() float64 {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var count int64
+	err := st.db.QueryRowContext(ctx, `SELECT count(*) FROM pending_deletes WHERE applied_at IS NULL`).Scan(&count)
+	if err != nil {
+		st.log(ctx).Warn("unable to count pending deletes", zap.Error(err))
+		return math.NaN()
+	}
+
+	return float64(count)
+}
@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres" // postgres init for golang-migrate
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+
+	"github.com/ImpressionableRaccoon/urlshortener/migrations"
+)
+
+// NewMigrator - мигратор схемы базы данных dsn со встроенными в бинарник миграциями.
+//
+// Вызывающий должен закрыть мигратор методом Close.
+func NewMigrator(dsn string) (*migrate.Migrate, error) {
+	src, err := iofs.New(migrations.Postgres, "postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, dsn)
+	if err != nil {
+		_ = src.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
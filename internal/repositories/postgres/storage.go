@@ -5,38 +5,60 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres" // postgres init for golang-migrate
-	_ "github.com/golang-migrate/migrate/v4/source/file"       // file init for golang-migrate
+	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/lib/pq"
+	"go.uber.org/zap"
 
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/logger"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
-	"github.com/ImpressionableRaccoon/urlshortener/internal/utils"
 )
 
 const (
-	deleteBufferSize    = 100
-	deleteBufferTimeout = time.Second
-	shutdownTimeout     = 15 * time.Second
+	deleteBufferSize      = 100
+	deleteBufferTimeout   = time.Second
+	deleteRetryMaxBackoff = time.Minute
+	deletionRetention     = 24 * time.Hour
+	shutdownTimeout       = 15 * time.Second
 )
 
 // PsqlStorage - структура для хранилища Postgres.
+//
+// Запросы на удаление ссылок сначала сохраняются в таблицу pending_deletes,
+// а затем применяются фоновым воркером, поэтому переживают перезапуск сервиса.
 type PsqlStorage struct {
 	db             *sql.DB
-	deleteCh       chan repositories.LinkData
+	dedup          repositories.DedupMode
+	ids            idgen.IDGenerator
+	logger         *zap.Logger
+	deleteCh       chan struct{}
 	deleteWg       sync.WaitGroup
 	deleteShutdown chan struct{}
 }
 
 // NewPsqlStorage - конструктор для PsqlStorage.
-func NewPsqlStorage(dsn string) (*PsqlStorage, error) {
+//
+// Режим dedup определяет, какие ссылки считаются совпадающими: его ключ хранится в колонке dedup_key,
+// на которой построен уникальный индекс. Если режим сменился, ключи всех ссылок пересчитываются, см. syncDedup.
+// Генератор ids по умолчанию - idgen.Default.
+// Если autoMigrate равен false, миграции не применяются: схему нужно обновить заранее, см. NewMigrator.
+func NewPsqlStorage(
+	dsn string,
+	dedup repositories.DedupMode,
+	ids idgen.IDGenerator,
+	autoMigrate bool,
+	l *zap.Logger,
+) (*PsqlStorage, error) {
 	st := &PsqlStorage{
-		deleteCh:       make(chan repositories.LinkData),
+		dedup:          dedup,
+		ids:            ids,
+		logger:         l,
+		deleteCh:       make(chan struct{}, 1),
 		deleteShutdown: make(chan struct{}),
 	}
 
@@ -46,8 +68,16 @@ func NewPsqlStorage(dsn string) (*PsqlStorage, error) {
 		return nil, err
 	}
 
-	err = st.doMigrate(dsn)
+	if autoMigrate {
+		err = st.doMigrate(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = st.syncDedup(context.Background())
 	if err != nil {
+		_ = st.db.Close()
 		return nil, err
 	}
 
@@ -58,40 +88,105 @@ func NewPsqlStorage(dsn string) (*PsqlStorage, error) {
 }
 
 // Add - сократить ссылку.
+//
+// Создать ссылку в команде opts.Team может только ее редактор или владелец.
+// Если заданы квоты opts.Quota, они проверяются и ссылка создается в одной транзакции.
 func (st *PsqlStorage) Add(
 	ctx context.Context,
 	url repositories.URL,
 	userID repositories.User,
+	opts repositories.LinkOptions,
 ) (id repositories.ID, err error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
-	for {
-		id, err = utils.GenRandomID()
+	if opts.Quota.Limited() {
+		err = st.inTx(ctx, func(ctx context.Context) error {
+			err := st.checkQuota(ctx, userID, opts.Quota, 1)
+			if err != nil {
+				return err
+			}
+
+			id, err = st.add(ctx, url, userID, opts)
+			return err
+		})
+	} else {
+		id, err = st.add(ctx, url, userID, opts)
+	}
+
+	if errors.Is(err, errDuplicateURL) {
+		// Запрос делается уже после транзакции: после ошибки в ней запросы не выполняются.
+		row := st.queryRowContext(ctx, `SELECT id FROM links WHERE dedup_key = $1`, st.dedupKey(url, userID))
+		err = row.Scan(&id)
+		if err != nil {
+			st.log(ctx).Error("query failed", zap.Error(err))
+			return "", err
+		}
+		return id, repositories.ErrURLAlreadyExists
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// errDuplicateURL - ссылка с таким же ключом dedup_key уже есть, ее ID ищет Add.
+var errDuplicateURL = errors.New("duplicate dedup key")
+
+// add - вставить ссылку, подбирая свободный ID.
+func (st *PsqlStorage) add(
+	ctx context.Context,
+	url repositories.URL,
+	userID repositories.User,
+	opts repositories.LinkOptions,
+) (id repositories.ID, err error) {
+	if opts.Team != uuid.Nil {
+		err = st.requireTeamRole(ctx, opts.Team, userID, repositories.RoleEditor)
 		if err != nil {
-			log.Printf("generate id failed: %v", err)
 			return "", err
 		}
+	}
+
+	expiresAt := sql.NullTime{Time: opts.ExpiresAt, Valid: !opts.ExpiresAt.IsZero()}
+	dedupKey := st.dedupKey(url, userID)
+
+	gen := st.ids
+	if gen == nil {
+		gen = idgen.Default
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt == idgen.MaxAttempts {
+			st.log(ctx).Warn("no free id", zap.Int("attempts", attempt))
+			return "", repositories.ErrNoFreeID
+		}
+
+		if opts.Alias != "" {
+			id = opts.Alias
+		} else {
+			id, err = gen.Generate(url, attempt)
+			if err != nil {
+				st.log(ctx).Error("generate id failed", zap.Error(err))
+				return "", err
+			}
+		}
 
 		var res sql.Result
-		res, err = st.db.ExecContext(
+		res, err = st.execContext(
 			ctx,
-			`INSERT INTO links (id, url, user_id) VALUES ($1, $2, $3) ON CONFLICT (id) DO NOTHING`,
-			id, url, userID,
+			`INSERT INTO links (id, url, user_id, expires_at, max_hits, dedup_key, team_id)
+             VALUES ($1, $2, $3, $4, $5, $6, $7)
+             ON CONFLICT (id) DO NOTHING`,
+			id, url, userID, expiresAt, opts.MaxHits, dedupKey, nullTeam(opts.Team),
 		)
 
 		var pgErr *pq.Error
 		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			row := st.db.QueryRowContext(ctx, `SELECT id FROM links WHERE url = $1`, url)
-			err = row.Scan(&id)
-			if err != nil {
-				log.Printf("query failed: %v", err)
-				return "", err
-			}
-			return id, repositories.ErrURLAlreadyExists
+			return "", errDuplicateURL
 		}
 		if err != nil {
-			log.Printf("exec failed: %v", err)
+			st.log(ctx).Error("exec failed", zap.Error(err))
 			return "", err
 		}
 
@@ -104,32 +199,83 @@ func (st *PsqlStorage) Add(
 		if aff == 1 {
 			break
 		}
+
+		if opts.Alias != "" {
+			return "", repositories.ErrIDAlreadyExists
+		}
 	}
 
 	return id, nil
 }
 
+// nullTeam - значение колонки team_id, NULL - личная ссылка.
+func nullTeam(team repositories.TeamID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: team, Valid: team != uuid.Nil}
+}
+
+// dedupKey - значение колонки dedup_key для ссылки, NULL - ссылка не участвует в поиске совпадений.
+func (st *PsqlStorage) dedupKey(url repositories.URL, user repositories.User) sql.NullString {
+	key, ok := st.dedup.Key(url, user)
+	return sql.NullString{String: key, Valid: ok}
+}
+
 // Get - получить оригинальную ссылку по ID.
 func (st *PsqlStorage) Get(ctx context.Context, id repositories.ID) (url repositories.URL, deleted bool, err error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
-	row := st.db.QueryRowContext(
+	row := st.queryRowContext(
 		ctx,
-		`SELECT url, deleted FROM links WHERE id = $1`,
+		`SELECT url, deleted, expires_at, max_hits, hits FROM links WHERE id = $1`,
 		id,
 	)
 
-	err = row.Scan(&url, &deleted)
+	link := repositories.LinkData{ID: id}
+	var expiresAt sql.NullTime
+	err = row.Scan(&link.URL, &link.Deleted, &expiresAt, &link.MaxHits, &link.Hits)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, repositories.ErrURLNotFound
+	}
 	if err != nil {
-		log.Printf("query failed: %v", err)
+		st.log(ctx).Error("query failed", zap.Error(err))
 		return "", false, err
 	}
+	link.ExpiresAt = expiresAt.Time
+
+	if link.Deleted {
+		return link.URL, true, nil
+	}
 
-	return url, deleted, nil
+	if link.Expired(time.Now()) {
+		return "", false, repositories.ErrLinkExpired
+	}
+
+	if link.MaxHits > 0 {
+		var res sql.Result
+		res, err = st.execContext(
+			ctx,
+			`UPDATE links SET hits = hits + 1 WHERE id = $1 AND hits < max_hits`,
+			id,
+		)
+		if err != nil {
+			st.log(ctx).Error("exec failed", zap.Error(err))
+			return "", false, err
+		}
+
+		var aff int64
+		aff, err = res.RowsAffected()
+		if err != nil {
+			return "", false, err
+		}
+		if aff == 0 {
+			return "", false, repositories.ErrLinkExpired
+		}
+	}
+
+	return link.URL, false, nil
 }
 
-// GetUserLinks - получить все ссылки пользователя.
+// GetUserLinks - получить все ссылки пользователя: личные и ссылки команд, в которых он состоит.
 func (st *PsqlStorage) GetUserLinks(
 	ctx context.Context,
 	user repositories.User,
@@ -139,48 +285,434 @@ func (st *PsqlStorage) GetUserLinks(
 
 	data = make([]repositories.LinkData, 0)
 
-	rows, err := st.db.QueryContext(
+	rows, err := st.queryContext(
 		ctx,
-		`SELECT id, url FROM links WHERE user_id = $1 AND deleted = FALSE`,
+		`SELECT id, url, user_id, team_id, expires_at, max_hits, hits FROM links
+         WHERE deleted = FALSE AND (
+             (team_id IS NULL AND user_id = $1)
+             OR team_id IN (SELECT team_id FROM team_members WHERE user_id = $1)
+         )`,
 		user,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		return data, nil
 	}
 	if err != nil {
-		log.Printf("query failed: %v", err)
+		st.log(ctx).Error("query failed", zap.Error(err))
 		return nil, err
 	}
 	if rows.Err() != nil {
-		log.Printf("rows failed: %v", err)
+		st.log(ctx).Error("rows failed", zap.Error(err))
 		return nil, err
 	}
 
 	for rows.Next() {
 		link := repositories.LinkData{
-			User:    user,
 			Deleted: false,
 		}
-		err = rows.Scan(&link.ID, &link.URL)
+		var expiresAt sql.NullTime
+		var team uuid.NullUUID
+		err = rows.Scan(&link.ID, &link.URL, &link.User, &team, &expiresAt, &link.MaxHits, &link.Hits)
 		if err != nil {
-			log.Printf("row scan failed: %v", err)
+			st.log(ctx).Error("row scan failed", zap.Error(err))
 			return nil, err
 		}
+		link.ExpiresAt = expiresAt.Time
+		link.Team = team.UUID
 		data = append(data, link)
 	}
 
 	return data, nil
 }
 
-// DeleteUserLinks - удалить ссылки пользователя.
-func (st *PsqlStorage) DeleteUserLinks(_ context.Context, ids []repositories.ID, user repositories.User) error {
-	for _, id := range ids {
-		st.deleteCh <- repositories.LinkData{ID: id, User: user}
+// GetLink - получить данные ссылки по ID, переход при этом не учитывается.
+func (st *PsqlStorage) GetLink(ctx context.Context, id repositories.ID) (link repositories.LinkData, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	row := st.queryRowContext(
+		ctx,
+		`SELECT id, url, user_id, team_id, deleted, expires_at, max_hits, hits, created_at FROM links WHERE id = $1`,
+		id,
+	)
+
+	link, err = scanLink(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repositories.LinkData{}, repositories.ErrURLNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return repositories.LinkData{}, err
+	}
+
+	return link, nil
+}
+
+// Iterate - обойти все ссылки в порядке возрастания ID, начиная со следующей после after.
+//
+// fn вызывается во время чтения результата запроса, соединение с базой при этом занято.
+func (st *PsqlStorage) Iterate(
+	ctx context.Context,
+	after repositories.ID,
+	fn func(link repositories.LinkData) error,
+) error {
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT id, url, user_id, team_id, deleted, expires_at, max_hits, hits, created_at
+         FROM links WHERE id > $1 ORDER BY id`,
+		after,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link repositories.LinkData
+		link, err = scanLink(rows)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return err
+		}
+
+		err = fn(link)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ListUserLinks - получить страницу личных ссылок пользователя или ссылок команды q.Team.
+//
+// Страница выбирается по курсору (created_at, id), поэтому использует индекс
+// links_user_id_created_at_idx (links_team_id_created_at_idx для команды) и не зависит от номера страницы.
+func (st *PsqlStorage) ListUserLinks(
+	ctx context.Context,
+	user repositories.User,
+	q repositories.LinkQuery,
+) (page repositories.LinkPage, err error) {
+	q, err = q.Normalize()
+	if err != nil {
+		return page, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	cmp, order := ">", "ASC"
+	if q.Order == repositories.SortDesc {
+		cmp, order = "<", "DESC"
+	}
+
+	owner := `user_id = $1 AND team_id IS NULL`
+	args := []any{user, q.Filter, q.Limit + 1}
+	if q.Team != uuid.Nil {
+		err = st.requireTeamRole(ctx, q.Team, user, repositories.RoleViewer)
+		if err != nil {
+			return page, err
+		}
+		owner = `team_id = $1`
+		args[0] = q.Team
+	}
+
+	query := `SELECT id, url, user_id, team_id, deleted, expires_at, max_hits, hits, created_at FROM links
+              WHERE ` + owner + ` AND deleted = FALSE AND strpos(url, $2) > 0`
+	if q.Cursor != "" {
+		c, _ := repositories.DecodeCursor(q.Cursor)
+		query += ` AND (created_at, id) ` + cmp + ` ($4, $5)`
+		args = append(args, c.CreatedAt, c.ID)
+	}
+	query += ` ORDER BY created_at ` + order + `, id ` + order + ` LIMIT $3`
+
+	rows, err := st.queryContext(ctx, query, args...)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return page, err
+	}
+	defer rows.Close()
+
+	page.Links = make([]repositories.LinkData, 0, q.Limit)
+	for rows.Next() {
+		var link repositories.LinkData
+		link, err = scanLink(rows)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return page, err
+		}
+
+		if len(page.Links) == q.Limit {
+			page.NextCursor = repositories.LinkCursor(page.Links[len(page.Links)-1]).Encode()
+			break
+		}
+		page.Links = append(page.Links, link)
+	}
+
+	return page, rows.Err()
+}
+
+// Import - сохранить ссылку со всеми данными как есть.
+func (st *PsqlStorage) Import(ctx context.Context, link repositories.LinkData) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(
+		ctx,
+		`INSERT INTO links (
+             id, url, user_id, deleted, expires_at, max_hits, hits, created_at, dedup_key, team_id, deleted_at
+         )
+         VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, now()), $9, $10, CASE WHEN $4 THEN COALESCE($11, now()) END)
+         ON CONFLICT (id) DO NOTHING`,
+		link.ID, link.URL, link.User, link.Deleted,
+		sql.NullTime{Time: link.ExpiresAt, Valid: !link.ExpiresAt.IsZero()}, link.MaxHits, link.Hits,
+		sql.NullTime{Time: link.CreatedAt, Valid: !link.CreatedAt.IsZero()},
+		st.dedupKey(link.URL, link.User), nullTeam(link.Team),
+		sql.NullTime{Time: link.DeletedAt, Valid: !link.DeletedAt.IsZero()},
+	)
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return repositories.ErrURLAlreadyExists
+	}
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff == 0 {
+		return repositories.ErrIDAlreadyExists
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLink(row scanner) (link repositories.LinkData, err error) {
+	var expiresAt sql.NullTime
+	var team uuid.NullUUID
+	err = row.Scan(
+		&link.ID, &link.URL, &link.User, &team, &link.Deleted, &expiresAt, &link.MaxHits, &link.Hits, &link.CreatedAt,
+	)
+	if err != nil {
+		return repositories.LinkData{}, err
+	}
+	link.ExpiresAt = expiresAt.Time
+	link.Team = team.UUID
+	return link, nil
+}
+
+// DeleteUserLinks - поставить ссылки пользователя в очередь на удаление.
+//
+// Когда метод вернул управление, запрос уже сохранен в базе данных
+// и будет применен, даже если сервис перезапустится.
+func (st *PsqlStorage) DeleteUserLinks(
+	ctx context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+) (deletion repositories.DeletionID, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	deletion = uuid.New()
+
+	_, err = st.execContext(
+		ctx,
+		`INSERT INTO pending_deletes (request_id, link_id, user_id) SELECT $1, unnest($2::text[]), $3`,
+		deletion, pq.Array(ids), user,
+	)
+	if err != nil {
+		st.log(ctx).Error("insert failed", zap.Error(err))
+		return uuid.Nil, err
+	}
+
+	select {
+	case st.deleteCh <- struct{}{}:
+	default:
+	}
+
+	return deletion, nil
+}
+
+// GetDeletionStatus - получить состояние запроса на удаление ссылок.
+func (st *PsqlStorage) GetDeletionStatus(
+	ctx context.Context,
+	deletion repositories.DeletionID,
+	user repositories.User,
+) (repositories.DeletionStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	var total, pending int
+	err := st.queryRowContext(
+		ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE applied_at IS NULL)
+         FROM pending_deletes WHERE request_id = $1 AND user_id = $2`,
+		deletion, user,
+	).Scan(&total, &pending)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return "", err
+	}
+
+	if total == 0 {
+		return "", repositories.ErrDeletionNotFound
+	}
+	if pending > 0 {
+		return repositories.DeletionPending, nil
+	}
+
+	return repositories.DeletionDone, nil
+}
+
+// PurgeExpired - удалить ссылки с истекшим сроком действия.
+func (st *PsqlStorage) PurgeExpired(ctx context.Context) (count int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(
+		ctx,
+		`DELETE FROM links WHERE expires_at <= now() OR (max_hits > 0 AND hits >= max_hits)`,
+	)
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// AddClicks - сохранить переходы по ссылкам.
+func (st *PsqlStorage) AddClicks(ctx context.Context, clicks []repositories.Click) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	ids := make([]repositories.ID, 0, len(clicks))
+	times := make([]string, 0, len(clicks))
+	referrers := make([]string, 0, len(clicks))
+	userAgents := make([]string, 0, len(clicks))
+	ips := make([]string, 0, len(clicks))
+	for _, click := range clicks {
+		ids = append(ids, click.ID)
+		times = append(times, click.Time.Format(time.RFC3339Nano))
+		referrers = append(referrers, click.Referrer)
+		userAgents = append(userAgents, click.UserAgent)
+		ips = append(ips, click.IP)
+	}
+
+	_, err := st.execContext(
+		ctx,
+		`INSERT INTO clicks (link_id, clicked_at, referrer, user_agent, ip)
+         SELECT data.link_id, data.clicked_at, data.referrer, data.user_agent, data.ip
+         FROM unnest($1::text[], $2::timestamptz[], $3::text[], $4::text[], $5::text[])
+             AS data(link_id, clicked_at, referrer, user_agent, ip)
+         WHERE EXISTS (SELECT 1 FROM links WHERE links.id = data.link_id)`,
+		pq.Array(ids), pq.Array(times), pq.Array(referrers), pq.Array(userAgents), pq.Array(ips),
+	)
+	if err != nil {
+		st.log(ctx).Error("insert failed", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
+// GetLinkStats - получить статистику переходов по ссылке пользователя или его команды.
+func (st *PsqlStorage) GetLinkStats(
+	ctx context.Context,
+	id repositories.ID,
+	user repositories.User,
+) (stats repositories.LinkStats, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	link, role, err := st.linkRole(ctx, id, user)
+	if err != nil {
+		return stats, err
+	}
+	if !repositories.LinkRole(link, user, role).Allows(repositories.RoleViewer) {
+		return stats, repositories.ErrUserNotMatch
+	}
+
+	err = st.queryRowContext(
+		ctx,
+		`SELECT COUNT(*), COUNT(DISTINCT ip) FROM clicks WHERE link_id = $1`,
+		id,
+	).Scan(&stats.Clicks, &stats.UniqueVisitors)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return stats, err
+	}
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT date_trunc('day', clicked_at AT TIME ZONE 'UTC') AS day, COUNT(*)
+         FROM clicks WHERE link_id = $1 GROUP BY day ORDER BY day`,
+		id,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return stats, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	stats.Days = make([]repositories.DayClicks, 0)
+	for rows.Next() {
+		var day repositories.DayClicks
+		err = rows.Scan(&day.Day, &day.Clicks)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return stats, err
+		}
+		day.Day = time.Date(day.Day.Year(), day.Day.Month(), day.Day.Day(), 0, 0, 0, 0, time.UTC)
+		stats.Days = append(stats.Days, day)
+	}
+	if err = rows.Err(); err != nil {
+		st.log(ctx).Error("rows failed", zap.Error(err))
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// CountUserLinks - посчитать активные ссылки пользователя и ссылки, созданные начиная с since.
+func (st *PsqlStorage) CountUserLinks(
+	ctx context.Context,
+	user repositories.User,
+	since time.Time,
+) (counts repositories.LinkCounts, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	row := st.queryRowContext(ctx,
+		`SELECT
+             COUNT(id) FILTER (
+                 WHERE NOT deleted
+                   AND (expires_at IS NULL OR expires_at > now())
+                   AND (max_hits = 0 OR hits < max_hits)
+             ) AS active_count,
+             COUNT(id) FILTER (WHERE created_at >= $2) AS created_count
+         FROM links WHERE user_id = $1`,
+		user, since,
+	)
+
+	err = row.Scan(&counts.Active, &counts.CreatedSince)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return counts, err
+	}
+
+	return counts, nil
+}
+
 // GetStats - получить статистику сервиса.
 func (st *PsqlStorage) GetStats(ctx context.Context) (repositories.ServiceStats, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
@@ -188,13 +720,13 @@ func (st *PsqlStorage) GetStats(ctx context.Context) (repositories.ServiceStats,
 
 	stats := repositories.ServiceStats{}
 
-	row := st.db.QueryRowContext(ctx,
+	row := st.queryRowContext(ctx,
 		`SELECT COUNT(id) AS links_count, COUNT(DISTINCT user_id) AS users_count FROM links`,
 	)
 
 	err := row.Scan(&stats.URLs, &stats.Users)
 	if err != nil {
-		log.Printf("query failed: %v", err)
+		st.log(ctx).Error("query failed", zap.Error(err))
 		return stats, err
 	}
 
@@ -210,7 +742,7 @@ func (st *PsqlStorage) Pool(ctx context.Context) (ok bool) {
 }
 
 // Close - мягко завершить работу хранилища.
-func (st *PsqlStorage) Close(_ context.Context) error {
+func (st *PsqlStorage) Close(ctx context.Context) error {
 	close(st.deleteShutdown)
 
 	c := make(chan struct{})
@@ -221,17 +753,23 @@ func (st *PsqlStorage) Close(_ context.Context) error {
 	select {
 	case <-c:
 	case <-time.After(shutdownTimeout):
-		log.Print("storage close timeout exceed")
+		st.log(ctx).Warn("storage close timeout exceed")
 	}
 
 	return st.db.Close()
 }
 
+// log - логгер хранилища с полями запроса из ctx.
+func (st *PsqlStorage) log(ctx context.Context) *zap.Logger {
+	return logger.Ctx(ctx, st.logger)
+}
+
 func (st *PsqlStorage) doMigrate(dsn string) error {
-	m, err := migrate.New("file://migrations/postgres", dsn)
+	m, err := NewMigrator(dsn)
 	if err != nil {
 		return err
 	}
+	defer func() { _, _ = m.Close() }()
 
 	err = m.Up()
 	if errors.Is(err, migrate.ErrNoChange) {
@@ -242,62 +780,120 @@ func (st *PsqlStorage) doMigrate(dsn string) error {
 }
 
 func (st *PsqlStorage) deleteUserLinksWorker(ctx context.Context, bufferSize int, bufferTimeout time.Duration) {
-	ids := make([]repositories.ID, 0, bufferSize)
-	users := make([]repositories.User, 0, bufferSize)
+	defer st.deleteWg.Done()
+
+	backoff := bufferTimeout
+	var lastCleanup time.Time
 
-worker:
 	for {
+		applied, err := st.applyPendingDeletes(ctx, bufferSize)
+		if err != nil {
+			st.log(ctx).Error("apply pending deletes failed", zap.Duration("retry_in", backoff), zap.Error(err))
+		} else if time.Since(lastCleanup) > time.Hour {
+			st.cleanupAppliedDeletes(ctx)
+			lastCleanup = time.Now()
+		}
+
+		wait := bufferTimeout
+		switch {
+		case err != nil:
+			wait = backoff
+			backoff *= 2
+			if backoff > deleteRetryMaxBackoff {
+				backoff = deleteRetryMaxBackoff
+			}
+		case applied == bufferSize:
+			// В очереди могут остаться еще записи, забираем их сразу.
+			backoff = bufferTimeout
+			wait = 0
+		default:
+			backoff = bufferTimeout
+		}
+
 		select {
 		case <-st.deleteShutdown:
-			break worker
+			return
 		case <-ctx.Done():
-			break worker
+			return
 		default:
 		}
 
-		ids = ids[:0]
-		users = users[:0]
-
-		timeoutCtx, timeoutCancel := context.WithTimeout(ctx, bufferTimeout)
-
-	loop:
-		for {
-			select {
-			case v := <-st.deleteCh:
-				ids = append(ids, v.ID)
-				users = append(users, v.User)
-				if len(ids) == bufferSize {
-					timeoutCancel()
-					break loop
-				}
-			case <-timeoutCtx.Done():
-				timeoutCancel()
-				break loop
-			case <-st.deleteShutdown:
-				timeoutCancel()
-				break loop
-			}
+		if wait == 0 {
+			continue
 		}
 
-		if len(ids) == 0 {
-			continue
+		timer := time.NewTimer(wait)
+		select {
+		case <-st.deleteCh:
+		case <-timer.C:
+		case <-st.deleteShutdown:
+			timer.Stop()
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			return
 		}
+		timer.Stop()
+	}
+}
 
-		ctxLocal, cancelLocal := context.WithTimeout(ctx, time.Second*10)
+// applyPendingDeletes - применить пачку запросов на удаление из pending_deletes.
+//
+// Ссылку команды удаляют, только если автор запроса - ее редактор или владелец на момент применения.
+// Удаление ссылок и отметка о применении выполняются одним запросом, поэтому
+// при ошибке записи остаются в очереди и будут применены при следующей попытке.
+func (st *PsqlStorage) applyPendingDeletes(ctx context.Context, bufferSize int) (applied int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
 
-		_, err := st.db.ExecContext(
-			ctxLocal,
-			`UPDATE links SET deleted = TRUE 
-             FROM (select unnest($1::text[]) as id, unnest($2::uuid[]) as user) as data_table
-             WHERE links.id = data_table.id AND user_id = data_table.user`,
-			pq.Array(ids), pq.Array(users),
-		)
-		if err != nil {
-			log.Printf("update failed: %v", err)
-		}
+	res, err := st.execContext(
+		ctx,
+		`WITH batch AS (
+             SELECT ctid, link_id, user_id FROM pending_deletes
+             WHERE applied_at IS NULL
+             ORDER BY created_at
+             LIMIT $1
+             FOR UPDATE SKIP LOCKED
+         ), deleted AS (
+             UPDATE links SET deleted = TRUE, deleted_at = COALESCE(links.deleted_at, now())
+             FROM batch
+             WHERE links.id = batch.link_id AND (
+                 (links.team_id IS NULL AND links.user_id = batch.user_id)
+                 OR EXISTS (
+                     SELECT 1 FROM team_members
+                     WHERE team_members.team_id = links.team_id AND team_members.user_id = batch.user_id
+                       AND team_members.role IN ('owner', 'editor')
+                 )
+             )
+         )
+         UPDATE pending_deletes SET applied_at = now()
+         FROM batch
+         WHERE pending_deletes.ctid = batch.ctid`,
+		bufferSize,
+	)
+	if err != nil {
+		return 0, err
+	}
 
-		cancelLocal()
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
 	}
 
-	st.deleteWg.Done()
+	return int(aff), nil
+}
+
+// cleanupAppliedDeletes - удалить давно примененные запросы на удаление.
+func (st *PsqlStorage) cleanupAppliedDeletes(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	_, err := st.execContext(
+		ctx,
+		`DELETE FROM pending_deletes WHERE applied_at < $1`,
+		time.Now().Add(-deletionRetention),
+	)
+	if err != nil {
+		st.log(ctx).Error("cleanup applied deletes failed", zap.Error(err))
+	}
 }
@@ -3,17 +3,33 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
-
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/tracing"
+	"github.com/ImpressionableRaccoon/urlshortener/migrations"
 )
 
 func TestPsqlStorage_Add(t *testing.T) {
@@ -31,11 +47,30 @@ func TestPsqlStorage_Add(t *testing.T) {
 		ctx := context.Background()
 
 		mock.ExpectExec("INSERT").
-			WithArgs(sqlmock.AnyArg(), url, userID).
+			WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		_, err = st.Add(ctx, url, userID, repositories.LinkOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("long url", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+
+		url := "https://example.com/" + strings.Repeat("a", 300-len("https://example.com/"))
+		userID := uuid.New()
+
+		st := &PsqlStorage{db: db}
+
+		mock.ExpectExec("INSERT").
+			WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		_, err = st.Add(ctx, url, userID)
+		_, err = st.Add(context.Background(), url, userID, repositories.LinkOptions{})
 		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("id already exists", func(t *testing.T) {
@@ -52,14 +87,14 @@ func TestPsqlStorage_Add(t *testing.T) {
 		ctx := context.Background()
 
 		mock.ExpectExec("INSERT").
-			WithArgs(sqlmock.AnyArg(), url, userID).
+			WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
 			WillReturnResult(sqlmock.NewResult(1, 0))
 
 		mock.ExpectExec("INSERT").
-			WithArgs(sqlmock.AnyArg(), url, userID).
+			WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		_, err = st.Add(ctx, url, userID)
+		_, err = st.Add(ctx, url, userID, repositories.LinkOptions{})
 		assert.NoError(t, err)
 
 		err = mock.ExpectationsWereMet()
@@ -81,7 +116,7 @@ func TestPsqlStorage_Add(t *testing.T) {
 		ctx := context.Background()
 
 		mock.ExpectExec("INSERT").
-			WithArgs(sqlmock.AnyArg(), url, userID).
+			WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
 			WillReturnError(&pq.Error{Code: pgerrcode.UniqueViolation})
 
 		rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
@@ -89,7 +124,7 @@ func TestPsqlStorage_Add(t *testing.T) {
 			WithArgs(url).
 			WillReturnRows(rows)
 
-		linkID, err := st.Add(ctx, url, userID)
+		linkID, err := st.Add(ctx, url, userID, repositories.LinkOptions{})
 		assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
 		assert.Equal(t, id, linkID)
 
@@ -111,7 +146,7 @@ func TestPsqlStorage_Add(t *testing.T) {
 		ctx := context.Background()
 
 		mock.ExpectExec("INSERT").
-			WithArgs(sqlmock.AnyArg(), url, userID).
+			WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
 			WillReturnError(&pq.Error{Code: pgerrcode.UniqueViolation})
 
 		rows := sqlmock.NewRows([]string{"id"})
@@ -119,13 +154,67 @@ func TestPsqlStorage_Add(t *testing.T) {
 			WithArgs(url).
 			WillReturnRows(rows)
 
-		_, err = st.Add(ctx, url, userID)
+		_, err = st.Add(ctx, url, userID, repositories.LinkOptions{})
 		assert.ErrorIs(t, err, sql.ErrNoRows)
 
 		err = mock.ExpectationsWereMet()
 		assert.NoError(t, err)
 	})
 
+	t.Run("alias already exists", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		url := "https://example.com/spring"
+		userID := uuid.New()
+
+		st := &PsqlStorage{db: db}
+		ctx := context.Background()
+
+		mock.ExpectExec("INSERT").
+			WithArgs("spring-sale", url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
+			WillReturnResult(sqlmock.NewResult(1, 0))
+
+		_, err = st.Add(ctx, url, userID, repositories.LinkOptions{Alias: "spring-sale"})
+		assert.ErrorIs(t, err, repositories.ErrIDAlreadyExists)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("dedup modes", func(t *testing.T) {
+		url := "https://example.com/dedup"
+		userID := uuid.New()
+
+		tests := []struct {
+			dedup repositories.DedupMode
+			key   driver.Value
+		}{
+			{dedup: repositories.DedupPerUser, key: userID.String() + " " + url},
+			{dedup: repositories.DedupNone, key: nil},
+		}
+		for _, tt := range tests {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+
+			mock.ExpectExec("INSERT").
+				WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), tt.key, nil).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			st := &PsqlStorage{db: db, dedup: tt.dedup}
+			_, err = st.Add(context.Background(), url, userID, repositories.LinkOptions{})
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+
+			_ = db.Close()
+		}
+	})
+
 	t.Run("already exists", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
 		if err != nil {
@@ -140,12 +229,38 @@ func TestPsqlStorage_Add(t *testing.T) {
 		ctx := context.Background()
 
 		mock.ExpectExec("INSERT").
-			WithArgs(sqlmock.AnyArg(), url, userID).
+			WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
 			WillReturnError(errors.New("test"))
 
-		_, err = st.Add(ctx, url, userID)
+		_, err = st.Add(ctx, url, userID, repositories.LinkOptions{})
 		assert.Error(t, err)
 	})
+
+	t.Run("no free id", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		url := "https://go.dev"
+		userID := uuid.New()
+
+		st := &PsqlStorage{db: db, ids: idgen.NewHash(idgen.DefaultAlphabet, idgen.DefaultLength, 0)}
+		ctx := context.Background()
+
+		for i := 0; i < idgen.MaxAttempts; i++ {
+			mock.ExpectExec("INSERT").
+				WithArgs(sqlmock.AnyArg(), url, userID, sqlmock.AnyArg(), sqlmock.AnyArg(), url, nil).
+				WillReturnResult(sqlmock.NewResult(1, 0))
+		}
+
+		_, err = st.Add(ctx, url, userID, repositories.LinkOptions{})
+		assert.ErrorIs(t, err, repositories.ErrNoFreeID)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
 }
 
 func TestPsqlStorage_Get(t *testing.T) {
@@ -159,7 +274,8 @@ func TestPsqlStorage_Get(t *testing.T) {
 		url := "https://imgur.com"
 		id := "imgur"
 
-		rows := sqlmock.NewRows([]string{"url", "deleted"}).AddRow(url, false)
+		rows := sqlmock.NewRows([]string{"url", "deleted", "expires_at", "max_hits", "hits"}).
+			AddRow(url, false, nil, 0, 0)
 		mock.ExpectQuery("SELECT url, deleted").
 			WithArgs(id).
 			WillReturnRows(rows)
@@ -190,7 +306,7 @@ func TestPsqlStorage_Get(t *testing.T) {
 		ctx := context.Background()
 		_, deleted, err := st.Get(ctx, id)
 
-		assert.Error(t, err, sql.ErrNoRows)
+		assert.ErrorIs(t, err, repositories.ErrURLNotFound)
 		assert.False(t, deleted)
 	})
 
@@ -204,7 +320,8 @@ func TestPsqlStorage_Get(t *testing.T) {
 		url := "https://stackoverflow.com"
 		id := "stack"
 
-		rows := sqlmock.NewRows([]string{"url", "deleted"}).AddRow(url, true)
+		rows := sqlmock.NewRows([]string{"url", "deleted", "expires_at", "max_hits", "hits"}).
+			AddRow(url, true, nil, 0, 0)
 		mock.ExpectQuery("SELECT url, deleted").
 			WithArgs(id).
 			WillReturnRows(rows)
@@ -219,6 +336,106 @@ func TestPsqlStorage_Get(t *testing.T) {
 	})
 }
 
+func TestPsqlStorage_GetExpired(t *testing.T) {
+	t.Run("expired by time", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		id := "old"
+
+		rows := sqlmock.NewRows([]string{"url", "deleted", "expires_at", "max_hits", "hits"}).
+			AddRow("https://example.com", false, time.Now().Add(-time.Hour), 0, 0)
+		mock.ExpectQuery("SELECT url, deleted").
+			WithArgs(id).
+			WillReturnRows(rows)
+
+		st := &PsqlStorage{db: db}
+		_, _, err = st.Get(context.Background(), id)
+		assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("hit counted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		url := "https://example.com/limited"
+		id := "limit"
+
+		rows := sqlmock.NewRows([]string{"url", "deleted", "expires_at", "max_hits", "hits"}).
+			AddRow(url, false, nil, 2, 1)
+		mock.ExpectQuery("SELECT url, deleted").
+			WithArgs(id).
+			WillReturnRows(rows)
+		mock.ExpectExec("UPDATE links SET hits").
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		st := &PsqlStorage{db: db}
+		res, deleted, err := st.Get(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, url, res)
+		assert.False(t, deleted)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("hit limit reached concurrently", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		id := "limit"
+
+		rows := sqlmock.NewRows([]string{"url", "deleted", "expires_at", "max_hits", "hits"}).
+			AddRow("https://example.com/limited", false, nil, 2, 1)
+		mock.ExpectQuery("SELECT url, deleted").
+			WithArgs(id).
+			WillReturnRows(rows)
+		mock.ExpectExec("UPDATE links SET hits").
+			WithArgs(id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		st := &PsqlStorage{db: db}
+		_, _, err = st.Get(context.Background(), id)
+		assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+}
+
+func TestPsqlStorage_PurgeExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec("DELETE FROM links").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	st := &PsqlStorage{db: db}
+	count, err := st.PurgeExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
 func TestPsqlStorage_GetUserLinks(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		db, mock, err := sqlmock.New()
@@ -233,9 +450,9 @@ func TestPsqlStorage_GetUserLinks(t *testing.T) {
 			"mskls": "https://impressionableracoob.com/mskls",
 		}
 
-		rows := sqlmock.NewRows([]string{"id", "url"})
+		rows := sqlmock.NewRows([]string{"id", "url", "user_id", "team_id", "expires_at", "max_hits", "hits"})
 		for k, v := range data {
-			rows = rows.AddRow(k, v)
+			rows = rows.AddRow(k, v, uuid.New(), nil, nil, 0, 0)
 		}
 
 		mock.ExpectQuery("SELECT id, url").
@@ -291,25 +508,454 @@ func TestPsqlStorage_GetUserLinks(t *testing.T) {
 	})
 }
 
+func TestPsqlStorage_ListUserLinks(t *testing.T) {
+	linkColumns := []string{"id", "url", "user_id", "team_id", "deleted", "expires_at", "max_hits", "hits", "created_at"}
+	user := uuid.New()
+	createdAt := time.Unix(1700000000, 0)
+
+	t.Run("first page", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectQuery("SELECT id, url(.+)ORDER BY created_at ASC, id ASC LIMIT \\$3").
+			WithArgs(user, "example", 3).
+			WillReturnRows(sqlmock.NewRows(linkColumns).
+				AddRow("a", "https://example.com/a", user, nil, false, nil, 0, 0, createdAt).
+				AddRow("b", "https://example.com/b", user, nil, false, nil, 0, 0, createdAt).
+				AddRow("c", "https://example.com/c", user, nil, false, nil, 0, 0, createdAt.Add(time.Second)))
+
+		st := &PsqlStorage{db: db}
+		page, err := st.ListUserLinks(context.Background(), user, repositories.LinkQuery{
+			Filter: "example",
+			Order:  repositories.SortAsc,
+			Limit:  2,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, page.Links, 2)
+		assert.Equal(t, repositories.Cursor{CreatedAt: createdAt, ID: "b"}.Encode(), page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("next page", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectQuery("AND \\(created_at, id\\) < \\(\\$4, \\$5\\) ORDER BY created_at DESC, id DESC").
+			WithArgs(user, "", repositories.DefaultPageSize+1, createdAt, "b").
+			WillReturnRows(sqlmock.NewRows(linkColumns).
+				AddRow("a", "https://example.com/a", user, nil, false, nil, 0, 0, createdAt))
+
+		st := &PsqlStorage{db: db}
+		page, err := st.ListUserLinks(context.Background(), user, repositories.LinkQuery{
+			Cursor: repositories.Cursor{CreatedAt: createdAt, ID: "b"}.Encode(),
+		})
+		assert.NoError(t, err)
+		assert.Len(t, page.Links, 1)
+		assert.Empty(t, page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("wrong cursor", func(t *testing.T) {
+		st := &PsqlStorage{}
+		_, err := st.ListUserLinks(context.Background(), user, repositories.LinkQuery{Cursor: "!"})
+		assert.ErrorIs(t, err, repositories.ErrWrongCursor)
+	})
+}
+
+func TestPsqlStorage_GetLink(t *testing.T) {
+	linkColumns := []string{"id", "url", "user_id", "team_id", "deleted", "expires_at", "max_hits", "hits", "created_at"}
+
+	t.Run("ok", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		user := uuid.New()
+		mock.ExpectQuery("SELECT id, url, user_id").
+			WithArgs("smdlx").
+			WillReturnRows(sqlmock.NewRows(linkColumns).
+				AddRow("smdlx", "https://impressionableracoob.com", user, nil, true, nil, 5, 2, time.Unix(1700000000, 0)))
+
+		st := &PsqlStorage{db: db}
+		link, err := st.GetLink(context.Background(), "smdlx")
+		assert.NoError(t, err)
+		assert.Equal(t, repositories.LinkData{
+			ID:        "smdlx",
+			URL:       "https://impressionableracoob.com",
+			User:      user,
+			Deleted:   true,
+			MaxHits:   5,
+			Hits:      2,
+			CreatedAt: time.Unix(1700000000, 0),
+		}, link)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectQuery("SELECT id, url, user_id").
+			WithArgs("smdlx").
+			WillReturnError(sql.ErrNoRows)
+
+		st := &PsqlStorage{db: db}
+		_, err = st.GetLink(context.Background(), "smdlx")
+		assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	})
+}
+
+func TestPsqlStorage_Iterate(t *testing.T) {
+	linkColumns := []string{"id", "url", "user_id", "team_id", "deleted", "expires_at", "max_hits", "hits", "created_at"}
+
+	t.Run("ok", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		user := uuid.New()
+		mock.ExpectQuery("SELECT id, url, user_id(.+)WHERE id > \\$1 ORDER BY id").
+			WithArgs("a").
+			WillReturnRows(sqlmock.NewRows(linkColumns).
+				AddRow("b", "https://impressionableracoob.com/b", user, nil, false, nil, 0, 0, time.Now()).
+				AddRow("c", "https://impressionableracoob.com/c", user, nil, true, time.Now(), 0, 0, time.Now()))
+
+		st := &PsqlStorage{db: db}
+		var ids []repositories.ID
+		err = st.Iterate(context.Background(), "a", func(link repositories.LinkData) error {
+			ids = append(ids, link.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []repositories.ID{"b", "c"}, ids)
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectQuery("SELECT id, url, user_id").
+			WithArgs("").
+			WillReturnRows(sqlmock.NewRows(linkColumns).
+				AddRow("b", "https://impressionableracoob.com/b", uuid.New(), nil, false, nil, 0, 0, time.Now()))
+
+		st := &PsqlStorage{db: db}
+		wantErr := errors.New("stop")
+		err = st.Iterate(context.Background(), "", func(repositories.LinkData) error {
+			return wantErr
+		})
+		assert.ErrorIs(t, err, wantErr)
+	})
+}
+
+func TestPsqlStorage_Import(t *testing.T) {
+	link := repositories.LinkData{
+		ID:   "smdlx",
+		URL:  "https://impressionableracoob.com",
+		User: uuid.New(),
+	}
+
+	tests := []struct {
+		name    string
+		result  driver.Result
+		err     error
+		wantErr error
+	}{
+		{
+			name:   "ok",
+			result: sqlmock.NewResult(1, 1),
+		},
+		{
+			name:    "id already exists",
+			result:  sqlmock.NewResult(1, 0),
+			wantErr: repositories.ErrIDAlreadyExists,
+		},
+		{
+			name:    "url already exists",
+			err:     &pq.Error{Code: pgerrcode.UniqueViolation},
+			wantErr: repositories.ErrURLAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer func() { _ = db.Close() }()
+
+			exec := mock.ExpectExec("INSERT INTO links").
+				WithArgs(link.ID, link.URL, link.User, false, sqlmock.AnyArg(), 0, 0, sqlmock.AnyArg(), link.URL, nil, nil)
+			if tt.err != nil {
+				exec.WillReturnError(tt.err)
+			} else {
+				exec.WillReturnResult(tt.result)
+			}
+
+			st := &PsqlStorage{db: db}
+			err = st.Import(context.Background(), link)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestPsqlStorage_DeleteUserLinks(t *testing.T) {
-	st := &PsqlStorage{
-		deleteCh: make(chan repositories.LinkData),
+	t.Run("ok", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		ids := []repositories.ID{"link1", "link2", "link3"}
+		userID := uuid.New()
+
+		mock.ExpectExec("INSERT INTO pending_deletes").
+			WithArgs(sqlmock.AnyArg(), pq.Array(ids), userID).
+			WillReturnResult(sqlmock.NewResult(0, int64(len(ids))))
+
+		st := &PsqlStorage{
+			db:       db,
+			deleteCh: make(chan struct{}, 1),
+		}
+
+		deletion, err := st.DeleteUserLinks(context.Background(), ids, userID)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, deletion)
+		assert.Len(t, st.deleteCh, 1)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("insert failed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectExec("INSERT INTO pending_deletes").
+			WillReturnError(sql.ErrConnDone)
+
+		st := &PsqlStorage{
+			db:       db,
+			deleteCh: make(chan struct{}, 1),
+		}
+
+		_, err = st.DeleteUserLinks(context.Background(), []repositories.ID{"link1"}, uuid.New())
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		assert.Len(t, st.deleteCh, 0)
+	})
+}
+
+func TestPsqlStorage_GetDeletionStatus(t *testing.T) {
+	tests := []struct {
+		err     error
+		name    string
+		status  repositories.DeletionStatus
+		total   int
+		pending int
+	}{
+		{name: "pending", total: 3, pending: 1, status: repositories.DeletionPending},
+		{name: "done", total: 3, pending: 0, status: repositories.DeletionDone},
+		{name: "not found", total: 0, pending: 0, err: repositories.ErrDeletionNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer func() { _ = db.Close() }()
+
+			deletion := uuid.New()
+			userID := uuid.New()
+
+			mock.ExpectQuery("SELECT COUNT").
+				WithArgs(deletion, userID).
+				WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(tt.total, tt.pending))
+
+			st := &PsqlStorage{db: db}
+			status, err := st.GetDeletionStatus(context.Background(), deletion, userID)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.status, status)
+		})
 	}
+}
 
-	ctx := context.Background()
-	ids := []repositories.ID{"link1", "link2", "link3"}
-	userID := uuid.New()
+func TestPsqlStorage_applyPendingDeletes(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectExec("WITH batch AS").
+			WithArgs(deleteBufferSize).
+			WillReturnResult(sqlmock.NewResult(0, 42))
+
+		st := &PsqlStorage{db: db}
+		applied, err := st.applyPendingDeletes(context.Background(), deleteBufferSize)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, applied)
+	})
 
-	go func() {
-		for i := 0; i < len(ids); i++ {
-			link := <-st.deleteCh
-			assert.Equal(t, link.ID, ids[i])
-			assert.Equal(t, link.User, userID)
+	t.Run("update failed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
-	}()
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectExec("WITH batch AS").
+			WillReturnError(errors.New("test"))
+
+		st := &PsqlStorage{db: db}
+		_, err = st.applyPendingDeletes(context.Background(), deleteBufferSize)
+		assert.Error(t, err)
+	})
+}
+
+func TestPsqlStorage_deleteUserLinksWorker(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// Первая попытка падает, вторая применяет удаление, после чего воркер чистит старые записи.
+	mock.ExpectExec("WITH batch AS").WillReturnError(errors.New("test"))
+	mock.ExpectExec("WITH batch AS").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM pending_deletes").WillReturnResult(sqlmock.NewResult(0, 0))
 
-	err := st.DeleteUserLinks(ctx, ids, userID)
+	st := &PsqlStorage{
+		db:             db,
+		deleteCh:       make(chan struct{}, 1),
+		deleteShutdown: make(chan struct{}),
+	}
+
+	st.deleteWg.Add(1)
+	go st.deleteUserLinksWorker(context.Background(), deleteBufferSize, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return mock.ExpectationsWereMet() == nil
+	}, time.Second, 10*time.Millisecond)
+
+	close(st.deleteShutdown)
+	st.deleteWg.Wait()
+}
+
+func TestPsqlStorage_AddClicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec("INSERT INTO clicks").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	st := &PsqlStorage{db: db}
+	err = st.AddClicks(context.Background(), []repositories.Click{
+		{ID: "link1", Time: time.Now(), IP: "10.0.0.1"},
+		{ID: "link2", Time: time.Now(), IP: "10.0.0.2"},
+	})
 	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestPsqlStorage_GetLinkStats(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		id := "stats"
+		user := uuid.New()
+		day := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		mock.ExpectQuery("SELECT links.user_id").
+			WithArgs(id, user).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_id", "role"}).AddRow(user, nil, ""))
+		mock.ExpectQuery("SELECT COUNT").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "count"}).AddRow(3, 2))
+		mock.ExpectQuery("SELECT date_trunc").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"day", "count"}).AddRow(day, 3))
+
+		st := &PsqlStorage{db: db}
+		stats, err := st.GetLinkStats(context.Background(), id, user)
+
+		assert.NoError(t, err)
+		assert.Equal(t, repositories.LinkStats{
+			Days:           []repositories.DayClicks{{Day: day, Clicks: 3}},
+			Clicks:         3,
+			UniqueVisitors: 2,
+		}, stats)
+
+		err = mock.ExpectationsWereMet()
+		assert.NoError(t, err)
+	})
+
+	t.Run("another user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		id := "stats"
+
+		mock.ExpectQuery("SELECT links.user_id").
+			WithArgs(id, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_id", "role"}).AddRow(uuid.New(), nil, ""))
+
+		st := &PsqlStorage{db: db}
+		_, err = st.GetLinkStats(context.Background(), id, uuid.New())
+		assert.ErrorIs(t, err, repositories.ErrUserNotMatch)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectQuery("SELECT links.user_id").
+			WillReturnError(sql.ErrNoRows)
+
+		st := &PsqlStorage{db: db}
+		_, err = st.GetLinkStats(context.Background(), "none", uuid.New())
+		assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	})
 }
 
 func TestPsqlStorage_GetStats(t *testing.T) {
@@ -335,6 +981,27 @@ func TestPsqlStorage_GetStats(t *testing.T) {
 	assert.Equal(t, exp, stats)
 }
 
+func TestPsqlStorage_CountUserLinks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	user := uuid.New()
+	since := time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"active_count", "created_count"}).AddRow(7, 2)
+	mock.ExpectQuery("SELECT").WithArgs(user, since).WillReturnRows(rows)
+
+	st := &PsqlStorage{db: db}
+	counts, err := st.CountUserLinks(context.Background(), user, since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, repositories.LinkCounts{Active: 7, CreatedSince: 2}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPsqlStorage_Pool(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
@@ -457,3 +1124,657 @@ func TestPsqlStorage_doMigrate(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestPsqlStorage_syncDedup(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   repositories.DedupMode
+		stored string // Режим в таблице meta, пустая строка - записи нет.
+		rekey  string // Фрагмент запроса пересчета, пустая строка - пересчета нет.
+	}{
+		{
+			name:   "same mode",
+			mode:   repositories.DedupGlobal,
+			stored: "global",
+		},
+		{
+			name:  "mode is not stored",
+			mode:  repositories.DedupGlobal,
+			rekey: "PARTITION BY url ORDER BY",
+		},
+		{
+			name:   "global to per-user",
+			mode:   repositories.DedupPerUser,
+			stored: "global",
+			rekey:  "PARTITION BY user_id::text || ' ' || url ORDER BY",
+		},
+		{
+			name:   "per-user to global",
+			mode:   repositories.DedupGlobal,
+			stored: "per-user",
+			rekey:  "PARTITION BY url ORDER BY",
+		},
+		{
+			name:   "global to none",
+			mode:   repositories.DedupNone,
+			stored: "global",
+			rekey:  "SET dedup_key = NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+				func(expected, actual string) error {
+					if !strings.Contains(actual, expected) {
+						return fmt.Errorf("query %q does not contain %q", actual, expected)
+					}
+					return nil
+				})))
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("LOCK TABLE meta").WillReturnResult(sqlmock.NewResult(0, 0))
+			rows := sqlmock.NewRows([]string{"value"})
+			if tt.stored != "" {
+				rows.AddRow(tt.stored)
+			}
+			mock.ExpectQuery("SELECT value FROM meta").WithArgs(dedupModeKey).WillReturnRows(rows)
+			if tt.rekey != "" {
+				mock.ExpectExec(tt.rekey).WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec("INSERT INTO meta").
+					WithArgs(dedupModeKey, string(tt.mode)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			st := &PsqlStorage{db: db, dedup: tt.mode}
+			require.NoError(t, st.syncDedup(context.Background()))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("update failed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer func() { _ = db.Close() }()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE meta").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT value FROM meta").
+			WithArgs(dedupModeKey).
+			WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow("global"))
+		mock.ExpectExec("UPDATE links").WillReturnError(errors.New("test"))
+		mock.ExpectRollback()
+
+		st := &PsqlStorage{db: db, dedup: repositories.DedupPerUser}
+		assert.Error(t, st.syncDedup(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewMigrator(t *testing.T) {
+	t.Run("embedded migrations", func(t *testing.T) {
+		src, err := iofs.New(migrations.Postgres, "postgres")
+		require.NoError(t, err)
+		defer func() { _ = src.Close() }()
+
+		version, err := src.First()
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), version)
+
+		for {
+			for _, read := range []func(uint) (io.ReadCloser, string, error){src.ReadUp, src.ReadDown} {
+				r, _, err := read(version)
+				require.NoError(t, err, "version %d", version)
+				_ = r.Close()
+			}
+
+			version, err = src.Next(version)
+			if errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("url column is not limited", func(t *testing.T) {
+		// URLMaxLength по умолчанию - 2048 байт, а в первой миграции links.url - varchar(255).
+		up, err := fs.ReadFile(migrations.Postgres, "postgres/000012_text_url_for_links.up.sql")
+		require.NoError(t, err)
+		assert.Contains(t, string(up), "ALTER COLUMN url TYPE TEXT")
+	})
+
+	t.Run("wrong dsn", func(t *testing.T) {
+		_, err := NewMigrator("wrongDSN")
+		assert.Error(t, err)
+	})
+}
+
+func TestPsqlStorage_tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectQuery("SELECT id, url, user_id").
+		WithArgs("smdlx").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT id, url, user_id").
+		WithArgs("smdlx").
+		WillReturnError(sql.ErrNoRows)
+
+	st := &PsqlStorage{db: db}
+
+	_, err = st.GetLink(context.Background(), "smdlx")
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	assert.Empty(t, recorder.Ended())
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+	_, err = st.GetLink(ctx, "smdlx")
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "postgres SELECT", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), semconv.DBSystemPostgreSQL)
+}
+
+func TestPsqlStorage_AcceptInvite(t *testing.T) {
+	id, team, user := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	columns := []string{"id", "team_id", "role", "created_at", "expires_at", "used_at", "used_by", "revoked_at"}
+	invite := func(usedAt, revokedAt interface{}, expiresAt time.Time) *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow(id, team, repositories.RoleViewer, now, expiresAt, usedAt, nil, revokedAt)
+	}
+
+	tests := []struct {
+		name   string
+		invite *sqlmock.Rows
+		role   *sqlmock.Rows
+		join   bool
+		err    error
+	}{
+		{
+			name:   "new member",
+			invite: invite(nil, nil, now.Add(time.Hour)),
+			role:   sqlmock.NewRows([]string{"role"}),
+			join:   true,
+		},
+		{
+			name:   "member keeps role",
+			invite: invite(nil, nil, now.Add(time.Hour)),
+			role:   sqlmock.NewRows([]string{"role"}).AddRow(repositories.RoleEditor),
+		},
+		{name: "not found", invite: sqlmock.NewRows(columns), err: repositories.ErrInviteNotFound},
+		{name: "used", invite: invite(now, nil, now.Add(time.Hour)), err: repositories.ErrInviteUsed},
+		{name: "revoked", invite: invite(nil, now, now.Add(time.Hour)), err: repositories.ErrInviteRevoked},
+		{name: "expired", invite: invite(nil, nil, now.Add(-time.Hour)), err: repositories.ErrInviteExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer func() { _ = db.Close() }()
+
+			st := &PsqlStorage{db: db}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT (.+) FROM team_invites WHERE id = (.+) FOR UPDATE").
+				WithArgs(id).
+				WillReturnRows(tt.invite)
+			if tt.err == nil {
+				mock.ExpectExec("UPDATE team_invites SET used_at").
+					WithArgs(id, now, user).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT role FROM team_members").
+					WithArgs(team, user).
+					WillReturnRows(tt.role)
+			}
+			if tt.join {
+				mock.ExpectExec("INSERT INTO team_members").
+					WithArgs(team, user, repositories.RoleViewer).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if tt.err == nil {
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			got, err := st.AcceptInvite(context.Background(), id, user, now)
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err == nil {
+				assert.Equal(t, user, got.UsedBy)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPsqlStorage_SetTeamMember(t *testing.T) {
+	team, user := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name     string
+		affected int64
+		role     *sqlmock.Rows
+		err      error
+	}{
+		{name: "ok", affected: 1},
+		{name: "team not found", role: sqlmock.NewRows([]string{"role"}), err: repositories.ErrTeamNotFound},
+		{
+			name: "last owner",
+			role: sqlmock.NewRows([]string{"role"}).AddRow(repositories.RoleOwner),
+			err:  repositories.ErrLastOwner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			defer func() { _ = db.Close() }()
+
+			st := &PsqlStorage{db: db}
+
+			mock.ExpectExec("INSERT INTO team_members").
+				WithArgs(team, user, repositories.RoleEditor).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.role != nil {
+				mock.ExpectQuery("SELECT role FROM team_members").
+					WithArgs(team, user).
+					WillReturnRows(tt.role)
+			}
+
+			err = st.SetTeamMember(context.Background(), team, user, repositories.RoleEditor)
+			assert.ErrorIs(t, err, tt.err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPsqlStorage_Update(t *testing.T) {
+	id, user := "abc", uuid.New()
+	url := "https://example.com/new"
+
+	t.Run("ok", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		st := &PsqlStorage{db: db}
+
+		mock.ExpectQuery("SELECT links.user_id").
+			WithArgs(id, user).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_id", "role"}).AddRow(user, nil, ""))
+		mock.ExpectExec("INSERT INTO link_versions").
+			WithArgs(id, url, sqlmock.AnyArg(), user).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT id, url").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "url", "user_id", "team_id", "deleted", "expires_at", "max_hits", "hits", "created_at",
+			}).AddRow(id, url, user, nil, false, nil, 0, 0, time.Now()))
+
+		link, err := st.Update(context.Background(), id, url, user)
+		assert.NoError(t, err)
+		assert.Equal(t, url, link.URL)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not match", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		st := &PsqlStorage{db: db}
+
+		mock.ExpectQuery("SELECT links.user_id").
+			WithArgs(id, user).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "team_id", "role"}).AddRow(uuid.New(), nil, ""))
+
+		_, err = st.Update(context.Background(), id, url, user)
+		assert.ErrorIs(t, err, repositories.ErrUserNotMatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPsqlStorage_RestoreUserLinks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	user := uuid.New()
+	since := time.Now().Add(-time.Hour)
+	ids := []repositories.ID{"abc", "def"}
+
+	mock.ExpectQuery("UPDATE links SET deleted = FALSE").
+		WithArgs(pq.Array(ids), user, since).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("abc"))
+
+	st := &PsqlStorage{db: db}
+	restored, err := st.RestoreUserLinks(context.Background(), ids, user, since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []repositories.ID{"abc"}, restored)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPsqlStorage_PurgeDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	before := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectExec("DELETE FROM links WHERE deleted").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	st := &PsqlStorage{db: db}
+	count, err := st.PurgeDeleted(context.Background(), before)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPsqlStorage_AddBatch(t *testing.T) {
+	user := uuid.New()
+	gen := idgen.NewHash(idgen.DefaultAlphabet, idgen.DefaultLength, 0)
+	links := []repositories.BatchLink{
+		{URL: "https://example.com/1", Opts: repositories.LinkOptions{Alias: "first"}},
+		{URL: "https://example.com/2"},
+		{URL: "https://example.com/3", Opts: repositories.LinkOptions{Alias: "taken"}},
+		{URL: "https://example.com/4"},
+	}
+	generated, err := gen.Generate(links[1].URL, 0)
+	assert.NoError(t, err)
+	collided, err := gen.Generate(links[3].URL, 0)
+	assert.NoError(t, err)
+	retried, err := gen.Generate(links[3].URL, 1)
+	assert.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	st := &PsqlStorage{db: db, dedup: repositories.DedupPerUser, ids: gen}
+
+	mock.ExpectQuery("INSERT INTO links").
+		WithArgs(pq.Array([]string{"first", generated, "taken", collided}), sqlmock.AnyArg(), user,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("first").AddRow(generated))
+	mock.ExpectQuery("SELECT dedup_key, id FROM links").
+		WillReturnRows(sqlmock.NewRows([]string{"dedup_key", "id"}))
+	mock.ExpectQuery("INSERT INTO links").
+		WithArgs(pq.Array([]string{retried}), sqlmock.AnyArg(), user,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(retried))
+
+	results, err := st.AddBatch(context.Background(), links, user)
+	assert.NoError(t, err)
+	assert.Equal(t, []repositories.BatchResult{
+		{ID: "first"},
+		{ID: generated},
+		{Err: repositories.ErrIDAlreadyExists},
+		{ID: retried},
+	}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPsqlStorage_CreateAccount(t *testing.T) {
+	account := repositories.Account{
+		ID:           uuid.New(),
+		Login:        "john",
+		PasswordHash: []byte("hash"),
+		CreatedAt:    time.Now(),
+	}
+
+	tests := []struct {
+		name  string
+		dbErr error
+		err   error
+	}{
+		{name: "ok"},
+		{name: "login taken", dbErr: &pq.Error{Code: pgerrcode.UniqueViolation}, err: repositories.ErrLoginTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			st := &PsqlStorage{db: db}
+
+			exec := mock.ExpectExec("INSERT INTO accounts").
+				WithArgs(account.ID, account.Login, account.PasswordHash, account.CreatedAt)
+			if tt.dbErr != nil {
+				exec.WillReturnError(tt.dbErr)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			err = st.CreateAccount(context.Background(), account)
+			assert.ErrorIs(t, err, tt.err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPsqlStorage_GetAPIKey(t *testing.T) {
+	id, account := uuid.New(), uuid.New()
+	hash := []byte("hash")
+	columns := []string{"id", "account_id", "name", "prefix", "hash", "scope", "created_at", "revoked_at"}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	st := &PsqlStorage{db: db}
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE hash").
+		WithArgs(hash).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(id, account, "ci", "usk_12345678", hash, "read", time.Now(), nil))
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE hash").
+		WithArgs(hash).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	key, err := st.GetAPIKey(context.Background(), hash)
+	require.NoError(t, err)
+	assert.Equal(t, id, key.ID)
+	assert.Equal(t, account, key.Account)
+	assert.Equal(t, repositories.ScopeRead, key.Scope)
+	assert.False(t, key.Revoked())
+
+	_, err = st.GetAPIKey(context.Background(), hash)
+	assert.ErrorIs(t, err, repositories.ErrAPIKeyNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPsqlStorage_RevokeAPIKey(t *testing.T) {
+	id, account := uuid.New(), uuid.New()
+	at := time.Now()
+
+	tests := []struct {
+		name     string
+		affected int64
+		err      error
+	}{
+		{name: "ok", affected: 1},
+		{name: "not found", affected: 0, err: repositories.ErrAPIKeyNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			st := &PsqlStorage{db: db}
+
+			mock.ExpectExec("UPDATE api_keys SET revoked_at").
+				WithArgs(id, account, at).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err = st.RevokeAPIKey(context.Background(), id, account, at)
+			assert.ErrorIs(t, err, tt.err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPsqlStorage_GetAccount(t *testing.T) {
+	id := uuid.New()
+	revokedAt := time.Now()
+	columns := []string{"id", "login", "password_hash", "created_at", "sessions_revoked_at"}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	st := &PsqlStorage{db: db}
+
+	mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "john", []byte("hash"), time.Now(), revokedAt))
+	mock.ExpectQuery("SELECT (.+) FROM accounts WHERE id").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	account, err := st.GetAccount(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "john", account.Login)
+	assert.Equal(t, revokedAt, account.SessionsRevokedAt)
+
+	_, err = st.GetAccount(context.Background(), id)
+	assert.ErrorIs(t, err, repositories.ErrAccountNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPsqlStorage_RevokeSessions(t *testing.T) {
+	account := uuid.New()
+	at := time.Now()
+
+	tests := []struct {
+		name     string
+		affected int64
+		err      error
+	}{
+		{name: "ok", affected: 1},
+		{name: "not found", affected: 0, err: repositories.ErrAccountNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			st := &PsqlStorage{db: db}
+
+			mock.ExpectExec("UPDATE accounts SET sessions_revoked_at").
+				WithArgs(account, at).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err = st.RevokeSessions(context.Background(), account, at)
+			assert.ErrorIs(t, err, tt.err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPsqlStorage_ClaimLinks(t *testing.T) {
+	from, to := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name  string
+		dedup repositories.DedupMode
+		query string
+	}{
+		{name: "global", dedup: repositories.DedupGlobal, query: "UPDATE links SET user_id = (.+) WHERE user_id"},
+		{name: "per user", dedup: repositories.DedupPerUser, query: "UPDATE links SET user_id = (.+), dedup_key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			st := &PsqlStorage{db: db, dedup: tt.dedup}
+
+			mock.ExpectQuery(tt.query).
+				WithArgs(from, to).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("abc").AddRow("def"))
+
+			claimed, err := st.ClaimLinks(context.Background(), from, to)
+			require.NoError(t, err)
+			assert.Equal(t, []repositories.ID{"abc", "def"}, claimed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPsqlStorage_AddQuota(t *testing.T) {
+	user := uuid.New()
+	since := time.Now().UTC().Truncate(24 * time.Hour)
+	q := repositories.Quota{Since: since, MaxLinks: 3}
+
+	tests := []struct {
+		name   string
+		active int64
+		err    error
+	}{
+		{name: "ok", active: 2},
+		{name: "exceeded", active: 3, err: repositories.ErrMaxLinks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer func() { _ = db.Close() }()
+
+			st := &PsqlStorage{db: db, ids: idgen.Default}
+
+			mock.ExpectBegin()
+			mock.ExpectExec("SELECT pg_advisory_xact_lock").
+				WithArgs("quota:" + user.String()).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT (.+) FROM links WHERE user_id").
+				WithArgs(user, since).
+				WillReturnRows(sqlmock.NewRows([]string{"active_count", "created_count"}).AddRow(tt.active, 0))
+			if tt.err == nil {
+				mock.ExpectExec("INSERT INTO links").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			_, err = st.Add(context.Background(), "https://example.com", user, repositories.LinkOptions{Quota: q})
+			assert.ErrorIs(t, err, tt.err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
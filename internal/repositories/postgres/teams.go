@@ -0,0 +1,483 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// CreateTeam - создать команду, owner становится ее владельцем.
+func (st *PsqlStorage) CreateTeam(ctx context.Context, team repositories.Team, owner repositories.User) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	_, err := st.execContext(
+		ctx,
+		`WITH team AS (
+             INSERT INTO teams (id, name, created_at) VALUES ($1, $2, $3)
+         )
+         INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $4, $5)`,
+		team.ID, team.Name, team.CreatedAt, owner, repositories.RoleOwner,
+	)
+	if err != nil {
+		st.log(ctx).Error("insert failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetTeamRole - получить роль пользователя в команде.
+func (st *PsqlStorage) GetTeamRole(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+) (role repositories.Role, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	err = st.queryRowContext(
+		ctx,
+		`SELECT role FROM team_members WHERE team_id = $1 AND user_id = $2`,
+		team, user,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", repositories.ErrTeamNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return "", err
+	}
+
+	return role, nil
+}
+
+// GetUserTeams - получить команды, в которых состоит пользователь, в порядке создания.
+func (st *PsqlStorage) GetUserTeams(
+	ctx context.Context,
+	user repositories.User,
+) (teams []repositories.Membership, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT teams.id, teams.name, teams.created_at, team_members.role
+         FROM team_members JOIN teams ON teams.id = team_members.team_id
+         WHERE team_members.user_id = $1
+         ORDER BY teams.created_at, teams.id`,
+		user,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams = make([]repositories.Membership, 0)
+	for rows.Next() {
+		var m repositories.Membership
+		err = rows.Scan(&m.ID, &m.Name, &m.CreatedAt, &m.Role)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		teams = append(teams, m)
+	}
+
+	return teams, rows.Err()
+}
+
+// GetTeamMembers - получить участников команды: сначала старшие роли, внутри роли - по ID пользователя.
+func (st *PsqlStorage) GetTeamMembers(
+	ctx context.Context,
+	team repositories.TeamID,
+) (members []repositories.TeamMember, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT user_id, role FROM team_members WHERE team_id = $1
+         ORDER BY CASE role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, user_id::text`,
+		team,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	members = make([]repositories.TeamMember, 0)
+	for rows.Next() {
+		var m repositories.TeamMember
+		err = rows.Scan(&m.User, &m.Role)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// У команды всегда есть владелец, поэтому пустой список значит, что команды нет.
+	if len(members) == 0 {
+		return nil, repositories.ErrTeamNotFound
+	}
+
+	return members, nil
+}
+
+// SetTeamMember - добавить пользователя в команду или изменить его роль.
+//
+// Последнего владельца команды нельзя понизить, иначе вернет repositories.ErrLastOwner.
+func (st *PsqlStorage) SetTeamMember(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	role repositories.Role,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(
+		ctx,
+		`INSERT INTO team_members (team_id, user_id, role)
+         SELECT $1, $2, $3 WHERE EXISTS (SELECT 1 FROM teams WHERE id = $1)
+         ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+         WHERE EXCLUDED.role = 'owner' OR team_members.role <> 'owner' OR EXISTS (
+             SELECT 1 FROM team_members owners
+             WHERE owners.team_id = EXCLUDED.team_id AND owners.role = 'owner' AND owners.user_id <> EXCLUDED.user_id
+         )`,
+		team, user, role,
+	)
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return err
+	}
+
+	return st.teamMemberResult(ctx, res, team, user)
+}
+
+// RemoveTeamMember - исключить пользователя из команды.
+//
+// Последнего владельца команды исключить нельзя, иначе вернет repositories.ErrLastOwner.
+func (st *PsqlStorage) RemoveTeamMember(ctx context.Context, team repositories.TeamID, user repositories.User) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(
+		ctx,
+		`DELETE FROM team_members
+         WHERE team_id = $1 AND user_id = $2 AND (role <> 'owner' OR EXISTS (
+             SELECT 1 FROM team_members owners
+             WHERE owners.team_id = $1 AND owners.role = 'owner' AND owners.user_id <> $2
+         ))`,
+		team, user,
+	)
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return err
+	}
+
+	return st.teamMemberResult(ctx, res, team, user)
+}
+
+// teamMemberResult - разобрать результат изменения состава команды.
+//
+// Если ни одна строка не изменилась, значит либо команды (или участника) нет,
+// либо изменение оставило бы команду без владельца.
+func (st *PsqlStorage) teamMemberResult(
+	ctx context.Context,
+	res sql.Result,
+	team repositories.TeamID,
+	user repositories.User,
+) error {
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff > 0 {
+		return nil
+	}
+
+	_, err = st.GetTeamRole(ctx, team, user)
+	if err != nil {
+		return err
+	}
+
+	return repositories.ErrLastOwner
+}
+
+// CreateInvite - сохранить приглашение в команду.
+func (st *PsqlStorage) CreateInvite(ctx context.Context, invite repositories.TeamInvite) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	_, err := st.execContext(
+		ctx,
+		`INSERT INTO team_invites (id, team_id, role, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)`,
+		invite.ID, invite.Team, invite.Role, invite.CreatedAt, invite.ExpiresAt,
+	)
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+		return repositories.ErrTeamNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("insert failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// AcceptInvite - принять приглашение.
+//
+// Приглашение блокируется до конца транзакции, поэтому одно приглашение не могут принять двое.
+// Если пользователь уже состоит в команде с той же или старшей ролью, его роль не меняется.
+func (st *PsqlStorage) AcceptInvite(
+	ctx context.Context,
+	id repositories.InviteID,
+	user repositories.User,
+	at time.Time,
+) (invite repositories.TeamInvite, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	err = st.inTx(ctx, func(ctx context.Context) error {
+		var usedAt, revokedAt sql.NullTime
+		var usedBy uuid.NullUUID
+		err := st.queryRowContext(
+			ctx,
+			`SELECT id, team_id, role, created_at, expires_at, used_at, used_by, revoked_at
+             FROM team_invites WHERE id = $1 FOR UPDATE`,
+			id,
+		).Scan(&invite.ID, &invite.Team, &invite.Role, &invite.CreatedAt, &invite.ExpiresAt,
+			&usedAt, &usedBy, &revokedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repositories.ErrInviteNotFound
+		}
+		if err != nil {
+			st.log(ctx).Error("query failed", zap.Error(err))
+			return err
+		}
+		invite.UsedAt, invite.UsedBy, invite.RevokedAt = usedAt.Time, usedBy.UUID, revokedAt.Time
+
+		err = invite.Usable(at)
+		if err != nil {
+			return err
+		}
+
+		_, err = st.execContext(ctx, `UPDATE team_invites SET used_at = $2, used_by = $3 WHERE id = $1`, id, at, user)
+		if err != nil {
+			st.log(ctx).Error("update failed", zap.Error(err))
+			return err
+		}
+		invite.UsedAt, invite.UsedBy = at, user
+
+		role, err := st.GetTeamRole(ctx, invite.Team, user)
+		if err != nil && !errors.Is(err, repositories.ErrTeamNotFound) {
+			return err
+		}
+		if role.Allows(invite.Role) {
+			return nil
+		}
+
+		return st.SetTeamMember(ctx, invite.Team, user, invite.Role)
+	})
+
+	return invite, err
+}
+
+// RevokeInvite - отозвать приглашение в команду team.
+//
+// Принятое приглашение отозвать нельзя, повторный отзыв не меняет время отзыва.
+func (st *PsqlStorage) RevokeInvite(
+	ctx context.Context,
+	id repositories.InviteID,
+	team repositories.TeamID,
+	at time.Time,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(
+		ctx,
+		`UPDATE team_invites SET revoked_at = $3
+         WHERE id = $1 AND team_id = $2 AND used_at IS NULL AND revoked_at IS NULL`,
+		id, team, at,
+	)
+	if err != nil {
+		st.log(ctx).Error("update failed", zap.Error(err))
+		return err
+	}
+
+	aff, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if aff > 0 {
+		return nil
+	}
+
+	var used bool
+	err = st.queryRowContext(
+		ctx,
+		`SELECT used_at IS NOT NULL FROM team_invites WHERE id = $1 AND team_id = $2`,
+		id, team,
+	).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repositories.ErrInviteNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return err
+	}
+	if used {
+		return repositories.ErrInviteUsed
+	}
+
+	return nil
+}
+
+// TransferLinks - передать ссылки в команду team или, если team == uuid.Nil, в личные ссылки user.
+//
+// Передать ссылку может только ее владелец, а в команду - только ее редактор или владелец.
+// Ссылки передаются одним запросом: все вместе или ни одна.
+func (st *PsqlStorage) TransferLinks(
+	ctx context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+	team repositories.TeamID,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	if team != uuid.Nil {
+		err := st.requireTeamRole(ctx, team, user, repositories.RoleEditor)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT links.id, links.user_id, links.team_id, COALESCE(team_members.role, '')
+         FROM links LEFT JOIN team_members
+             ON team_members.team_id = links.team_id AND team_members.user_id = $2
+         WHERE links.id = ANY($1)`,
+		pq.Array(ids), user,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return err
+	}
+	defer rows.Close()
+
+	found := make(map[repositories.ID]bool, len(ids))
+	for rows.Next() {
+		var link repositories.LinkData
+		var linkTeam uuid.NullUUID
+		var role repositories.Role
+		err = rows.Scan(&link.ID, &link.User, &linkTeam, &role)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return err
+		}
+		link.Team = linkTeam.UUID
+
+		if repositories.LinkRole(link, user, role) != repositories.RoleOwner {
+			return repositories.ErrForbidden
+		}
+		found[link.ID] = true
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return repositories.ErrURLNotFound
+		}
+	}
+
+	query := `UPDATE links SET team_id = $2 WHERE id = ANY($1)`
+	args := []any{pq.Array(ids), nullTeam(team)}
+	if team == uuid.Nil {
+		query = `UPDATE links SET team_id = NULL, user_id = $2 WHERE id = ANY($1)`
+		if st.dedup == repositories.DedupPerUser {
+			query = `UPDATE links SET team_id = NULL, user_id = $2, dedup_key = $2::text || ' ' || url
+                     WHERE id = ANY($1)`
+		}
+		args[1] = user
+	}
+
+	_, err = st.execContext(ctx, query, args...)
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return repositories.ErrURLAlreadyExists
+	}
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// requireTeamRole - проверить, что роль пользователя в команде не ниже need.
+func (st *PsqlStorage) requireTeamRole(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	need repositories.Role,
+) error {
+	role, err := st.GetTeamRole(ctx, team, user)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(need) {
+		return repositories.ErrForbidden
+	}
+	return nil
+}
+
+// linkRole - получить владельца ссылки и роль пользователя в ее команде.
+func (st *PsqlStorage) linkRole(
+	ctx context.Context,
+	id repositories.ID,
+	user repositories.User,
+) (link repositories.LinkData, role repositories.Role, err error) {
+	var team uuid.NullUUID
+	err = st.queryRowContext(
+		ctx,
+		`SELECT links.user_id, links.team_id, COALESCE(team_members.role, '')
+         FROM links LEFT JOIN team_members
+             ON team_members.team_id = links.team_id AND team_members.user_id = $2
+         WHERE links.id = $1`,
+		id, user,
+	).Scan(&link.User, &team, &role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return link, "", repositories.ErrURLNotFound
+	}
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return link, "", err
+	}
+
+	link.ID = id
+	link.Team = team.UUID
+
+	return link, role, nil
+}
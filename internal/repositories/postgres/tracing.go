@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/tracing"
+)
+
+// Обертки над методами sql.DB или транзакции inTx, которые открывают спан трассировки на каждый запрос к базе.
+//
+// Спан открывается только внутри уже начатой трассировки, поэтому фоновые запросы воркера удаления,
+// которые выполняются раз в секунду, не создают отдельных трасс.
+
+func (st *PsqlStorage) execContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	res, err := st.conn(ctx).ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return res, err
+}
+
+func (st *PsqlStorage) queryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := st.conn(ctx).QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (st *PsqlStorage) queryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := st.conn(ctx).QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
+}
+
+// startQuerySpan - начать спан запроса, его имя - первое слово запроса, например "postgres SELECT".
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	statement := strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+
+	return tracing.Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBStatement(statement)),
+	)
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// GetDeletedUserLinks - получить удаленные ссылки, которые пользователь может восстановить:
+// личные и ссылки команд, где он редактор или владелец. Сначала удаленные позже.
+func (st *PsqlStorage) GetDeletedUserLinks(
+	ctx context.Context,
+	user repositories.User,
+) (links []repositories.LinkData, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT links.id, links.url, links.user_id, links.team_id, links.expires_at, links.max_hits, links.hits,
+                links.created_at, links.deleted_at
+         FROM links LEFT JOIN team_members
+             ON team_members.team_id = links.team_id AND team_members.user_id = $1
+         WHERE links.deleted AND (
+             (links.team_id IS NULL AND links.user_id = $1) OR team_members.role IN ('owner', 'editor')
+         )
+         ORDER BY links.deleted_at DESC, links.id`,
+		user,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	links = make([]repositories.LinkData, 0)
+	for rows.Next() {
+		var link repositories.LinkData
+		var team uuid.NullUUID
+		var expiresAt, deletedAt sql.NullTime
+		err = rows.Scan(
+			&link.ID, &link.URL, &link.User, &team, &expiresAt, &link.MaxHits, &link.Hits,
+			&link.CreatedAt, &deletedAt,
+		)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		link.Team = team.UUID
+		link.ExpiresAt = expiresAt.Time
+		link.Deleted = true
+		link.DeletedAt = deletedAt.Time
+		links = append(links, link)
+	}
+	if err = rows.Err(); err != nil {
+		st.log(ctx).Error("rows failed", zap.Error(err))
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// RestoreUserLinks - восстановить ссылки пользователя, удаленные не раньше since.
+//
+// Ссылки, которые не удалены, удалены раньше since или недоступны пользователю, пропускаются.
+// Запросы на удаление, которые еще стоят в очереди, применятся уже после восстановления.
+func (st *PsqlStorage) RestoreUserLinks(
+	ctx context.Context,
+	ids []repositories.ID,
+	user repositories.User,
+	since time.Time,
+) (restored []repositories.ID, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	rows, err := st.queryContext(
+		ctx,
+		`UPDATE links SET deleted = FALSE, deleted_at = NULL
+         WHERE links.id = ANY($1) AND links.deleted AND links.deleted_at >= $3 AND (
+             (links.team_id IS NULL AND links.user_id = $2)
+             OR EXISTS (
+                 SELECT 1 FROM team_members
+                 WHERE team_members.team_id = links.team_id AND team_members.user_id = $2
+                   AND team_members.role IN ('owner', 'editor')
+             )
+         )
+         RETURNING links.id`,
+		pq.Array(ids), user, since,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	restored = make([]repositories.ID, 0, len(ids))
+	for rows.Next() {
+		var id repositories.ID
+		err = rows.Scan(&id)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		restored = append(restored, id)
+	}
+
+	return restored, rows.Err()
+}
+
+// PurgeDeleted - безвозвратно удалить ссылки, удаленные раньше before.
+//
+// Вместе со ссылками удаляются их переходы и история URL, ID и URL снова становятся свободными.
+func (st *PsqlStorage) PurgeDeleted(ctx context.Context, before time.Time) (count int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	res, err := st.execContext(ctx, `DELETE FROM links WHERE deleted AND deleted_at < $1`, before)
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// txKey - ключ контекста, под которым хранится транзакция inTx.
+type txKey struct{}
+
+// querier - общие методы sql.DB и sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn - транзакция, если запрос выполняется внутри inTx, иначе - пул соединений.
+func (st *PsqlStorage) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return st.db
+}
+
+// inTx - выполнить fn в одной транзакции.
+//
+// Все запросы fn через контекст, который она получает, выполняются в этой транзакции.
+// Если fn вернет ошибку, транзакция откатывается.
+func (st *PsqlStorage) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := st.db.BeginTx(ctx, nil)
+	if err != nil {
+		st.log(ctx).Error("begin failed", zap.Error(err))
+		return err
+	}
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		st.log(ctx).Error("commit failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// checkQuota - проверить, что пользователь может создать еще n ссылок.
+//
+// Вызывается внутри inTx: блокирует квоты пользователя до конца транзакции, поэтому одновременные
+// запросы одного пользователя проверяют квоты и создают ссылки по очереди.
+func (st *PsqlStorage) checkQuota(ctx context.Context, user repositories.User, q repositories.Quota, n int) error {
+	_, err := st.execContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "quota:"+user.String())
+	if err != nil {
+		st.log(ctx).Error("lock failed", zap.Error(err))
+		return err
+	}
+
+	counts, err := st.CountUserLinks(ctx, user, q.Since)
+	if err != nil {
+		return err
+	}
+
+	return q.Check(counts, n)
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Update - заменить исходный URL ссылки, прежний URL сохраняется в link_versions.
+//
+// Изменить ссылку может ее владелец, а ссылку команды - ее редактор или владелец.
+func (st *PsqlStorage) Update(
+	ctx context.Context,
+	id repositories.ID,
+	url repositories.URL,
+	user repositories.User,
+) (link repositories.LinkData, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	link, role, err := st.linkRole(ctx, id, user)
+	if err != nil {
+		return repositories.LinkData{}, err
+	}
+	if !repositories.LinkRole(link, user, role).Allows(repositories.RoleEditor) {
+		return repositories.LinkData{}, repositories.ErrUserNotMatch
+	}
+
+	_, err = st.execContext(
+		ctx,
+		`WITH prev AS (
+             SELECT url FROM links WHERE id = $1 AND NOT deleted AND url <> $2 FOR UPDATE
+         ), updated AS (
+             UPDATE links SET url = $2, dedup_key = $3 FROM prev WHERE links.id = $1
+             RETURNING prev.url
+         )
+         INSERT INTO link_versions (link_id, url, user_id) SELECT $1, url, $4 FROM updated`,
+		id, url, st.dedupKey(url, link.User), user,
+	)
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return repositories.LinkData{}, repositories.ErrURLAlreadyExists
+	}
+	if err != nil {
+		st.log(ctx).Error("exec failed", zap.Error(err))
+		return repositories.LinkData{}, err
+	}
+
+	link, err = st.GetLink(ctx, id)
+	if err != nil {
+		return repositories.LinkData{}, err
+	}
+	if link.Deleted {
+		return repositories.LinkData{}, repositories.ErrURLNotFound
+	}
+
+	return link, nil
+}
+
+// GetLinkHistory - получить прежние исходные URL ссылки, от старых к новым.
+func (st *PsqlStorage) GetLinkHistory(
+	ctx context.Context,
+	id repositories.ID,
+	user repositories.User,
+) (versions []repositories.URLVersion, err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	link, role, err := st.linkRole(ctx, id, user)
+	if err != nil {
+		return nil, err
+	}
+	if !repositories.LinkRole(link, user, role).Allows(repositories.RoleViewer) {
+		return nil, repositories.ErrUserNotMatch
+	}
+
+	rows, err := st.queryContext(
+		ctx,
+		`SELECT url, user_id, replaced_at FROM link_versions WHERE link_id = $1 ORDER BY id`,
+		id,
+	)
+	if err != nil {
+		st.log(ctx).Error("query failed", zap.Error(err))
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	versions = make([]repositories.URLVersion, 0)
+	for rows.Next() {
+		var v repositories.URLVersion
+		err = rows.Scan(&v.URL, &v.User, &v.ReplacedAt)
+		if err != nil {
+			st.log(ctx).Error("row scan failed", zap.Error(err))
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err = rows.Err(); err != nil {
+		st.log(ctx).Error("rows failed", zap.Error(err))
+		return nil, err
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TeamID - тип для хранения ID команды.
+type TeamID = uuid.UUID
+
+// Role - роль участника команды.
+type Role string
+
+// Возможные значения Role, от старшей к младшей.
+const (
+	RoleOwner  Role = "owner"  // Управляет участниками и может передавать ссылки команды.
+	RoleEditor Role = "editor" // Создает, изменяет и удаляет ссылки команды.
+	RoleViewer Role = "viewer" // Видит ссылки команды и их статистику.
+)
+
+// ParseRole - получить Role из строки.
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return r, nil
+	default:
+		return "", ErrUnknownRole
+	}
+}
+
+// Allows - достаточно ли роли r для действия, которое требует роль need.
+func (r Role) Allows(need Role) bool {
+	return r.rank() >= need.rank() && r.rank() > 0
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Team - команда, которой могут принадлежать ссылки.
+type Team struct {
+	CreatedAt time.Time `json:"created_at"` // Время создания команды.
+	ID        TeamID    `json:"id"`         // ID команды.
+	Name      string    `json:"name"`       // Название команды.
+}
+
+// InviteID - тип для хранения ID приглашения в команду.
+type InviteID = uuid.UUID
+
+// TeamInvite - приглашение в команду.
+//
+// Приглашение можно принять один раз и только до отзыва и окончания срока действия.
+type TeamInvite struct {
+	CreatedAt time.Time // Время создания.
+	ExpiresAt time.Time // Время, после которого приглашение перестает работать.
+	UsedAt    time.Time // Время, когда приглашение принято, нулевое значение - не принято.
+	RevokedAt time.Time // Время отзыва, нулевое значение - не отозвано.
+	ID        InviteID  // ID приглашения.
+	Team      TeamID    // Команда.
+	Role      Role      // Роль, которую получит приглашенный.
+	UsedBy    User      // Пользователь, который принял приглашение.
+}
+
+// Usable - можно ли принять приглашение в момент now.
+//
+// Если нельзя, вернет ErrInviteUsed, ErrInviteRevoked или ErrInviteExpired.
+func (i TeamInvite) Usable(now time.Time) error {
+	switch {
+	case !i.UsedAt.IsZero():
+		return ErrInviteUsed
+	case !i.RevokedAt.IsZero():
+		return ErrInviteRevoked
+	case !now.Before(i.ExpiresAt):
+		return ErrInviteExpired
+	default:
+		return nil
+	}
+}
+
+// TeamMember - участник команды.
+type TeamMember struct {
+	Role Role `json:"role"` // Роль участника.
+	User User `json:"user"` // Пользователь.
+}
+
+// Membership - команда, в которой состоит пользователь, и его роль в ней.
+type Membership struct {
+	Team
+	Role Role `json:"role"` // Роль пользователя в команде.
+}
+
+// LinkRole - роль пользователя по отношению к ссылке.
+//
+// Автор личной ссылки - ее владелец, для ссылки команды это teamRole - роль пользователя в link.Team.
+// Если у пользователя нет доступа к ссылке, вернет пустую роль.
+func LinkRole(link LinkData, user User, teamRole Role) Role {
+	if link.Team == uuid.Nil {
+		if link.User == user {
+			return RoleOwner
+		}
+		return ""
+	}
+	return teamRole
+}
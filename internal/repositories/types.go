@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"sort"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -10,14 +13,163 @@ type (
 	URL     = string    // Тип для хранения исходного URL.
 	User    = uuid.UUID // Тип для хранения ID пользователя.
 	Deleted = bool      // Тип для хранения, удалена ли ссылка.
+
+	DeletionID = uuid.UUID // Тип для хранения ID запроса на удаление ссылок.
+)
+
+// DeletionStatus - состояние запроса на удаление ссылок.
+type DeletionStatus string
+
+// Возможные состояния DeletionStatus.
+const (
+	DeletionPending DeletionStatus = "pending" // Запрос принят, но еще не применен.
+	DeletionDone    DeletionStatus = "done"    // Ссылки удалены.
 )
 
 // LinkData - структура для хранения данных о ссылке.
 type LinkData struct {
-	ID      ID      // ID сокращенной ссылки.
-	URL     URL     // Исходный URL.
-	User    User    // Пользователь, которому принадлежит ссылка.
-	Deleted Deleted // Удалена ли ссылка.
+	ExpiresAt time.Time // Время, после которого ссылка перестает работать. Нулевое значение - бессрочно.
+	CreatedAt time.Time // Время создания ссылки.
+	DeletedAt time.Time // Время удаления ссылки, нулевое значение - ссылка не удалена.
+	ID        ID        // ID сокращенной ссылки.
+	URL       URL       // Исходный URL.
+	MaxHits   uint64    // Максимальное количество переходов. 0 - без ограничений.
+	Hits      uint64    // Количество переходов по ссылке.
+	User      User      // Пользователь, который создал ссылку (владелец личной ссылки).
+	Team      TeamID    // Команда, которой принадлежит ссылка. uuid.Nil - личная ссылка пользователя.
+	Deleted   Deleted   // Удалена ли ссылка.
+}
+
+// Expired - истек ли срок действия ссылки на момент now.
+func (l LinkData) Expired(now time.Time) bool {
+	if !l.ExpiresAt.IsZero() && !now.Before(l.ExpiresAt) {
+		return true
+	}
+	return l.MaxHits > 0 && l.Hits >= l.MaxHits
+}
+
+// LinkOptions - необязательные параметры при создании ссылки.
+type LinkOptions struct {
+	ExpiresAt time.Time // Время, после которого ссылка перестает работать. Нулевое значение - бессрочно.
+	Alias     ID        // ID, выбранный пользователем. Пустая строка - сгенерировать случайный.
+	MaxHits   uint64    // Максимальное количество переходов. 0 - без ограничений.
+	Team      TeamID    // Команда, в которой создается ссылка. uuid.Nil - личная ссылка.
+
+	// Квоты пользователя. Хранилище проверяет их вместе с записью ссылки,
+	// поэтому одновременные запросы одного пользователя не могут их превысить.
+	Quota Quota
+}
+
+// BatchLink - ссылка, которую нужно сократить в составе пачки.
+type BatchLink struct {
+	URL  URL         // Исходный URL.
+	Opts LinkOptions // Необязательные параметры ссылки.
+}
+
+// BatchResult - результат сокращения одной ссылки из пачки.
+//
+// При ошибке ErrURLAlreadyExists в ID записан ID уже существующей ссылки.
+type BatchResult struct {
+	ID  ID    // ID созданной ссылки.
+	Err error // Почему ссылку не удалось создать, nil - ссылка создана.
+}
+
+// URLVersion - прежний исходный URL ссылки.
+type URLVersion struct {
+	ReplacedAt time.Time `json:"replaced_at"` // Время, когда URL был заменен.
+	URL        URL       `json:"url"`         // Прежний исходный URL.
+	User       User      `json:"user"`        // Пользователь, который заменил URL.
+}
+
+// Click - структура для хранения информации о переходе по ссылке.
+type Click struct {
+	Time      time.Time // Время перехода.
+	ID        ID        // ID сокращенной ссылки.
+	Referrer  string    // Значение заголовка Referer.
+	UserAgent string    // Значение заголовка User-Agent.
+	IP        string    // IP-адрес клиента.
+}
+
+// DayClicks - количество переходов по ссылке за сутки (UTC).
+type DayClicks struct {
+	Day    time.Time // Начало суток.
+	Clicks uint64    // Количество переходов.
+}
+
+// LinkStats - структура для хранения статистики переходов по ссылке.
+type LinkStats struct {
+	Days           []DayClicks // Гистограмма переходов по дням, упорядочена по возрастанию.
+	Clicks         uint64      // Общее количество переходов.
+	UniqueVisitors uint64      // Количество уникальных посетителей (по IP-адресу).
+}
+
+// NewLinkStats - посчитать статистику по списку переходов.
+func NewLinkStats(clicks []Click) LinkStats {
+	stats := LinkStats{
+		Days:   make([]DayClicks, 0),
+		Clicks: uint64(len(clicks)),
+	}
+
+	visitors := make(map[string]struct{})
+	days := make(map[time.Time]uint64)
+	for _, click := range clicks {
+		visitors[click.IP] = struct{}{}
+		days[click.Time.UTC().Truncate(24*time.Hour)]++
+	}
+	stats.UniqueVisitors = uint64(len(visitors))
+
+	for day, count := range days {
+		stats.Days = append(stats.Days, DayClicks{Day: day, Clicks: count})
+	}
+	sort.Slice(stats.Days, func(i, j int) bool {
+		return stats.Days[i].Day.Before(stats.Days[j].Day)
+	})
+
+	return stats
+}
+
+// LinkCounts - количество ссылок пользователя, по которым проверяются квоты.
+type LinkCounts struct {
+	Active       int64 // Ссылки, которые не удалены и не истекли.
+	CreatedSince int64 // Ссылки, созданные начиная с заданного момента, включая уже удаленные.
+}
+
+// Quota - квоты пользователя на создание ссылок, нулевое значение - без ограничений.
+type Quota struct {
+	Since      time.Time // Начало текущих суток: ссылки, созданные с этого момента, считаются в DailyLinks.
+	MaxLinks   int64     // Квота активных ссылок, 0 - без ограничений.
+	DailyLinks int64     // Квота ссылок за сутки, 0 - без ограничений.
+}
+
+// Limited - задана ли хотя бы одна квота.
+func (q Quota) Limited() bool {
+	return q.MaxLinks > 0 || q.DailyLinks > 0
+}
+
+// Check - проверить, что при уже созданных ссылках counts можно создать еще n ссылок.
+//
+// Если нельзя, вернет ErrMaxLinks или ErrDailyLinks.
+func (q Quota) Check(counts LinkCounts, n int) error {
+	if q.MaxLinks > 0 && counts.Active+int64(n) > q.MaxLinks {
+		return ErrMaxLinks
+	}
+	if q.DailyLinks > 0 && counts.CreatedSince+int64(n) > q.DailyLinks {
+		return ErrDailyLinks
+	}
+	return nil
+}
+
+// BatchQuota - квоты пачки ссылок.
+//
+// Все ссылки пачки принадлежат одному пользователю, поэтому квоты у них одни и те же,
+// и пачка проверяется по ним целиком: либо квот хватает на все ссылки, либо ни одна не создается.
+func BatchQuota(links []BatchLink) Quota {
+	for _, link := range links {
+		if link.Opts.Quota.Limited() {
+			return link.Opts.Quota
+		}
+	}
+	return Quota{}
 }
 
 // ServiceStats - структура для хранения статистики сервиса.
@@ -15,32 +15,72 @@ import (
 func NewRouter(handler *handlers.Handler, m middlewares.Middlewares) chi.Router {
 	r := chi.NewRouter()
 
+	r.Use(m.Tracing)
+	r.Use(m.Metrics)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(m.RequestLog)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Compress(flate.BestSpeed))
 	r.Use(m.GzipRequest)
 	r.Use(m.UserCookie)
 
 	r.Route("/", func(r chi.Router) {
-		r.Post("/", handler.CreateShortURL)
+		r.With(m.RateLimit).Post("/", handler.CreateShortURL)
 		r.Get("/{ID}", handler.GetURL)
 
 		r.Get("/ping", handler.PingDB)
 
 		r.Route("/api", func(r chi.Router) {
 			r.Route("/shorten", func(r chi.Router) {
+				r.Use(m.RateLimit)
 				r.Post("/", handler.ShortenURL)
 				r.Post("/batch", handler.ShortenBatch)
 			})
 
 			r.Route("/user", func(r chi.Router) {
 				r.Get("/urls", handler.GetUserURLs)
+				r.Get("/urls/export", handler.ExportUserURLs)
+				r.With(m.RateLimit).Post("/urls/import", handler.ImportUserURLs)
+				r.Get("/urls/{ID}/stats", handler.GetUserURLStats)
+				r.Patch("/urls/{ID}", handler.UpdateUserURL)
+				r.Get("/urls/{ID}/history", handler.GetUserURLHistory)
 				r.Delete("/urls", handler.DeleteUserURLs)
+				r.Get("/urls/trash", handler.GetDeletedUserURLs)
+				r.Post("/urls/restore", handler.RestoreUserURLs)
+				r.Get("/urls/deletions/{DeletionID}", handler.GetDeletionStatus)
+				r.Post("/urls/transfer", handler.TransferUserURLs)
+				r.Get("/quota", handler.GetUserQuota)
+			})
+
+			r.Route("/teams", func(r chi.Router) {
+				r.Get("/", handler.GetUserTeams)
+				r.Post("/", handler.CreateTeam)
+				r.Post("/join", handler.JoinTeam)
+				r.Get("/{TeamID}/members", handler.GetTeamMembers)
+				r.Put("/{TeamID}/members/{UserID}", handler.SetTeamMember)
+				r.Delete("/{TeamID}/members/{UserID}", handler.RemoveTeamMember)
+				r.Post("/{TeamID}/invites", handler.CreateTeamInvite)
+				r.Delete("/{TeamID}/invites/{InviteID}", handler.RevokeTeamInvite)
+			})
+
+			r.Route("/auth", func(r chi.Router) {
+				r.Use(m.AuthRateLimit)
+				r.Post("/register", handler.Register)
+				r.Post("/login", handler.Login)
+				r.Post("/logout", handler.Logout)
+			})
+
+			r.Route("/account", func(r chi.Router) {
+				r.Get("/", handler.GetAccount)
+				r.Get("/keys", handler.GetAPIKeys)
+				r.Post("/keys", handler.CreateAPIKey)
+				r.Delete("/keys/{KeyID}", handler.RevokeAPIKey)
+				r.Post("/claim", handler.ClaimUserURLs)
 			})
 
 			r.Route("/internal", func(r chi.Router) {
 				r.Get("/stats", handler.GetStats)
+				r.Post("/trash/purge", handler.PurgeDeleted)
 			})
 		})
 	})
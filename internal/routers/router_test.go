@@ -3,6 +3,7 @@ package routers
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
@@ -12,20 +13,42 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/accounts"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/alias"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/analytics"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/authenticator"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/bulk"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/handlers"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/middlewares"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/policy"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/quota"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/ratelimit"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/storage"
-	"github.com/ImpressionableRaccoon/urlshortener/internal/utils"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/teams"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/tracing"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/urlnorm"
 )
 
 // TestLink - структура для хранения тестовой ссылки.
@@ -106,7 +129,7 @@ func genTestLink() (link TestLink, err error) {
 	site := sites[n.Int64()]
 
 	var page string
-	page, err = utils.GenRandomID()
+	page, err = idgen.Default.Generate("", 0)
 	if err != nil {
 		return
 	}
@@ -132,17 +155,32 @@ func TestRouter(t *testing.T) {
 		ServerBaseURL: "http://localhost:31222",
 		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
 		TrustedSubnet: "127.0.0.1/32",
+
+		AliasAlphabet:  "abcdefghijklmnopqrstuvwxyz0123456789-",
+		AliasMinLength: 3,
+		AliasMaxLength: 32,
+		AliasReserved:  []string{"ping", "api"},
 	}
 
-	s, err := storage.NewStorager(cfg)
+	core, logs := observer.New(zap.InfoLevel)
+	l := zap.New(core)
+
+	s, err := storage.NewStorager(cfg, l)
 	require.NoError(t, err)
 
 	a := authenticator.New(cfg)
 
 	_, n, _ := net.ParseCIDR(cfg.TrustedSubnet)
 
-	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, n)
-	m := middlewares.NewMiddlewares(cfg, a)
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	q, err := quota.NewChecker(cfg, s)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, n,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, q, nil, nil, 0, 0, l)
+	m := middlewares.NewMiddlewares(cfg, a, nil, l, nil, nil)
 	r := NewRouter(h, m)
 
 	ts := httptest.NewServer(r)
@@ -169,6 +207,18 @@ func TestRouter(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, statusCode)
 	})
 
+	t.Run("POST /: wrong URLs are rejected", func(t *testing.T) {
+		for _, url := range []string{"javascript:alert(1)", "example.com", cfg.ServerBaseURL + "/abc"} {
+			statusCode, _, _ := testRequest(t, ts, jar, http.MethodPost, "/", strings.NewReader(url), nil)
+			assert.Equal(t, http.StatusBadRequest, statusCode, url)
+		}
+
+		statusCode, body, _ := testRequest(t, ts, jar, http.MethodPost, "/api/shorten",
+			strings.NewReader(`{"url":"ftp://example.com/"}`), nil)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.JSONEq(t, `{"error":"url scheme is not allowed"}`, string(body))
+	})
+
 	t.Run("GET /api/user/urls: no user URLs", func(t *testing.T) {
 		statusCode, _, _ := testRequest(t, ts, jar, http.MethodGet, "/api/user/urls", nil, nil)
 
@@ -237,11 +287,58 @@ func TestRouter(t *testing.T) {
 		err = json.Unmarshal(body, &data)
 		require.NoError(t, err)
 
+		for i := range data {
+			assert.False(t, data[i].CreatedAt.IsZero())
+			data[i].CreatedAt = time.Time{}
+		}
 		for _, link := range links {
 			assert.Contains(t, data, handlers.UserLink{ShortURL: link.ShortLink, OriginalURL: link.URL})
 		}
 	})
 
+	t.Run("GET /api/user/urls: paginate user URLs", func(t *testing.T) {
+		seen := make(map[string]bool)
+		path := "/api/user/urls?limit=3&order=asc"
+		var prev time.Time
+		for pages := 0; path != ""; pages++ {
+			require.Less(t, pages, 4)
+
+			statusCode, body, header := testRequest(t, ts, jar, http.MethodGet, path, nil, nil)
+			assert.Equal(t, http.StatusOK, statusCode)
+
+			data := make([]handlers.UserLink, 0)
+			err = json.Unmarshal(body, &data)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(data), 3)
+
+			for _, link := range data {
+				assert.False(t, seen[link.ShortURL])
+				assert.False(t, link.CreatedAt.Before(prev))
+				seen[link.ShortURL] = true
+				prev = link.CreatedAt
+			}
+
+			path = ""
+			if cursor := header.Get("X-Next-Cursor"); cursor != "" {
+				path = "/api/user/urls?limit=3&order=asc&cursor=" + cursor
+				assert.Contains(t, header.Get("Link"), `rel="next"`)
+			}
+		}
+		assert.Len(t, seen, len(links))
+
+		statusCode, _, _ := testRequest(t, ts, jar, http.MethodGet, "/api/user/urls?limit=x", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+
+		statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, "/api/user/urls?order=up", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+
+		statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, "/api/user/urls?cursor=!", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+
+		statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, "/api/user/urls?q=no-such-url", nil, nil)
+		assert.Equal(t, http.StatusNoContent, statusCode)
+	})
+
 	t.Run("DELETE /api/user/urls: delete user URLs", func(t *testing.T) {
 		linksIDs := make([]repositories.ID, 0)
 
@@ -255,12 +352,24 @@ func TestRouter(t *testing.T) {
 		data, err = json.Marshal(linksIDs)
 		require.NoError(t, err)
 
-		statusCode, _, _ := testRequest(
+		statusCode, body, header := testRequest(
 			t, ts, jar, http.MethodDelete, "/api/user/urls",
 			bytes.NewReader(data), nil,
 		)
 
 		assert.Equal(t, http.StatusAccepted, statusCode)
+
+		deletion := handlers.DeletionResponse{}
+		err = json.Unmarshal(body, &deletion)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/user/urls/deletions/"+deletion.DeletionID, header.Get("Location"))
+
+		statusCode, body, _ = testRequest(t, ts, jar, http.MethodGet, header.Get("Location"), nil, nil)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		err = json.Unmarshal(body, &deletion)
+		require.NoError(t, err)
+		assert.Equal(t, repositories.DeletionDone, deletion.Status)
 	})
 
 	t.Run("GET /{id}: check if only needed links deleted", func(t *testing.T) {
@@ -474,4 +583,1056 @@ func TestRouter(t *testing.T) {
 		assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
 		assert.Equal(t, link.URL, header.Get("Location"))
 	})
+
+	t.Run("POST /api/shorten: link expires after max hits", func(t *testing.T) {
+		var request []byte
+		request, err = json.Marshal(handlers.ShortenURLRequest{
+			URL:     "https://example.com/one-time",
+			MaxHits: 1,
+		})
+		require.NoError(t, err)
+
+		statusCode, body, _ := testRequest(
+			t, ts, jar, http.MethodPost, "/api/shorten",
+			bytes.NewReader(request), nil,
+		)
+		require.Equal(t, http.StatusCreated, statusCode)
+
+		response := handlers.ShortenURLResponse{}
+		err = json.Unmarshal(body, &response)
+		require.NoError(t, err)
+
+		path := strings.TrimPrefix(response.Result, cfg.ServerBaseURL)
+
+		statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, path, nil, nil)
+		assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
+
+		statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, path, nil, nil)
+		assert.Equal(t, http.StatusGone, statusCode)
+	})
+
+	t.Run("POST /api/shorten: custom alias", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			url        string
+			alias      string
+			statusCode int
+		}{
+			{name: "ok", url: "https://example.com/spring", alias: "spring-sale", statusCode: http.StatusCreated},
+			{name: "taken", url: "https://example.com/summer", alias: "spring-sale", statusCode: http.StatusConflict},
+			{name: "reserved", url: "https://example.com/ping", alias: "ping", statusCode: http.StatusBadRequest},
+			{name: "wrong characters", url: "https://example.com/x", alias: "spring/sale", statusCode: http.StatusBadRequest},
+		}
+
+		for _, tt := range tests {
+			var request []byte
+			request, err = json.Marshal(handlers.ShortenURLRequest{URL: tt.url, Alias: tt.alias})
+			require.NoError(t, err)
+
+			statusCode, body, _ := testRequest(
+				t, ts, jar, http.MethodPost, "/api/shorten",
+				bytes.NewReader(request), nil,
+			)
+			assert.Equal(t, tt.statusCode, statusCode, tt.name)
+
+			if tt.statusCode == http.StatusCreated {
+				response := handlers.ShortenURLResponse{}
+				err = json.Unmarshal(body, &response)
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("%s/%s", cfg.ServerBaseURL, tt.alias), response.Result)
+			}
+		}
+
+		statusCode, _, header := testRequest(t, ts, jar, http.MethodGet, "/spring-sale", nil, nil)
+		assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
+		assert.Equal(t, "https://example.com/spring", header.Get("Location"))
+	})
+
+	t.Run("GET /api/user/urls/{id}/stats: link stats", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			statusCode, body, _ := testRequest(
+				t, ts, jar, http.MethodGet, "/api/user/urls/spring-sale/stats", nil, nil,
+			)
+			if statusCode != http.StatusOK {
+				return false
+			}
+
+			response := handlers.LinkStatsResponse{}
+			if json.Unmarshal(body, &response) != nil {
+				return false
+			}
+			return response.Clicks == 1 && response.UniqueVisitors == 1 && len(response.Days) == 1
+		}, 5*time.Second, 100*time.Millisecond)
+
+		var otherJar http.CookieJar
+		otherJar, err = cookiejar.New(&cookiejar.Options{})
+		require.NoError(t, err)
+
+		statusCode, _, _ := testRequest(
+			t, ts, otherJar, http.MethodGet, "/api/user/urls/spring-sale/stats", nil, nil,
+		)
+		assert.Equal(t, http.StatusForbidden, statusCode)
+
+		statusCode, _, _ = testRequest(
+			t, ts, jar, http.MethodGet, "/api/user/urls/not-exists/stats", nil, nil,
+		)
+		assert.Equal(t, http.StatusNotFound, statusCode)
+	})
+
+	t.Run("POST /api/user/urls/import and GET /api/user/urls/export", func(t *testing.T) {
+		var bulkJar http.CookieJar
+		bulkJar, err = cookiejar.New(&cookiejar.Options{})
+		require.NoError(t, err)
+
+		body := strings.Join([]string{
+			`{"original_url": "https://example.com/bulk/1", "alias": "bulk-one"}`,
+			`{"original_url": ""}`,
+			`not json`,
+			`{"original_url": "https://example.com/bulk/2", "max_hits": 5}`,
+		}, "\n")
+		statusCode, respBody, header := testRequest(t, ts, bulkJar, http.MethodPost,
+			"/api/user/urls/import", strings.NewReader(body),
+			map[string]string{"Content-Type": "application/x-ndjson"})
+		require.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "application/x-ndjson", header.Get("Content-Type"))
+
+		results := make([]bulk.Result, 0)
+		decoder := json.NewDecoder(bytes.NewReader(respBody))
+		for decoder.More() {
+			var result bulk.Result
+			require.NoError(t, decoder.Decode(&result))
+			results = append(results, result)
+		}
+		require.Len(t, results, 4)
+		assert.Equal(t, bulk.Result{
+			Row:         1,
+			OriginalURL: "https://example.com/bulk/1",
+			ShortURL:    cfg.ServerBaseURL + "/bulk-one",
+			Status:      bulk.StatusCreated,
+		}, results[0])
+		assert.Equal(t, bulk.StatusError, results[1].Status)
+		assert.Equal(t, bulk.StatusError, results[2].Status)
+		assert.Equal(t, 3, results[2].Row)
+		assert.Equal(t, bulk.StatusCreated, results[3].Status)
+
+		var gz bytes.Buffer
+		zw := gzip.NewWriter(&gz)
+		_, err = zw.Write([]byte("original_url,alias\nhttps://example.com/bulk/1,\nhttps://example.com/bulk/3,bulk-one\n"))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		statusCode, respBody, _ = testRequest(t, ts, bulkJar, http.MethodPost,
+			"/api/user/urls/import?format=csv", &gz, map[string]string{"Content-Encoding": "gzip"})
+		require.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "row,original_url,short_url,status,error\n"+
+			"1,https://example.com/bulk/1,"+cfg.ServerBaseURL+"/bulk-one,exists,\n"+
+			"2,https://example.com/bulk/3,,error,alias already exists\n", string(respBody))
+
+		statusCode, respBody, header = testRequest(t, ts, bulkJar, http.MethodGet,
+			"/api/user/urls/export?format=csv", nil, nil)
+		require.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", header.Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(string(respBody)), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "id,short_url,original_url,expires_at,max_hits,hits,team", lines[0])
+		assert.Equal(t, "bulk-one,"+cfg.ServerBaseURL+"/bulk-one,https://example.com/bulk/1,,,,", lines[1])
+
+		// Другой пользователь загружает выгрузку: ссылки создаются с теми же ID, а они уже заняты.
+		statusCode, respBody, _ = testRequest(t, ts, nil, http.MethodPost,
+			"/api/user/urls/import?format=csv", bytes.NewReader(respBody), nil)
+		require.Equal(t, http.StatusOK, statusCode)
+		assert.Contains(t, string(respBody), "1,https://example.com/bulk/1,,error,alias already exists\n")
+
+		statusCode, respBody, _ = testRequest(t, ts, bulkJar, http.MethodGet, "/api/user/urls/export", nil, nil)
+		require.Equal(t, http.StatusOK, statusCode)
+		assert.Len(t, strings.Split(strings.TrimSpace(string(respBody)), "\n"), 2)
+
+		statusCode, _, _ = testRequest(t, ts, bulkJar, http.MethodGet, "/api/user/urls/export?format=xml", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+
+		statusCode, _, _ = testRequest(t, ts, bulkJar, http.MethodPost, "/api/user/urls/import",
+			strings.NewReader("original_url\n"), map[string]string{"Content-Type": "text/plain"})
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+	})
+
+	t.Run("metrics: requests are counted by route pattern", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		body := w.Body.String()
+
+		assert.Contains(t, body, `urlshortener_http_requests_total{code="307",method="GET",route="/{ID}"}`)
+		assert.Contains(t, body, `urlshortener_http_request_duration_seconds_count{method="GET",route="/api/user/urls"}`)
+		assert.NotContains(t, body, `route="/`+links[0].ID+`"`)
+	})
+
+	t.Run("tracing: traceparent is continued and span is named by route", func(t *testing.T) {
+		_, err := tracing.Setup(context.Background(), configs.Config{})
+		require.NoError(t, err)
+		recorder := tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+		const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		statusCode, _, _ := testRequest(t, ts, jar, http.MethodGet, "/unknown", nil,
+			map[string]string{"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01"})
+		assert.Equal(t, http.StatusNotFound, statusCode)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET /{ID}", spans[0].Name())
+		assert.Equal(t, traceID, spans[0].SpanContext().TraceID().String())
+		assert.True(t, spans[0].Parent().IsRemote())
+	})
+
+	t.Run("request log: request and user IDs are logged", func(t *testing.T) {
+		const requestID = "test-request-id"
+		statusCode, _, header := testRequest(t, ts, jar, http.MethodGet, "/api/user/urls", nil,
+			map[string]string{"X-Request-ID": requestID})
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, requestID, header.Get("X-Request-ID"))
+
+		var entries []observer.LoggedEntry
+		require.Eventually(t, func() bool {
+			entries = logs.FilterMessage("http request").FilterField(zap.String("request_id", requestID)).All()
+			return len(entries) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		fields := entries[0].ContextMap()
+		assert.Equal(t, "/api/user/urls", fields["route"])
+		assert.Equal(t, int64(http.StatusOK), fields["status"])
+		assert.NotEmpty(t, fields["user_id"])
+
+		_, _, header = testRequest(t, ts, jar, http.MethodGet, "/ping", nil, nil)
+		assert.NotEmpty(t, header.Get("X-Request-ID"))
+	})
+}
+
+func TestRouter_RateLimit(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL:  "http://localhost:31222",
+		CookieKey:      []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		RateLimit:      0.01,
+		RateLimitBurst: 2,
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, nil, 0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(),
+		ratelimit.NewLimiter(cfg, ratelimit.NewMemoryStore()), nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		statusCode, _, _ := testRequest(t, ts, jar, http.MethodPost, "/",
+			strings.NewReader(fmt.Sprintf("https://example.com/%d", i)), nil)
+		assert.Equal(t, http.StatusCreated, statusCode)
+	}
+
+	statusCode, _, header := testRequest(t, ts, jar, http.MethodPost, "/api/shorten/batch",
+		strings.NewReader(`[{"correlation_id":"1","original_url":"https://example.com/batch"}]`), nil)
+	assert.Equal(t, http.StatusTooManyRequests, statusCode)
+	assert.Equal(t, "100", header.Get("Retry-After"))
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/api/user/urls/import",
+		strings.NewReader(`[{"original_url":"https://example.com/import"}]`), nil)
+	assert.Equal(t, http.StatusTooManyRequests, statusCode, "import is limited too")
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, "/api/user/urls", nil, nil)
+	assert.Equal(t, http.StatusOK, statusCode, "reading links is not limited")
+}
+
+func TestRouter_AuthRateLimit(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL:      "http://localhost:31222",
+		CookieKey:          []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		AuthRateLimit:      0.01,
+		AuthRateLimitBurst: 2,
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	acc := accounts.NewService(cfg, s)
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, acc, 0, 0, zap.NewNop())
+	store := ratelimit.NewMemoryStore()
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), acc, zap.NewNop(),
+		ratelimit.NewLimiter(cfg, store), ratelimit.NewAuthLimiter(cfg, store))
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	for i := 0; i < 2; i++ {
+		statusCode, _, _ := testRequest(t, ts, nil, http.MethodPost, "/api/auth/login",
+			strings.NewReader(`{"login":"john","password":"password"}`), nil)
+		assert.Equal(t, http.StatusUnauthorized, statusCode)
+	}
+
+	statusCode, _, header := testRequest(t, ts, nil, http.MethodPost, "/api/auth/login",
+		strings.NewReader(`{"login":"john","password":"password"}`), nil)
+	assert.Equal(t, http.StatusTooManyRequests, statusCode, "new cookie does not reset the limit")
+	assert.Equal(t, "100", header.Get("Retry-After"))
+
+	statusCode, _, _ = testRequest(t, ts, nil, http.MethodPost, "/api/auth/register",
+		strings.NewReader(`{"login":"john","password":"password"}`), nil)
+	assert.Equal(t, http.StatusTooManyRequests, statusCode)
+
+	for i := 0; i < 3; i++ {
+		statusCode, _, _ = testRequest(t, ts, nil, http.MethodPost, "/",
+			strings.NewReader(fmt.Sprintf("https://example.com/%d", i)), nil)
+		assert.Equal(t, http.StatusCreated, statusCode, "general limit is disabled")
+	}
+}
+
+func TestRouter_Quota(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		Quota:         configs.QuotaLimits{MaxLinks: 3, DailyLinks: 10},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	q, err := quota.NewChecker(cfg, s)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, q, nil, nil, 0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	statusCode, _, header := testRequest(t, ts, jar, http.MethodPost, "/",
+		strings.NewReader("https://example.com/1"), nil)
+	assert.Equal(t, http.StatusCreated, statusCode)
+	assert.Equal(t, "2", header.Get(quota.HeaderLinksRemaining))
+	assert.Equal(t, "9", header.Get(quota.HeaderDailyRemaining))
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/api/shorten/batch",
+		strings.NewReader(`[{"correlation_id":"1","original_url":"https://example.com/2"},`+
+			`{"correlation_id":"2","original_url":"https://example.com/3"},`+
+			`{"correlation_id":"3","original_url":"https://example.com/4"}]`), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode, "batch is rejected as a whole")
+
+	statusCode, _, header = testRequest(t, ts, jar, http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url":"https://example.com/2"}`), nil)
+	assert.Equal(t, http.StatusCreated, statusCode)
+	assert.Equal(t, "1", header.Get(quota.HeaderLinksRemaining))
+
+	statusCode, _, header = testRequest(t, ts, jar, http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url":"https://example.com/2"}`), nil)
+	assert.Equal(t, http.StatusConflict, statusCode)
+	assert.Equal(t, "1", header.Get(quota.HeaderLinksRemaining), "existing link does not use quota")
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/",
+		strings.NewReader("https://example.com/3"), nil)
+	assert.Equal(t, http.StatusCreated, statusCode)
+
+	statusCode, _, header = testRequest(t, ts, jar, http.MethodPost, "/",
+		strings.NewReader("https://example.com/4"), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode)
+	assert.Equal(t, "0", header.Get(quota.HeaderLinksRemaining))
+
+	statusCode, body, _ := testRequest(t, ts, jar, http.MethodGet, "/api/user/quota", nil, nil)
+	assert.Equal(t, http.StatusOK, statusCode)
+	var usage quota.Usage
+	require.NoError(t, json.Unmarshal(body, &usage))
+	assert.Equal(t, quota.Usage{MaxLinks: 3, ActiveLinks: 3, DailyLinks: 10, LinksToday: 3}, usage)
+}
+
+func TestRouter_Policy(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path,
+		[]byte(`{"deny": [{"host": "*.blocked.example"}, {"cidr": "127.0.0.0/8"}]}`), 0o600))
+	p, err := policy.New(path)
+	require.NoError(t, err)
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), p, rec, nil, nil, nil, 0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	statusCode, _, _ := testRequest(t, ts, jar, http.MethodPost, "/",
+		strings.NewReader("https://www.blocked.example/"), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url":"https://www.blocked.example/"}`), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode)
+
+	for _, url := range []string{"http://2130706433/", "http://0x7f.1/", "http://017700000001/", "http://127.1/"} {
+		statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/", strings.NewReader(url), nil)
+		assert.Equal(t, http.StatusForbidden, statusCode, url)
+	}
+
+	statusCode, body, _ := testRequest(t, ts, jar, http.MethodPost, "/",
+		strings.NewReader("https://www.example.com/"), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	shortPath := strings.TrimPrefix(string(body), cfg.ServerBaseURL)
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, shortPath, nil, nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"deny": [{"host": "www.example.com"}]}`), 0o600))
+	require.NoError(t, p.Reload())
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, shortPath, nil, nil)
+	assert.Equal(t, http.StatusForbidden, statusCode, "newly blocked link does not resolve")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0o600))
+	require.NoError(t, p.Reload())
+	statusCode, body, _ = testRequest(t, ts, jar, http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url":"https://hits.example.com/","max_hits":1}`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	var short handlers.ShortenURLResponse
+	require.NoError(t, json.Unmarshal(body, &short))
+	shortPath = strings.TrimPrefix(short.Result, cfg.ServerBaseURL)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"deny": [{"host": "hits.example.com"}]}`), 0o600))
+	require.NoError(t, p.Reload())
+	for i := 0; i < 2; i++ {
+		statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, shortPath, nil, nil)
+		assert.Equal(t, http.StatusForbidden, statusCode)
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0o600))
+	require.NoError(t, p.Reload())
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, shortPath, nil, nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, statusCode, "blocked requests do not use up hits")
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, shortPath, nil, nil)
+	assert.Equal(t, http.StatusGone, statusCode)
+}
+
+func TestRouter_Teams(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, teams.NewService(cfg, s), nil,
+		0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	owner, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+	viewer, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	statusCode, body, _ := testRequest(t, ts, owner, http.MethodPost, "/api/teams",
+		strings.NewReader(`{"name":"Marketing"}`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	var team repositories.Membership
+	require.NoError(t, json.Unmarshal(body, &team))
+	assert.Equal(t, "Marketing", team.Name)
+	assert.Equal(t, repositories.RoleOwner, team.Role)
+	teamPath := "/api/teams/" + team.ID.String()
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url":"https://team.example.com/","team":"`+team.ID.String()+`"}`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	var short handlers.ShortenURLResponse
+	require.NoError(t, json.Unmarshal(body, &short))
+	id := strings.TrimPrefix(short.Result, cfg.ServerBaseURL+"/")
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodGet, "/api/user/urls?team="+team.ID.String(), nil, nil)
+	assert.Equal(t, http.StatusNotFound, statusCode, "not a member yet")
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodPost, teamPath+"/invites",
+		strings.NewReader(`{"role":"viewer"}`), nil)
+	assert.Equal(t, http.StatusNotFound, statusCode, "only members can see the team")
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodPost, teamPath+"/invites",
+		strings.NewReader(`{"role":"viewer"}`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	var invite teams.Invite
+	require.NoError(t, json.Unmarshal(body, &invite))
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodPost, "/api/teams/join",
+		strings.NewReader(`{"token":"`+invite.Token+`"}`), nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodPost, "/api/teams/join",
+		strings.NewReader(`{"token":"`+invite.Token+`"}`), nil)
+	assert.Equal(t, http.StatusConflict, statusCode, "invites are single use")
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodDelete, teamPath+"/invites/"+invite.ID.String(), nil, nil)
+	assert.Equal(t, http.StatusConflict, statusCode, "used invites can not be revoked")
+
+	statusCode, body, _ = testRequest(t, ts, viewer, http.MethodGet, "/api/user/urls?team="+team.ID.String(), nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Contains(t, string(body), "https://team.example.com/")
+
+	statusCode, body, _ = testRequest(t, ts, viewer, http.MethodGet, teamPath+"/members", nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	var members []repositories.TeamMember
+	require.NoError(t, json.Unmarshal(body, &members))
+	require.Len(t, members, 2)
+	viewerID := members[1].User
+	assert.Equal(t, repositories.RoleViewer, members[1].Role)
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url":"https://viewer.example.com/","team":"`+team.ID.String()+`"}`), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode, "viewer can not create team links")
+
+	statusCode, body, _ = testRequest(t, ts, viewer, http.MethodGet, "/api/user/urls/export", nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	var exported bulk.Link
+	require.NoError(t, json.Unmarshal(body, &exported))
+	assert.Equal(t, id, exported.ID)
+	require.NotNil(t, exported.Team)
+	assert.Equal(t, team.ID, *exported.Team)
+
+	statusCode, body, _ = testRequest(t, ts, viewer, http.MethodPost, "/api/user/urls/import",
+		strings.NewReader(`{"original_url":"https://viewer.example.com/","team":"`+team.ID.String()+`"}`),
+		map[string]string{"Content-Type": "application/x-ndjson"})
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Contains(t, string(body), `"error":"forbidden"`, "import checks the team role")
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodGet, "/api/user/urls/"+id+"/stats", nil, nil)
+	assert.Equal(t, http.StatusOK, statusCode, "viewer sees team link stats")
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodPut, teamPath+"/members/"+viewerID.String(),
+		strings.NewReader(`{"role":"owner"}`), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodPut, teamPath+"/members/"+viewerID.String(),
+		strings.NewReader(`{"role":"editor"}`), nil)
+	assert.Equal(t, http.StatusOK, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodPost, "/api/user/urls/transfer",
+		strings.NewReader(`{"ids":["`+id+`"]}`), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode, "editor can not take team links")
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodPost, "/api/user/urls/transfer",
+		strings.NewReader(`{"ids":["`+id+`"]}`), nil)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodGet, "/api/user/urls?team="+team.ID.String(), nil, nil)
+	assert.Equal(t, http.StatusNoContent, statusCode, "link left the team")
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodDelete, teamPath+"/members/"+viewerID.String(), nil, nil)
+	assert.Equal(t, http.StatusNoContent, statusCode, "members can leave")
+
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodGet, "/api/teams", nil, nil)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodPost, teamPath+"/invites",
+		strings.NewReader(`{"role":"viewer"}`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	require.NoError(t, json.Unmarshal(body, &invite))
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodDelete, teamPath+"/invites/"+invite.ID.String(), nil, nil)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+	statusCode, _, _ = testRequest(t, ts, viewer, http.MethodPost, "/api/teams/join",
+		strings.NewReader(`{"token":"`+invite.Token+`"}`), nil)
+	assert.Equal(t, http.StatusConflict, statusCode, "revoked invite")
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodDelete, teamPath+"/invites/"+uuid.NewString(), nil, nil)
+	assert.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func TestRouter_UpdateURL(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, nil, 0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	owner, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+	stranger, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	statusCode, body, _ := testRequest(t, ts, owner, http.MethodPost, "/",
+		strings.NewReader("https://www.example.com/flyer"), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	shortPath := strings.TrimPrefix(string(body), cfg.ServerBaseURL)
+	linkPath := "/api/user/urls" + shortPath
+
+	statusCode, _, _ = testRequest(t, ts, stranger, http.MethodPatch, linkPath,
+		strings.NewReader(`{"url":"https://evil.example.com/"}`), nil)
+	assert.Equal(t, http.StatusForbidden, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodPatch, linkPath,
+		strings.NewReader(`{"url":"not a url"}`), nil)
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodPatch, linkPath,
+		strings.NewReader(`{"url":"https://www.example.com/landing"}`), nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	var link handlers.UserLink
+	require.NoError(t, json.Unmarshal(body, &link))
+	assert.Equal(t, cfg.ServerBaseURL+shortPath, link.ShortURL)
+	assert.Equal(t, "https://www.example.com/landing", link.OriginalURL)
+
+	statusCode, _, header := testRequest(t, ts, stranger, http.MethodGet, shortPath, nil, nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
+	assert.Equal(t, "https://www.example.com/landing", header.Get("Location"))
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodGet, linkPath+"/history", nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	var versions []repositories.URLVersion
+	require.NoError(t, json.Unmarshal(body, &versions))
+	require.Len(t, versions, 1)
+	assert.Equal(t, "https://www.example.com/flyer", versions[0].URL)
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodPatch, "/api/user/urls/missing",
+		strings.NewReader(`{"url":"https://www.example.com/"}`), nil)
+	assert.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func TestRouter_Trash(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	_, trusted, _ := net.ParseCIDR("127.0.0.1/32")
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, trusted,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, nil, time.Hour, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	owner, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+	stranger, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	statusCode, body, _ := testRequest(t, ts, owner, http.MethodPost, "/",
+		strings.NewReader("https://www.example.com/trash"), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	shortPath := strings.TrimPrefix(string(body), cfg.ServerBaseURL)
+	id := strings.TrimPrefix(shortPath, "/")
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodGet, "/api/user/urls/trash", nil, nil)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodDelete, "/api/user/urls",
+		strings.NewReader(`["`+id+`"]`), nil)
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodGet, "/api/user/urls/trash", nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	var links []handlers.DeletedUserLink
+	require.NoError(t, json.Unmarshal(body, &links))
+	require.Len(t, links, 1)
+	assert.Equal(t, cfg.ServerBaseURL+shortPath, links[0].ShortURL)
+	assert.Equal(t, links[0].DeletedAt.Add(time.Hour), links[0].RestoreUntil)
+
+	statusCode, body, _ = testRequest(t, ts, stranger, http.MethodPost, "/api/user/urls/restore",
+		strings.NewReader(`["`+id+`"]`), nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.JSONEq(t, `[]`, string(body))
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodPost, "/api/user/urls/restore",
+		strings.NewReader(`["`+id+`"]`), nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.JSONEq(t, `["`+id+`"]`, string(body))
+
+	statusCode, _, _ = testRequest(t, ts, stranger, http.MethodGet, shortPath, nil, nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodPost, "/api/internal/trash/purge", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, statusCode, "retention is not configured")
+
+	statusCode, _, _ = testRequest(t, ts, owner, http.MethodDelete, "/api/user/urls",
+		strings.NewReader(`["`+id+`"]`), nil)
+	require.Equal(t, http.StatusAccepted, statusCode)
+
+	statusCode, body, _ = testRequest(t, ts, owner, http.MethodPost, "/api/internal/trash/purge?older_than=0s",
+		nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.JSONEq(t, `{"purged":1}`, string(body))
+
+	statusCode, _, _ = testRequest(t, ts, stranger, http.MethodGet, shortPath, nil, nil)
+	assert.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func TestRouter_BatchErrors(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		URLDedup:      "global",
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	cfg.AliasAlphabet, cfg.AliasMinLength, cfg.AliasMaxLength = "abcdefghijklmnopqrstuvwxyz", 3, 64
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, nil, 0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	statusCode, _, _ := testRequest(t, ts, jar, http.MethodPost, "/api/shorten",
+		strings.NewReader(`{"url":"https://www.example.com/taken","alias":"taken"}`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	statusCode, body, _ := testRequest(t, ts, jar, http.MethodPost, "/api/shorten/batch",
+		strings.NewReader(`[
+			{"correlation_id":"new","original_url":"https://www.example.com/new"},
+			{"correlation_id":"alias","original_url":"https://www.example.com/alias","alias":"taken"},
+			{"correlation_id":"exists","original_url":"https://www.example.com/taken"}
+		]`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	var response []handlers.BatchResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.Len(t, response, 3)
+	assert.NotEmpty(t, response[0].ShortURL)
+	assert.Empty(t, response[0].Error)
+	assert.Equal(t, "alias", response[1].CorrelationID)
+	assert.Empty(t, response[1].ShortURL)
+	assert.Equal(t, repositories.ErrIDAlreadyExists.Error(), response[1].Error)
+	assert.Equal(t, cfg.ServerBaseURL+"/taken", response[2].ShortURL)
+	assert.Empty(t, response[2].Error)
+}
+
+func TestRouter_Accounts(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	acc := accounts.NewService(cfg, s)
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, acc, 0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), acc, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	shorten := func(jar http.CookieJar, headers map[string]string, url string) (int, repositories.ID, http.Header) {
+		statusCode, body, header := testRequest(t, ts, jar, http.MethodPost, "/api/shorten",
+			strings.NewReader(`{"url":"`+url+`"}`), headers)
+		var short handlers.ShortenURLResponse
+		_ = json.Unmarshal(body, &short)
+		return statusCode, strings.TrimPrefix(short.Result, cfg.ServerBaseURL+"/"), header
+	}
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+
+	statusCode, anonymousID, _ := shorten(jar, nil, "https://anonymous.example.com/")
+	require.Equal(t, http.StatusCreated, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, "/api/account", nil, nil)
+	assert.Equal(t, http.StatusForbidden, statusCode, "anonymous user has no account")
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/api/auth/register",
+		strings.NewReader(`{"login":"john","password":"short"}`), nil)
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+
+	statusCode, body, _ := testRequest(t, ts, jar, http.MethodPost, "/api/auth/register",
+		strings.NewReader(`{"login":"john","password":"password","claim":true}`), nil)
+	require.Equal(t, http.StatusCreated, statusCode)
+	var account handlers.AccountResponse
+	require.NoError(t, json.Unmarshal(body, &account))
+	assert.Equal(t, "john", account.Login)
+	assert.Equal(t, []repositories.ID{anonymousID}, account.Claimed)
+
+	statusCode, _, _ = testRequest(t, ts, nil, http.MethodPost, "/api/auth/register",
+		strings.NewReader(`{"login":"John","password":"password"}`), nil)
+	assert.Equal(t, http.StatusConflict, statusCode)
+
+	statusCode, _, _ = testRequest(t, ts, nil, http.MethodPost, "/api/auth/login",
+		strings.NewReader(`{"login":"john","password":"wrong password"}`), nil)
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+
+	other, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+	statusCode, _, _ = testRequest(t, ts, other, http.MethodPost, "/api/auth/login",
+		strings.NewReader(`{"login":"john","password":"password"}`), nil)
+	require.Equal(t, http.StatusOK, statusCode)
+
+	statusCode, body, _ = testRequest(t, ts, other, http.MethodGet, "/api/user/urls", nil, nil)
+	require.Equal(t, http.StatusOK, statusCode, "login restores the account cookie")
+	assert.Contains(t, string(body), "https://anonymous.example.com/")
+
+	createKey := func(scope repositories.Scope) handlers.APIKeyResponse {
+		statusCode, body, _ := testRequest(t, ts, jar, http.MethodPost, "/api/account/keys",
+			strings.NewReader(`{"name":"ci","scope":"`+string(scope)+`"}`), nil)
+		require.Equal(t, http.StatusCreated, statusCode)
+		var key handlers.APIKeyResponse
+		require.NoError(t, json.Unmarshal(body, &key))
+		require.NotEmpty(t, key.Key)
+		return key
+	}
+	readKey, writeKey := createKey(repositories.ScopeRead), createKey(repositories.ScopeWrite)
+	bearer := func(key handlers.APIKeyResponse) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + key.Key}
+	}
+
+	statusCode, body, header := testRequest(t, ts, nil, http.MethodGet, "/api/user/urls", nil, bearer(readKey))
+	require.Equal(t, http.StatusOK, statusCode)
+	assert.Contains(t, string(body), "https://anonymous.example.com/")
+	assert.Empty(t, header.Get("Set-Cookie"), "API key requests get no cookie")
+
+	statusCode, _, _ = shorten(nil, bearer(readKey), "https://read.example.com/")
+	assert.Equal(t, http.StatusForbidden, statusCode, "read key can not create links")
+	statusCode, _, _ = shorten(nil, bearer(writeKey), "https://write.example.com/")
+	assert.Equal(t, http.StatusCreated, statusCode)
+	statusCode, _, header = shorten(nil, map[string]string{"Authorization": "Bearer usk_wrong"}, "https://x.example.com/")
+	assert.Equal(t, http.StatusUnauthorized, statusCode)
+	assert.NotEmpty(t, header.Get("WWW-Authenticate"))
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodDelete, "/api/account/keys/"+readKey.ID.String(), nil, nil)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+	statusCode, _, _ = testRequest(t, ts, nil, http.MethodGet, "/api/user/urls", nil, bearer(readKey))
+	assert.Equal(t, http.StatusUnauthorized, statusCode, "revoked key")
+
+	statusCode, body, _ = testRequest(t, ts, jar, http.MethodGet, "/api/account/keys", nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	var keys []handlers.APIKeyResponse
+	require.NoError(t, json.Unmarshal(body, &keys))
+	require.Len(t, keys, 2)
+	assert.NotNil(t, keys[0].RevokedAt)
+	assert.Empty(t, keys[0].Key, "keys are shown only once")
+
+	statusCode, laterID, header := shorten(nil, nil, "https://later.example.com/")
+	require.Equal(t, http.StatusCreated, statusCode)
+	cookies := (&http.Response{Header: header}).Cookies()
+	require.Len(t, cookies, 1)
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/api/account/claim",
+		strings.NewReader(`{"identity":"garbage"}`), nil)
+	assert.Equal(t, http.StatusBadRequest, statusCode)
+
+	statusCode, body, _ = testRequest(t, ts, jar, http.MethodPost, "/api/account/claim",
+		strings.NewReader(`{"identity":"`+cookies[0].Value+`"}`), nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	var claim handlers.ClaimResponse
+	require.NoError(t, json.Unmarshal(body, &claim))
+	assert.Equal(t, []repositories.ID{laterID}, claim.Claimed)
+
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodPost, "/api/auth/logout", nil, nil)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+	statusCode, _, _ = testRequest(t, ts, jar, http.MethodGet, "/api/account", nil, nil)
+	assert.Equal(t, http.StatusForbidden, statusCode, "logout removes the cookie")
+	statusCode, _, _ = testRequest(t, ts, other, http.MethodGet, "/api/account", nil, nil)
+	assert.Equal(t, http.StatusForbidden, statusCode, "logout ends sessions on other devices")
+	statusCode, _, _ = testRequest(t, ts, nil, http.MethodGet, "/api/account", nil,
+		map[string]string{"Cookie": authenticator.CookieName + "=" + account.Session})
+	assert.Equal(t, http.StatusForbidden, statusCode, "a copied session cookie stops working")
+
+	statusCode, _, _ = testRequest(t, ts, other, http.MethodPost, "/api/auth/login",
+		strings.NewReader(`{"login":"john","password":"password"}`), nil)
+	require.Equal(t, http.StatusOK, statusCode)
+	statusCode, _, _ = testRequest(t, ts, other, http.MethodGet, "/api/account", nil, nil)
+	assert.Equal(t, http.StatusOK, statusCode)
+}
+
+// failingStorager - хранилище, у которого Get всегда возвращает err.
+type failingStorager struct {
+	storage.Storager
+	err error
+}
+
+func (st failingStorager) Get(_ context.Context, _ repositories.ID) (repositories.URL, bool, error) {
+	return "", false, st.err
+}
+
+func (st failingStorager) Add(
+	_ context.Context, _ repositories.URL, _ repositories.User, _ repositories.LinkOptions,
+) (repositories.ID, error) {
+	return "", st.err
+}
+
+func TestRouter_GetURLErrors(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "not found", err: repositories.ErrURLNotFound, code: http.StatusNotFound},
+		{name: "expired", err: repositories.ErrLinkExpired, code: http.StatusGone},
+		{name: "storage failure", err: context.DeadlineExceeded, code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := failingStorager{Storager: s, err: tt.err}
+			rec := analytics.NewRecorder(st)
+			defer rec.Close(context.Background())
+
+			h := handlers.NewHandler(st, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+				alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, nil, 0, 0, zap.NewNop())
+			m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+			ts := httptest.NewServer(NewRouter(h, m))
+			defer ts.Close()
+
+			statusCode, _, _ := testRequest(t, ts, nil, http.MethodGet, "/abc", nil, nil)
+			assert.Equal(t, tt.code, statusCode)
+		})
+	}
+}
+
+func TestRouter_CreateURLErrors(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "plain body", path: "/", body: "https://example.com/"},
+		{name: "json", path: "/api/shorten", body: `{"url":"https://example.com/"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := failingStorager{Storager: s, err: context.DeadlineExceeded}
+			rec := analytics.NewRecorder(st)
+			defer rec.Close(context.Background())
+
+			core, logs := observer.New(zap.ErrorLevel)
+			h := handlers.NewHandler(st, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+				alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, nil, nil, nil, 0, 0, zap.New(core))
+			m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+			ts := httptest.NewServer(NewRouter(h, m))
+			defer ts.Close()
+
+			statusCode, _, _ := testRequest(t, ts, nil, http.MethodPost, tt.path, strings.NewReader(tt.body), nil)
+			assert.Equal(t, http.StatusInternalServerError, statusCode)
+			assert.Equal(t, 1, logs.FilterMessage("unable to add link").Len(), "storage error is logged")
+		})
+	}
+}
+
+func TestRouter_QuotaConcurrent(t *testing.T) {
+	cfg := configs.Config{
+		ServerBaseURL: "http://localhost:31222",
+		CookieKey:     []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		Quota:         configs.QuotaLimits{MaxLinks: 3},
+	}
+
+	s, err := storage.NewStorager(cfg, nil)
+	require.NoError(t, err)
+
+	rec := analytics.NewRecorder(s)
+	defer rec.Close(context.Background())
+
+	q, err := quota.NewChecker(cfg, s)
+	require.NoError(t, err)
+
+	h := handlers.NewHandler(s, cfg.EnableHTTPS, cfg.ServerBaseURL, nil,
+		alias.NewValidator(cfg), urlnorm.NewNormalizer(cfg), nil, rec, q, nil, nil, 0, 0, zap.NewNop())
+	m := middlewares.NewMiddlewares(cfg, authenticator.New(cfg), nil, zap.NewNop(), nil, nil)
+	ts := httptest.NewServer(NewRouter(h, m))
+	defer ts.Close()
+
+	jar, err := cookiejar.New(&cookiejar.Options{})
+	require.NoError(t, err)
+	statusCode, _, _ := testRequest(t, ts, jar, http.MethodGet, "/api/user/quota", nil, nil)
+	require.Equal(t, http.StatusOK, statusCode)
+
+	client := &http.Client{Jar: jar}
+	codes := make(chan int, 20)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(codes); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.Post(ts.URL+"/api/shorten", "application/json",
+				strings.NewReader(fmt.Sprintf(`{"url":"https://example.com/%d"}`, i)))
+			if err != nil {
+				codes <- 0
+				return
+			}
+			_ = resp.Body.Close()
+			codes <- resp.StatusCode
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		if code == http.StatusCreated {
+			created++
+			continue
+		}
+		assert.Equal(t, http.StatusForbidden, code)
+	}
+	assert.Equal(t, 3, created, "concurrent requests can not exceed the quota")
 }
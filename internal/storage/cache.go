@@ -0,0 +1,547 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// CacheStats - счетчики кэша переходов.
+type CacheStats struct {
+	Hits      uint64 // Сколько раз ссылка нашлась в кэше.
+	Misses    uint64 // Сколько раз пришлось обратиться к хранилищу.
+	Evictions uint64 // Сколько записей вытеснено из-за ограничения размера.
+	Size      int    // Сколько записей сейчас в кэше.
+}
+
+// cachedStorager - обертка над Storager, которая кэширует ссылки для Get и GetLink в памяти процесса.
+//
+// Кэш ограничен по количеству записей (вытесняются давно не использованные) и по времени жизни записи.
+// Неизвестные ID тоже кэшируются, на более короткое время. Ссылки с ограничением MaxHits не кэшируются:
+// каждый переход по ним нужно засчитать в хранилище.
+//
+// Записи сбрасываются при изменениях через эту обертку. Изменения, сделанные другими экземплярами
+// сервиса, и отложенное удаление в PsqlStorage становятся видны после истечения времени жизни записи.
+//
+// Методы перечислены явно, а не получены встраиванием, чтобы новый метод Storager нельзя было забыть учесть.
+type cachedStorager struct {
+	st    Storager
+	cache *linkCache
+}
+
+// Cache - обернуть хранилище кэшем для Get и GetLink.
+//
+// size - максимальное количество записей, ttl - время жизни найденной ссылки,
+// negativeTTL - время жизни записи о том, что ссылки нет, 0 - не кэшировать отсутствие ссылки.
+func Cache(st Storager, size int, ttl, negativeTTL time.Duration) Storager {
+	return &cachedStorager{
+		st:    st,
+		cache: newLinkCache(size, ttl, negativeTTL),
+	}
+}
+
+// CacheStats - получить счетчики кэша.
+func (c *cachedStorager) CacheStats() CacheStats {
+	return c.cache.stats()
+}
+
+// Collectors - метрики кэша и обернутого хранилища, если оно их отдает.
+func (c *cachedStorager) Collectors() []prometheus.Collector {
+	counter := func(name, help string, fn func(s CacheStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: "urlshortener",
+			Subsystem: "cache",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return float64(fn(c.CacheStats())) })
+	}
+
+	cs := []prometheus.Collector{
+		counter("hits_total", "Number of redirects served from the link cache.",
+			func(s CacheStats) uint64 { return s.Hits }),
+		counter("misses_total", "Number of redirects that had to query the storage.",
+			func(s CacheStats) uint64 { return s.Misses }),
+		counter("evictions_total", "Number of link cache entries evicted by the size limit.",
+			func(s CacheStats) uint64 { return s.Evictions }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "urlshortener",
+			Subsystem: "cache",
+			Name:      "entries",
+			Help:      "Number of entries in the link cache.",
+		}, func() float64 { return float64(c.CacheStats().Size) }),
+	}
+
+	if inner, ok := c.st.(interface{ Collectors() []prometheus.Collector }); ok {
+		cs = append(cs, inner.Collectors()...)
+	}
+
+	return cs
+}
+
+func (c *cachedStorager) Add(
+	ctx context.Context, url repositories.URL, userID repositories.User, opts repositories.LinkOptions,
+) (id repositories.ID, err error) {
+	id, err = c.st.Add(ctx, url, userID, opts)
+	if err == nil {
+		c.cache.invalidate(id)
+	}
+	return id, err
+}
+
+func (c *cachedStorager) AddBatch(
+	ctx context.Context, links []repositories.BatchLink, userID repositories.User,
+) (results []repositories.BatchResult, err error) {
+	results, err = c.st.AddBatch(ctx, links, userID)
+	for _, res := range results {
+		if res.Err == nil {
+			c.cache.invalidate(res.ID)
+		}
+	}
+	return results, err
+}
+
+// Get - получить оригинальную ссылку по ID из кэша, а если ее там нет - из хранилища.
+func (c *cachedStorager) Get(
+	ctx context.Context, id repositories.ID,
+) (url repositories.URL, deleted bool, err error) {
+	e, err := c.entry(ctx, id)
+	if err != nil {
+		return "", false, err
+	}
+	if e.uncacheable {
+		return c.st.Get(ctx, id)
+	}
+	return e.result(time.Now())
+}
+
+// entry - запись о ссылке id из кэша, а если ее там нет - прочитанная из хранилища и положенная в кэш.
+//
+// Для только что прочитанной ссылки с ограничением MaxHits в записи есть link, в кэш она кладется без него.
+func (c *cachedStorager) entry(ctx context.Context, id repositories.ID) (e cacheEntry, err error) {
+	now := time.Now()
+
+	e, gen, ok := c.cache.get(id, now)
+	if ok {
+		return e, nil
+	}
+
+	link, err := c.st.GetLink(ctx, id)
+	if errors.Is(err, repositories.ErrURLNotFound) {
+		e = cacheEntry{notFound: true}
+		c.cache.put(id, e, gen, now)
+		return e, nil
+	}
+	if err != nil {
+		return cacheEntry{}, err
+	}
+
+	if link.MaxHits > 0 {
+		c.cache.put(id, cacheEntry{uncacheable: true}, gen, now)
+		return cacheEntry{uncacheable: true, link: link}, nil
+	}
+
+	e = cacheEntry{url: link.URL, deleted: link.Deleted, expiresAt: link.ExpiresAt, link: link}
+	c.cache.put(id, e, gen, now)
+	return e, nil
+}
+
+func (c *cachedStorager) GetUserLinks(
+	ctx context.Context, user repositories.User,
+) (links []repositories.LinkData, err error) {
+	return c.st.GetUserLinks(ctx, user)
+}
+
+func (c *cachedStorager) ListUserLinks(
+	ctx context.Context, user repositories.User, q repositories.LinkQuery,
+) (page repositories.LinkPage, err error) {
+	return c.st.ListUserLinks(ctx, user, q)
+}
+
+func (c *cachedStorager) CountUserLinks(
+	ctx context.Context, user repositories.User, since time.Time,
+) (counts repositories.LinkCounts, err error) {
+	return c.st.CountUserLinks(ctx, user, since)
+}
+
+// GetLink - получить данные ссылки по ID из кэша, а если их там нет - из хранилища.
+//
+// Счетчик переходов в данных из кэша может отставать на время жизни записи,
+// поэтому для ссылок с ограничением MaxHits запрос всегда передается в хранилище.
+func (c *cachedStorager) GetLink(
+	ctx context.Context, id repositories.ID,
+) (link repositories.LinkData, err error) {
+	e, err := c.entry(ctx, id)
+	if err != nil {
+		return repositories.LinkData{}, err
+	}
+	if e.notFound {
+		return repositories.LinkData{}, repositories.ErrURLNotFound
+	}
+	if e.uncacheable && e.link.ID == "" {
+		return c.st.GetLink(ctx, id)
+	}
+	return e.link, nil
+}
+
+func (c *cachedStorager) Iterate(
+	ctx context.Context, after repositories.ID, fn func(link repositories.LinkData) error,
+) (err error) {
+	return c.st.Iterate(ctx, after, fn)
+}
+
+func (c *cachedStorager) Import(ctx context.Context, link repositories.LinkData) (err error) {
+	err = c.st.Import(ctx, link)
+	c.cache.invalidate(link.ID)
+	return err
+}
+
+func (c *cachedStorager) DeleteUserLinks(
+	ctx context.Context, ids []repositories.ID, user repositories.User,
+) (deletion repositories.DeletionID, err error) {
+	deletion, err = c.st.DeleteUserLinks(ctx, ids, user)
+	c.cache.invalidate(ids...)
+	return deletion, err
+}
+
+func (c *cachedStorager) GetDeletionStatus(
+	ctx context.Context, deletion repositories.DeletionID, user repositories.User,
+) (s repositories.DeletionStatus, err error) {
+	return c.st.GetDeletionStatus(ctx, deletion, user)
+}
+
+func (c *cachedStorager) GetDeletedUserLinks(
+	ctx context.Context, user repositories.User,
+) (links []repositories.LinkData, err error) {
+	return c.st.GetDeletedUserLinks(ctx, user)
+}
+
+func (c *cachedStorager) RestoreUserLinks(
+	ctx context.Context, ids []repositories.ID, user repositories.User, since time.Time,
+) (restored []repositories.ID, err error) {
+	restored, err = c.st.RestoreUserLinks(ctx, ids, user, since)
+	c.cache.invalidate(restored...)
+	return restored, err
+}
+
+func (c *cachedStorager) PurgeDeleted(ctx context.Context, before time.Time) (count int64, err error) {
+	count, err = c.st.PurgeDeleted(ctx, before)
+	c.cache.invalidateIf(func(e cacheEntry) bool { return e.deleted })
+	return count, err
+}
+
+func (c *cachedStorager) Update(
+	ctx context.Context, id repositories.ID, url repositories.URL, user repositories.User,
+) (link repositories.LinkData, err error) {
+	link, err = c.st.Update(ctx, id, url, user)
+	c.cache.invalidate(id)
+	return link, err
+}
+
+func (c *cachedStorager) GetLinkHistory(
+	ctx context.Context, id repositories.ID, user repositories.User,
+) (versions []repositories.URLVersion, err error) {
+	return c.st.GetLinkHistory(ctx, id, user)
+}
+
+func (c *cachedStorager) TransferLinks(
+	ctx context.Context, ids []repositories.ID, user repositories.User, team repositories.TeamID,
+) (err error) {
+	err = c.st.TransferLinks(ctx, ids, user, team)
+	c.cache.invalidate(ids...)
+	return err
+}
+
+func (c *cachedStorager) CreateTeam(
+	ctx context.Context, team repositories.Team, owner repositories.User,
+) (err error) {
+	return c.st.CreateTeam(ctx, team, owner)
+}
+
+func (c *cachedStorager) GetTeamRole(
+	ctx context.Context, team repositories.TeamID, user repositories.User,
+) (role repositories.Role, err error) {
+	return c.st.GetTeamRole(ctx, team, user)
+}
+
+func (c *cachedStorager) GetUserTeams(
+	ctx context.Context, user repositories.User,
+) (teams []repositories.Membership, err error) {
+	return c.st.GetUserTeams(ctx, user)
+}
+
+func (c *cachedStorager) GetTeamMembers(
+	ctx context.Context, team repositories.TeamID,
+) (members []repositories.TeamMember, err error) {
+	return c.st.GetTeamMembers(ctx, team)
+}
+
+func (c *cachedStorager) SetTeamMember(
+	ctx context.Context, team repositories.TeamID, user repositories.User, role repositories.Role,
+) (err error) {
+	return c.st.SetTeamMember(ctx, team, user, role)
+}
+
+func (c *cachedStorager) RemoveTeamMember(
+	ctx context.Context, team repositories.TeamID, user repositories.User,
+) (err error) {
+	return c.st.RemoveTeamMember(ctx, team, user)
+}
+
+func (c *cachedStorager) CreateInvite(ctx context.Context, invite repositories.TeamInvite) (err error) {
+	return c.st.CreateInvite(ctx, invite)
+}
+
+func (c *cachedStorager) AcceptInvite(
+	ctx context.Context, id repositories.InviteID, user repositories.User, at time.Time,
+) (invite repositories.TeamInvite, err error) {
+	return c.st.AcceptInvite(ctx, id, user, at)
+}
+
+func (c *cachedStorager) RevokeInvite(
+	ctx context.Context, id repositories.InviteID, team repositories.TeamID, at time.Time,
+) (err error) {
+	return c.st.RevokeInvite(ctx, id, team, at)
+}
+
+func (c *cachedStorager) CreateAccount(ctx context.Context, account repositories.Account) (err error) {
+	return c.st.CreateAccount(ctx, account)
+}
+
+func (c *cachedStorager) GetAccount(
+	ctx context.Context, id repositories.User,
+) (account repositories.Account, err error) {
+	return c.st.GetAccount(ctx, id)
+}
+
+func (c *cachedStorager) GetAccountByLogin(
+	ctx context.Context, login string,
+) (account repositories.Account, err error) {
+	return c.st.GetAccountByLogin(ctx, login)
+}
+
+func (c *cachedStorager) CreateAPIKey(ctx context.Context, key repositories.APIKey) (err error) {
+	return c.st.CreateAPIKey(ctx, key)
+}
+
+func (c *cachedStorager) GetAPIKey(ctx context.Context, hash []byte) (key repositories.APIKey, err error) {
+	return c.st.GetAPIKey(ctx, hash)
+}
+
+func (c *cachedStorager) GetAccountAPIKeys(
+	ctx context.Context, account repositories.User,
+) (keys []repositories.APIKey, err error) {
+	return c.st.GetAccountAPIKeys(ctx, account)
+}
+
+func (c *cachedStorager) RevokeAPIKey(
+	ctx context.Context, id repositories.KeyID, account repositories.User, at time.Time,
+) (err error) {
+	return c.st.RevokeAPIKey(ctx, id, account, at)
+}
+
+func (c *cachedStorager) RevokeSessions(
+	ctx context.Context, account repositories.User, at time.Time,
+) (err error) {
+	return c.st.RevokeSessions(ctx, account, at)
+}
+
+func (c *cachedStorager) ClaimLinks(
+	ctx context.Context, from, to repositories.User,
+) (claimed []repositories.ID, err error) {
+	claimed, err = c.st.ClaimLinks(ctx, from, to)
+	c.cache.invalidate(claimed...)
+	return claimed, err
+}
+
+func (c *cachedStorager) AddClicks(ctx context.Context, clicks []repositories.Click) (err error) {
+	return c.st.AddClicks(ctx, clicks)
+}
+
+func (c *cachedStorager) GetLinkStats(
+	ctx context.Context, id repositories.ID, user repositories.User,
+) (stats repositories.LinkStats, err error) {
+	return c.st.GetLinkStats(ctx, id, user)
+}
+
+func (c *cachedStorager) PurgeExpired(ctx context.Context) (count int64, err error) {
+	count, err = c.st.PurgeExpired(ctx)
+	now := time.Now()
+	c.cache.invalidateIf(func(e cacheEntry) bool { return !e.expiresAt.IsZero() && !now.Before(e.expiresAt) })
+	return count, err
+}
+
+func (c *cachedStorager) GetStats(ctx context.Context) (stats repositories.ServiceStats, err error) {
+	return c.st.GetStats(ctx)
+}
+
+func (c *cachedStorager) Pool(ctx context.Context) (ok bool) {
+	return c.st.Pool(ctx)
+}
+
+func (c *cachedStorager) Close(ctx context.Context) (err error) {
+	return c.st.Close(ctx)
+}
+
+// cacheEntry - запись кэша ссылок.
+type cacheEntry struct {
+	expiresAt   time.Time // Срок действия ссылки.
+	url         repositories.URL
+	deleted     bool
+	notFound    bool                  // Ссылки с таким ID нет.
+	uncacheable bool                  // Ссылку нельзя кэшировать, Get нужно передать в хранилище.
+	link        repositories.LinkData // Данные ссылки для GetLink.
+}
+
+// result - ответ Get для ссылки из записи.
+func (e cacheEntry) result(now time.Time) (url repositories.URL, deleted bool, err error) {
+	if e.notFound {
+		return "", false, repositories.ErrURLNotFound
+	}
+	if e.deleted {
+		return e.url, true, nil
+	}
+	if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+		return "", false, repositories.ErrLinkExpired
+	}
+	return e.url, false, nil
+}
+
+// linkCache - LRU-кэш записей о ссылках с ограничением времени жизни.
+type linkCache struct {
+	mu          sync.Mutex
+	items       map[repositories.ID]*list.Element
+	order       *list.List // Записи от недавно использованных к давно использованным.
+	size        int
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	// gen увеличивается при каждом сбросе записей: запись, прочитанная из хранилища
+	// до сброса, могла устареть, и ее нельзя класть в кэш.
+	gen       uint64
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// cacheItem - элемент списка linkCache.order.
+type cacheItem struct {
+	validUntil time.Time
+	id         repositories.ID
+	entry      cacheEntry
+}
+
+func newLinkCache(size int, ttl, negativeTTL time.Duration) *linkCache {
+	return &linkCache{
+		items:       make(map[repositories.ID]*list.Element, size),
+		order:       list.New(),
+		size:        size,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// get - найти запись для id.
+//
+// Если записи нет, возвращает поколение кэша, которое нужно передать в put.
+func (c *linkCache) get(id repositories.ID, now time.Time) (e cacheEntry, gen uint64, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[id]
+	if ok && now.Before(el.Value.(*cacheItem).validUntil) {
+		c.order.MoveToFront(el)
+		c.hits.Add(1)
+		return el.Value.(*cacheItem).entry, c.gen, true
+	}
+	if ok {
+		c.remove(el)
+	}
+
+	c.misses.Add(1)
+	return cacheEntry{}, c.gen, false
+}
+
+// put - положить запись для id, если с момента get с поколением gen записи не сбрасывались.
+func (c *linkCache) put(id repositories.ID, e cacheEntry, gen uint64, now time.Time) {
+	ttl := c.ttl
+	if e.notFound {
+		ttl = c.negativeTTL
+	}
+	if ttl <= 0 || c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	item := &cacheItem{validUntil: now.Add(ttl), id: id, entry: e}
+	if el, ok := c.items[id]; ok {
+		el.Value = item
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[id] = c.order.PushFront(item)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// invalidate - сбросить записи для ids.
+func (c *linkCache) invalidate(ids ...repositories.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	for _, id := range ids {
+		if el, ok := c.items[id]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+// invalidateIf - сбросить записи, для которых fn возвращает true.
+func (c *linkCache) invalidateIf(fn func(e cacheEntry) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if fn(el.Value.(*cacheItem).entry) {
+			c.remove(el)
+		}
+		el = next
+	}
+}
+
+// remove - убрать элемент из кэша, вызывающий должен держать c.mu.
+func (c *linkCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*cacheItem).id)
+}
+
+func (c *linkCache) stats() CacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+	}
+}
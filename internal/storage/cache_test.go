@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/policy"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
+)
+
+// slowStorager - хранилище, которое считает чтения ссылок и имитирует задержку базы данных.
+type slowStorager struct {
+	Storager
+	delay time.Duration
+	reads atomic.Int64
+}
+
+func (s *slowStorager) Get(ctx context.Context, id repositories.ID) (repositories.URL, bool, error) {
+	s.reads.Add(1)
+	time.Sleep(s.delay)
+	return s.Storager.Get(ctx, id)
+}
+
+func (s *slowStorager) GetLink(ctx context.Context, id repositories.ID) (repositories.LinkData, error) {
+	s.reads.Add(1)
+	time.Sleep(s.delay)
+	return s.Storager.GetLink(ctx, id)
+}
+
+func newSlowStorager(t testing.TB, delay time.Duration) *slowStorager {
+	mem, err := memory.NewMemoryStorage()
+	require.NoError(t, err)
+	return &slowStorager{Storager: mem, delay: delay}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	user := uuid.New()
+
+	t.Run("hits and misses", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, time.Minute, time.Minute).(*cachedStorager)
+
+		id, err := st.Add(ctx, "https://example.com/cache", user, repositories.LinkOptions{})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			url, deleted, err := st.Get(ctx, id)
+			require.NoError(t, err)
+			assert.False(t, deleted)
+			assert.Equal(t, repositories.URL("https://example.com/cache"), url)
+		}
+
+		assert.Equal(t, int64(1), backend.reads.Load())
+		assert.Equal(t, CacheStats{Hits: 2, Misses: 1, Size: 1}, st.CacheStats())
+	})
+
+	t.Run("get link", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, time.Minute, time.Minute)
+
+		id, err := st.Add(ctx, "https://example.com/link", user, repositories.LinkOptions{})
+		require.NoError(t, err)
+
+		link, err := st.GetLink(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, repositories.URL("https://example.com/link"), link.URL)
+		assert.Equal(t, user, link.User)
+		url, _, err := st.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, repositories.URL("https://example.com/link"), url)
+		assert.Equal(t, int64(1), backend.reads.Load())
+
+		team := uuid.New()
+		require.NoError(t, st.CreateTeam(ctx, repositories.Team{ID: team, Name: "team"}, user))
+		require.NoError(t, st.TransferLinks(ctx, []repositories.ID{id}, user, team))
+		link, err = st.GetLink(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, team, link.Team)
+
+		_, err = st.GetLink(ctx, "unknown")
+		assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, time.Minute, time.Minute)
+
+		for i := 0; i < 2; i++ {
+			_, _, err := st.Get(ctx, "unknown")
+			assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+		}
+		assert.Equal(t, int64(1), backend.reads.Load())
+
+		id, err := st.Add(ctx, "https://example.com/alias", user, repositories.LinkOptions{Alias: "unknown"})
+		require.NoError(t, err)
+		url, _, err := st.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, repositories.URL("https://example.com/alias"), url)
+	})
+
+	t.Run("no negative caching", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, time.Minute, 0)
+
+		for i := 0; i < 2; i++ {
+			_, _, err := st.Get(ctx, "unknown")
+			assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+		}
+		assert.Equal(t, int64(2), backend.reads.Load())
+	})
+
+	t.Run("invalidation", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, time.Minute, time.Minute)
+
+		id, err := st.Add(ctx, "https://example.com/old", user, repositories.LinkOptions{})
+		require.NoError(t, err)
+		_, _, err = st.Get(ctx, id)
+		require.NoError(t, err)
+
+		_, err = st.Update(ctx, id, "https://example.com/new", user)
+		require.NoError(t, err)
+		url, _, err := st.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, repositories.URL("https://example.com/new"), url)
+
+		_, err = st.DeleteUserLinks(ctx, []repositories.ID{id}, user)
+		require.NoError(t, err)
+		_, deleted, err := st.Get(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+
+		_, err = st.RestoreUserLinks(ctx, []repositories.ID{id}, user, time.Time{})
+		require.NoError(t, err)
+		_, deleted, err = st.Get(ctx, id)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+
+	t.Run("max hits", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, time.Minute, time.Minute)
+
+		id, err := st.Add(ctx, "https://example.com/limited", user, repositories.LinkOptions{MaxHits: 2})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, _, err = st.Get(ctx, id)
+			require.NoError(t, err)
+		}
+		_, _, err = st.Get(ctx, id)
+		assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+
+		link, err := st.GetLink(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), link.Hits)
+	})
+
+	t.Run("expiration", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, time.Minute, time.Minute)
+
+		id, err := st.Add(ctx, "https://example.com/expiring", user, repositories.LinkOptions{
+			ExpiresAt: time.Now().Add(50 * time.Millisecond),
+		})
+		require.NoError(t, err)
+		_, _, err = st.Get(ctx, id)
+		require.NoError(t, err)
+
+		time.Sleep(60 * time.Millisecond)
+		_, _, err = st.Get(ctx, id)
+		assert.ErrorIs(t, err, repositories.ErrLinkExpired)
+		assert.Equal(t, int64(1), backend.reads.Load())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 10, 20*time.Millisecond, time.Minute)
+
+		id, err := st.Add(ctx, "https://example.com/ttl", user, repositories.LinkOptions{})
+		require.NoError(t, err)
+		_, _, err = st.Get(ctx, id)
+		require.NoError(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+		_, _, err = st.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), backend.reads.Load())
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		backend := newSlowStorager(t, 0)
+		st := Cache(backend, 2, time.Minute, time.Minute).(*cachedStorager)
+
+		ids := make([]repositories.ID, 3)
+		for i := range ids {
+			var err error
+			ids[i], err = st.Add(ctx, repositories.URL("https://example.com/"+strconv.Itoa(i)), user,
+				repositories.LinkOptions{})
+			require.NoError(t, err)
+		}
+
+		_, _, _ = st.Get(ctx, ids[0])
+		_, _, _ = st.Get(ctx, ids[1])
+		_, _, _ = st.Get(ctx, ids[0])
+		_, _, _ = st.Get(ctx, ids[2]) // Вытесняет ids[1], который использовался давнее всех.
+		assert.Equal(t, int64(3), backend.reads.Load())
+
+		_, _, _ = st.Get(ctx, ids[0])
+		assert.Equal(t, int64(3), backend.reads.Load())
+		_, _, _ = st.Get(ctx, ids[1])
+		assert.Equal(t, int64(4), backend.reads.Load())
+
+		stats := st.CacheStats()
+		assert.Equal(t, uint64(2), stats.Evictions)
+		assert.Equal(t, 2, stats.Size)
+	})
+
+	t.Run("stale put", func(t *testing.T) {
+		c := newLinkCache(10, time.Minute, time.Minute)
+		now := time.Now()
+
+		_, gen, ok := c.get("id", now)
+		require.False(t, ok)
+		c.invalidate("id")
+		c.put("id", cacheEntry{url: "https://example.com/stale"}, gen, now)
+
+		_, _, ok = c.get("id", now)
+		assert.False(t, ok)
+	})
+
+	t.Run("collectors", func(t *testing.T) {
+		st := Cache(newSlowStorager(t, 0), 10, time.Minute, time.Minute)
+		cs := st.(interface{ Collectors() []prometheus.Collector }).Collectors()
+		assert.Len(t, cs, 4)
+	})
+}
+
+// benchmarkGet - переходы по ссылкам; с policyPath, как в обработчике, ссылка сначала проверяется политикой.
+func benchmarkGet(b *testing.B, cached bool, policyPath string) {
+	ctx := context.Background()
+	backend := newSlowStorager(b, 100*time.Microsecond)
+
+	var st Storager = backend
+	if cached {
+		st = Cache(backend, 1000, time.Minute, time.Minute)
+	}
+
+	p, err := policy.New(policyPath)
+	require.NoError(b, err)
+
+	ids := make([]repositories.ID, 100)
+	for i := range ids {
+		ids[i], err = st.Add(ctx, repositories.URL("https://example.com/"+strconv.Itoa(i)), uuid.New(),
+			repositories.LinkOptions{})
+		require.NoError(b, err)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			id := ids[i%len(ids)]
+			i++
+			if p.Enabled() {
+				link, err := st.GetLink(ctx, id)
+				if err != nil || p.Check(link.URL) != nil {
+					continue
+				}
+			}
+			_, _, _ = st.Get(ctx, id)
+		}
+	})
+}
+
+// writeBenchPolicy - файл политики, который пропускает ссылки из benchmarkGet.
+func writeBenchPolicy(b *testing.B) string {
+	path := filepath.Join(b.TempDir(), "policy.json")
+	require.NoError(b, os.WriteFile(path, []byte(`{"allow": [{"host": "example.com"}]}`), 0o600))
+	return path
+}
+
+func BenchmarkGet_Uncached(b *testing.B) {
+	benchmarkGet(b, false, "")
+}
+
+func BenchmarkGet_Cached(b *testing.B) {
+	benchmarkGet(b, true, "")
+}
+
+func BenchmarkGet_UncachedPolicy(b *testing.B) {
+	benchmarkGet(b, false, writeBenchPolicy(b))
+}
+
+func BenchmarkGet_CachedPolicy(b *testing.B) {
+	benchmarkGet(b, true, writeBenchPolicy(b))
+}
@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/tracing"
+)
+
+var errPoolFailed = errors.New("storage unavailable")
+
+// instrumentedStorager - обертка над Storager, которая для каждой операции открывает спан трассировки
+// и считает время выполнения и ошибки в метриках.
+//
+// Методы перечислены явно, а не получены встраиванием, чтобы новый метод Storager нельзя было забыть учесть.
+type instrumentedStorager struct {
+	st Storager
+}
+
+// Instrument - обернуть хранилище, чтобы его операции попадали в метрики и трассировку.
+func Instrument(st Storager) Storager {
+	return instrumentedStorager{st: st}
+}
+
+// instrument - начать операцию с хранилищем.
+//
+// Возвращенная функция вызывается через defer, поэтому ошибку получает по указателю.
+func instrument(ctx context.Context, operation string) (context.Context, func(err *error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "storage."+operation)
+	return ctx, func(err *error) {
+		metrics.ObserveStorageOperation(operation, *err, time.Since(start))
+		tracing.End(span, *err)
+	}
+}
+
+func (m instrumentedStorager) Add(
+	ctx context.Context, url repositories.URL, userID repositories.User, opts repositories.LinkOptions,
+) (id repositories.ID, err error) {
+	ctx, end := instrument(ctx, "Add")
+	defer end(&err)
+	return m.st.Add(ctx, url, userID, opts)
+}
+
+func (m instrumentedStorager) AddBatch(
+	ctx context.Context, links []repositories.BatchLink, userID repositories.User,
+) (results []repositories.BatchResult, err error) {
+	ctx, end := instrument(ctx, "AddBatch")
+	defer end(&err)
+	return m.st.AddBatch(ctx, links, userID)
+}
+
+func (m instrumentedStorager) Get(
+	ctx context.Context, id repositories.ID,
+) (url repositories.URL, deleted bool, err error) {
+	ctx, end := instrument(ctx, "Get")
+	defer end(&err)
+	return m.st.Get(ctx, id)
+}
+
+func (m instrumentedStorager) GetUserLinks(
+	ctx context.Context, user repositories.User,
+) (links []repositories.LinkData, err error) {
+	ctx, end := instrument(ctx, "GetUserLinks")
+	defer end(&err)
+	return m.st.GetUserLinks(ctx, user)
+}
+
+func (m instrumentedStorager) ListUserLinks(
+	ctx context.Context, user repositories.User, q repositories.LinkQuery,
+) (page repositories.LinkPage, err error) {
+	ctx, end := instrument(ctx, "ListUserLinks")
+	defer end(&err)
+	return m.st.ListUserLinks(ctx, user, q)
+}
+
+func (m instrumentedStorager) CountUserLinks(
+	ctx context.Context, user repositories.User, since time.Time,
+) (counts repositories.LinkCounts, err error) {
+	ctx, end := instrument(ctx, "CountUserLinks")
+	defer end(&err)
+	return m.st.CountUserLinks(ctx, user, since)
+}
+
+func (m instrumentedStorager) GetLink(
+	ctx context.Context, id repositories.ID,
+) (link repositories.LinkData, err error) {
+	ctx, end := instrument(ctx, "GetLink")
+	defer end(&err)
+	return m.st.GetLink(ctx, id)
+}
+
+func (m instrumentedStorager) Iterate(
+	ctx context.Context, after repositories.ID, fn func(link repositories.LinkData) error,
+) (err error) {
+	ctx, end := instrument(ctx, "Iterate")
+	defer end(&err)
+	return m.st.Iterate(ctx, after, fn)
+}
+
+func (m instrumentedStorager) Import(ctx context.Context, link repositories.LinkData) (err error) {
+	ctx, end := instrument(ctx, "Import")
+	defer end(&err)
+	return m.st.Import(ctx, link)
+}
+
+func (m instrumentedStorager) DeleteUserLinks(
+	ctx context.Context, ids []repositories.ID, user repositories.User,
+) (deletion repositories.DeletionID, err error) {
+	ctx, end := instrument(ctx, "DeleteUserLinks")
+	defer end(&err)
+	return m.st.DeleteUserLinks(ctx, ids, user)
+}
+
+func (m instrumentedStorager) GetDeletionStatus(
+	ctx context.Context, deletion repositories.DeletionID, user repositories.User,
+) (s repositories.DeletionStatus, err error) {
+	ctx, end := instrument(ctx, "GetDeletionStatus")
+	defer end(&err)
+	return m.st.GetDeletionStatus(ctx, deletion, user)
+}
+
+func (m instrumentedStorager) GetDeletedUserLinks(
+	ctx context.Context, user repositories.User,
+) (links []repositories.LinkData, err error) {
+	ctx, end := instrument(ctx, "GetDeletedUserLinks")
+	defer end(&err)
+	return m.st.GetDeletedUserLinks(ctx, user)
+}
+
+func (m instrumentedStorager) RestoreUserLinks(
+	ctx context.Context, ids []repositories.ID, user repositories.User, since time.Time,
+) (restored []repositories.ID, err error) {
+	ctx, end := instrument(ctx, "RestoreUserLinks")
+	defer end(&err)
+	return m.st.RestoreUserLinks(ctx, ids, user, since)
+}
+
+func (m instrumentedStorager) PurgeDeleted(ctx context.Context, before time.Time) (count int64, err error) {
+	ctx, end := instrument(ctx, "PurgeDeleted")
+	defer end(&err)
+	return m.st.PurgeDeleted(ctx, before)
+}
+
+func (m instrumentedStorager) Update(
+	ctx context.Context, id repositories.ID, url repositories.URL, user repositories.User,
+) (link repositories.LinkData, err error) {
+	ctx, end := instrument(ctx, "Update")
+	defer end(&err)
+	return m.st.Update(ctx, id, url, user)
+}
+
+func (m instrumentedStorager) GetLinkHistory(
+	ctx context.Context, id repositories.ID, user repositories.User,
+) (versions []repositories.URLVersion, err error) {
+	ctx, end := instrument(ctx, "GetLinkHistory")
+	defer end(&err)
+	return m.st.GetLinkHistory(ctx, id, user)
+}
+
+func (m instrumentedStorager) TransferLinks(
+	ctx context.Context, ids []repositories.ID, user repositories.User, team repositories.TeamID,
+) (err error) {
+	ctx, end := instrument(ctx, "TransferLinks")
+	defer end(&err)
+	return m.st.TransferLinks(ctx, ids, user, team)
+}
+
+func (m instrumentedStorager) CreateTeam(
+	ctx context.Context, team repositories.Team, owner repositories.User,
+) (err error) {
+	ctx, end := instrument(ctx, "CreateTeam")
+	defer end(&err)
+	return m.st.CreateTeam(ctx, team, owner)
+}
+
+func (m instrumentedStorager) GetTeamRole(
+	ctx context.Context, team repositories.TeamID, user repositories.User,
+) (role repositories.Role, err error) {
+	ctx, end := instrument(ctx, "GetTeamRole")
+	defer end(&err)
+	return m.st.GetTeamRole(ctx, team, user)
+}
+
+func (m instrumentedStorager) GetUserTeams(
+	ctx context.Context, user repositories.User,
+) (teams []repositories.Membership, err error) {
+	ctx, end := instrument(ctx, "GetUserTeams")
+	defer end(&err)
+	return m.st.GetUserTeams(ctx, user)
+}
+
+func (m instrumentedStorager) GetTeamMembers(
+	ctx context.Context, team repositories.TeamID,
+) (members []repositories.TeamMember, err error) {
+	ctx, end := instrument(ctx, "GetTeamMembers")
+	defer end(&err)
+	return m.st.GetTeamMembers(ctx, team)
+}
+
+func (m instrumentedStorager) SetTeamMember(
+	ctx context.Context, team repositories.TeamID, user repositories.User, role repositories.Role,
+) (err error) {
+	ctx, end := instrument(ctx, "SetTeamMember")
+	defer end(&err)
+	return m.st.SetTeamMember(ctx, team, user, role)
+}
+
+func (m instrumentedStorager) RemoveTeamMember(
+	ctx context.Context, team repositories.TeamID, user repositories.User,
+) (err error) {
+	ctx, end := instrument(ctx, "RemoveTeamMember")
+	defer end(&err)
+	return m.st.RemoveTeamMember(ctx, team, user)
+}
+
+func (m instrumentedStorager) CreateInvite(ctx context.Context, invite repositories.TeamInvite) (err error) {
+	ctx, end := instrument(ctx, "CreateInvite")
+	defer end(&err)
+	return m.st.CreateInvite(ctx, invite)
+}
+
+func (m instrumentedStorager) AcceptInvite(
+	ctx context.Context, id repositories.InviteID, user repositories.User, at time.Time,
+) (invite repositories.TeamInvite, err error) {
+	ctx, end := instrument(ctx, "AcceptInvite")
+	defer end(&err)
+	return m.st.AcceptInvite(ctx, id, user, at)
+}
+
+func (m instrumentedStorager) RevokeInvite(
+	ctx context.Context, id repositories.InviteID, team repositories.TeamID, at time.Time,
+) (err error) {
+	ctx, end := instrument(ctx, "RevokeInvite")
+	defer end(&err)
+	return m.st.RevokeInvite(ctx, id, team, at)
+}
+
+func (m instrumentedStorager) CreateAccount(ctx context.Context, account repositories.Account) (err error) {
+	ctx, end := instrument(ctx, "CreateAccount")
+	defer end(&err)
+	return m.st.CreateAccount(ctx, account)
+}
+
+func (m instrumentedStorager) GetAccount(
+	ctx context.Context, id repositories.User,
+) (account repositories.Account, err error) {
+	ctx, end := instrument(ctx, "GetAccount")
+	defer end(&err)
+	return m.st.GetAccount(ctx, id)
+}
+
+func (m instrumentedStorager) GetAccountByLogin(
+	ctx context.Context, login string,
+) (account repositories.Account, err error) {
+	ctx, end := instrument(ctx, "GetAccountByLogin")
+	defer end(&err)
+	return m.st.GetAccountByLogin(ctx, login)
+}
+
+func (m instrumentedStorager) CreateAPIKey(ctx context.Context, key repositories.APIKey) (err error) {
+	ctx, end := instrument(ctx, "CreateAPIKey")
+	defer end(&err)
+	return m.st.CreateAPIKey(ctx, key)
+}
+
+func (m instrumentedStorager) GetAPIKey(ctx context.Context, hash []byte) (key repositories.APIKey, err error) {
+	ctx, end := instrument(ctx, "GetAPIKey")
+	defer end(&err)
+	return m.st.GetAPIKey(ctx, hash)
+}
+
+func (m instrumentedStorager) GetAccountAPIKeys(
+	ctx context.Context, account repositories.User,
+) (keys []repositories.APIKey, err error) {
+	ctx, end := instrument(ctx, "GetAccountAPIKeys")
+	defer end(&err)
+	return m.st.GetAccountAPIKeys(ctx, account)
+}
+
+func (m instrumentedStorager) RevokeAPIKey(
+	ctx context.Context, id repositories.KeyID, account repositories.User, at time.Time,
+) (err error) {
+	ctx, end := instrument(ctx, "RevokeAPIKey")
+	defer end(&err)
+	return m.st.RevokeAPIKey(ctx, id, account, at)
+}
+
+func (m instrumentedStorager) RevokeSessions(
+	ctx context.Context, account repositories.User, at time.Time,
+) (err error) {
+	ctx, end := instrument(ctx, "RevokeSessions")
+	defer end(&err)
+	return m.st.RevokeSessions(ctx, account, at)
+}
+
+func (m instrumentedStorager) ClaimLinks(
+	ctx context.Context, from, to repositories.User,
+) (claimed []repositories.ID, err error) {
+	ctx, end := instrument(ctx, "ClaimLinks")
+	defer end(&err)
+	return m.st.ClaimLinks(ctx, from, to)
+}
+
+func (m instrumentedStorager) AddClicks(ctx context.Context, clicks []repositories.Click) (err error) {
+	ctx, end := instrument(ctx, "AddClicks")
+	defer end(&err)
+	return m.st.AddClicks(ctx, clicks)
+}
+
+func (m instrumentedStorager) GetLinkStats(
+	ctx context.Context, id repositories.ID, user repositories.User,
+) (stats repositories.LinkStats, err error) {
+	ctx, end := instrument(ctx, "GetLinkStats")
+	defer end(&err)
+	return m.st.GetLinkStats(ctx, id, user)
+}
+
+func (m instrumentedStorager) PurgeExpired(ctx context.Context) (count int64, err error) {
+	ctx, end := instrument(ctx, "PurgeExpired")
+	defer end(&err)
+	return m.st.PurgeExpired(ctx)
+}
+
+func (m instrumentedStorager) GetStats(ctx context.Context) (stats repositories.ServiceStats, err error) {
+	ctx, end := instrument(ctx, "GetStats")
+	defer end(&err)
+	return m.st.GetStats(ctx)
+}
+
+func (m instrumentedStorager) Pool(ctx context.Context) (ok bool) {
+	var err error
+	ctx, end := instrument(ctx, "Pool")
+	defer end(&err)
+	if ok = m.st.Pool(ctx); !ok {
+		err = errPoolFailed
+	}
+	return ok
+}
+
+func (m instrumentedStorager) Close(ctx context.Context) (err error) {
+	ctx, end := instrument(ctx, "Close")
+	defer end(&err)
+	return m.st.Close(ctx)
+}
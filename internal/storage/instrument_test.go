@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/metrics"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/tracing"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestInstrument(t *testing.T) {
+	ctx := context.Background()
+	mem, err := memory.NewMemoryStorage()
+	require.NoError(t, err)
+	st := Instrument(mem)
+
+	user := uuid.New()
+	id, err := st.Add(ctx, "https://example.com/metrics", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+
+	existing, err := st.Add(ctx, "https://example.com/metrics", user, repositories.LinkOptions{})
+	assert.ErrorIs(t, err, repositories.ErrURLAlreadyExists)
+	assert.Equal(t, id, existing)
+
+	_, err = st.GetLink(ctx, "unknown")
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `urlshortener_storage_operations_total{operation="Add"} 2`)
+	assert.Contains(t, body, `urlshortener_storage_errors_total{operation="Add"} 1`)
+	assert.Contains(t, body, `urlshortener_storage_errors_total{operation="GetLink"} 1`)
+	assert.Contains(t, body, `urlshortener_storage_operation_duration_seconds_count{operation="Add"} 2`)
+}
+
+func TestInstrument_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	mem, err := memory.NewMemoryStorage()
+	require.NoError(t, err)
+	st := Instrument(mem)
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+	_, err = st.GetLink(ctx, "unknown")
+	assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "storage.GetLink", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
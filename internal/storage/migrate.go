@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+const defaultCheckpointEvery = 1000
+
+// MigrateOptions - параметры переноса ссылок между хранилищами.
+type MigrateOptions struct {
+	DryRun          bool   // Только проверить, что будет перенесено, ничего не записывая.
+	StateFile       string // Файл с ID последней перенесенной ссылки для продолжения после остановки.
+	CheckpointEvery int    // Как часто сохранять прогресс в StateFile, по умолчанию раз в 1000 ссылок.
+}
+
+// MigrateConflict - ссылка, которую не удалось перенести.
+type MigrateConflict struct {
+	ID  repositories.ID // ID ссылки в исходном хранилище.
+	Err error           // Причина: repositories.ErrIDAlreadyExists или repositories.ErrURLAlreadyExists.
+}
+
+// MigrateReport - результат переноса ссылок.
+type MigrateReport struct {
+	Migrated  int               // Перенесено ссылок (в режиме DryRun - будет перенесено).
+	Skipped   int               // Ссылки, которые уже есть в целевом хранилище.
+	Conflicts []MigrateConflict // Ссылки, которые конфликтуют с данными целевого хранилища.
+	LastID    repositories.ID   // ID последней обработанной ссылки.
+}
+
+// Migrate - перенести все ссылки из src в dst, сохраняя ID, владельцев и признак удаления.
+//
+// Ссылка, которая уже есть в dst с тем же URL и владельцем, считается перенесенной,
+// поэтому повторный запуск безопасен. Конфликты не прерывают перенос и попадают в отчет.
+// В режиме DryRun конфликты по URL не обнаруживаются: они видны только при записи.
+// Статистика переходов не переносится.
+func Migrate(ctx context.Context, src, dst Storager, opts MigrateOptions) (report MigrateReport, err error) {
+	if opts.CheckpointEvery <= 0 {
+		opts.CheckpointEvery = defaultCheckpointEvery
+	}
+
+	report.LastID, err = readMigrateState(opts.StateFile)
+	if err != nil {
+		return report, err
+	}
+	if report.LastID != "" {
+		zap.L().Info("resuming migration", zap.String("after", report.LastID))
+	}
+
+	processed := 0
+	err = src.Iterate(ctx, report.LastID, func(link repositories.LinkData) error {
+		err := migrateLink(ctx, dst, link, opts.DryRun, &report)
+		if err != nil {
+			return err
+		}
+
+		report.LastID = link.ID
+		processed++
+		if !opts.DryRun && processed%opts.CheckpointEvery == 0 {
+			return writeMigrateState(opts.StateFile, report.LastID)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if !opts.DryRun {
+		err = writeMigrateState(opts.StateFile, report.LastID)
+	}
+	return report, err
+}
+
+func migrateLink(
+	ctx context.Context,
+	dst Storager,
+	link repositories.LinkData,
+	dryRun bool,
+	report *MigrateReport,
+) error {
+	existing, err := dst.GetLink(ctx, link.ID)
+	switch {
+	case err == nil:
+		if existing.URL == link.URL && existing.User == link.User {
+			report.Skipped++
+			return nil
+		}
+		report.Conflicts = append(report.Conflicts, MigrateConflict{ID: link.ID, Err: repositories.ErrIDAlreadyExists})
+		return nil
+	case !errors.Is(err, repositories.ErrURLNotFound):
+		return err
+	}
+
+	if dryRun {
+		report.Migrated++
+		return nil
+	}
+
+	err = dst.Import(ctx, link)
+	if errors.Is(err, repositories.ErrIDAlreadyExists) || errors.Is(err, repositories.ErrURLAlreadyExists) {
+		report.Conflicts = append(report.Conflicts, MigrateConflict{ID: link.ID, Err: err})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Migrated++
+	return nil
+}
+
+func readMigrateState(path string) (repositories.ID, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+func writeMigrateState(path string, id repositories.ID) error {
+	if path == "" || id == "" {
+		return nil
+	}
+
+	tmp := path + ".tmp"
+	err := os.WriteFile(tmp, []byte(id+"\n"), 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
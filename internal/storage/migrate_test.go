@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
+)
+
+func newMigrateStorages(t *testing.T) (src, dst *memory.MemStorage, user repositories.User) {
+	var err error
+	src, err = memory.NewMemoryStorage()
+	require.NoError(t, err)
+	dst, err = memory.NewMemoryStorage()
+	require.NoError(t, err)
+
+	user = uuid.New()
+	for _, link := range []repositories.LinkData{
+		{ID: "a", URL: "https://example.com/a", User: user},
+		{ID: "b", URL: "https://example.com/b", User: user, Deleted: true},
+		{ID: "c", URL: "https://example.com/c", User: user},
+	} {
+		require.NoError(t, src.Import(context.Background(), link))
+	}
+
+	return src, dst, user
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ok", func(t *testing.T) {
+		src, dst, user := newMigrateStorages(t)
+
+		report, err := Migrate(ctx, src, dst, MigrateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Migrated)
+		assert.Equal(t, repositories.ID("c"), report.LastID)
+
+		link, err := dst.GetLink(ctx, "b")
+		require.NoError(t, err)
+		assert.Equal(t, user, link.User)
+		assert.True(t, link.Deleted)
+
+		report, err = Migrate(ctx, src, dst, MigrateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Migrated)
+		assert.Equal(t, 3, report.Skipped)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		src, dst, _ := newMigrateStorages(t)
+		require.NoError(t, dst.Import(ctx, repositories.LinkData{ID: "a", URL: "https://example.com/x", User: uuid.New()}))
+		require.NoError(t, dst.Import(ctx, repositories.LinkData{ID: "x", URL: "https://example.com/c", User: uuid.New()}))
+
+		report, err := Migrate(ctx, src, dst, MigrateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Migrated)
+		assert.Equal(t, []MigrateConflict{
+			{ID: "a", Err: repositories.ErrIDAlreadyExists},
+			{ID: "c", Err: repositories.ErrURLAlreadyExists},
+		}, report.Conflicts)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		src, dst, _ := newMigrateStorages(t)
+		state := filepath.Join(t.TempDir(), "state")
+
+		report, err := Migrate(ctx, src, dst, MigrateOptions{DryRun: true, StateFile: state})
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Migrated)
+
+		_, err = dst.GetLink(ctx, "a")
+		assert.ErrorIs(t, err, repositories.ErrURLNotFound)
+		_, err = os.Stat(state)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		src, dst, _ := newMigrateStorages(t)
+		state := filepath.Join(t.TempDir(), "state")
+		opts := MigrateOptions{StateFile: state, CheckpointEvery: 1}
+
+		ctx, cancel := context.WithCancel(ctx)
+		stopAfter := &stopStorager{MemStorage: dst, left: 2, cancel: cancel}
+
+		_, err := Migrate(ctx, src, stopAfter, opts)
+		require.ErrorIs(t, err, context.Canceled)
+
+		data, err := os.ReadFile(state)
+		require.NoError(t, err)
+		assert.Equal(t, "b\n", string(data))
+
+		report, err := Migrate(context.Background(), src, dst, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Migrated)
+		assert.Equal(t, 0, report.Skipped)
+	})
+
+	t.Run("target error", func(t *testing.T) {
+		src, _, _ := newMigrateStorages(t)
+
+		_, err := Migrate(ctx, src, &brokenStorager{}, MigrateOptions{})
+		assert.Error(t, err)
+	})
+}
+
+// stopStorager - хранилище, которое отменяет контекст после заданного числа импортов.
+type stopStorager struct {
+	*memory.MemStorage
+	left   int
+	cancel context.CancelFunc
+}
+
+func (s *stopStorager) Import(ctx context.Context, link repositories.LinkData) error {
+	err := s.MemStorage.Import(ctx, link)
+	s.left--
+	if s.left == 0 {
+		s.cancel()
+	}
+	return err
+}
+
+// brokenStorager - хранилище, которое не может прочитать ссылку.
+type brokenStorager struct {
+	memory.MemStorage
+}
+
+func (s *brokenStorager) GetLink(context.Context, repositories.ID) (repositories.LinkData, error) {
+	return repositories.LinkData{}, errors.New("connection refused")
+}
@@ -3,9 +3,15 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/disk"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
@@ -15,17 +21,117 @@ import (
 // Storager - интерфейс для хранилища.
 type Storager interface {
 	Add( // Сократить ссылку.
-		ctx context.Context, url repositories.URL, userID repositories.User,
+		ctx context.Context, url repositories.URL, userID repositories.User, opts repositories.LinkOptions,
 	) (id repositories.ID, err error)
-	Get( // Получить оригинальную ссылку по ID.
+	AddBatch( // Сократить пачку ссылок, ошибки отдельных ссылок возвращаются в результатах.
+		ctx context.Context, links []repositories.BatchLink, userID repositories.User,
+	) (results []repositories.BatchResult, err error)
+	Get( // Получить оригинальную ссылку по ID, для ссылок с истекшим сроком вернет repositories.ErrLinkExpired.
 		ctx context.Context, id repositories.ID,
 	) (url repositories.URL, deleted bool, err error)
 	GetUserLinks( // Получить все ссылки пользователя.
 		ctx context.Context, user repositories.User,
 	) (links []repositories.LinkData, err error)
-	DeleteUserLinks( // Удалить ссылки пользователя.
+	ListUserLinks( // Получить страницу ссылок пользователя, отсортированных по времени создания.
+		ctx context.Context, user repositories.User, q repositories.LinkQuery,
+	) (page repositories.LinkPage, err error)
+	CountUserLinks( // Посчитать активные ссылки пользователя и ссылки, созданные начиная с since.
+		ctx context.Context, user repositories.User, since time.Time,
+	) (counts repositories.LinkCounts, err error)
+	GetLink( // Получить данные ссылки по ID без учета перехода.
+		ctx context.Context, id repositories.ID,
+	) (link repositories.LinkData, err error)
+	Iterate( // Обойти все ссылки в порядке возрастания ID, начиная со следующей после after.
+		ctx context.Context, after repositories.ID, fn func(link repositories.LinkData) error,
+	) error
+	Import( // Сохранить ссылку как есть, с ее ID, владельцем и состоянием.
+		ctx context.Context, link repositories.LinkData,
+	) error
+	DeleteUserLinks( // Удалить ссылки пользователя, вернет ID запроса на удаление.
 		ctx context.Context, ids []repositories.ID, user repositories.User,
+	) (deletion repositories.DeletionID, err error)
+	GetDeletionStatus( // Получить состояние запроса на удаление ссылок.
+		ctx context.Context, deletion repositories.DeletionID, user repositories.User,
+	) (repositories.DeletionStatus, error)
+	GetDeletedUserLinks( // Получить удаленные ссылки, которые пользователь может восстановить.
+		ctx context.Context, user repositories.User,
+	) (links []repositories.LinkData, err error)
+	RestoreUserLinks( // Восстановить ссылки пользователя, удаленные не раньше since.
+		ctx context.Context, ids []repositories.ID, user repositories.User, since time.Time,
+	) (restored []repositories.ID, err error)
+	PurgeDeleted( // Безвозвратно удалить ссылки, удаленные раньше before.
+		ctx context.Context, before time.Time,
+	) (count int64, err error)
+	AddClicks( // Сохранить переходы по ссылкам.
+		ctx context.Context, clicks []repositories.Click,
+	) error
+	GetLinkStats( // Получить статистику переходов по ссылке пользователя.
+		ctx context.Context, id repositories.ID, user repositories.User,
+	) (stats repositories.LinkStats, err error)
+	Update( // Заменить исходный URL ссылки пользователя, прежний URL сохраняется в истории.
+		ctx context.Context, id repositories.ID, url repositories.URL, user repositories.User,
+	) (link repositories.LinkData, err error)
+	GetLinkHistory( // Получить прежние исходные URL ссылки пользователя, от старых к новым.
+		ctx context.Context, id repositories.ID, user repositories.User,
+	) (versions []repositories.URLVersion, err error)
+	TransferLinks( // Передать ссылки в команду или, если team == uuid.Nil, в личные ссылки пользователя.
+		ctx context.Context, ids []repositories.ID, user repositories.User, team repositories.TeamID,
+	) error
+	CreateTeam( // Создать команду, owner становится ее владельцем.
+		ctx context.Context, team repositories.Team, owner repositories.User,
+	) error
+	GetTeamRole( // Получить роль пользователя в команде, если он в ней не состоит - repositories.ErrTeamNotFound.
+		ctx context.Context, team repositories.TeamID, user repositories.User,
+	) (repositories.Role, error)
+	GetUserTeams( // Получить команды, в которых состоит пользователь.
+		ctx context.Context, user repositories.User,
+	) ([]repositories.Membership, error)
+	GetTeamMembers( // Получить участников команды.
+		ctx context.Context, team repositories.TeamID,
+	) ([]repositories.TeamMember, error)
+	SetTeamMember( // Добавить пользователя в команду или изменить его роль.
+		ctx context.Context, team repositories.TeamID, user repositories.User, role repositories.Role,
+	) error
+	RemoveTeamMember( // Исключить пользователя из команды.
+		ctx context.Context, team repositories.TeamID, user repositories.User,
+	) error
+	CreateInvite( // Сохранить приглашение в команду.
+		ctx context.Context, invite repositories.TeamInvite,
+	) error
+	AcceptInvite( // Принять приглашение: отметить его принятым и добавить user в команду с ролью из него.
+		ctx context.Context, id repositories.InviteID, user repositories.User, at time.Time,
+	) (repositories.TeamInvite, error)
+	RevokeInvite( // Отозвать приглашение в команду team.
+		ctx context.Context, id repositories.InviteID, team repositories.TeamID, at time.Time,
+	) error
+	CreateAccount( // Зарегистрировать учетную запись, если логин занят - repositories.ErrLoginTaken.
+		ctx context.Context, account repositories.Account,
+	) error
+	GetAccount( // Получить учетную запись пользователя, если ее нет - repositories.ErrAccountNotFound.
+		ctx context.Context, id repositories.User,
+	) (repositories.Account, error)
+	GetAccountByLogin( // Получить учетную запись по логину.
+		ctx context.Context, login string,
+	) (repositories.Account, error)
+	CreateAPIKey( // Сохранить API-ключ учетной записи.
+		ctx context.Context, key repositories.APIKey,
 	) error
+	GetAPIKey( // Найти API-ключ по его хешу, в том числе отозванный.
+		ctx context.Context, hash []byte,
+	) (repositories.APIKey, error)
+	GetAccountAPIKeys( // Получить API-ключи учетной записи в порядке создания.
+		ctx context.Context, account repositories.User,
+	) ([]repositories.APIKey, error)
+	RevokeAPIKey( // Отозвать API-ключ учетной записи.
+		ctx context.Context, id repositories.KeyID, account repositories.User, at time.Time,
+	) error
+	RevokeSessions( // Завершить сессии учетной записи, выданные до at.
+		ctx context.Context, account repositories.User, at time.Time,
+	) error
+	ClaimLinks( // Передать личные ссылки пользователя from пользователю to.
+		ctx context.Context, from, to repositories.User,
+	) (claimed []repositories.ID, err error)
+	PurgeExpired(ctx context.Context) (count int64, err error)       // Удалить ссылки с истекшим сроком действия.
 	GetStats(ctx context.Context) (repositories.ServiceStats, error) // Получить статистику сервиса.
 	Pool(ctx context.Context) (ok bool)                              // Проверить соединение с базой данных.
 	Close(ctx context.Context) (err error)                           // Мягко завершить работу хранилища.
@@ -47,18 +153,151 @@ const (
 //  0. PsqlStorage
 //  1. FileStorage
 //  2. MemoryStorage
-func NewStorager(cfg configs.Config) (Storager, error) {
+//
+// Логгер l передается в PsqlStorage и FileStorage.
+// Генератор ID со счетчиком продвигается за уже выданные ID, см. seed.
+// Если задан cfg.CacheSize, хранилище оборачивается кэшем переходов (см. Cache).
+func NewStorager(cfg configs.Config, l *zap.Logger) (Storager, error) {
+	dedup, err := repositories.ParseDedupMode(cfg.URLDedup)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := idgen.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := newStorager(cfg, dedup, ids, l)
+	if err != nil {
+		return nil, err
+	}
+
+	if seeder, ok := ids.(idgen.Seeder); ok {
+		err = seed(context.Background(), st, seeder)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.CacheSize > 0 {
+		st = Cache(st, cfg.CacheSize, cfg.CacheTTL, cfg.CacheNegativeTTL)
+	}
+
+	return st, nil
+}
+
+// seed - продвинуть счетчик генератора за наибольшее значение, из которого получен ID сохраненной ссылки.
+//
+// Количество ссылок для этого не подходит: после безвозвратного удаления ссылок оно меньше
+// выданных значений, и генератор выдал бы ID удаленной ссылки повторно. Алиасы, которые
+// генератор мог бы выдать сам, тоже продвигают счетчик.
+func seed(ctx context.Context, st Storager, seeder idgen.Seeder) error {
+	var next uint64
+	err := st.Iterate(ctx, "", func(link repositories.LinkData) error {
+		if n, ok := seeder.Decode(link.ID); ok && n >= next {
+			next = n + 1
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	seeder.Seed(next)
+	return nil
+}
+
+// WalkUserLinks - обойти ссылки пользователя: сначала личные, затем ссылки его команд.
+//
+// Ссылки читаются из ListUserLinks постранично, поэтому в памяти одновременно
+// находится не больше одной страницы. Ошибка fn прерывает обход и возвращается как есть.
+func WalkUserLinks(
+	ctx context.Context,
+	st Storager,
+	user repositories.User,
+	fn func(repositories.LinkData) error,
+) error {
+	err := walkLinks(ctx, st, user, uuid.Nil, fn)
+	if err != nil {
+		return err
+	}
+
+	teams, err := st.GetUserTeams(ctx, user)
+	if err != nil {
+		return err
+	}
+	for _, m := range teams {
+		err = walkLinks(ctx, st, user, m.ID, fn)
+		if errors.Is(err, repositories.ErrTeamNotFound) {
+			continue // Пользователя удалили из команды во время обхода.
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkLinks - обойти ссылки пользователя в команде team страницами по MaxPageSize.
+func walkLinks(
+	ctx context.Context,
+	st Storager,
+	user repositories.User,
+	team repositories.TeamID,
+	fn func(repositories.LinkData) error,
+) error {
+	q := repositories.LinkQuery{Order: repositories.SortAsc, Limit: repositories.MaxPageSize, Team: team}
+	for {
+		page, err := st.ListUserLinks(ctx, user, q)
+		if err != nil {
+			return err
+		}
+		for _, link := range page.Links {
+			err = fn(link)
+			if err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+func newStorager(
+	cfg configs.Config,
+	dedup repositories.DedupMode,
+	ids idgen.IDGenerator,
+	l *zap.Logger,
+) (Storager, error) {
 	switch getStoragerType(cfg) {
 	case PsqlStorage:
-		return postgres.NewPsqlStorage(cfg.DatabaseDSN)
+		return postgres.NewPsqlStorage(cfg.DatabaseDSN, dedup, ids, !cfg.DatabaseSkipMigrate, l)
 	case FileStorage:
 		file, err := os.OpenFile(cfg.FileStoragePath, os.O_RDWR|os.O_CREATE, 0o777)
 		if err != nil {
 			return nil, err
 		}
-		return disk.NewFileStorage(file)
+		return disk.NewFileStorage(file, disk.Options{
+			CompactInterval: cfg.FileCompactInterval,
+			Snapshot:        cfg.FileSnapshot,
+			Sync:            disk.SyncMode(cfg.FileSync),
+			SyncInterval:    cfg.FileSyncInterval,
+			Dedup:           dedup,
+			IDs:             ids,
+			Logger:          l,
+		})
 	default:
-		return memory.NewMemoryStorage()
+		st, err := memory.NewMemoryStorage()
+		if err != nil {
+			return nil, err
+		}
+		st.Dedup = dedup
+		st.IDs = ids
+		return st, nil
 	}
 }
 
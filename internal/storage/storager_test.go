@@ -4,19 +4,25 @@ import (
 	"context"
 	"errors"
 	"os"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/idgen"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/disk"
 	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
 )
 
 func TestNewStorager(t *testing.T) {
 	t.Run("memory storage", func(t *testing.T) {
-		got, err := NewStorager(configs.Config{})
+		got, err := NewStorager(configs.Config{}, nil)
 		require.NoError(t, err)
 		switch got.(type) {
 		case *memory.MemStorage:
@@ -28,7 +34,7 @@ func TestNewStorager(t *testing.T) {
 	t.Run("file storage with wrong file name", func(t *testing.T) {
 		_, err := NewStorager(configs.Config{
 			FileStoragePath: "/",
-		})
+		}, nil)
 		require.Error(t, err)
 	})
 
@@ -37,7 +43,7 @@ func TestNewStorager(t *testing.T) {
 
 		got, err := NewStorager(configs.Config{
 			FileStoragePath: fileName,
-		})
+		}, nil)
 		require.NoError(t, err)
 		switch got.(type) {
 		case *disk.FileStorage:
@@ -55,11 +61,41 @@ func TestNewStorager(t *testing.T) {
 	t.Run("psql storage with wrong file name", func(t *testing.T) {
 		_, err := NewStorager(configs.Config{
 			DatabaseDSN: "lalala",
-		})
+		}, nil)
 		require.Error(t, err)
 	})
 }
 
+func TestNewStorager_seed(t *testing.T) {
+	ctx := context.Background()
+	user := uuid.New()
+	cfg := configs.Config{
+		FileStoragePath: filepath.Join(t.TempDir(), "storage"),
+		IDStrategy:      idgen.StrategySequential,
+	}
+
+	st, err := NewStorager(cfg, nil)
+	require.NoError(t, err)
+	ids := make([]repositories.ID, 3)
+	for i := range ids {
+		ids[i], err = st.Add(ctx, "https://example.com/"+strconv.Itoa(i), user, repositories.LinkOptions{})
+		require.NoError(t, err)
+	}
+	_, err = st.DeleteUserLinks(ctx, ids[:2], user)
+	require.NoError(t, err)
+	_, err = st.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, st.Close(ctx))
+
+	st, err = NewStorager(cfg, nil)
+	require.NoError(t, err)
+	defer func() { _ = st.Close(ctx) }()
+
+	id, err := st.Add(ctx, "https://example.com/new", user, repositories.LinkOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, ids, id, "ids of purged links are not issued again")
+}
+
 func Test_getStoragerType(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SweepExpired - периодически удаляет из хранилища ссылки с истекшим сроком действия.
+//
+// Блокирует выполнение, пока не будет отменен ctx.
+func SweepExpired(ctx context.Context, st Storager, interval time.Duration) {
+	if interval <= 0 {
+		zap.L().Warn("expired links sweep interval is not positive, skipping")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		count, err := st.PurgeExpired(ctx)
+		if err != nil {
+			zap.L().Error("unable to purge expired links", zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			zap.L().Info("purged expired links", zap.Int64("count", count))
+		}
+	}
+}
+
+// SweepDeleted - периодически безвозвратно удаляет ссылки, удаленные пользователями дольше retention назад.
+//
+// Блокирует выполнение, пока не будет отменен ctx. retention <= 0 - удаленные ссылки хранятся всегда.
+func SweepDeleted(ctx context.Context, st Storager, interval time.Duration, retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	if interval <= 0 {
+		zap.L().Warn("deleted links sweep interval is not positive, skipping")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		count, err := st.PurgeDeleted(ctx, time.Now().Add(-retention))
+		if err != nil {
+			zap.L().Error("unable to purge deleted links", zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			zap.L().Info("purged deleted links", zap.Int64("count", count))
+		}
+	}
+}
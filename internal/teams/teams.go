@@ -0,0 +1,335 @@
+// Package teams хранит управление командами пользователей и приглашениями в них.
+//
+// Приглашение сохраняется в хранилище, а приглашенному передается подписанный ключом сервера
+// токен с его ID. Приглашение можно принять один раз, владелец команды может отозвать его раньше срока.
+package teams
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+)
+
+// Ошибки управления командами.
+var (
+	ErrWrongName     = errors.New("team name must be 1 to 100 characters") // Пустое или слишком длинное название.
+	ErrWrongInvite   = errors.New("wrong invite")                          // Приглашение подделано или повреждено.
+	ErrInviteExpired = repositories.ErrInviteExpired                       // Срок действия приглашения истек.
+)
+
+// Ограничения команд и приглашений.
+const (
+	MaxNameLength    = 100                // Максимальная длина названия команды в символах.
+	DefaultInviteTTL = 7 * 24 * time.Hour // Срок действия приглашения по умолчанию.
+)
+
+// invitePrefix - отделяет подписи приглашений от подписей cookie, которые делаются тем же ключом.
+const invitePrefix = "team-invite:"
+
+// Store - хранилище команд.
+type Store interface {
+	CreateTeam(ctx context.Context, team repositories.Team, owner repositories.User) error
+	GetTeamRole(ctx context.Context, team repositories.TeamID, user repositories.User) (repositories.Role, error)
+	GetUserTeams(ctx context.Context, user repositories.User) ([]repositories.Membership, error)
+	GetTeamMembers(ctx context.Context, team repositories.TeamID) ([]repositories.TeamMember, error)
+	SetTeamMember(ctx context.Context, team repositories.TeamID, user repositories.User, role repositories.Role) error
+	RemoveTeamMember(ctx context.Context, team repositories.TeamID, user repositories.User) error
+	CreateInvite(ctx context.Context, invite repositories.TeamInvite) error
+	AcceptInvite(
+		ctx context.Context, id repositories.InviteID, user repositories.User, at time.Time,
+	) (repositories.TeamInvite, error)
+	RevokeInvite(ctx context.Context, id repositories.InviteID, team repositories.TeamID, at time.Time) error
+}
+
+// Invite - приглашение в команду.
+type Invite struct {
+	ExpiresAt time.Time             `json:"expires_at"` // Время, после которого приглашение перестает работать.
+	Token     string                `json:"token"`      // Токен, который передается приглашенному.
+	Role      repositories.Role     `json:"role"`       // Роль, которую получит приглашенный.
+	ID        repositories.InviteID `json:"id"`         // ID приглашения, по нему владелец может его отозвать.
+	Team      repositories.TeamID   `json:"team"`       // Команда.
+}
+
+// Service - управление командами с проверкой ролей пользователей.
+type Service struct {
+	st  Store
+	now func() time.Time
+	key []byte
+}
+
+// NewService - конструктор для Service, приглашения подписываются ключом cookie.
+func NewService(cfg configs.Config, st Store) *Service {
+	return &Service{
+		st:  st,
+		now: time.Now,
+		key: cfg.CookieKey,
+	}
+}
+
+// Create - создать команду, user становится ее владельцем.
+func (s *Service) Create(ctx context.Context, user repositories.User, name string) (repositories.Membership, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameLength {
+		return repositories.Membership{}, ErrWrongName
+	}
+
+	team := repositories.Team{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedAt: s.now().Round(0),
+	}
+
+	err := s.st.CreateTeam(ctx, team, user)
+	if err != nil {
+		return repositories.Membership{}, err
+	}
+
+	return repositories.Membership{Team: team, Role: repositories.RoleOwner}, nil
+}
+
+// List - команды, в которых состоит user.
+func (s *Service) List(ctx context.Context, user repositories.User) ([]repositories.Membership, error) {
+	return s.st.GetUserTeams(ctx, user)
+}
+
+// Members - участники команды, их видит любой участник.
+func (s *Service) Members(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+) ([]repositories.TeamMember, error) {
+	err := s.require(ctx, team, user, repositories.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.st.GetTeamMembers(ctx, team)
+}
+
+// Invite - создать приглашение в команду с ролью role, приглашать может только владелец.
+//
+// ttl <= 0 - срок действия DefaultInviteTTL.
+func (s *Service) Invite(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	role repositories.Role,
+	ttl time.Duration,
+) (Invite, error) {
+	role, err := repositories.ParseRole(string(role))
+	if err != nil {
+		return Invite{}, err
+	}
+
+	err = s.require(ctx, team, user, repositories.RoleOwner)
+	if err != nil {
+		return Invite{}, err
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultInviteTTL
+	}
+
+	now := s.now().Round(0)
+	invite := Invite{
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+		Role:      role,
+		ID:        uuid.New(),
+		Team:      team,
+	}
+
+	err = s.st.CreateInvite(ctx, repositories.TeamInvite{
+		CreatedAt: now,
+		ExpiresAt: invite.ExpiresAt,
+		ID:        invite.ID,
+		Team:      invite.Team,
+		Role:      invite.Role,
+	})
+	if err != nil {
+		return Invite{}, err
+	}
+
+	payload := fmt.Sprintf("%s:%s:%d:%s", invite.Team, invite.Role, invite.ExpiresAt.Unix(), invite.ID)
+	invite.Token = base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.sign(payload))
+
+	return invite, nil
+}
+
+// RevokeInvite - отозвать приглашение в команду, это может только владелец.
+//
+// Принятое приглашение отозвать нельзя, вернет repositories.ErrInviteUsed.
+func (s *Service) RevokeInvite(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	id repositories.InviteID,
+) error {
+	err := s.require(ctx, team, user, repositories.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return s.st.RevokeInvite(ctx, id, team, s.now().Round(0))
+}
+
+// Join - принять приглашение.
+//
+// Приглашение принимается один раз: повторный Join с тем же токеном вернет repositories.ErrInviteUsed,
+// в том числе после исключения пользователя из команды.
+// Если пользователь уже состоит в команде с той же или старшей ролью, его роль не меняется.
+func (s *Service) Join(ctx context.Context, token string, user repositories.User) (repositories.Membership, error) {
+	invite, err := s.parse(token)
+	if err != nil {
+		return repositories.Membership{}, err
+	}
+
+	_, err = s.st.AcceptInvite(ctx, invite.ID, user, s.now().Round(0))
+	if errors.Is(err, repositories.ErrInviteNotFound) {
+		return repositories.Membership{}, ErrWrongInvite
+	}
+	if err != nil {
+		return repositories.Membership{}, err
+	}
+
+	teams, err := s.st.GetUserTeams(ctx, user)
+	if err != nil {
+		return repositories.Membership{}, err
+	}
+	for _, m := range teams {
+		if m.ID == invite.Team {
+			return m, nil
+		}
+	}
+
+	return repositories.Membership{}, repositories.ErrTeamNotFound
+}
+
+// SetRole - изменить роль участника команды или добавить его, это может только владелец.
+func (s *Service) SetRole(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	member repositories.User,
+	role repositories.Role,
+) error {
+	role, err := repositories.ParseRole(string(role))
+	if err != nil {
+		return err
+	}
+
+	err = s.require(ctx, team, user, repositories.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	return s.st.SetTeamMember(ctx, team, member, role)
+}
+
+// Remove - исключить участника из команды.
+//
+// Исключать других может только владелец, выйти из команды может любой участник.
+// Ссылки команды при этом остаются у команды.
+func (s *Service) Remove(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	member repositories.User,
+) error {
+	need := repositories.RoleOwner
+	if member == user {
+		need = repositories.RoleViewer
+	}
+
+	err := s.require(ctx, team, user, need)
+	if err != nil {
+		return err
+	}
+
+	return s.st.RemoveTeamMember(ctx, team, member)
+}
+
+// require - проверить, что роль пользователя в команде не ниже need.
+func (s *Service) require(
+	ctx context.Context,
+	team repositories.TeamID,
+	user repositories.User,
+	need repositories.Role,
+) error {
+	role, err := s.st.GetTeamRole(ctx, team, user)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(need) {
+		return repositories.ErrForbidden
+	}
+	return nil
+}
+
+func (s *Service) sign(payload string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(invitePrefix + payload))
+	return h.Sum(nil)
+}
+
+// parse - проверить подпись и срок действия токена приглашения.
+func (s *Service) parse(token string) (invite Invite, err error) {
+	encodedPayload, encodedSign, ok := strings.Cut(token, ".")
+	if !ok {
+		return invite, ErrWrongInvite
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return invite, ErrWrongInvite
+	}
+	sign, err := base64.RawURLEncoding.DecodeString(encodedSign)
+	if err != nil {
+		return invite, ErrWrongInvite
+	}
+	if !hmac.Equal(sign, s.sign(string(payload))) {
+		return invite, ErrWrongInvite
+	}
+
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 4 {
+		return invite, ErrWrongInvite
+	}
+
+	invite.Team, err = uuid.Parse(fields[0])
+	if err != nil {
+		return invite, ErrWrongInvite
+	}
+	invite.Role, err = repositories.ParseRole(fields[1])
+	if err != nil {
+		return invite, ErrWrongInvite
+	}
+	expiresAt, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return invite, ErrWrongInvite
+	}
+	invite.ExpiresAt = time.Unix(expiresAt, 0)
+	invite.ID, err = uuid.Parse(fields[3])
+	if err != nil {
+		return invite, ErrWrongInvite
+	}
+
+	if !s.now().Before(invite.ExpiresAt) {
+		return invite, ErrInviteExpired
+	}
+
+	invite.Token = token
+	return invite, nil
+}
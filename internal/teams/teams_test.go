@@ -0,0 +1,142 @@
+package teams
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories"
+	"github.com/ImpressionableRaccoon/urlshortener/internal/repositories/memory"
+)
+
+func newService(t *testing.T) *Service {
+	st, err := memory.NewMemoryStorage()
+	require.NoError(t, err)
+
+	return NewService(configs.Config{CookieKey: []byte("0123456789abcdef")}, st)
+}
+
+func TestService_Invite(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+	owner, user := uuid.New(), uuid.New()
+
+	team, err := s.Create(ctx, owner, "  Marketing  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Marketing", team.Name)
+
+	_, err = s.Invite(ctx, team.ID, user, repositories.RoleViewer, 0)
+	assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+	_, err = s.Invite(ctx, team.ID, owner, "admin", 0)
+	assert.ErrorIs(t, err, repositories.ErrUnknownRole)
+
+	invite, err := s.Invite(ctx, team.ID, owner, repositories.RoleEditor, time.Hour)
+	require.NoError(t, err)
+
+	t.Run("forged", func(t *testing.T) {
+		payload, sign, _ := strings.Cut(invite.Token, ".")
+		_, err = s.Join(ctx, payload+"x."+sign, user)
+		assert.ErrorIs(t, err, ErrWrongInvite)
+		_, err = s.Join(ctx, "garbage", user)
+		assert.ErrorIs(t, err, ErrWrongInvite)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { s.now = time.Now }()
+
+		_, err = s.Join(ctx, invite.Token, user)
+		assert.ErrorIs(t, err, ErrInviteExpired)
+	})
+
+	m, err := s.Join(ctx, invite.Token, user)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.RoleEditor, m.Role)
+
+	viewerInvite, err := s.Invite(ctx, team.ID, owner, repositories.RoleViewer, 0)
+	require.NoError(t, err)
+	m, err = s.Join(ctx, viewerInvite.Token, user)
+	require.NoError(t, err)
+	assert.Equal(t, repositories.RoleEditor, m.Role, "joining does not downgrade")
+
+	_, err = s.Invite(ctx, team.ID, user, repositories.RoleOwner, 0)
+	assert.ErrorIs(t, err, repositories.ErrForbidden, "only owners invite")
+
+	t.Run("used", func(t *testing.T) {
+		_, err = s.Join(ctx, invite.Token, uuid.New())
+		assert.ErrorIs(t, err, repositories.ErrInviteUsed)
+	})
+
+	t.Run("removed member", func(t *testing.T) {
+		require.NoError(t, s.Remove(ctx, team.ID, owner, user))
+		_, err = s.Join(ctx, invite.Token, user)
+		assert.ErrorIs(t, err, repositories.ErrInviteUsed)
+		_, err = s.Members(ctx, team.ID, user)
+		assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		revoked, err := s.Invite(ctx, team.ID, owner, repositories.RoleViewer, 0)
+		require.NoError(t, err)
+
+		err = s.RevokeInvite(ctx, team.ID, user, revoked.ID)
+		assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+		require.NoError(t, s.RevokeInvite(ctx, team.ID, owner, revoked.ID))
+		require.NoError(t, s.RevokeInvite(ctx, team.ID, owner, revoked.ID), "revoking twice is fine")
+
+		_, err = s.Join(ctx, revoked.Token, user)
+		assert.ErrorIs(t, err, repositories.ErrInviteRevoked)
+
+		err = s.RevokeInvite(ctx, team.ID, owner, invite.ID)
+		assert.ErrorIs(t, err, repositories.ErrInviteUsed)
+		err = s.RevokeInvite(ctx, team.ID, owner, uuid.New())
+		assert.ErrorIs(t, err, repositories.ErrInviteNotFound)
+	})
+
+	t.Run("not stored", func(t *testing.T) {
+		other := newService(t)
+		otherTeam, err := other.Create(ctx, owner, "other")
+		require.NoError(t, err)
+		otherInvite, err := other.Invite(ctx, otherTeam.ID, owner, repositories.RoleViewer, 0)
+		require.NoError(t, err)
+
+		_, err = s.Join(ctx, otherInvite.Token, user)
+		assert.ErrorIs(t, err, ErrWrongInvite, "signed with the same key, but unknown to this storage")
+	})
+}
+
+func TestService_Members(t *testing.T) {
+	ctx := context.Background()
+	s := newService(t)
+	owner, editor := uuid.New(), uuid.New()
+
+	_, err := s.Create(ctx, owner, "")
+	assert.ErrorIs(t, err, ErrWrongName)
+
+	team, err := s.Create(ctx, owner, "team")
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetRole(ctx, team.ID, owner, editor, repositories.RoleEditor))
+	assert.ErrorIs(t, s.SetRole(ctx, team.ID, editor, editor, repositories.RoleOwner), repositories.ErrForbidden)
+	assert.ErrorIs(t, s.Remove(ctx, team.ID, editor, owner), repositories.ErrForbidden)
+	assert.ErrorIs(t, s.Remove(ctx, team.ID, owner, owner), repositories.ErrLastOwner)
+
+	members, err := s.Members(ctx, team.ID, editor)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	require.NoError(t, s.Remove(ctx, team.ID, editor, editor))
+	_, err = s.Members(ctx, team.ID, editor)
+	assert.ErrorIs(t, err, repositories.ErrTeamNotFound)
+
+	list, err := s.List(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, team.ID, list[0].ID)
+}
@@ -0,0 +1,90 @@
+// Package tracing настраивает трассировку OpenTelemetry и передачу контекста трассировки между сервисами.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+const instrumentationName = "github.com/ImpressionableRaccoon/urlshortener"
+
+// Экспортеры трассировки.
+const (
+	ExporterNone   = "none"   // Трассировка выключена, режим по умолчанию.
+	ExporterStdout = "stdout" // Спаны выводятся в stdout, удобно для проверки без коллектора.
+	ExporterOTLP   = "otlp"   // Спаны отправляются коллектору по OTLP/HTTP.
+)
+
+// ErrUnknownExporter - неизвестный экспортер трассировки.
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// Setup - настроить глобальный TracerProvider и пропагатор W3C Trace Context.
+//
+// Возвращает функцию, которая отправляет оставшиеся спаны и останавливает экспортер.
+func Setup(ctx context.Context, cfg configs.Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.TraceExporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpoint(cfg.TraceEndpoint),
+			otlptracehttp.WithInsecure(),
+		)
+	default:
+		return nil, ErrUnknownExporter
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName("urlshortener"),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+
+	return tp.Shutdown, nil
+}
+
+// Tracer - трассировщик сервиса из глобального TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start - начать спан, дочерний к спану из ctx.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End - завершить спан, отметив его ошибкой, если она есть.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
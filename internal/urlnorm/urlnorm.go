@@ -0,0 +1,268 @@
+// Package urlnorm хранит проверку и нормализацию URL перед сокращением.
+package urlnorm
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/idna"
+
+	"github.com/ImpressionableRaccoon/urlshortener/configs"
+)
+
+// Ошибки проверки URL.
+var (
+	ErrEmpty     = errors.New("url is empty")                  // После удаления пробелов URL пустой.
+	ErrTooLong   = errors.New("url is too long")               // URL длиннее допустимого.
+	ErrMalformed = errors.New("url is malformed")              // URL не удалось разобрать.
+	ErrScheme    = errors.New("url scheme is not allowed")     // Схема URL не входит в список разрешенных.
+	ErrHost      = errors.New("url host is missing or wrong")  // В URL нет хоста или его нельзя перевести в punycode.
+	ErrUserInfo  = errors.New("url contains user credentials") // URL содержит имя пользователя или пароль.
+	ErrSelfLink  = errors.New("url points to the shortener")   // URL ведет на сам сервис и создаст цикл переходов.
+)
+
+// defaultPorts - порты по умолчанию, которые убираются из URL.
+var defaultPorts = map[string]string{"http": "80", "https": "443"}
+
+// Normalizer - структура для проверки и нормализации URL.
+type Normalizer struct {
+	schemes       map[string]struct{}
+	selfHost      string
+	maxLength     int
+	stripFragment bool
+}
+
+// NewNormalizer - конструктор для Normalizer.
+//
+// Если список разрешенных схем пуст, разрешены http и https.
+func NewNormalizer(cfg configs.Config) Normalizer {
+	schemes := cfg.URLAllowedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+
+	n := Normalizer{
+		schemes:       make(map[string]struct{}, len(schemes)),
+		maxLength:     cfg.URLMaxLength,
+		stripFragment: cfg.URLStripFragment,
+	}
+
+	for _, scheme := range schemes {
+		n.schemes[strings.ToLower(strings.TrimSpace(scheme))] = struct{}{}
+	}
+
+	n.selfHost = selfHost(cfg.ServerBaseURL, cfg.EnableHTTPS)
+
+	return n
+}
+
+// Normalize - проверить URL и привести его к каноническому виду.
+//
+// Убирает пробелы по краям, приводит схему и хост к нижнему регистру,
+// переводит международные домены в punycode, IPv4-адреса в любой записи - в обычную,
+// убирает порт по умолчанию и, если это включено в конфигурации, фрагмент.
+func (n Normalizer) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", ErrEmpty
+	}
+	if n.maxLength > 0 && len(raw) > n.maxLength {
+		return "", ErrTooLong
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := n.schemes[u.Scheme]; !ok {
+		return "", ErrScheme
+	}
+	if u.User != nil {
+		return "", ErrUserInfo
+	}
+
+	u.Host, err = normalizeHost(u.Scheme, u.Host)
+	if err != nil {
+		return "", err
+	}
+	if n.selfHost != "" && u.Host == n.selfHost {
+		return "", ErrSelfLink
+	}
+
+	if n.stripFragment {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+
+	normalized := u.String()
+	if n.maxLength > 0 && len(normalized) > n.maxLength {
+		return "", ErrTooLong
+	}
+
+	return normalized, nil
+}
+
+// normalizeHost - привести хост к нижнему регистру и punycode и убрать порт по умолчанию для схемы.
+func normalizeHost(scheme, host string) (string, error) {
+	if host == "" {
+		return "", ErrHost
+	}
+
+	hostname, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		hostname, port = h, p
+	}
+	hostname = strings.TrimSuffix(strings.TrimPrefix(hostname, "["), "]")
+	if hostname == "" {
+		return "", ErrHost
+	}
+
+	ipv4, isIPv4, err := parseIPv4(hostname)
+	if err != nil {
+		return "", err
+	}
+
+	switch ip := net.ParseIP(hostname); {
+	case isIPv4:
+		hostname = ipv4.String()
+	case ip != nil:
+		hostname = ip.String()
+	default:
+		ascii, err := toASCII(hostname)
+		if err != nil {
+			return "", ErrHost
+		}
+		hostname = ascii
+	}
+
+	if port == defaultPorts[scheme] {
+		port = ""
+	}
+
+	if port != "" {
+		return net.JoinHostPort(hostname, port), nil
+	}
+	if strings.Contains(hostname, ":") {
+		return "[" + hostname + "]", nil
+	}
+	return hostname, nil
+}
+
+// parseIPv4 - разобрать IPv4-адрес так же, как это делают браузеры.
+//
+// Кроме обычной записи браузеры понимают части в восьмеричной и шестнадцатеричной записи
+// и адреса из меньшего числа частей: 2130706433, 0x7f.1 и 017700000001 - это 127.0.0.1.
+// Такие адреса нужно приводить к обычной записи, иначе их не узнают правила политики по подсетям.
+// Если хост - не IPv4-адрес, вернет ok == false. Если хост заканчивается числом,
+// но адрес неверный, вернет ErrHost: браузер такой URL не откроет.
+func parseIPv4(hostname string) (ip net.IP, ok bool, err error) {
+	if strings.Contains(hostname, ":") {
+		return nil, false, nil
+	}
+
+	parts := strings.Split(hostname, ".")
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+
+	last := parts[len(parts)-1]
+	if _, numeric := parseIPv4Part(last); !numeric && (last == "" || strings.Trim(last, "0123456789") != "") {
+		return nil, false, nil
+	}
+	if len(parts) > 4 {
+		return nil, false, ErrHost
+	}
+
+	var addr uint64
+	for i, part := range parts {
+		n, valid := parseIPv4Part(part)
+		if !valid || part == "" {
+			return nil, false, ErrHost
+		}
+
+		if i < len(parts)-1 {
+			if n > 255 {
+				return nil, false, ErrHost
+			}
+			addr |= n << (8 * (3 - i))
+			continue
+		}
+
+		if n >= 1<<(8*(5-len(parts))) {
+			return nil, false, ErrHost
+		}
+		addr |= n
+	}
+
+	return net.IPv4(byte(addr>>24), byte(addr>>16), byte(addr>>8), byte(addr)), true, nil
+}
+
+// parseIPv4Part - разобрать часть IPv4-адреса: десятичную, восьмеричную с ведущим 0
+// или шестнадцатеричную с префиксом 0x.
+func parseIPv4Part(part string) (n uint64, ok bool) {
+	base := 10
+	switch {
+	case len(part) >= 2 && (part[:2] == "0x" || part[:2] == "0X"):
+		part, base = part[2:], 16
+	case len(part) >= 2 && part[0] == '0':
+		part, base = part[1:], 8
+	}
+	if part == "" {
+		return 0, true
+	}
+
+	n, err := strconv.ParseUint(part, base, 32)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// toASCII - перевести доменное имя в punycode в нижнем регистре.
+func toASCII(hostname string) (string, error) {
+	hostname = strings.TrimSuffix(hostname, ".")
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "" {
+			return "", ErrHost
+		}
+	}
+
+	ascii, err := idna.Lookup.ToASCII(hostname)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(ascii), nil
+}
+
+// selfHost - хост сервиса в нормализованном виде.
+//
+// В режиме HTTPS ServerBaseURL хранит только доменное имя.
+func selfHost(baseURL string, https bool) string {
+	if baseURL == "" {
+		return ""
+	}
+
+	scheme := "http"
+	if https {
+		scheme = "https"
+	}
+	if !strings.Contains(baseURL, "://") {
+		baseURL = scheme + "://" + baseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+
+	host, err := normalizeHost(strings.ToLower(u.Scheme), u.Host)
+	if err != nil {
+		return ""
+	}
+	return host
+}
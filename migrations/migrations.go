@@ -0,0 +1,9 @@
+// Package migrations содержит SQL-миграции схемы хранилищ, встроенные в бинарник.
+package migrations
+
+import "embed"
+
+// Postgres - миграции для хранилища Postgres, лежат в каталоге postgres.
+//
+//go:embed postgres/*.sql
+var Postgres embed.FS
@@ -0,0 +1,3 @@
+ALTER TABLE links DROP COLUMN hits;
+ALTER TABLE links DROP COLUMN max_hits;
+ALTER TABLE links DROP COLUMN expires_at;
\ No newline at end of file
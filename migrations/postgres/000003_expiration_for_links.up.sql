@@ -0,0 +1,3 @@
+ALTER TABLE links ADD COLUMN expires_at TIMESTAMPTZ;
+ALTER TABLE links ADD COLUMN max_hits BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN hits BIGINT NOT NULL DEFAULT 0;
\ No newline at end of file
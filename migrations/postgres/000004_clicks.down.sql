@@ -0,0 +1 @@
+DROP TABLE clicks;
\ No newline at end of file
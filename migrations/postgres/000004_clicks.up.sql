@@ -0,0 +1,9 @@
+CREATE TABLE clicks
+(
+    link_id    varchar(255) NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    clicked_at TIMESTAMPTZ  NOT NULL,
+    referrer   TEXT         NOT NULL DEFAULT '',
+    user_agent TEXT         NOT NULL DEFAULT '',
+    ip         varchar(45)  NOT NULL DEFAULT ''
+);
+CREATE INDEX clicks_link_id_idx ON clicks (link_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE pending_deletes;
\ No newline at end of file
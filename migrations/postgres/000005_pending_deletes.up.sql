@@ -0,0 +1,10 @@
+CREATE TABLE pending_deletes
+(
+    request_id uuid         NOT NULL,
+    link_id    varchar(255) NOT NULL,
+    user_id    uuid         NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    applied_at TIMESTAMPTZ
+);
+CREATE INDEX pending_deletes_request_id_idx ON pending_deletes (request_id);
+CREATE INDEX pending_deletes_pending_idx ON pending_deletes (created_at) WHERE applied_at IS NULL;
\ No newline at end of file
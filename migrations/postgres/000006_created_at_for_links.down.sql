@@ -0,0 +1,2 @@
+DROP INDEX links_user_id_created_at_idx;
+ALTER TABLE links DROP COLUMN created_at;
\ No newline at end of file
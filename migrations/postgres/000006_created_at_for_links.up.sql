@@ -0,0 +1,2 @@
+ALTER TABLE links ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX links_user_id_created_at_idx ON links (user_id, created_at, id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX links_dedup_key_idx;
+ALTER TABLE links DROP COLUMN dedup_key;
+ALTER TABLE links ADD CONSTRAINT links_url_key UNIQUE (url);
\ No newline at end of file
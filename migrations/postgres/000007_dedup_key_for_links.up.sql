@@ -0,0 +1,4 @@
+ALTER TABLE links ADD COLUMN dedup_key TEXT;
+UPDATE links SET dedup_key = url;
+ALTER TABLE links DROP CONSTRAINT links_url_key;
+CREATE UNIQUE INDEX links_dedup_key_idx ON links (dedup_key);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP INDEX links_team_id_created_at_idx;
+ALTER TABLE links DROP COLUMN team_id;
+DROP TABLE team_members;
+DROP TABLE teams;
\ No newline at end of file
@@ -0,0 +1,16 @@
+CREATE TABLE teams
+(
+    id         uuid        NOT NULL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE TABLE team_members
+(
+    team_id uuid NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id uuid NOT NULL,
+    role    TEXT NOT NULL,
+    PRIMARY KEY (team_id, user_id)
+);
+CREATE INDEX team_members_user_id_idx ON team_members (user_id);
+ALTER TABLE links ADD COLUMN team_id uuid;
+CREATE INDEX links_team_id_created_at_idx ON links (team_id, created_at, id);
\ No newline at end of file
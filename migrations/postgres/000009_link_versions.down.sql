@@ -0,0 +1 @@
+DROP TABLE link_versions;
\ No newline at end of file
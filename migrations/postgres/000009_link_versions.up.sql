@@ -0,0 +1,9 @@
+CREATE TABLE link_versions
+(
+    id          BIGSERIAL    NOT NULL PRIMARY KEY,
+    link_id     varchar(255) NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+    url         TEXT         NOT NULL,
+    user_id     uuid         NOT NULL,
+    replaced_at TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+CREATE INDEX link_versions_link_id_idx ON link_versions (link_id, id);
\ No newline at end of file
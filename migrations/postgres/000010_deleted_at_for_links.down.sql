@@ -0,0 +1,2 @@
+DROP INDEX links_deleted_at_idx;
+ALTER TABLE links DROP COLUMN deleted_at;
\ No newline at end of file
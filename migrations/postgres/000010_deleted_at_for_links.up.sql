@@ -0,0 +1,3 @@
+ALTER TABLE links ADD COLUMN deleted_at TIMESTAMPTZ;
+UPDATE links SET deleted_at = now() WHERE deleted;
+CREATE INDEX links_deleted_at_idx ON links (deleted_at) WHERE deleted;
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE accounts
+(
+    id            uuid        NOT NULL PRIMARY KEY,
+    login         TEXT        NOT NULL UNIQUE,
+    password_hash BYTEA       NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE TABLE api_keys
+(
+    id         uuid        NOT NULL PRIMARY KEY,
+    account_id uuid        NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    prefix     TEXT        NOT NULL,
+    hash       BYTEA       NOT NULL UNIQUE,
+    scope      TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ
+);
+CREATE INDEX api_keys_account_id_created_at_idx ON api_keys (account_id, created_at);
\ No newline at end of file
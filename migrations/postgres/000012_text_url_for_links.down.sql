@@ -0,0 +1 @@
+ALTER TABLE links ALTER COLUMN url TYPE varchar(255);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE links ALTER COLUMN url TYPE TEXT;
\ No newline at end of file
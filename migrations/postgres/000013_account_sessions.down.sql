@@ -0,0 +1 @@
+ALTER TABLE accounts DROP COLUMN sessions_revoked_at;
\ No newline at end of file
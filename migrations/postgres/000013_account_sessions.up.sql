@@ -0,0 +1 @@
+ALTER TABLE accounts ADD COLUMN sessions_revoked_at TIMESTAMPTZ;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE meta;
\ No newline at end of file
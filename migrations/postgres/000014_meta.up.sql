@@ -0,0 +1,5 @@
+CREATE TABLE meta
+(
+    key   TEXT NOT NULL PRIMARY KEY,
+    value TEXT NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE team_invites;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE team_invites
+(
+    id         uuid        NOT NULL PRIMARY KEY,
+    team_id    uuid        NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    role       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    used_by    uuid,
+    revoked_at TIMESTAMPTZ
+);
+CREATE INDEX team_invites_team_id_idx ON team_invites (team_id);
\ No newline at end of file
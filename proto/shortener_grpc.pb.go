@@ -21,13 +21,21 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	Shortener_Ping_FullMethodName       = "/urlshortener.Shortener/Ping"
-	Shortener_Short_FullMethodName      = "/urlshortener.Shortener/Short"
-	Shortener_Get_FullMethodName        = "/urlshortener.Shortener/Get"
-	Shortener_GetLinks_FullMethodName   = "/urlshortener.Shortener/GetLinks"
-	Shortener_BatchShort_FullMethodName = "/urlshortener.Shortener/BatchShort"
-	Shortener_Delete_FullMethodName     = "/urlshortener.Shortener/Delete"
-	Shortener_GetStats_FullMethodName   = "/urlshortener.Shortener/GetStats"
+	Shortener_Ping_FullMethodName              = "/urlshortener.Shortener/Ping"
+	Shortener_Short_FullMethodName             = "/urlshortener.Shortener/Short"
+	Shortener_Get_FullMethodName               = "/urlshortener.Shortener/Get"
+	Shortener_GetLinks_FullMethodName          = "/urlshortener.Shortener/GetLinks"
+	Shortener_BatchShort_FullMethodName        = "/urlshortener.Shortener/BatchShort"
+	Shortener_StreamShort_FullMethodName       = "/urlshortener.Shortener/StreamShort"
+	Shortener_Delete_FullMethodName            = "/urlshortener.Shortener/Delete"
+	Shortener_GetDeletionStatus_FullMethodName = "/urlshortener.Shortener/GetDeletionStatus"
+	Shortener_GetStats_FullMethodName          = "/urlshortener.Shortener/GetStats"
+	Shortener_GetLinkStats_FullMethodName      = "/urlshortener.Shortener/GetLinkStats"
+	Shortener_Export_FullMethodName            = "/urlshortener.Shortener/Export"
+	Shortener_Import_FullMethodName            = "/urlshortener.Shortener/Import"
+	Shortener_GetQuota_FullMethodName          = "/urlshortener.Shortener/GetQuota"
+	Shortener_Transfer_FullMethodName          = "/urlshortener.Shortener/Transfer"
+	Shortener_Update_FullMethodName            = "/urlshortener.Shortener/Update"
 )
 
 // ShortenerClient is the client API for Shortener service.
@@ -37,10 +45,18 @@ type ShortenerClient interface {
 	Ping(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	Short(ctx context.Context, in *ShortRequest, opts ...grpc.CallOption) (*ShortResponse, error)
 	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
-	GetLinks(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetLinksResponse, error)
+	GetLinks(ctx context.Context, in *GetLinksRequest, opts ...grpc.CallOption) (*GetLinksResponse, error)
 	BatchShort(ctx context.Context, in *BatchShortRequest, opts ...grpc.CallOption) (*BatchShortResponse, error)
-	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	StreamShort(ctx context.Context, opts ...grpc.CallOption) (Shortener_StreamShortClient, error)
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	GetDeletionStatus(ctx context.Context, in *GetDeletionStatusRequest, opts ...grpc.CallOption) (*GetDeletionStatusResponse, error)
 	GetStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	GetLinkStats(ctx context.Context, in *GetLinkStatsRequest, opts ...grpc.CallOption) (*GetLinkStatsResponse, error)
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Shortener_ExportClient, error)
+	Import(ctx context.Context, opts ...grpc.CallOption) (Shortener_ImportClient, error)
+	GetQuota(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetQuotaResponse, error)
+	Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error)
 }
 
 type shortenerClient struct {
@@ -78,7 +94,7 @@ func (c *shortenerClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.
 	return out, nil
 }
 
-func (c *shortenerClient) GetLinks(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetLinksResponse, error) {
+func (c *shortenerClient) GetLinks(ctx context.Context, in *GetLinksRequest, opts ...grpc.CallOption) (*GetLinksResponse, error) {
 	out := new(GetLinksResponse)
 	err := c.cc.Invoke(ctx, Shortener_GetLinks_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -96,8 +112,39 @@ func (c *shortenerClient) BatchShort(ctx context.Context, in *BatchShortRequest,
 	return out, nil
 }
 
-func (c *shortenerClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *shortenerClient) StreamShort(ctx context.Context, opts ...grpc.CallOption) (Shortener_StreamShortClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[0], Shortener_StreamShort_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortenerStreamShortClient{stream}
+	return x, nil
+}
+
+type Shortener_StreamShortClient interface {
+	Send(*BatchShortRequest_Link) error
+	Recv() (*BatchShortResponse_Link, error)
+	grpc.ClientStream
+}
+
+type shortenerStreamShortClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortenerStreamShortClient) Send(m *BatchShortRequest_Link) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *shortenerStreamShortClient) Recv() (*BatchShortResponse_Link, error) {
+	m := new(BatchShortResponse_Link)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shortenerClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
 	err := c.cc.Invoke(ctx, Shortener_Delete_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -105,6 +152,15 @@ func (c *shortenerClient) Delete(ctx context.Context, in *DeleteRequest, opts ..
 	return out, nil
 }
 
+func (c *shortenerClient) GetDeletionStatus(ctx context.Context, in *GetDeletionStatusRequest, opts ...grpc.CallOption) (*GetDeletionStatusResponse, error) {
+	out := new(GetDeletionStatusResponse)
+	err := c.cc.Invoke(ctx, Shortener_GetDeletionStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *shortenerClient) GetStats(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetStatsResponse, error) {
 	out := new(GetStatsResponse)
 	err := c.cc.Invoke(ctx, Shortener_GetStats_FullMethodName, in, out, opts...)
@@ -114,6 +170,108 @@ func (c *shortenerClient) GetStats(ctx context.Context, in *emptypb.Empty, opts
 	return out, nil
 }
 
+func (c *shortenerClient) GetLinkStats(ctx context.Context, in *GetLinkStatsRequest, opts ...grpc.CallOption) (*GetLinkStatsResponse, error) {
+	out := new(GetLinkStatsResponse)
+	err := c.cc.Invoke(ctx, Shortener_GetLinkStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (Shortener_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[1], Shortener_Export_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortenerExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Shortener_ExportClient interface {
+	Recv() (*ExportedLink, error)
+	grpc.ClientStream
+}
+
+type shortenerExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortenerExportClient) Recv() (*ExportedLink, error) {
+	m := new(ExportedLink)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shortenerClient) Import(ctx context.Context, opts ...grpc.CallOption) (Shortener_ImportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Shortener_ServiceDesc.Streams[2], Shortener_Import_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortenerImportClient{stream}
+	return x, nil
+}
+
+type Shortener_ImportClient interface {
+	Send(*ImportRequest) error
+	CloseAndRecv() (*ImportResponse, error)
+	grpc.ClientStream
+}
+
+type shortenerImportClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortenerImportClient) Send(m *ImportRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *shortenerImportClient) CloseAndRecv() (*ImportResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shortenerClient) GetQuota(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetQuotaResponse, error) {
+	out := new(GetQuotaResponse)
+	err := c.cc.Invoke(ctx, Shortener_GetQuota_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Shortener_Transfer_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortenerClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*UpdateResponse, error) {
+	out := new(UpdateResponse)
+	err := c.cc.Invoke(ctx, Shortener_Update_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortenerServer is the server API for Shortener service.
 // All implementations must embed UnimplementedShortenerServer
 // for forward compatibility
@@ -121,10 +279,18 @@ type ShortenerServer interface {
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	Short(context.Context, *ShortRequest) (*ShortResponse, error)
 	Get(context.Context, *GetRequest) (*GetResponse, error)
-	GetLinks(context.Context, *emptypb.Empty) (*GetLinksResponse, error)
+	GetLinks(context.Context, *GetLinksRequest) (*GetLinksResponse, error)
 	BatchShort(context.Context, *BatchShortRequest) (*BatchShortResponse, error)
-	Delete(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	StreamShort(Shortener_StreamShortServer) error
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	GetDeletionStatus(context.Context, *GetDeletionStatusRequest) (*GetDeletionStatusResponse, error)
 	GetStats(context.Context, *emptypb.Empty) (*GetStatsResponse, error)
+	GetLinkStats(context.Context, *GetLinkStatsRequest) (*GetLinkStatsResponse, error)
+	Export(*ExportRequest, Shortener_ExportServer) error
+	Import(Shortener_ImportServer) error
+	GetQuota(context.Context, *emptypb.Empty) (*GetQuotaResponse, error)
+	Transfer(context.Context, *TransferRequest) (*emptypb.Empty, error)
+	Update(context.Context, *UpdateRequest) (*UpdateResponse, error)
 	mustEmbedUnimplementedShortenerServer()
 }
 
@@ -141,18 +307,42 @@ func (UnimplementedShortenerServer) Short(context.Context, *ShortRequest) (*Shor
 func (UnimplementedShortenerServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
-func (UnimplementedShortenerServer) GetLinks(context.Context, *emptypb.Empty) (*GetLinksResponse, error) {
+func (UnimplementedShortenerServer) GetLinks(context.Context, *GetLinksRequest) (*GetLinksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLinks not implemented")
 }
 func (UnimplementedShortenerServer) BatchShort(context.Context, *BatchShortRequest) (*BatchShortResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchShort not implemented")
 }
-func (UnimplementedShortenerServer) Delete(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+func (UnimplementedShortenerServer) StreamShort(Shortener_StreamShortServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamShort not implemented")
+}
+func (UnimplementedShortenerServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedShortenerServer) GetDeletionStatus(context.Context, *GetDeletionStatusRequest) (*GetDeletionStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeletionStatus not implemented")
+}
 func (UnimplementedShortenerServer) GetStats(context.Context, *emptypb.Empty) (*GetStatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
+func (UnimplementedShortenerServer) GetLinkStats(context.Context, *GetLinkStatsRequest) (*GetLinkStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLinkStats not implemented")
+}
+func (UnimplementedShortenerServer) Export(*ExportRequest, Shortener_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedShortenerServer) Import(Shortener_ImportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedShortenerServer) GetQuota(context.Context, *emptypb.Empty) (*GetQuotaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuota not implemented")
+}
+func (UnimplementedShortenerServer) Transfer(context.Context, *TransferRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transfer not implemented")
+}
+func (UnimplementedShortenerServer) Update(context.Context, *UpdateRequest) (*UpdateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
 func (UnimplementedShortenerServer) mustEmbedUnimplementedShortenerServer() {}
 
 // UnsafeShortenerServer may be embedded to opt out of forward compatibility for this service.
@@ -221,7 +411,7 @@ func _Shortener_Get_Handler(srv interface{}, ctx context.Context, dec func(inter
 }
 
 func _Shortener_GetLinks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(emptypb.Empty)
+	in := new(GetLinksRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -233,7 +423,7 @@ func _Shortener_GetLinks_Handler(srv interface{}, ctx context.Context, dec func(
 		FullMethod: Shortener_GetLinks_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ShortenerServer).GetLinks(ctx, req.(*emptypb.Empty))
+		return srv.(ShortenerServer).GetLinks(ctx, req.(*GetLinksRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -256,6 +446,32 @@ func _Shortener_BatchShort_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Shortener_StreamShort_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShortenerServer).StreamShort(&shortenerStreamShortServer{stream})
+}
+
+type Shortener_StreamShortServer interface {
+	Send(*BatchShortResponse_Link) error
+	Recv() (*BatchShortRequest_Link, error)
+	grpc.ServerStream
+}
+
+type shortenerStreamShortServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortenerStreamShortServer) Send(m *BatchShortResponse_Link) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *shortenerStreamShortServer) Recv() (*BatchShortRequest_Link, error) {
+	m := new(BatchShortRequest_Link)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Shortener_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteRequest)
 	if err := dec(in); err != nil {
@@ -274,6 +490,24 @@ func _Shortener_Delete_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Shortener_GetDeletionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeletionStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).GetDeletionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_GetDeletionStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).GetDeletionStatus(ctx, req.(*GetDeletionStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Shortener_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -292,6 +526,125 @@ func _Shortener_GetStats_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Shortener_GetLinkStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLinkStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).GetLinkStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_GetLinkStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).GetLinkStats(ctx, req.(*GetLinkStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortenerServer).Export(m, &shortenerExportServer{stream})
+}
+
+type Shortener_ExportServer interface {
+	Send(*ExportedLink) error
+	grpc.ServerStream
+}
+
+type shortenerExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortenerExportServer) Send(m *ExportedLink) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Shortener_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShortenerServer).Import(&shortenerImportServer{stream})
+}
+
+type Shortener_ImportServer interface {
+	SendAndClose(*ImportResponse) error
+	Recv() (*ImportRequest, error)
+	grpc.ServerStream
+}
+
+type shortenerImportServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortenerImportServer) SendAndClose(m *ImportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *shortenerImportServer) Recv() (*ImportRequest, error) {
+	m := new(ImportRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Shortener_GetQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).GetQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_GetQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).GetQuota(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_Transfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).Transfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_Transfer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).Transfer(ctx, req.(*TransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shortener_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortenerServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Shortener_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortenerServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Shortener_ServiceDesc is the grpc.ServiceDesc for Shortener service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -323,11 +676,48 @@ var Shortener_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _Shortener_Delete_Handler,
 		},
+		{
+			MethodName: "GetDeletionStatus",
+			Handler:    _Shortener_GetDeletionStatus_Handler,
+		},
 		{
 			MethodName: "GetStats",
 			Handler:    _Shortener_GetStats_Handler,
 		},
+		{
+			MethodName: "GetLinkStats",
+			Handler:    _Shortener_GetLinkStats_Handler,
+		},
+		{
+			MethodName: "GetQuota",
+			Handler:    _Shortener_GetQuota_Handler,
+		},
+		{
+			MethodName: "Transfer",
+			Handler:    _Shortener_Transfer_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _Shortener_Update_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamShort",
+			Handler:       _Shortener_StreamShort_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Export",
+			Handler:       _Shortener_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Import",
+			Handler:       _Shortener_Import_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/shortener.proto",
 }